@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"GoMusic/common/event"
+	"GoMusic/common/local"
+	"GoMusic/handler"
+	"GoMusic/initialize"
+	"GoMusic/initialize/config"
+	"GoMusic/initialize/log"
+	"GoMusic/logic"
+	"GoMusic/repo/db"
+)
+
+// 退出码；日志统一输出至 stderr，stdout 只输出命令结果，便于脚本解析
+const (
+	exitOK    = 0
+	exitError = 1 // 执行失败
+	exitUsage = 2 // 参数错误
+)
+
+// shutdownTimeout 退出时等待进行中的请求与事件投递完成的最长时间
+const shutdownTimeout = 10 * time.Second
+
+const usage = `Usage: GoMusic [command] [flags]
+
+Commands:
+  serve                 启动服务（默认）
+  migrate               执行数据库迁移后退出
+  compare               对比歌单与本地音乐目录，列出本地没有的歌曲
+  mpd                   按本地音乐目录生成 MPD 播放列表
+  completion <shell>    输出 bash、zsh 或 fish 的补全脚本
+
+Flags for migrate:
+  --json                以 JSON 输出结果
+  --quiet               成功时不输出任何内容
+
+Flags for compare:
+  --url                 歌单链接
+  --dir                 本地音乐目录
+  --json                以 JSON 输出结果
+
+Flags for mpd:
+  --url                 歌单链接
+  --dir                 本地音乐目录，即 MPD 的 music_directory
+  --map                 路径映射 from=to，以逗号分隔，默认读取 GOMUSIC_MPD_PATH_MAP
+`
+
+func run(args []string) int {
+	command := "serve"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+	switch command {
+	case "serve":
+		return serve()
+	case "migrate":
+		return migrate(args)
+	case "compare":
+		return compare(args)
+	case "mpd":
+		return mpd(args)
+	case "completion":
+		return completion(args)
+	case "help", "-h", "--help":
+		fmt.Fprint(os.Stdout, usage)
+		return exitOK
+	}
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", command, usage)
+	return exitUsage
+}
+
+func serve() int {
+	if err := db.Open(); err != nil {
+		return exitError
+	}
+	logic.StartRetention()
+	logic.StartHealthCheck()
+	logic.StartJobWorkers()
+	server := &http.Server{Addr: fmt.Sprintf(":%d", config.Conf.Port), Handler: initialize.NewRouter()}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errs := make(chan error, 1)
+	go func() { errs <- server.ListenAndServe() }()
+	select {
+	case err := <-errs:
+		log.Errorf("fail to run server: %v", err)
+		return exitError
+	case <-ctx.Done():
+	}
+	// 收到退出信号后不再接受新请求，等待进行中的请求完成
+	shutdown, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdown); err != nil {
+		log.Errorf("fail to shutdown server: %v", err)
+	}
+	waitEvents()
+	return exitOK
+}
+
+// waitEvents 退出前等待已发布的事件投递完成，避免丢失 webhook 等通知
+func waitEvents() {
+	if !event.Wait(shutdownTimeout) {
+		log.Warnf("event subscribers did not finish within %v", shutdownTimeout)
+	}
+}
+
+type migrateResult struct {
+	Applied []int  `json:"applied"`
+	Version int    `json:"version"`
+	Error   string `json:"error,omitempty"`
+}
+
+// migrate 执行数据库迁移后退出，用于关闭 GOMUSIC_AUTO_MIGRATE 的部署
+func migrate(args []string) int {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "以 JSON 输出结果")
+	quiet := flags.Bool("quiet", false, "成功时不输出任何内容")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	// 由 migrate 命令显式执行，连接时不再自动迁移
+	config.Conf.AutoMigrate = false
+
+	result := &migrateResult{Applied: make([]int, 0)}
+	err := db.Open()
+	if err == nil {
+		result.Applied, err = db.Migrate()
+	}
+	if err == nil {
+		result.Version, err = db.MigrationVersion()
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	switch {
+	case *asJSON:
+		_ = json.NewEncoder(os.Stdout).Encode(result)
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "migrate failed: %v\n", err)
+	case !*quiet:
+		fmt.Fprintf(os.Stdout, "applied %d migration(s), schema version %d\n", len(result.Applied), result.Version)
+	}
+	if err != nil {
+		return exitError
+	}
+	return exitOK
+}
+
+// compare 对比歌单与本地音乐目录，每行输出一首本地没有的歌曲
+func compare(args []string) int {
+	flags := flag.NewFlagSet("compare", flag.ContinueOnError)
+	link := flags.String("url", "", "歌单链接")
+	dir := flags.String("dir", "", "本地音乐目录")
+	asJSON := flags.Bool("json", false, "以 JSON 输出结果")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *link == "" || *dir == "" {
+		fmt.Fprint(os.Stderr, "usage: GoMusic compare --url <playlist> --dir <folder> [--json]\n")
+		return exitUsage
+	}
+
+	tracks, err := local.Scan(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scan failed: %v\n", err)
+		return exitError
+	}
+	if err = db.Open(); err != nil {
+		return exitError
+	}
+	// Ctrl-C 时中止进行中的上游请求
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	defer waitEvents()
+	songList, err := handler.Discover(ctx, *link)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "discover failed: %v\n", err)
+		return exitError
+	}
+
+	result := local.Compare(songList.Songs, tracks)
+	if *asJSON {
+		_ = json.NewEncoder(os.Stdout).Encode(result)
+		return exitOK
+	}
+	for _, v := range result.Missing {
+		fmt.Fprintln(os.Stdout, v)
+	}
+	fmt.Fprintf(os.Stderr, "%d/%d songs found in %d local files\n", result.Found, result.Total, len(tracks))
+	return exitOK
+}
+
+// mpd 将歌单中本地已有的歌曲按顺序输出为 MPD 播放列表，保存至 playlist_directory 即可在 MPD 中播放
+func mpd(args []string) int {
+	flags := flag.NewFlagSet("mpd", flag.ContinueOnError)
+	link := flags.String("url", "", "歌单链接")
+	dir := flags.String("dir", "", "本地音乐目录")
+	mapping := flags.String("map", strings.Join(config.Conf.MPDPathMap, ","), "路径映射 from=to")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *link == "" || *dir == "" {
+		fmt.Fprint(os.Stderr, "usage: GoMusic mpd --url <playlist> --dir <music_directory> [--map from=to,...] > playlist.m3u\n")
+		return exitUsage
+	}
+
+	tracks, err := local.Scan(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scan failed: %v\n", err)
+		return exitError
+	}
+	if err = db.Open(); err != nil {
+		return exitError
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	defer waitEvents()
+	songList, err := handler.Discover(ctx, *link)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "discover failed: %v\n", err)
+		return exitError
+	}
+
+	result := local.Compare(songList.Songs, tracks)
+	count, err := local.WriteMPDPlaylist(os.Stdout, result.Pairs, *dir, local.ParsePathMappings(strings.Split(*mapping, ",")))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "write failed: %v\n", err)
+		return exitError
+	}
+	fmt.Fprintf(os.Stderr, "%d/%d songs written, %d not found locally\n", count, result.Total, len(result.Missing))
+	return exitOK
+}
+
+func completion(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprint(os.Stderr, "usage: GoMusic completion bash|zsh|fish\n")
+		return exitUsage
+	}
+	script, ok := completionScripts[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unsupported shell %q, expected bash, zsh or fish\n", args[0])
+		return exitUsage
+	}
+	fmt.Fprint(os.Stdout, script)
+	return exitOK
+}
+
+var completionScripts = map[string]string{
+	"bash": `_gomusic() {
+  local cur=${COMP_WORDS[COMP_CWORD]}
+  case ${COMP_WORDS[1]} in
+    migrate) COMPREPLY=($(compgen -W "--json --quiet" -- "$cur")) ;;
+    compare) COMPREPLY=($(compgen -W "--url --dir --json" -- "$cur")) ;;
+    mpd) COMPREPLY=($(compgen -W "--url --dir --map" -- "$cur")) ;;
+    completion) COMPREPLY=($(compgen -W "bash zsh fish" -- "$cur")) ;;
+    *) COMPREPLY=($(compgen -W "serve migrate compare mpd completion help" -- "$cur")) ;;
+  esac
+}
+complete -F _gomusic GoMusic
+`,
+	"zsh": `#compdef GoMusic
+_gomusic() {
+  case $words[2] in
+    migrate) _arguments '--json[以 JSON 输出结果]' '--quiet[成功时不输出任何内容]' ;;
+    compare) _arguments '--url[歌单链接]:url' '--dir[本地音乐目录]:dir:_files -/' '--json[以 JSON 输出结果]' ;;
+    mpd) _arguments '--url[歌单链接]:url' '--dir[本地音乐目录]:dir:_files -/' '--map[路径映射]:map' ;;
+    completion) _values shell bash zsh fish ;;
+    *) _values command serve migrate compare mpd completion help ;;
+  esac
+}
+compdef _gomusic GoMusic
+`,
+	"fish": `complete -c GoMusic -f -n __fish_use_subcommand -a "serve migrate compare mpd completion help"
+complete -c GoMusic -f -n "__fish_seen_subcommand_from migrate" -l json -d "以 JSON 输出结果"
+complete -c GoMusic -f -n "__fish_seen_subcommand_from migrate" -l quiet -d "成功时不输出任何内容"
+complete -c GoMusic -f -n "__fish_seen_subcommand_from compare" -l url -d "歌单链接"
+complete -c GoMusic -n "__fish_seen_subcommand_from compare" -l dir -d "本地音乐目录"
+complete -c GoMusic -f -n "__fish_seen_subcommand_from compare" -l json -d "以 JSON 输出结果"
+complete -c GoMusic -f -n "__fish_seen_subcommand_from mpd" -l url -d "歌单链接"
+complete -c GoMusic -n "__fish_seen_subcommand_from mpd" -l dir -d "本地音乐目录"
+complete -c GoMusic -f -n "__fish_seen_subcommand_from mpd" -l map -d "路径映射 from=to"
+complete -c GoMusic -f -n "__fish_seen_subcommand_from completion" -a "bash zsh fish"
+`,
+}
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunUsage(t *testing.T) {
+	assert.Equal(t, exitOK, run([]string{"help"}))
+	assert.Equal(t, exitUsage, run([]string{"unknown"}))
+	assert.Equal(t, exitOK, run([]string{"completion", "bash"}))
+	assert.Equal(t, exitUsage, run([]string{"completion", "powershell"}))
+	assert.Equal(t, exitUsage, run([]string{"migrate", "--bogus"}))
+	assert.Equal(t, exitUsage, run([]string{"compare", "--url", "https://music.163.com/playlist?id=1"}))
+	assert.Equal(t, exitUsage, run([]string{"mpd", "--dir", "/music"}))
+}
@@ -0,0 +1,129 @@
+// Package event 进程内的生命周期事件总线：核心逻辑只发布事件，webhook、指标、审计日志等通知渠道以订阅者接入
+package event
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"GoMusic/initialize/log"
+)
+
+type Type string
+
+const (
+	// DiscoveryCompleted 获取歌单完成，Data 为 *models.DiscoveryResult
+	DiscoveryCompleted Type = "discovery.completed"
+	// TransferFinished 导入目标平台完成，Data 为 *models.ExportReport
+	TransferFinished Type = "transfer.finished"
+	// SyncDiffDetected 订阅歌单检查发现变化，Data 为 *models.HealthReport 或 *models.ReleaseReport
+	SyncDiffDetected Type = "sync.diff_detected"
+)
+
+// deliverTimeout 单个订阅者处理一个事件的最长时间
+const deliverTimeout = 30 * time.Second
+
+type Event struct {
+	Type      Type      `json:"type"`
+	Time      time.Time `json:"time"`
+	RequestId string    `json:"request_id,omitempty"`
+	Data      any       `json:"data"`
+}
+
+// Handler 处理事件，ctx 与发布事件的请求无关，只带有请求 id 等日志字段
+type Handler func(ctx context.Context, e *Event)
+
+type subscriber struct {
+	name   string
+	types  map[Type]bool
+	handle Handler
+}
+
+// Bus 事件总线，每个订阅者在单独的 goroutine 中处理事件，慢订阅者不阻塞发布方与其他订阅者
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers []*subscriber
+	wg          sync.WaitGroup
+}
+
+func New() *Bus {
+	return &Bus{}
+}
+
+// Subscribe 订阅事件，types 为空时订阅全部类型
+func (b *Bus) Subscribe(name string, handle Handler, types ...Type) {
+	s := &subscriber{name: name, handle: handle}
+	if len(types) > 0 {
+		s.types = make(map[Type]bool, len(types))
+		for _, v := range types {
+			s.types[v] = true
+		}
+	}
+	b.mu.Lock()
+	b.subscribers = append(b.subscribers, s)
+	b.mu.Unlock()
+}
+
+// Publish 发布事件后立即返回
+func (b *Bus) Publish(ctx context.Context, typ Type, data any) {
+	e := &Event{Type: typ, Time: time.Now(), Data: data}
+	fields := []interface{}{"event", string(typ)}
+	if id, ok := log.Field(ctx, "request_id").(string); ok {
+		e.RequestId = id
+		fields = append(fields, "request_id", id)
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, s := range b.subscribers {
+		if s.types != nil && !s.types[typ] {
+			continue
+		}
+		b.wg.Add(1)
+		go b.deliver(log.WithFields(context.Background(), fields...), s, e)
+	}
+}
+
+func (b *Bus) deliver(ctx context.Context, s *subscriber, e *Event) {
+	defer b.wg.Done()
+	// 订阅者的 panic 不影响服务
+	defer func() {
+		if r := recover(); r != nil {
+			log.WithContext(ctx).Errorf("event subscriber %v panic: %v", s.name, r)
+		}
+	}()
+	ctx, cancel := context.WithTimeout(ctx, deliverTimeout)
+	defer cancel()
+	s.handle(ctx, e)
+}
+
+// Wait 等待已发布的事件处理完成，最多等待 timeout，超时返回 false
+func (b *Bus) Wait(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+var bus = New()
+
+// Subscribe 订阅默认总线的事件
+func Subscribe(name string, handle Handler, types ...Type) {
+	bus.Subscribe(name, handle, types...)
+}
+
+// Publish 向默认总线发布事件
+func Publish(ctx context.Context, typ Type, data any) {
+	bus.Publish(ctx, typ, data)
+}
+
+// Wait 等待默认总线已发布的事件处理完成，进程退出前调用，避免丢失尚未发出的 webhook 等通知
+func Wait(timeout time.Duration) bool {
+	return bus.Wait(timeout)
+}
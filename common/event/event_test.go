@@ -0,0 +1,57 @@
+package event
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/initialize/log"
+)
+
+func TestBus(t *testing.T) {
+	bus := New()
+	var (
+		mu       sync.Mutex
+		all      []Type
+		transfer []*Event
+	)
+	bus.Subscribe("all", func(_ context.Context, e *Event) {
+		mu.Lock()
+		all = append(all, e.Type)
+		mu.Unlock()
+	})
+	bus.Subscribe("transfer", func(ctx context.Context, e *Event) {
+		assert.Equal(t, "abc", log.Field(ctx, "request_id"))
+		assert.NoError(t, ctx.Err())
+		mu.Lock()
+		transfer = append(transfer, e)
+		mu.Unlock()
+	}, TransferFinished)
+	bus.Subscribe("panic", func(context.Context, *Event) { panic("boom") })
+
+	ctx, cancel := context.WithCancel(log.WithFields(context.Background(), "request_id", "abc"))
+	bus.Publish(ctx, DiscoveryCompleted, 1)
+	bus.Publish(ctx, TransferFinished, 2)
+	// 请求结束后订阅者仍可正常处理
+	cancel()
+	assert.True(t, bus.Wait(time.Second))
+
+	assert.ElementsMatch(t, []Type{DiscoveryCompleted, TransferFinished}, all)
+	if assert.Len(t, transfer, 1) {
+		assert.Equal(t, 2, transfer[0].Data)
+		assert.Equal(t, "abc", transfer[0].RequestId)
+	}
+}
+
+func TestBusWaitTimeout(t *testing.T) {
+	bus := New()
+	release := make(chan struct{})
+	bus.Subscribe("slow", func(context.Context, *Event) { <-release })
+	bus.Publish(context.Background(), TransferFinished, nil)
+	assert.False(t, bus.Wait(10*time.Millisecond))
+	close(release)
+	assert.True(t, bus.Wait(time.Second))
+}
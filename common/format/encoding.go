@@ -0,0 +1,71 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+const DefaultEncoding = "utf-8"
+
+// Encoding 导出文件的字符编码
+type Encoding struct {
+	Name    string
+	Charset string // Content-Type 中的 charset
+	bom     []byte
+	encoder func() *encoding.Encoder
+}
+
+var encodings = map[string]*Encoding{
+	"utf-8": {Name: "utf-8", Charset: "utf-8"},
+	// 中文版 Excel 依赖 BOM 识别 UTF-8，否则按 GBK 打开导致乱码
+	"utf-8-bom": {Name: "utf-8-bom", Charset: "utf-8", bom: []byte{0xEF, 0xBB, 0xBF}},
+	// GBK 无法表示的字符（如 emoji）替换为占位符
+	"gbk": {Name: "gbk", Charset: "gbk", encoder: func() *encoding.Encoder {
+		return encoding.ReplaceUnsupported(simplifiedchinese.GBK.NewEncoder())
+	}},
+	"utf-16le": {Name: "utf-16le", Charset: "utf-16le", encoder: func() *encoding.Encoder {
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder()
+	}},
+}
+
+// GetEncoding 根据名称获取编码，名称不区分大小写
+func GetEncoding(name string) (*Encoding, error) {
+	if name == "" {
+		name = DefaultEncoding
+	}
+	if e, ok := encodings[strings.ToLower(name)]; ok {
+		return e, nil
+	}
+	names := make([]string, 0, len(encodings))
+	for k := range encodings {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("不支持的编码：%v，可选：%v", name, strings.Join(names, ", "))
+}
+
+// NewWriter 返回按该编码写入 w 的 Writer，写入完毕后需调用 Close 刷新缓冲
+func (e *Encoding) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	if len(e.bom) > 0 {
+		if _, err := w.Write(e.bom); err != nil {
+			return nil, err
+		}
+	}
+	if e.encoder == nil {
+		return nopCloser{w}, nil
+	}
+	return transform.NewWriter(w, e.encoder()), nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
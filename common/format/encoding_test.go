@@ -0,0 +1,35 @@
+package format
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoding(t *testing.T) {
+	cases := []struct {
+		name string
+		want []byte
+	}{
+		{"utf-8", []byte("歌")},
+		{"UTF-8-BOM", []byte("\xEF\xBB\xBF歌")},
+		{"gbk", []byte{0xB8, 0xE8}},
+		{"utf-16le", []byte{0xFF, 0xFE, 0x4C, 0x6B}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e, err := GetEncoding(c.name)
+			assert.NoError(t, err)
+			buf := &bytes.Buffer{}
+			w, err := e.NewWriter(buf)
+			assert.NoError(t, err)
+			_, err = w.Write([]byte("歌"))
+			assert.NoError(t, err)
+			assert.NoError(t, w.Close())
+			assert.Equal(t, c.want, buf.Bytes())
+		})
+	}
+	_, err := GetEncoding("big5")
+	assert.Error(t, err)
+}
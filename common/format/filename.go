@@ -0,0 +1,63 @@
+package format
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultFilenameTemplate 默认文件名模板，可用占位符：{platform} {name} {date} {profile}
+const DefaultFilenameTemplate = "{platform}-{name}-{date}"
+
+const maxFilenameLength = 120
+
+// Filename 按模板生成不含扩展名的文件名，并补充扩展名
+func Filename(template, platform, name, profile, ext string, now time.Time) string {
+	if template == "" {
+		template = DefaultFilenameTemplate
+	}
+	filename := strings.NewReplacer(
+		"{platform}", platform,
+		"{name}", name,
+		"{date}", now.Format("20060102"),
+		"{profile}", profile,
+	).Replace(template)
+	filename = sanitize(filename)
+	if filename == "" {
+		filename = "songlist"
+	}
+	return filename + "." + ext
+}
+
+// 去除文件系统不允许的字符与控制字符，并限制长度
+func sanitize(s string) string {
+	builder := strings.Builder{}
+	for _, r := range s {
+		switch {
+		case strings.ContainsRune(`/\:*?"<>|`, r), unicode.IsControl(r):
+			builder.WriteRune('_')
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	s = strings.Trim(strings.TrimSpace(builder.String()), ".")
+	for len(s) > maxFilenameLength {
+		_, size := utf8.DecodeLastRuneInString(s)
+		s = s[:len(s)-size]
+	}
+	return s
+}
+
+// ContentDisposition 生成附件下载头，非 ASCII 文件名按 RFC 6266 同时提供 filename*
+func ContentDisposition(filename string) string {
+	fallback := strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, filename)
+	return fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, fallback, url.PathEscape(filename))
+}
@@ -0,0 +1,20 @@
+package format
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilename(t *testing.T) {
+	now := time.Date(2023, 12, 8, 0, 0, 0, 0, time.Local)
+	assert.Equal(t, "netease-我喜欢的音乐-20231208.csv", Filename("", "netease", "我喜欢的音乐", "tunemymusic", "csv", now))
+	assert.Equal(t, "a_b_c-soundiiz.csv", Filename("{name}-{profile}", "qqmusic", `a/b:c`, "soundiiz", "csv", now))
+	assert.Equal(t, "songlist.txt", Filename("{name}", "qqmusic", "...", "spotlistr", "txt", now))
+}
+
+func TestContentDisposition(t *testing.T) {
+	assert.Equal(t, `attachment; filename="netease-__.csv"; filename*=UTF-8''netease-%E6%AD%8C%E5%8D%95.csv`,
+		ContentDisposition("netease-歌单.csv"))
+}
@@ -0,0 +1,27 @@
+package format
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+
+	"GoMusic/common/models"
+)
+
+// Hash 歌单内容的摘要，仅包含歌单名、歌曲总数与有序的歌曲，
+// 封面地址等易变字段不计入，用于判断定期导出的内容是否变化
+func Hash(songList *models.SongList) string {
+	h := sha256.New()
+	write := func(s string) {
+		// 以长度作前缀，避免不同拆分方式得到相同的摘要
+		h.Write([]byte(strconv.Itoa(len(s))))
+		h.Write([]byte{':'})
+		h.Write([]byte(s))
+	}
+	write(songList.Name)
+	write(strconv.Itoa(songList.SongsCount))
+	for _, v := range songList.Songs {
+		write(v)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,23 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/models"
+)
+
+func TestHash(t *testing.T) {
+	a := &models.SongList{Name: "歌单", Songs: []string{"江南 - 林俊杰", "晴天 - 周杰伦"}, SongsCount: 2, Cover: "a.jpg"}
+	b := &models.SongList{Name: "歌单", Songs: []string{"江南 - 林俊杰", "晴天 - 周杰伦"}, SongsCount: 2, Cover: "b.jpg"}
+	assert.Equal(t, Hash(a), Hash(b))
+
+	b.Songs = []string{"晴天 - 周杰伦", "江南 - 林俊杰"}
+	assert.NotEqual(t, Hash(a), Hash(b))
+
+	// 拼接后相同的内容摘要不同
+	assert.NotEqual(t,
+		Hash(&models.SongList{Songs: []string{"ab", "c"}}),
+		Hash(&models.SongList{Songs: []string{"a", "bc"}}))
+}
@@ -0,0 +1,17 @@
+package format
+
+import "regexp"
+
+// 伴奏、纯音乐、卡拉 OK 等无人声版本的常见标注
+var instrumentalRegex = regexp.MustCompile(`(?i)伴奏|纯音乐版|无人声|消音版|卡拉\s*ok|instrumental|karaoke|off\s*vocal|backing\s*track|\binst\b\.?`)
+
+// IsInstrumental 根据歌名判断“歌名 - 歌手”是否为伴奏等无人声版本
+func IsInstrumental(song string) bool {
+	title, _ := SplitSong(song)
+	return HasInstrumentalMark(title)
+}
+
+// HasInstrumentalMark 文本（如标签中的歌名、文件名）中是否带有伴奏等标注
+func HasInstrumentalMark(s string) bool {
+	return instrumentalRegex.MatchString(s)
+}
@@ -0,0 +1,27 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsInstrumental(t *testing.T) {
+	for _, song := range []string{
+		"江南 (伴奏) - 林俊杰",
+		"告白气球 (纯音乐版) - 周杰伦",
+		"Lemon (Instrumental) - 米津玄師",
+		"打上花火 (off vocal) - DAOKO",
+		"Shape of You (Karaoke Version) - Ed Sheeran",
+		"群青 (Inst.) - YOASOBI",
+	} {
+		assert.True(t, IsInstrumental(song), song)
+	}
+	for _, song := range []string{
+		"江南 - 林俊杰",
+		"Instant Crush - Daft Punk",
+		"Hello - 伴奏乐队",
+	} {
+		assert.False(t, IsInstrumental(song), song)
+	}
+}
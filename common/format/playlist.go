@@ -0,0 +1,181 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// jsonSongEncoder 逐首写入 {"name":"歌单名","songs":[{"title","artist","duration_ms"}],"summary":{...}}，
+// 首次写入时输出歌单名，写完后输出总时长与歌手数
+type jsonSongEncoder struct {
+	w            io.Writer
+	playlistName string
+	count        int
+	summary      summarizer
+}
+
+// jsonSummary 歌单统计，未知时长的歌曲不计入总时长
+type jsonSummary struct {
+	DurationMs  int `json:"duration_ms"`
+	ArtistCount int `json:"artist_count"`
+}
+
+type jsonSong struct {
+	Title      string `json:"title"`
+	Artist     string `json:"artist"`
+	DurationMs int    `json:"duration_ms,omitempty"`
+}
+
+func newJSONSongEncoder(w io.Writer, playlistName string) SongEncoder {
+	return &jsonSongEncoder{w: w, playlistName: playlistName}
+}
+
+func (e *jsonSongEncoder) WriteSong(song string, durationMs int) error {
+	e.summary.add(song, durationMs)
+	title, artist := SplitSong(song)
+	data, err := marshalJSON(&jsonSong{Title: title, Artist: artist, DurationMs: durationMs})
+	if err != nil {
+		return err
+	}
+	prefix := ","
+	if e.count == 0 {
+		name, _ := marshalJSON(e.playlistName)
+		prefix = fmt.Sprintf(`{"name":%s,"songs":[`, name)
+	}
+	e.count++
+	_, err = io.WriteString(e.w, prefix+string(data))
+	return err
+}
+
+func (e *jsonSongEncoder) Flush() error {
+	summary, err := marshalJSON(&jsonSummary{DurationMs: e.summary.durationMs, ArtistCount: len(e.summary.order)})
+	if err != nil {
+		return err
+	}
+	if e.count == 0 {
+		name, _ := marshalJSON(e.playlistName)
+		_, err = fmt.Fprintf(e.w, `{"name":%s,"songs":[],"summary":%s}`+"\n", name, summary)
+		return err
+	}
+	_, err = fmt.Fprintf(e.w, `],"summary":%s}`+"\n", summary)
+	return err
+}
+
+// marshalJSON 导出的是文件而非网页，不转义 <、>、&
+func marshalJSON(v any) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// m3uSongEncoder 扩展 M3U，歌单中没有文件路径，以“歌手 - 歌名”作为条目
+type m3uSongEncoder struct {
+	w            io.Writer
+	playlistName string
+	wroteHeader  bool
+}
+
+func newM3USongEncoder(w io.Writer, playlistName string) SongEncoder {
+	return &m3uSongEncoder{w: w, playlistName: playlistName}
+}
+
+func (e *m3uSongEncoder) writeHeader() error {
+	if e.wroteHeader {
+		return nil
+	}
+	e.wroteHeader = true
+	_, err := io.WriteString(e.w, "#EXTM3U\n#PLAYLIST:"+m3uLine(e.playlistName)+"\n")
+	return err
+}
+
+func (e *m3uSongEncoder) WriteSong(song string, durationMs int) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	title, artist := SplitSong(song)
+	entry := m3uLine(title)
+	if artist != "" {
+		entry = m3uLine(artist) + " - " + entry
+	}
+	// 时长未知时为 -1
+	seconds := -1
+	if durationMs > 0 {
+		seconds = (durationMs + 500) / 1000
+	}
+	_, err := fmt.Fprintf(e.w, "#EXTINF:%d,%s\n%s\n", seconds, entry, entry)
+	return err
+}
+
+func (e *m3uSongEncoder) Flush() error {
+	return e.writeHeader()
+}
+
+// m3uLine M3U 按行解析，去掉换行符
+func m3uLine(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
+
+// xspfSongEncoder 首次写入时输出 XML 头与歌单名，Flush 时闭合 trackList，
+// 写完才能得到统计，因此总时长与歌手数作为 annotation 附在 trackList 之后
+type xspfSongEncoder struct {
+	w            io.Writer
+	playlistName string
+	wroteHeader  bool
+	summary      summarizer
+}
+
+func newXSPFSongEncoder(w io.Writer, playlistName string) SongEncoder {
+	return &xspfSongEncoder{w: w, playlistName: playlistName}
+}
+
+func (e *xspfSongEncoder) writeHeader() error {
+	if e.wroteHeader {
+		return nil
+	}
+	e.wroteHeader = true
+	_, err := io.WriteString(e.w, xml.Header+`<playlist version="1" xmlns="http://xspf.org/ns/0/">`+"\n"+
+		"  <title>"+xmlText(e.playlistName)+"</title>\n  <trackList>\n")
+	return err
+}
+
+func (e *xspfSongEncoder) WriteSong(song string, durationMs int) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.summary.add(song, durationMs)
+	title, artist := SplitSong(song)
+	track := "    <track><title>" + xmlText(title) + "</title>"
+	if artist != "" {
+		track += "<creator>" + xmlText(artist) + "</creator>"
+	}
+	if durationMs > 0 {
+		track += fmt.Sprintf("<duration>%d</duration>", durationMs)
+	}
+	_, err := io.WriteString(e.w, track+"</track>\n")
+	return err
+}
+
+func (e *xspfSongEncoder) Flush() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	duration := (time.Duration(e.summary.durationMs) * time.Millisecond).Round(time.Second)
+	annotation := fmt.Sprintf("共 %d 首，总时长 %v，%d 位歌手", e.summary.songs, duration, len(e.summary.order))
+	_, err := io.WriteString(e.w, "  </trackList>\n  <annotation>"+xmlText(annotation)+"</annotation>\n</playlist>\n")
+	return err
+}
+
+func xmlText(s string) string {
+	buf := &strings.Builder{}
+	_ = xml.EscapeText(buf, []byte(s))
+	return buf.String()
+}
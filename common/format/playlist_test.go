@@ -0,0 +1,66 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/models"
+)
+
+var timedSongList = &models.SongList{
+	Name:      "测试 & 歌单",
+	Songs:     []string{"小酒窝 (Live) - 蔡卓妍 / 林俊杰", "<Intro>"},
+	Durations: []int{227400, 0},
+}
+
+func TestJSONProfile(t *testing.T) {
+	p, err := GetProfile("json")
+	assert.NoError(t, err)
+	buf := &bytes.Buffer{}
+	assert.NoError(t, p.Encode(buf, timedSongList))
+	assert.Equal(t, `{"name":"测试 & 歌单","songs":[{"title":"小酒窝 (Live)","artist":"蔡卓妍 / 林俊杰","duration_ms":227400},{"title":"<Intro>","artist":""}],"summary":{"duration_ms":227400,"artist_count":2}}`+"\n", buf.String())
+	assert.True(t, json.Valid(buf.Bytes()))
+
+	buf.Reset()
+	assert.NoError(t, p.Encode(buf, &models.SongList{Name: "空"}))
+	assert.Equal(t, `{"name":"空","songs":[],"summary":{"duration_ms":0,"artist_count":0}}`+"\n", buf.String())
+}
+
+func TestM3UProfile(t *testing.T) {
+	p, err := GetProfile("M3U8")
+	assert.NoError(t, err)
+	buf := &bytes.Buffer{}
+	assert.NoError(t, p.Encode(buf, timedSongList))
+	assert.Equal(t, "#EXTM3U\n#PLAYLIST:测试 & 歌单\n"+
+		"#EXTINF:227,蔡卓妍 / 林俊杰 - 小酒窝 (Live)\n蔡卓妍 / 林俊杰 - 小酒窝 (Live)\n"+
+		"#EXTINF:-1,<Intro>\n<Intro>\n", buf.String())
+}
+
+func TestXSPFProfile(t *testing.T) {
+	p, err := GetProfile("xspf")
+	assert.NoError(t, err)
+	buf := &bytes.Buffer{}
+	assert.NoError(t, p.Encode(buf, timedSongList))
+
+	var playlist struct {
+		Title      string `xml:"title"`
+		Annotation string `xml:"annotation"`
+		Tracks     []struct {
+			Title    string `xml:"title"`
+			Creator  string `xml:"creator"`
+			Duration int    `xml:"duration"`
+		} `xml:"trackList>track"`
+	}
+	assert.NoError(t, xml.Unmarshal(buf.Bytes(), &playlist))
+	assert.Equal(t, "测试 & 歌单", playlist.Title)
+	assert.Len(t, playlist.Tracks, 2)
+	assert.Equal(t, "小酒窝 (Live)", playlist.Tracks[0].Title)
+	assert.Equal(t, "蔡卓妍 / 林俊杰", playlist.Tracks[0].Creator)
+	assert.Equal(t, 227400, playlist.Tracks[0].Duration)
+	assert.Equal(t, "<Intro>", playlist.Tracks[1].Title)
+	assert.Equal(t, "共 2 首，总时长 3m47s，2 位歌手", playlist.Annotation)
+}
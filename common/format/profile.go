@@ -0,0 +1,210 @@
+package format
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"GoMusic/common/models"
+)
+
+// Profile 导出配置，按第三方迁移工具期望的列顺序与格式输出歌单
+type Profile struct {
+	Name        string
+	ContentType string
+	Extension   string
+	// UTF8Only 格式本身约定为 UTF-8，如 JSON、XML
+	UTF8Only   bool
+	newEncoder func(w io.Writer, playlistName string) SongEncoder
+}
+
+// SongEncoder 逐首写入歌曲，用于流式导出
+type SongEncoder interface {
+	// WriteSong 写入一首“歌名 - 歌手”，durationMs 为 0 表示时长未知
+	WriteSong(song string, durationMs int) error
+	// Flush 写入剩余的缓冲内容
+	Flush() error
+}
+
+// TrackEncoder 可按结构化歌曲写入的编码器，歌名中含“ - ”时也能正确拆分，并可输出专辑
+type TrackEncoder interface {
+	WriteTrack(track *models.Song) error
+}
+
+// Encode 将歌单按配置写入 w，歌单带有结构化歌曲且编码器支持时按结构化歌曲写入
+func (p *Profile) Encode(w io.Writer, songList *models.SongList) error {
+	encoder := p.NewEncoder(w, songList.Name)
+	trackEncoder, ok := encoder.(TrackEncoder)
+	// 旧的缓存歌单没有结构化歌曲，仍按“歌名 - 歌手”拆分
+	useTracks := ok && songList.Tracks != nil && len(songList.Tracks) == len(songList.Songs)
+	for i, song := range songList.Songs {
+		if useTracks {
+			if err := trackEncoder.WriteTrack(songList.Tracks[i]); err != nil {
+				return err
+			}
+			continue
+		}
+		duration := 0
+		if i < len(songList.Durations) {
+			duration = songList.Durations[i]
+		}
+		if err := encoder.WriteSong(song, duration); err != nil {
+			return err
+		}
+	}
+	return encoder.Flush()
+}
+
+// NewEncoder 返回逐首写入 w 的编码器，无需事先获取完整歌单
+func (p *Profile) NewEncoder(w io.Writer, playlistName string) SongEncoder {
+	return p.newEncoder(w, playlistName)
+}
+
+const DefaultProfile = "tunemymusic"
+
+var profiles = map[string]*Profile{
+	// https://www.tunemymusic.com 文件导入
+	"tunemymusic": {
+		Name: "tunemymusic", ContentType: "text/csv", Extension: "csv",
+		newEncoder: csvEncoder([]string{"Track name", "Artist name", "Album", "Playlist name", "Type", "ISRC"},
+			func(playlistName, title, artist, album string) []string {
+				return []string{title, artist, album, playlistName, "Playlist", ""}
+			}),
+	},
+	// https://soundiiz.com 文件导入
+	"soundiiz": {
+		Name: "soundiiz", ContentType: "text/csv", Extension: "csv",
+		newEncoder: csvEncoder([]string{"title", "artist", "album", "isrc"},
+			func(_, title, artist, album string) []string {
+				return []string{title, artist, album, ""}
+			}),
+	},
+	// https://www.spotlistr.com 文本搜索，每行“歌手 - 歌名”
+	"spotlistr": {
+		Name: "spotlistr", ContentType: "text/plain", Extension: "txt",
+		newEncoder: textEncoder(func(title, artist string) string {
+			return artist + " - " + title
+		}),
+	},
+	// 通用 CSV，旧的缓存歌单没有专辑信息，album 列留空
+	"csv": {
+		Name: "csv", ContentType: "text/csv", Extension: "csv",
+		newEncoder: csvEncoder([]string{"title", "artist", "album"},
+			func(_, title, artist, album string) []string {
+				return []string{title, artist, album}
+			}),
+	},
+	// 歌单名与逐首的歌名、歌手、时长
+	"json": {Name: "json", ContentType: "application/json", Extension: "json", UTF8Only: true, newEncoder: newJSONSongEncoder},
+	// 扩展 M3U，播放器与迁移工具按 #EXTINF 中的“歌手 - 歌名”匹配
+	"m3u8": {Name: "m3u8", ContentType: "audio/x-mpegurl", Extension: "m3u8", UTF8Only: true, newEncoder: newM3USongEncoder},
+	// https://xspf.org
+	"xspf": {Name: "xspf", ContentType: "application/xspf+xml", Extension: "xspf", UTF8Only: true, newEncoder: newXSPFSongEncoder},
+	// https://freeyourmusic.com 文件导入
+	"freeyourmusic": {
+		Name: "freeyourmusic", ContentType: "text/csv", Extension: "csv",
+		newEncoder: csvEncoder([]string{"Title", "Artist", "Album"},
+			func(_, title, artist, album string) []string {
+				return []string{title, artist, album}
+			}),
+	},
+}
+
+// GetProfile 根据名称获取导出配置，名称不区分大小写
+func GetProfile(name string) (*Profile, error) {
+	if name == "" {
+		name = DefaultProfile
+	}
+	if p, ok := profiles[strings.ToLower(name)]; ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("不支持的导出格式：%v，可选：%v", name, strings.Join(ProfileNames(), ", "))
+}
+
+// ProfileNames 所有可用的导出配置名称
+func ProfileNames() []string {
+	names := make([]string, 0, len(profiles))
+	for k := range profiles {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func csvEncoder(header []string, row func(playlistName, title, artist, album string) []string) func(io.Writer, string) SongEncoder {
+	return func(w io.Writer, playlistName string) SongEncoder {
+		return &csvSongEncoder{writer: csv.NewWriter(w), header: header, row: row, playlistName: playlistName}
+	}
+}
+
+// csvSongEncoder 在写入首行数据或 Flush 时写入表头，空歌单同样输出表头
+type csvSongEncoder struct {
+	writer       *csv.Writer
+	header       []string
+	row          func(playlistName, title, artist, album string) []string
+	playlistName string
+	wroteHeader  bool
+}
+
+func (e *csvSongEncoder) writeHeader() error {
+	if e.wroteHeader {
+		return nil
+	}
+	e.wroteHeader = true
+	return e.writer.Write(e.header)
+}
+
+func (e *csvSongEncoder) WriteSong(song string, _ int) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	title, artist := SplitSong(song)
+	return e.writer.Write(e.row(e.playlistName, title, artist, ""))
+}
+
+func (e *csvSongEncoder) WriteTrack(track *models.Song) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	track = NormalizeTrack(track)
+	return e.writer.Write(e.row(e.playlistName, track.Name, strings.Join(track.Artists, " / "), track.Album))
+}
+
+func (e *csvSongEncoder) Flush() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+func textEncoder(line func(title, artist string) string) func(io.Writer, string) SongEncoder {
+	return func(w io.Writer, _ string) SongEncoder {
+		return &textSongEncoder{w: w, line: line}
+	}
+}
+
+type textSongEncoder struct {
+	w    io.Writer
+	line func(title, artist string) string
+}
+
+func (e *textSongEncoder) WriteSong(song string, _ int) error {
+	_, err := io.WriteString(e.w, e.line(SplitSong(song))+"\n")
+	return err
+}
+
+func (e *textSongEncoder) Flush() error {
+	return nil
+}
+
+// SplitSong 将“歌名 - 歌手”拆分为歌名与歌手，歌名中可能包含“ - ”，因此以最后一个分隔符为准
+func SplitSong(song string) (title, artist string) {
+	index := strings.LastIndex(song, " - ")
+	if index < 0 {
+		return song, ""
+	}
+	return song[:index], song[index+3:]
+}
@@ -0,0 +1,88 @@
+package format
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/models"
+)
+
+var songList = &models.SongList{
+	Name:       "测试歌单",
+	Songs:      []string{"小酒窝 (Live) - 蔡卓妍 / 林俊杰", "Song - Remix - Artist, Jr."},
+	SongsCount: 2,
+}
+
+func TestSplitSong(t *testing.T) {
+	title, artist := SplitSong("Song - Remix - Artist")
+	assert.Equal(t, "Song - Remix", title)
+	assert.Equal(t, "Artist", artist)
+
+	title, artist = SplitSong("无歌手")
+	assert.Equal(t, "无歌手", title)
+	assert.Equal(t, "", artist)
+}
+
+func TestProfiles(t *testing.T) {
+	t.Run("tunemymusic", func(t *testing.T) {
+		p, err := GetProfile("")
+		assert.NoError(t, err)
+		buf := &bytes.Buffer{}
+		assert.NoError(t, p.Encode(buf, songList))
+		assert.Equal(t, "Track name,Artist name,Album,Playlist name,Type,ISRC\n"+
+			"小酒窝 (Live),蔡卓妍 / 林俊杰,,测试歌单,Playlist,\n"+
+			"Song - Remix,\"Artist, Jr.\",,测试歌单,Playlist,\n", buf.String())
+	})
+	t.Run("tracks", func(t *testing.T) {
+		// 按结构化歌曲写入专辑，歌手名中含“ - ”时也能正确拆分
+		tracked := *songList
+		tracked.Songs = []string{"晴天 - 周杰伦", "Song - Artist - Jr."}
+		tracked.Tracks = []*models.Song{
+			{Name: "晴天", Artists: []string{"周杰伦"}, Album: "叶惠美"},
+			{Name: "Song", Artists: []string{"Artist - Jr."}},
+		}
+		for name, expected := range map[string]string{
+			"csv":           "title,artist,album\n晴天,周杰伦,叶惠美\nSong,Artist - Jr.,\n",
+			"freeyourmusic": "Title,Artist,Album\n晴天,周杰伦,叶惠美\nSong,Artist - Jr.,\n",
+			"soundiiz":      "title,artist,album,isrc\n晴天,周杰伦,叶惠美,\nSong,Artist - Jr.,,\n",
+			"tunemymusic":   "Track name,Artist name,Album,Playlist name,Type,ISRC\n晴天,周杰伦,叶惠美,测试歌单,Playlist,\nSong,Artist - Jr.,,测试歌单,Playlist,\n",
+		} {
+			p, err := GetProfile(name)
+			assert.NoError(t, err)
+			buf := &bytes.Buffer{}
+			assert.NoError(t, p.Encode(buf, &tracked))
+			assert.Equal(t, expected, buf.String(), name)
+		}
+	})
+	t.Run("spotlistr", func(t *testing.T) {
+		p, err := GetProfile("Spotlistr")
+		assert.NoError(t, err)
+		buf := &bytes.Buffer{}
+		assert.NoError(t, p.Encode(buf, songList))
+		assert.Equal(t, "蔡卓妍 / 林俊杰 - 小酒窝 (Live)\nArtist, Jr. - Song - Remix\n", buf.String())
+	})
+	t.Run("stream", func(t *testing.T) {
+		p, err := GetProfile("soundiiz")
+		assert.NoError(t, err)
+		buf := &bytes.Buffer{}
+		encoder := p.NewEncoder(buf, "测试歌单")
+		assert.NoError(t, encoder.Flush())
+		assert.Equal(t, "title,artist,album,isrc\n", buf.String())
+
+		buf.Reset()
+		encoder = p.NewEncoder(buf, "测试歌单")
+		for _, v := range songList.Songs {
+			assert.NoError(t, encoder.WriteSong(v, 0))
+		}
+		assert.NoError(t, encoder.Flush())
+		expected := &bytes.Buffer{}
+		assert.NoError(t, p.Encode(expected, songList))
+		assert.Equal(t, expected.String(), buf.String())
+	})
+	t.Run("unknown", func(t *testing.T) {
+		_, err := GetProfile("unknown")
+		assert.Error(t, err)
+	})
+}
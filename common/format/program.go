@@ -0,0 +1,21 @@
+package format
+
+import (
+	"regexp"
+	"time"
+)
+
+// programMinDuration 超过该时长的条目视为节目而非歌曲
+const programMinDuration = 20 * time.Minute
+
+// 有声书章节、电台节目、播客等常见的命名方式
+var programRegex = regexp.MustCompile(`(?i)第\s*[0-9零一二三四五六七八九十百千]+\s*[集期章回话讲]|有声书|有声小说|广播剧|评书|相声|播客|podcast|asmr|episode\s*\d+`)
+
+// IsProgram 根据时长与歌名判断“歌名 - 歌手”是否为有声书、电台节目等非音乐条目，durationMs 为 0 表示时长未知
+func IsProgram(song string, durationMs int) bool {
+	if time.Duration(durationMs)*time.Millisecond >= programMinDuration {
+		return true
+	}
+	title, _ := SplitSong(song)
+	return programRegex.MatchString(title)
+}
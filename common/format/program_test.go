@@ -0,0 +1,19 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsProgram(t *testing.T) {
+	assert.False(t, IsProgram("晴天 - 周杰伦", 269000))
+	assert.False(t, IsProgram("晴天 - 周杰伦", 0))
+	assert.True(t, IsProgram("雨声白噪音 - 助眠", 2*60*60*1000))
+	assert.True(t, IsProgram("三体 第12集 - 有声剧场", 900000))
+	assert.True(t, IsProgram("明朝那些事儿 第三章 - 主播", 0))
+	assert.True(t, IsProgram("[ASMR] 耳边低语 - 某主播", 600000))
+	assert.True(t, IsProgram("Episode 7: Origins - Some Podcast", 0))
+	// 歌手名不参与判断
+	assert.False(t, IsProgram("Hello - 第一集乐队", 0))
+}
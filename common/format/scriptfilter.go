@@ -0,0 +1,19 @@
+package format
+
+import (
+	"strconv"
+
+	"GoMusic/common/models"
+)
+
+// ScriptFilter 每首歌曲一项，标题为歌名、副标题为歌手，选中或复制时得到“歌名 - 歌手”
+func ScriptFilter(songList *models.SongList) *models.ScriptFilter {
+	result := &models.ScriptFilter{Items: make([]*models.ScriptFilterItem, 0, len(songList.Songs))}
+	for i, v := range songList.Songs {
+		title, artist := SplitSong(v)
+		item := &models.ScriptFilterItem{Uid: strconv.Itoa(i), Title: title, Subtitle: artist, Arg: v}
+		item.Text.Copy, item.Text.LargeType = v, v
+		result.Items = append(result.Items, item)
+	}
+	return result
+}
@@ -0,0 +1,18 @@
+package format
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScriptFilter(t *testing.T) {
+	result := ScriptFilter(songList)
+	assert.Len(t, result.Items, 2)
+	data, err := json.Marshal(result.Items[0])
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"uid":"0","title":"小酒窝 (Live)","subtitle":"蔡卓妍 / 林俊杰","arg":"小酒窝 (Live) - 蔡卓妍 / 林俊杰",
+		"text":{"copy":"小酒窝 (Live) - 蔡卓妍 / 林俊杰","largetype":"小酒窝 (Live) - 蔡卓妍 / 林俊杰"}}`, string(data))
+	assert.Equal(t, "Song - Remix", result.Items[1].Title)
+}
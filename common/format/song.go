@@ -0,0 +1,71 @@
+package format
+
+import (
+	"strings"
+
+	"GoMusic/common/models"
+	"GoMusic/common/utils"
+)
+
+// Song 将缓存中的歌曲格式化为“歌名 - 歌手”
+func Song(song *models.CachedSong) string {
+	return songText(song.Name, song.Artists)
+}
+
+// Track 将结构化歌曲格式化为“歌名 - 歌手”
+func Track(song *models.Song) string {
+	return songText(song.Name, song.Artists)
+}
+
+// Tracks 逐首格式化，得到与 tracks 一一对应的 SongList.Songs
+func Tracks(tracks []*models.Song) []string {
+	songs := make([]string, 0, len(tracks))
+	for _, v := range tracks {
+		songs = append(songs, Track(v))
+	}
+	return songs
+}
+
+// ListTracks 歌单的结构化歌曲；平台未提供或旧的缓存歌单没有时，由 Songs 按“歌名 - 歌手”拆分，专辑为空
+func ListTracks(songList *models.SongList) []*models.Song {
+	if len(songList.Tracks) == len(songList.Songs) && songList.Tracks != nil {
+		return songList.Tracks
+	}
+	tracks := make([]*models.Song, 0, len(songList.Songs))
+	for i, v := range songList.Songs {
+		name, artist := SplitSong(v)
+		track := &models.Song{Name: name, Artists: make([]string, 0)}
+		if artist != "" {
+			track.Artists = strings.Split(artist, " / ")
+		}
+		if i < len(songList.Durations) {
+			track.DurationMs = songList.Durations[i]
+		}
+		tracks = append(tracks, track)
+	}
+	return tracks
+}
+
+// NormalizeTrack 返回按 Track 的规则规范化歌名与歌手后的副本，与格式化后的“歌名 - 歌手”一致
+func NormalizeTrack(song *models.Song) *models.Song {
+	normalized := *song
+	normalized.Name, normalized.Artists = normalizeSong(song.Name, song.Artists)
+	return &normalized
+}
+
+func songText(name string, artists []string) string {
+	name, artists = normalizeSong(name, artists)
+	return name + " - " + strings.Join(artists, " / ")
+}
+
+func normalizeSong(name string, artists []string) (string, []string) {
+	name, artists = utils.CollapseArtists(name, artists)
+	// 去除多余符号
+	return utils.StandardSongName(name), artists
+}
+
+// ParseSong 将已格式化的“歌名 - 歌手”还原为结构化歌曲
+func ParseSong(song string, duration int) *models.CachedSong {
+	name, artist := SplitSong(song)
+	return &models.CachedSong{Name: name, Artists: strings.Split(artist, " / "), Duration: duration}
+}
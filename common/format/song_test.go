@@ -0,0 +1,39 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/models"
+)
+
+func TestSong(t *testing.T) {
+	song := &models.CachedSong{Name: "小酒窝（Live）", Artists: []string{"蔡卓妍", "林俊杰"}, Duration: 1000}
+	assert.Equal(t, "小酒窝 (Live) - 蔡卓妍 / 林俊杰", Song(song))
+
+	parsed := ParseSong("小酒窝 (Live) - 蔡卓妍 / 林俊杰", 1000)
+	assert.Equal(t, &models.CachedSong{Name: "小酒窝 (Live)", Artists: []string{"蔡卓妍", "林俊杰"}, Duration: 1000}, parsed)
+	assert.Equal(t, "小酒窝 (Live) - 蔡卓妍 / 林俊杰", Song(parsed))
+}
+
+func TestTracks(t *testing.T) {
+	tracks := []*models.Song{
+		{Name: "小酒窝（Live）", Artists: []string{"蔡卓妍", "林俊杰"}, Album: "JJ陆", DurationMs: 1000, SourceId: "1"},
+		{Name: "晴天", Artists: []string{"周杰伦"}},
+	}
+	assert.Equal(t, []string{"小酒窝 (Live) - 蔡卓妍 / 林俊杰", "晴天 - 周杰伦"}, Tracks(tracks))
+}
+
+func TestListTracks(t *testing.T) {
+	tracks := []*models.Song{{Name: "晴天", Artists: []string{"周杰伦"}, Album: "叶惠美"}}
+	assert.Equal(t, tracks, ListTracks(&models.SongList{Songs: []string{"晴天 - 周杰伦"}, Tracks: tracks}))
+
+	// 旧的歌单按“歌名 - 歌手”拆分
+	songList := &models.SongList{Songs: []string{"Ice - Dance - X / Y", "纯音乐"}, Durations: []int{1000}}
+	assert.Equal(t, []*models.Song{
+		{Name: "Ice - Dance", Artists: []string{"X", "Y"}, DurationMs: 1000},
+		{Name: "纯音乐", Artists: []string{}},
+	}, ListTracks(songList))
+	assert.Empty(t, ListTracks(&models.SongList{}))
+}
@@ -0,0 +1,73 @@
+package format
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+const (
+	SortNone   = ""
+	SortTitle  = "title"
+	SortArtist = "artist"
+
+	DefaultCollation = "pinyin"
+)
+
+// 中文按拼音或笔画排序，字节序会把汉字按 Unicode 码位排列，顺序没有意义
+var collations = map[string]language.Tag{
+	"pinyin": language.MustParse("zh-u-co-pinyin"),
+	"stroke": language.MustParse("zh-u-co-stroke"),
+}
+
+// SortSongs 按歌名或歌手对“歌名 - 歌手”排序，by 为空时保持原顺序；
+// collation 为 pinyin、stroke 或 binary（字节序），为空时按拼音排序
+func SortSongs(songs []string, by, collation string) error {
+	var index int // 排序主键：0 为歌名，1 为歌手
+	switch strings.ToLower(by) {
+	case SortNone:
+		return nil
+	case SortTitle:
+		index = 0
+	case SortArtist:
+		index = 1
+	default:
+		return fmt.Errorf("不支持的排序方式：%v，可选：%v, %v", by, SortTitle, SortArtist)
+	}
+
+	compare := strings.Compare
+	switch collation = strings.ToLower(collation); collation {
+	case "binary":
+	case "":
+		collation = DefaultCollation
+		fallthrough
+	default:
+		tag, ok := collations[collation]
+		if !ok {
+			return fmt.Errorf("不支持的排序规则：%v，可选：pinyin, stroke, binary", collation)
+		}
+		// Collator 不是并发安全的，每次排序单独创建
+		compare = collate.New(tag, collate.IgnoreCase).CompareString
+	}
+
+	keys := make(map[string][2]string, len(songs))
+	for _, v := range songs {
+		title, artist := SplitSong(v)
+		if index == 0 {
+			keys[v] = [2]string{title, artist}
+		} else {
+			keys[v] = [2]string{artist, title}
+		}
+	}
+	sort.SliceStable(songs, func(i, j int) bool {
+		a, b := keys[songs[i]], keys[songs[j]]
+		if c := compare(a[0], b[0]); c != 0 {
+			return c < 0
+		}
+		return compare(a[1], b[1]) < 0
+	})
+	return nil
+}
@@ -0,0 +1,32 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortSongs(t *testing.T) {
+	songs := func() []string {
+		return []string{"晴天 - 周杰伦", "江南 - 林俊杰", "十年 - 陈奕迅", "Hello - Adele", "一路向北 - 周杰伦"}
+	}
+
+	s := songs()
+	assert.NoError(t, SortSongs(s, SortArtist, ""))
+	assert.Equal(t, []string{"Hello - Adele", "十年 - 陈奕迅", "江南 - 林俊杰", "晴天 - 周杰伦", "一路向北 - 周杰伦"}, s)
+
+	s = songs()
+	assert.NoError(t, SortSongs(s, SortTitle, "pinyin"))
+	assert.Equal(t, []string{"Hello - Adele", "江南 - 林俊杰", "晴天 - 周杰伦", "十年 - 陈奕迅", "一路向北 - 周杰伦"}, s)
+
+	s = songs()
+	assert.NoError(t, SortSongs(s, SortTitle, "stroke"))
+	assert.Equal(t, []string{"Hello - Adele", "一路向北 - 周杰伦", "十年 - 陈奕迅", "江南 - 林俊杰", "晴天 - 周杰伦"}, s)
+
+	s = songs()
+	assert.NoError(t, SortSongs(s, SortNone, ""))
+	assert.Equal(t, songs(), s)
+
+	assert.Error(t, SortSongs(s, "album", ""))
+	assert.Error(t, SortSongs(s, SortTitle, "unknown"))
+}
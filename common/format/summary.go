@@ -0,0 +1,56 @@
+package format
+
+import (
+	"sort"
+	"strings"
+
+	"GoMusic/common/models"
+)
+
+// Summarize 统计歌单中各歌手的歌曲数，合唱歌曲计入每位歌手
+func Summarize(songs []string, durationMs int) *models.Summary {
+	s := &summarizer{}
+	for _, song := range songs {
+		s.add(song, 0)
+	}
+	s.durationMs = durationMs
+	return s.summary()
+}
+
+// summarizer 逐首累计歌单统计，流式导出时只保存各歌手的歌曲数
+type summarizer struct {
+	counts     map[string]int
+	order      []string
+	songs      int
+	durationMs int
+}
+
+func (s *summarizer) add(song string, durationMs int) {
+	if s.counts == nil {
+		s.counts = make(map[string]int)
+	}
+	s.songs++
+	s.durationMs += durationMs
+	_, artist := SplitSong(song)
+	for _, name := range strings.Split(artist, " / ") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if s.counts[name] == 0 {
+			s.order = append(s.order, name)
+		}
+		s.counts[name]++
+	}
+}
+
+func (s *summarizer) summary() *models.Summary {
+	artists := make([]*models.ArtistCount, 0, len(s.order))
+	for _, name := range s.order {
+		artists = append(artists, &models.ArtistCount{Name: name, Count: s.counts[name]})
+	}
+	// 歌曲数相同时保持首次出现的顺序
+	sort.SliceStable(artists, func(i, j int) bool {
+		return artists[i].Count > artists[j].Count
+	})
+	return &models.Summary{DurationMs: s.durationMs, Artists: artists}
+}
@@ -0,0 +1,23 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/models"
+)
+
+func TestSummarize(t *testing.T) {
+	summary := Summarize([]string{
+		"小酒窝 (Live) - 蔡卓妍 / 林俊杰",
+		"江南 - 林俊杰",
+		"星河万里 - 王大毛",
+	}, 600000)
+	assert.Equal(t, 600000, summary.DurationMs)
+	assert.Equal(t, []*models.ArtistCount{
+		{Name: "林俊杰", Count: 2},
+		{Name: "蔡卓妍", Count: 1},
+		{Name: "王大毛", Count: 1},
+	}, summary.Artists)
+}
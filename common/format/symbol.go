@@ -0,0 +1,65 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	SymbolKeep          = ""              // 保持原样
+	SymbolStrip         = "strip"         // 删除 emoji 与控制字符
+	SymbolTransliterate = "transliterate" // 常见符号替换为 ASCII，其余同 strip
+)
+
+// 部分目标平台拒绝 emoji，部分 CSV 工具会把它们写坏，transliterate 时尽量保留原意
+var symbolTransliterations = map[rune]string{
+	'★': "*", '☆': "*", '⭐': "*", '✰': "*",
+	'♥': "<3", '♡': "<3", '❤': "<3", '💕': "<3", '💖': "<3",
+	'♪': "~", '♫': "~", '♬': "~", '♩': "~", '🎵': "~", '🎶': "~",
+	'™': "TM", '©': "(C)", '®': "(R)", '℗': "(P)",
+	'✓': "v", '✔': "v", '✗': "x", '✘': "x",
+}
+
+// ValidateSymbols 校验 emoji 与控制字符的处理方式
+func ValidateSymbols(policy string) error {
+	switch policy {
+	case SymbolKeep, SymbolStrip, SymbolTransliterate:
+		return nil
+	}
+	return fmt.Errorf("不支持的符号处理方式：%v，可选：%v, %v", policy, SymbolStrip, SymbolTransliterate)
+}
+
+// ApplySymbols 按 policy 处理 emoji 与控制字符，换行、制表符等空白替换为空格，删除后产生的多余空白一并合并
+func ApplySymbols(s, policy string) string {
+	if policy == SymbolKeep {
+		return s
+	}
+	builder := strings.Builder{}
+	changed := false
+	for _, r := range s {
+		switch {
+		case unicode.IsSpace(r) && r != ' ':
+			builder.WriteRune(' ')
+		case unicode.IsControl(r), isEmojiPart(r):
+			// 删除
+		case unicode.Is(unicode.So, r):
+			if v, ok := symbolTransliterations[r]; ok && policy == SymbolTransliterate {
+				builder.WriteString(v)
+			}
+		default:
+			builder.WriteRune(r)
+			continue
+		}
+		changed = true
+	}
+	if !changed {
+		return s
+	}
+	return strings.Join(strings.Fields(builder.String()), " ")
+}
+
+// isEmojiPart 组成 emoji 序列但本身不可见的字符：零宽连接符、变体选择符、肤色修饰符及其他格式字符
+func isEmojiPart(r rune) bool {
+	return unicode.Is(unicode.Cf, r) || unicode.Is(unicode.Variation_Selector, r) || (r >= 0x1F3FB && r <= 0x1F3FF)
+}
@@ -0,0 +1,139 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	CaseNone     = ""
+	CaseTitle    = "title"
+	CaseSentence = "sentence"
+)
+
+// TitleRules 导出时对歌名的规范化规则，零值不做任何处理
+type TitleRules struct {
+	// Case 拉丁字母歌名的大小写：title（每个单词首字母大写）或 sentence（仅句首大写）
+	Case string
+	// CollapseSpace 合并连续空白并去除首尾空白
+	CollapseSpace bool
+	// UnifyBrackets 将【】、[]、（）等括号统一为半角圆括号
+	UnifyBrackets bool
+}
+
+// Title Case 中保持小写的虚词，位于首尾时除外
+var smallWords = map[string]struct{}{
+	"a": {}, "an": {}, "the": {}, "and": {}, "but": {}, "or": {}, "nor": {}, "of": {}, "in": {}, "on": {},
+	"at": {}, "to": {}, "for": {}, "by": {}, "with": {}, "from": {}, "as": {}, "vs": {}, "feat": {}, "ft": {},
+}
+
+var bracketReplacer = strings.NewReplacer(
+	"【", "(", "】", ")",
+	"[", "(", "]", ")",
+	"［", "(", "］", ")",
+	"（", "(", "）", ")",
+	"〔", "(", "〕", ")",
+	"「", "(", "」", ")",
+)
+
+func (r *TitleRules) Validate() error {
+	switch r.Case {
+	case CaseNone, CaseTitle, CaseSentence:
+		return nil
+	}
+	return fmt.Errorf("不支持的大小写规则：%v，可选：%v, %v", r.Case, CaseTitle, CaseSentence)
+}
+
+// Apply 对“歌名 - 歌手”中的歌名应用规则，歌手名保持不变
+func (r *TitleRules) Apply(song string) string {
+	title, artist := SplitSong(song)
+	title = r.ApplyTitle(title)
+	if !strings.Contains(song, " - ") {
+		return title
+	}
+	return title + " - " + artist
+}
+
+// ApplyTitle 对单独的歌名应用规则，用于结构化歌曲
+func (r *TitleRules) ApplyTitle(title string) string {
+	if r.UnifyBrackets {
+		title = bracketReplacer.Replace(title)
+	}
+	if r.CollapseSpace {
+		title = strings.Join(strings.Fields(title), " ")
+	}
+	if r.Case != CaseNone && isLatin(title) {
+		title = changeCase(title, r.Case)
+	}
+	return title
+}
+
+// isLatin 歌名中的字母均为拉丁字母时才调整大小写
+func isLatin(s string) bool {
+	for _, r := range s {
+		if unicode.IsLetter(r) && !unicode.Is(unicode.Latin, r) {
+			return false
+		}
+	}
+	return true
+}
+
+func changeCase(title, mode string) string {
+	words := strings.Split(title, " ")
+	first, last := -1, -1
+	for i, w := range words {
+		if w != "" {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	for i, w := range words {
+		if w == "" || isAcronym(w) {
+			continue
+		}
+		lower := strings.ToLower(w)
+		switch {
+		case mode == CaseSentence && i != first && lower != "i" && !strings.HasPrefix(lower, "i'"):
+			words[i] = lower
+		case mode == CaseTitle && i != first && i != last && isSmallWord(lower):
+			words[i] = lower
+		default:
+			words[i] = capitalize(lower)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// isAcronym 全大写的单词（如 AC/DC、USA）保持原样
+func isAcronym(w string) bool {
+	letters := 0
+	for _, r := range w {
+		if unicode.IsLetter(r) {
+			if !unicode.IsUpper(r) {
+				return false
+			}
+			letters++
+		}
+	}
+	return letters > 1
+}
+
+func isSmallWord(w string) bool {
+	_, ok := smallWords[strings.TrimRight(w, ".")]
+	return ok
+}
+
+// capitalize 首个字母大写，跳过开头的括号、引号等符号
+func capitalize(w string) string {
+	runes := []rune(w)
+	for i, r := range runes {
+		if unicode.IsLetter(r) {
+			runes[i] = unicode.ToUpper(r)
+			break
+		}
+	}
+	return string(runes)
+}
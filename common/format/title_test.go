@@ -0,0 +1,52 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTitleRules(t *testing.T) {
+	cases := []struct {
+		rules TitleRules
+		song  string
+		want  string
+	}{
+		{TitleRules{}, "hello  world - Adele", "hello  world - Adele"},
+		{TitleRules{Case: CaseTitle}, "the sound of silence - Simon & Garfunkel", "The Sound of Silence - Simon & Garfunkel"},
+		{TitleRules{Case: CaseTitle}, "back in black (live) - AC/DC", "Back in Black (Live) - AC/DC"},
+		{TitleRules{Case: CaseTitle}, "what are you waiting for - Gwen Stefani", "What Are You Waiting For - Gwen Stefani"},
+		{TitleRules{Case: CaseSentence}, "I WANT IT THAT WAY - Backstreet Boys", "I WANT IT THAT WAY - Backstreet Boys"},
+		{TitleRules{Case: CaseSentence}, "Bad Romance Remix - Lady Gaga", "Bad romance remix - Lady Gaga"},
+		{TitleRules{Case: CaseTitle}, "晴天 live - 周杰伦", "晴天 live - 周杰伦"},
+		{TitleRules{CollapseSpace: true}, "  江南   (Live)  - 林俊杰", "江南 (Live) - 林俊杰"},
+		{TitleRules{UnifyBrackets: true}, "江南【Live】[Remix] - 林俊杰", "江南(Live)(Remix) - 林俊杰"},
+		{TitleRules{Case: CaseTitle}, "no artist", "No Artist"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, c.rules.Apply(c.song), c.song)
+	}
+	assert.Error(t, (&TitleRules{Case: "upper"}).Validate())
+	assert.NoError(t, (&TitleRules{Case: CaseSentence}).Validate())
+}
+
+func TestApplySymbols(t *testing.T) {
+	cases := []struct {
+		policy string
+		s      string
+		want   string
+	}{
+		{SymbolKeep, "晴天 ☀️ - 周杰伦", "晴天 ☀️ - 周杰伦"},
+		{SymbolStrip, "晴天 ☀️ - 周杰伦", "晴天 - 周杰伦"},
+		{SymbolStrip, "Family 👨‍👩‍👧 👍🏽 - A", "Family - A"},
+		{SymbolStrip, "Line\nBreak\x07 - B", "Line Break - B"},
+		{SymbolTransliterate, "Love ♥ Song★ - C™", "Love <3 Song* - CTM"},
+		{SymbolTransliterate, "晴天 ☀️ - 周杰伦", "晴天 - 周杰伦"},
+		{SymbolStrip, "普通  歌名 - D", "普通  歌名 - D"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, ApplySymbols(c.s, c.policy), c.s)
+	}
+	assert.Error(t, ValidateSymbols("ascii"))
+	assert.NoError(t, ValidateSymbols(SymbolTransliterate))
+}
@@ -0,0 +1,247 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// ResolveFunc 字段解析函数，source 为父对象，根字段的 source 为请求的 context.Context
+type ResolveFunc func(source any, args map[string]any) (any, error)
+
+// Object 对象类型
+type Object struct {
+	Name   string
+	Fields map[string]*FieldDef
+}
+
+// FieldDef 字段定义，Type 为空表示标量，返回切片时按列表处理
+type FieldDef struct {
+	Type    *Object
+	Resolve ResolveFunc
+}
+
+type Schema struct {
+	Query *Object
+	// MaxFields 单个操作最多选择的字段数，含嵌套字段与别名重复的字段，0 表示不限制
+	MaxFields int
+	// MaxRootFields 单个操作最多选择的根字段数，根字段通常各自请求一次上游，0 表示不限制
+	MaxRootFields int
+}
+
+type Request struct {
+	Query         string         `json:"query" form:"query"`
+	OperationName string         `json:"operationName" form:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+type Error struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+type Response struct {
+	Data   *OrderedMap `json:"data"`
+	Errors []*Error    `json:"errors,omitempty"`
+}
+
+// OrderedMap 按选择集顺序输出字段，encoding/json 对 map 会按 key 排序
+type OrderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+func newOrderedMap(size int) *OrderedMap {
+	return &OrderedMap{keys: make([]string, 0, size), values: make(map[string]any, size)}
+}
+
+func (m *OrderedMap) Set(key string, value any) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+func (m *OrderedMap) Get(key string) any {
+	return m.values[key]
+}
+
+func (m *OrderedMap) MarshalJSON() ([]byte, error) {
+	if m == nil {
+		return []byte("null"), nil
+	}
+	buf := bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, k := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(k)
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(m.values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Execute 执行查询，字段级错误记录在 Errors 中，不影响其他字段
+func (s *Schema) Execute(ctx context.Context, req *Request) *Response {
+	doc, err := Parse(req.Query)
+	if err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+	op, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+	if err = s.checkLimits(op); err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+	e := &executor{variables: req.Variables}
+	data := e.executeSelections(s.Query, ctx, op.Selections, nil)
+	return &Response{Data: data, Errors: e.errors}
+}
+
+func selectOperation(doc *Document, name string) (*Operation, error) {
+	if name == "" {
+		if len(doc.Operations) > 1 {
+			return nil, fmt.Errorf("operationName is required for multiple operations")
+		}
+		return doc.Operations[0], nil
+	}
+	for _, op := range doc.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operation %q", name)
+}
+
+// checkLimits 执行前检查选择的字段数，避免通过别名重复同一字段放大请求
+func (s *Schema) checkLimits(op *Operation) error {
+	if s.MaxRootFields > 0 && len(op.Selections) > s.MaxRootFields {
+		return fmt.Errorf("query selects %d root fields, exceeds the limit of %d", len(op.Selections), s.MaxRootFields)
+	}
+	if n := countFields(op.Selections); s.MaxFields > 0 && n > s.MaxFields {
+		return fmt.Errorf("query selects %d fields, exceeds the limit of %d", n, s.MaxFields)
+	}
+	return nil
+}
+
+func countFields(fields []*Field) int {
+	n := len(fields)
+	for _, v := range fields {
+		n += countFields(v.Selections)
+	}
+	return n
+}
+
+type executor struct {
+	variables map[string]any
+	errors    []*Error
+}
+
+func (e *executor) fail(path []any, format string, args ...any) {
+	e.errors = append(e.errors, &Error{Message: fmt.Sprintf(format, args...), Path: append([]any{}, path...)})
+}
+
+func (e *executor) executeSelections(obj *Object, source any, fields []*Field, path []any) *OrderedMap {
+	result := newOrderedMap(len(fields))
+	for _, field := range fields {
+		key := field.ResponseKey()
+		fieldPath := append(path, key)
+		if field.Name == "__typename" {
+			result.Set(key, obj.Name)
+			continue
+		}
+		def, ok := obj.Fields[field.Name]
+		if !ok {
+			e.fail(fieldPath, "cannot query field %q on type %q", field.Name, obj.Name)
+			result.Set(key, nil)
+			continue
+		}
+		value, err := def.Resolve(source, e.resolveArgs(field.Args))
+		if err != nil {
+			e.fail(fieldPath, "%v", err)
+			result.Set(key, nil)
+			continue
+		}
+		result.Set(key, e.completeValue(def, field, value, fieldPath))
+	}
+	return result
+}
+
+func (e *executor) completeValue(def *FieldDef, field *Field, value any, path []any) any {
+	if def.Type == nil {
+		if len(field.Selections) > 0 {
+			e.fail(path, "field %q must not have a selection", field.Name)
+			return nil
+		}
+		return value
+	}
+	if len(field.Selections) == 0 {
+		e.fail(path, "field %q of type %q must have a selection", field.Name, def.Type.Name)
+		return nil
+	}
+	if value == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil
+	}
+	if rv.Kind() == reflect.Slice {
+		list := make([]any, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			list = append(list, e.executeSelections(def.Type, rv.Index(i).Interface(), field.Selections, append(path, i)))
+		}
+		return list
+	}
+	return e.executeSelections(def.Type, value, field.Selections, path)
+}
+
+func (e *executor) resolveArgs(args map[string]any) map[string]any {
+	resolved := make(map[string]any, len(args))
+	for k, v := range args {
+		resolved[k] = e.resolveValue(v)
+	}
+	return resolved
+}
+
+func (e *executor) resolveValue(v any) any {
+	switch value := v.(type) {
+	case Variable:
+		return e.variables[string(value)]
+	case []any:
+		list := make([]any, 0, len(value))
+		for _, item := range value {
+			list = append(list, e.resolveValue(item))
+		}
+		return list
+	}
+	return v
+}
+
+// StringArg 获取字符串参数
+func StringArg(args map[string]any, name string) (string, bool) {
+	s, ok := args[name].(string)
+	return s, ok
+}
+
+// IntArg 获取整型参数，变量经 JSON 解码后为 float64
+func IntArg(args map[string]any, name string, defaultValue int) int {
+	switch v := args[name].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return defaultValue
+}
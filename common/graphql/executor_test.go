@@ -0,0 +1,95 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type item struct {
+	Name string
+	Tags []string
+}
+
+var itemType = &Object{
+	Name: "Item",
+	Fields: map[string]*FieldDef{
+		"name": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*item).Name, nil
+		}},
+		"tags": {Resolve: func(source any, args map[string]any) (any, error) {
+			tags := source.(*item).Tags
+			if limit := IntArg(args, "limit", len(tags)); limit < len(tags) {
+				tags = tags[:limit]
+			}
+			return tags, nil
+		}},
+	},
+}
+
+var testSchema = &Schema{Query: &Object{
+	Name: "Query",
+	Fields: map[string]*FieldDef{
+		"item": {Type: itemType, Resolve: func(_ any, args map[string]any) (any, error) {
+			name, _ := StringArg(args, "name")
+			if name == "" {
+				return nil, errors.New("name is required")
+			}
+			return &item{Name: name, Tags: []string{"a", "b", "c"}}, nil
+		}},
+		"items": {Type: itemType, Resolve: func(_ any, _ map[string]any) (any, error) {
+			return []*item{{Name: "x"}, {Name: "y"}}, nil
+		}},
+	},
+}}
+
+func execute(t *testing.T, req *Request) string {
+	marshal, err := json.Marshal(testSchema.Execute(context.Background(), req))
+	assert.NoError(t, err)
+	return string(marshal)
+}
+
+func TestExecute(t *testing.T) {
+	t.Run("selection order and alias", func(t *testing.T) {
+		rs := execute(t, &Request{Query: `{ first: item(name: "x") { tags(limit: 2) name } }`})
+		assert.Equal(t, `{"data":{"first":{"tags":["a","b"],"name":"x"}}}`, rs)
+	})
+	t.Run("variables", func(t *testing.T) {
+		rs := execute(t, &Request{
+			Query:     `query Q($name: String!, $limit: Int) { item(name: $name) { name tags(limit: $limit) } }`,
+			Variables: map[string]any{"name": "v", "limit": float64(1)},
+		})
+		assert.Equal(t, `{"data":{"item":{"name":"v","tags":["a"]}}}`, rs)
+	})
+	t.Run("list", func(t *testing.T) {
+		rs := execute(t, &Request{Query: `{ items { __typename name } }`})
+		assert.Equal(t, `{"data":{"items":[{"__typename":"Item","name":"x"},{"__typename":"Item","name":"y"}]}}`, rs)
+	})
+	t.Run("field error", func(t *testing.T) {
+		rs := execute(t, &Request{Query: `{ item { name } unknown }`})
+		assert.Equal(t, `{"data":{"item":null,"unknown":null},"errors":[{"message":"name is required","path":["item"]},{"message":"cannot query field \"unknown\" on type \"Query\"","path":["unknown"]}]}`, rs)
+	})
+	t.Run("syntax error", func(t *testing.T) {
+		rs := execute(t, &Request{Query: `{ item(name: "x") { name }`})
+		assert.Contains(t, rs, `"errors"`)
+	})
+}
+
+func TestLimits(t *testing.T) {
+	schema := &Schema{Query: testSchema.Query, MaxFields: 4, MaxRootFields: 2}
+	rs := schema.Execute(context.Background(), &Request{Query: `{ a: item(name: "a") { name } b: item(name: "b") { name } }`})
+	assert.Empty(t, rs.Errors)
+	// 通过别名重复根字段
+	rs = schema.Execute(context.Background(), &Request{Query: `{ a: item(name: "a") { name } b: item(name: "b") { name } c: item(name: "c") { name } }`})
+	if assert.Len(t, rs.Errors, 1) {
+		assert.Contains(t, rs.Errors[0].Message, "root fields")
+	}
+	assert.Nil(t, rs.Data)
+	rs = schema.Execute(context.Background(), &Request{Query: `{ items { name n1: name n2: name n3: name } }`})
+	if assert.Len(t, rs.Errors, 1) {
+		assert.Contains(t, rs.Errors[0].Message, "5 fields")
+	}
+}
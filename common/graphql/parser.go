@@ -0,0 +1,312 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 仅支持 GraphQL 查询语言的常用子集：query 操作、别名、参数、变量与嵌套选择集，
+// 不支持 fragment、directive 与 mutation
+
+type Document struct {
+	Operations []*Operation
+}
+
+type Operation struct {
+	Type       string
+	Name       string
+	Selections []*Field
+}
+
+type Field struct {
+	Alias      string
+	Name       string
+	Args       map[string]any
+	Selections []*Field
+}
+
+// Variable 参数中引用的变量，执行时替换为实际值
+type Variable string
+
+// ResponseKey 返回结果中使用的字段名
+func (f *Field) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+const (
+	tokenEOF = iota
+	tokenName
+	tokenString
+	tokenInt
+	tokenFloat
+	tokenPunct
+)
+
+type token struct {
+	kind  int
+	value string
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// Parse 解析查询语句
+func Parse(query string) (*Document, error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	doc := &Document{}
+	for p.peek().kind != tokenEOF {
+		op, err := p.parseOperation()
+		if err != nil {
+			return nil, err
+		}
+		doc.Operations = append(doc.Operations, op)
+	}
+	if len(doc.Operations) == 0 {
+		return nil, fmt.Errorf("empty document")
+	}
+	return doc, nil
+}
+
+func lex(s string) ([]token, error) {
+	tokens := make([]token, 0, 32)
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r) || r == ',' || r == '\uFEFF':
+			i++
+		case r == '#': // 注释
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case strings.ContainsRune("{}():$!=[]", r):
+			tokens = append(tokens, token{kind: tokenPunct, value: string(r)})
+			i++
+		case r == '"':
+			j := i + 1
+			builder := strings.Builder{}
+			for ; j < len(runes) && runes[j] != '"'; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+					switch runes[j] {
+					case 'n':
+						builder.WriteRune('\n')
+					case 't':
+						builder.WriteRune('\t')
+					default:
+						builder.WriteRune(runes[j])
+					}
+					continue
+				}
+				builder.WriteRune(runes[j])
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, token{kind: tokenString, value: builder.String()})
+			i = j + 1
+		case r == '-' || unicode.IsDigit(r):
+			j := i + 1
+			kind := tokenInt
+			for ; j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.' || runes[j] == 'e' || runes[j] == 'E'); j++ {
+				if !unicode.IsDigit(runes[j]) {
+					kind = tokenFloat
+				}
+			}
+			tokens = append(tokens, token{kind: kind, value: string(runes[i:j])})
+			i = j
+		case r == '_' || unicode.IsLetter(r):
+			j := i + 1
+			for ; j < len(runes) && (runes[j] == '_' || unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j])); j++ {
+			}
+			tokens = append(tokens, token{kind: tokenName, value: string(runes[i:j])})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q", r)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF}), nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expect(punct string) error {
+	if t := p.next(); t.kind != tokenPunct || t.value != punct {
+		return fmt.Errorf("expected %q, got %q", punct, t.value)
+	}
+	return nil
+}
+
+func (p *parser) isPunct(punct string) bool {
+	t := p.peek()
+	return t.kind == tokenPunct && t.value == punct
+}
+
+func (p *parser) parseOperation() (*Operation, error) {
+	op := &Operation{Type: "query"}
+	if t := p.peek(); t.kind == tokenName {
+		if t.value != "query" {
+			return nil, fmt.Errorf("unsupported operation %q", t.value)
+		}
+		p.next()
+		if p.peek().kind == tokenName {
+			op.Name = p.next().value
+		}
+		if p.isPunct("(") {
+			if err := p.skipVariableDefinitions(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	selections, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.Selections = selections
+	return op, nil
+}
+
+// 变量类型由解析器忽略，实际值在执行阶段从 variables 中读取
+func (p *parser) skipVariableDefinitions() error {
+	depth := 0
+	for {
+		t := p.next()
+		switch {
+		case t.kind == tokenEOF:
+			return fmt.Errorf("unterminated variable definitions")
+		case t.kind == tokenPunct && t.value == "(":
+			depth++
+		case t.kind == tokenPunct && t.value == ")":
+			depth--
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+func (p *parser) parseSelectionSet() ([]*Field, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	fields := make([]*Field, 0, 4)
+	for !p.isPunct("}") {
+		field, err := p.parseField()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	p.next()
+	return fields, nil
+}
+
+func (p *parser) parseField() (*Field, error) {
+	t := p.next()
+	if t.kind != tokenName {
+		return nil, fmt.Errorf("expected field name, got %q", t.value)
+	}
+	field := &Field{Name: t.value}
+	if p.isPunct(":") {
+		p.next()
+		t = p.next()
+		if t.kind != tokenName {
+			return nil, fmt.Errorf("expected field name after alias %q", field.Name)
+		}
+		field.Alias, field.Name = field.Name, t.value
+	}
+	if p.isPunct("(") {
+		p.next()
+		field.Args = make(map[string]any)
+		for !p.isPunct(")") {
+			name := p.next()
+			if name.kind != tokenName {
+				return nil, fmt.Errorf("expected argument name, got %q", name.value)
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			field.Args[name.value] = value
+		}
+		p.next()
+	}
+	if p.isPunct("{") {
+		selections, err := p.parseSelectionSet()
+		if err != nil {
+			return nil, err
+		}
+		field.Selections = selections
+	}
+	return field, nil
+}
+
+func (p *parser) parseValue() (any, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenString:
+		return t.value, nil
+	case tokenInt:
+		return strconv.Atoi(t.value)
+	case tokenFloat:
+		return strconv.ParseFloat(t.value, 64)
+	case tokenName:
+		switch t.value {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		return t.value, nil // 枚举值按字符串处理
+	case tokenPunct:
+		switch t.value {
+		case "$":
+			name := p.next()
+			if name.kind != tokenName {
+				return nil, fmt.Errorf("expected variable name")
+			}
+			return Variable(name.value), nil
+		case "[":
+			list := make([]any, 0)
+			for !p.isPunct("]") {
+				if p.peek().kind == tokenEOF {
+					return nil, fmt.Errorf("unterminated list")
+				}
+				value, err := p.parseValue()
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, value)
+			}
+			p.next()
+			return list, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected token %q", t.value)
+}
@@ -0,0 +1,165 @@
+package local
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"GoMusic/common/format"
+	"GoMusic/common/utils"
+)
+
+// titleThreshold 标签中的歌名与歌单歌名的最低相似度
+const titleThreshold = 0.85
+
+var audioExtensions = map[string]struct{}{
+	".mp3": {}, ".flac": {}, ".m4a": {}, ".aac": {}, ".ogg": {}, ".opus": {}, ".wav": {}, ".ape": {}, ".wma": {},
+}
+
+// Comparison 歌单与本地音乐的对比结果
+type Comparison struct {
+	Total   int      `json:"total"`
+	Found   int      `json:"found"`
+	Missing []string `json:"missing"`
+	// Pairs 按歌单顺序逐首给出匹配到的本地文件，前端可据此直接渲染左右对照视图
+	Pairs []*Pair `json:"pairs"`
+	// Extra 未被歌单中任何歌曲匹配的本地文件
+	Extra []string `json:"extra"`
+}
+
+// Pair 歌单中的一首歌曲与匹配到的本地文件，Path 为空表示本地没有
+type Pair struct {
+	Index int    `json:"index"`
+	Song  string `json:"song"`
+	Path  string `json:"path,omitempty"`
+}
+
+// Scan 递归扫描目录中的音乐文件
+func Scan(dir string) ([]*Track, error) {
+	tracks := make([]*Track, 0)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// 跳过无权限的子目录，根目录不可读时返回错误
+			if path != dir && os.IsPermission(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if _, ok := audioExtensions[strings.ToLower(filepath.Ext(path))]; ok {
+			tracks = append(tracks, readTrack(path))
+		}
+		return nil
+	})
+	return tracks, err
+}
+
+// Compare 找出歌单中本地没有的歌曲：有标签时按歌名相似度与歌手匹配，否则要求文件名同时包含歌名与歌手；
+// 伴奏等无人声版本只与同样标注的本地文件匹配
+func Compare(songs []string, tracks []*Track) *Comparison {
+	type candidate struct {
+		title, artist string // 标签，已规范化
+		filename      string
+		path          string
+		matched       bool
+		instrumental  bool
+	}
+	candidates := make([]*candidate, 0, len(tracks))
+	byTitle := make(map[string][]*candidate, len(tracks))
+	for _, v := range tracks {
+		c := &candidate{title: normalize(v.Title), artist: normalize(v.Artist), path: v.Path}
+		if c.title == "" {
+			base := strings.TrimSuffix(filepath.Base(v.Path), filepath.Ext(v.Path))
+			c.filename = normalize(base)
+			c.instrumental = format.HasInstrumentalMark(base)
+		} else {
+			c.instrumental = format.HasInstrumentalMark(v.Title)
+			byTitle[c.title] = append(byTitle[c.title], c)
+		}
+		candidates = append(candidates, c)
+	}
+
+	artistMatch := func(c *candidate, artists []string, instrumental bool) bool {
+		// 原唱与伴奏互不匹配，避免把伴奏当作已收藏的原唱
+		if c.instrumental != instrumental {
+			return false
+		}
+		if len(artists) == 0 || (c.filename == "" && c.artist == "") {
+			return true
+		}
+		for _, a := range artists {
+			if c.filename != "" && strings.Contains(c.filename, a) {
+				return true
+			}
+			if c.artist != "" && (strings.Contains(c.artist, a) || strings.Contains(a, c.artist)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	result := &Comparison{Total: len(songs), Missing: make([]string, 0), Pairs: make([]*Pair, 0, len(songs)), Extra: make([]string, 0)}
+	for i, song := range songs {
+		title, artist := format.SplitSong(song)
+		instrumental := format.IsInstrumental(song)
+		rawTitle, t := title, normalize(title)
+		artists := make([]string, 0)
+		for _, v := range strings.Split(artist, " / ") {
+			if a := normalize(v); a != "" {
+				artists = append(artists, a)
+			}
+		}
+
+		var match *candidate
+		// 先按歌名精确查找，找不到时再逐个计算相似度
+		for _, c := range byTitle[t] {
+			if artistMatch(c, artists, instrumental) {
+				match = c
+				break
+			}
+		}
+		for j := 0; match == nil && j < len(candidates); j++ {
+			c, found := candidates[j], false
+			switch {
+			case c.filename != "":
+				found = t != "" && strings.Contains(c.filename, t) && artistMatch(c, artists, instrumental)
+			case c.title != t:
+				found = utils.NameSimilarity(rawTitle, c.title) >= titleThreshold && artistMatch(c, artists, instrumental)
+			}
+			if found {
+				match = c
+			}
+		}
+
+		pair := &Pair{Index: i, Song: song}
+		if match != nil {
+			match.matched = true
+			pair.Path = match.path
+			result.Found++
+		} else {
+			result.Missing = append(result.Missing, song)
+		}
+		result.Pairs = append(result.Pairs, pair)
+	}
+	for _, c := range candidates {
+		if !c.matched {
+			result.Extra = append(result.Extra, c.path)
+		}
+	}
+	return result
+}
+
+// normalize 小写并去除空白与标点，便于比较
+func normalize(s string) string {
+	builder := strings.Builder{}
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,97 @@
+package local
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func id3v23(frames map[string]string) []byte {
+	body := make([]byte, 0)
+	for id, text := range frames {
+		frame := append([]byte{3}, text...) // UTF-8
+		header := append([]byte(id), 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(header[4:], uint32(len(frame)))
+		body = append(body, header...)
+		body = append(body, frame...)
+	}
+	size := len(body)
+	header := []byte{'I', 'D', '3', 3, 0, 0, byte(size >> 21 & 0x7F), byte(size >> 14 & 0x7F), byte(size >> 7 & 0x7F), byte(size & 0x7F)}
+	return append(header, body...)
+}
+
+func flac(comments ...string) []byte {
+	block := binary.LittleEndian.AppendUint32(nil, 0) // vendor 为空
+	block = binary.LittleEndian.AppendUint32(block, uint32(len(comments)))
+	for _, v := range comments {
+		block = binary.LittleEndian.AppendUint32(block, uint32(len(v)))
+		block = append(block, v...)
+	}
+	data := []byte("fLaC")
+	data = append(data, 0x80|4, byte(len(block)>>16), byte(len(block)>>8), byte(len(block)))
+	return append(data, block...)
+}
+
+func TestScanAndCompare(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0o755))
+	files := map[string][]byte{
+		"a.mp3":                id3v23(map[string]string{"TIT2": "江南", "TPE1": "林俊杰"}),
+		"sub/b.flac":           flac("TITLE=Hello", "ARTIST=Adele"),
+		"sub/周杰伦 - 晴天.m4a":     nil,
+		"cover.jpg":            nil,
+		"Bad Guy (Remix).mp3":  nil,
+		"readme.txt":           []byte("not music"),
+		"sub/unknown-tags.ogg": []byte("OggS"),
+		"陈奕迅 - 十年 (伴奏).mp3":    nil,
+	}
+	for name, data := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0o644))
+	}
+
+	tracks, err := Scan(dir)
+	assert.NoError(t, err)
+	assert.Len(t, tracks, 6)
+	for _, v := range tracks {
+		switch filepath.Base(v.Path) {
+		case "a.mp3":
+			assert.Equal(t, "江南", v.Title)
+			assert.Equal(t, "林俊杰", v.Artist)
+		case "b.flac":
+			assert.Equal(t, "Hello", v.Title)
+			assert.Equal(t, "Adele", v.Artist)
+		}
+	}
+
+	songs := []string{"江南 - 林俊杰", "Hello - Adele", "晴天 - 周杰伦", "江南 - 其他人", "Bad Guy - Billie Eilish", "十年 - 陈奕迅"}
+	result := Compare(songs, tracks)
+	assert.Equal(t, 6, result.Total)
+	assert.Equal(t, 3, result.Found)
+	assert.Equal(t, []string{"江南 - 其他人", "Bad Guy - Billie Eilish", "十年 - 陈奕迅"}, result.Missing)
+
+	assert.Len(t, result.Pairs, 6)
+	for i, v := range result.Pairs {
+		assert.Equal(t, i, v.Index)
+		assert.Equal(t, songs[i], v.Song)
+	}
+	assert.Equal(t, filepath.Join(dir, "a.mp3"), result.Pairs[0].Path)
+	assert.Equal(t, filepath.Join(dir, "sub/b.flac"), result.Pairs[1].Path)
+	assert.Equal(t, filepath.Join(dir, "sub/周杰伦 - 晴天.m4a"), result.Pairs[2].Path)
+	assert.Empty(t, result.Pairs[3].Path)
+	// 伴奏不算作原唱
+	assert.Empty(t, result.Pairs[5].Path)
+	assert.ElementsMatch(t, []string{filepath.Join(dir, "Bad Guy (Remix).mp3"), filepath.Join(dir, "sub/unknown-tags.ogg"), filepath.Join(dir, "陈奕迅 - 十年 (伴奏).mp3")}, result.Extra)
+
+	result = Compare([]string{"十年 (伴奏) - 陈奕迅"}, tracks)
+	assert.Equal(t, 1, result.Found)
+}
+
+func TestDecodeText(t *testing.T) {
+	assert.Equal(t, "江南", decodeText([]byte{0, 0xBD, 0xAD, 0xC4, 0xCF})) // GBK
+	assert.Equal(t, "Hi", decodeText([]byte{1, 0xFF, 0xFE, 'H', 0, 'i', 0, 0, 0}))
+	assert.Equal(t, "Hi", decodeText([]byte{2, 0, 'H', 0, 'i'}))
+	assert.Equal(t, "江南", decodeText(append([]byte{3}, "江南\x00"...)))
+}
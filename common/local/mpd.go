@@ -0,0 +1,73 @@
+package local
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PathMapping 将扫描时的路径前缀 From 替换为 MPD 所见的路径 To，用于音乐目录挂载在不同位置的情况
+type PathMapping struct {
+	From string
+	To   string
+}
+
+// ParsePathMappings 解析 from=to 形式的路径映射，无法解析的项被忽略
+func ParsePathMappings(items []string) []*PathMapping {
+	mappings := make([]*PathMapping, 0, len(items))
+	for _, item := range items {
+		from, to, ok := strings.Cut(item, "=")
+		if from = strings.TrimSpace(from); !ok || from == "" {
+			continue
+		}
+		mappings = append(mappings, &PathMapping{From: filepath.Clean(from), To: strings.TrimSpace(to)})
+	}
+	// 优先匹配最长的前缀
+	sort.SliceStable(mappings, func(i, j int) bool {
+		return len(mappings[i].From) > len(mappings[j].From)
+	})
+	return mappings
+}
+
+// WriteMPDPlaylist 按歌单顺序将匹配到的本地文件写为 MPD 播放列表，每行一个路径，本地没有的歌曲被跳过。
+// 路径按 mappings 替换前缀；未匹配任何映射时输出相对 root 的路径，MPD 按 music_directory 解析，
+// 因此 root 应为 MPD 的 music_directory 在本机的位置。返回写入的歌曲数
+func WriteMPDPlaylist(w io.Writer, pairs []*Pair, root string, mappings []*PathMapping) (int, error) {
+	count := 0
+	for _, v := range pairs {
+		if v.Path == "" {
+			continue
+		}
+		if _, err := fmt.Fprintln(w, mpdPath(v.Path, root, mappings)); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+func mpdPath(path, root string, mappings []*PathMapping) string {
+	for _, v := range mappings {
+		if rel, ok := relPath(v.From, path); ok {
+			if v.To == "" {
+				return rel
+			}
+			return strings.TrimSuffix(filepath.ToSlash(v.To), "/") + "/" + rel
+		}
+	}
+	if rel, ok := relPath(root, path); ok {
+		return rel
+	}
+	return filepath.ToSlash(path)
+}
+
+// relPath path 位于 base 之下时返回以 / 分隔的相对路径
+func relPath(base, path string) (string, bool) {
+	rel, err := filepath.Rel(base, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
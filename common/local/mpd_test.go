@@ -0,0 +1,27 @@
+package local
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteMPDPlaylist(t *testing.T) {
+	pairs := []*Pair{
+		{Index: 0, Song: "江南 - 林俊杰", Path: "/mnt/nas/music/林俊杰/江南.mp3"},
+		{Index: 1, Song: "十年 - 陈奕迅"},
+		{Index: 2, Song: "Hello - Adele", Path: "/mnt/nas/music/lossless/Hello.flac"},
+		{Index: 3, Song: "晴天 - 周杰伦", Path: "/mnt/nas/musicbox/晴天.m4a"},
+		{Index: 4, Song: "Bad Guy - Billie Eilish", Path: "/home/me/Bad Guy.mp3"},
+	}
+	mappings := ParsePathMappings([]string{"/mnt/nas/music/lossless=/srv/flac", "bad", "/home/me/=", "=x"})
+	assert.Equal(t, []*PathMapping{{From: "/mnt/nas/music/lossless", To: "/srv/flac"}, {From: "/home/me", To: ""}}, mappings)
+
+	buf := &bytes.Buffer{}
+	count, err := WriteMPDPlaylist(buf, pairs, "/mnt/nas/music", mappings)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, count)
+	// 未映射且不在 root 下的路径原样输出；/mnt/nas/musicbox 不属于 /mnt/nas/music
+	assert.Equal(t, "林俊杰/江南.mp3\n/srv/flac/Hello.flac\n/mnt/nas/musicbox/晴天.m4a\nBad Guy.mp3\n", buf.String())
+}
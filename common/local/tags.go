@@ -0,0 +1,199 @@
+package local
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// Track 本地音乐文件，无法读取标签时 Title 与 Artist 为空，按文件名匹配
+type Track struct {
+	Path   string `json:"path"`
+	Title  string `json:"title"`
+	Artist string `json:"artist"`
+}
+
+// maxTagSize 只读取文件头部的标签，封面图片较大时可能超过该长度
+const maxTagSize = 1 << 20
+
+func readTrack(path string) *Track {
+	track := &Track{Path: path}
+	f, err := os.Open(path)
+	if err != nil {
+		return track
+	}
+	defer f.Close()
+
+	head := make([]byte, maxTagSize)
+	n, _ := io.ReadFull(f, head)
+	head = head[:n]
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".flac":
+		track.Title, track.Artist = readVorbisComment(head)
+	case ".mp3":
+		track.Title, track.Artist = readID3v2(head)
+		if track.Title == "" {
+			track.Title, track.Artist = readID3v1(f)
+		}
+	}
+	return track
+}
+
+// readID3v2 读取 ID3v2.2～2.4 的标题（TIT2/TT2）与歌手（TPE1/TP1）
+func readID3v2(data []byte) (title, artist string) {
+	if len(data) < 10 || string(data[:3]) != "ID3" {
+		return "", ""
+	}
+	version := data[3]
+	end := 10 + syncsafe(data[6:10])
+	if end > len(data) {
+		end = len(data)
+	}
+	idSize, headerSize := 4, 10
+	if version == 2 {
+		idSize, headerSize = 3, 6
+	}
+	for pos := 10; pos+headerSize <= end; {
+		id := string(data[pos : pos+idSize])
+		var size int
+		switch version {
+		case 2:
+			size = int(data[pos+3])<<16 | int(data[pos+4])<<8 | int(data[pos+5])
+		case 3:
+			size = int(binary.BigEndian.Uint32(data[pos+4 : pos+8]))
+		default:
+			size = syncsafe(data[pos+4 : pos+8])
+		}
+		if id[0] == 0 || size <= 0 || pos+headerSize+size > end {
+			break
+		}
+		body := data[pos+headerSize : pos+headerSize+size]
+		switch id {
+		case "TIT2", "TT2":
+			title = decodeText(body)
+		case "TPE1", "TP1":
+			artist = decodeText(body)
+		}
+		pos += headerSize + size
+	}
+	return title, artist
+}
+
+func readID3v1(f *os.File) (title, artist string) {
+	info, err := f.Stat()
+	if err != nil || info.Size() < 128 {
+		return "", ""
+	}
+	tag := make([]byte, 128)
+	if _, err := f.ReadAt(tag, info.Size()-128); err != nil || string(tag[:3]) != "TAG" {
+		return "", ""
+	}
+	return decodeLatin(bytes.TrimRight(tag[3:33], "\x00 ")), decodeLatin(bytes.TrimRight(tag[33:63], "\x00 "))
+}
+
+// readVorbisComment 读取 FLAC 的 TITLE 与 ARTIST
+func readVorbisComment(data []byte) (title, artist string) {
+	if len(data) < 4 || string(data[:4]) != "fLaC" {
+		return "", ""
+	}
+	for pos := 4; pos+4 <= len(data); {
+		last, kind := data[pos]&0x80 != 0, data[pos]&0x7F
+		size := int(data[pos+1])<<16 | int(data[pos+2])<<8 | int(data[pos+3])
+		pos += 4
+		if pos+size > len(data) {
+			break
+		}
+		if kind == 4 {
+			return parseVorbisComment(data[pos : pos+size])
+		}
+		if last {
+			break
+		}
+		pos += size
+	}
+	return "", ""
+}
+
+func parseVorbisComment(block []byte) (title, artist string) {
+	if len(block) < 8 {
+		return "", ""
+	}
+	pos := 4 + int(binary.LittleEndian.Uint32(block))
+	if pos+4 > len(block) {
+		return "", ""
+	}
+	count := int(binary.LittleEndian.Uint32(block[pos:]))
+	pos += 4
+	for i := 0; i < count && pos+4 <= len(block); i++ {
+		size := int(binary.LittleEndian.Uint32(block[pos:]))
+		pos += 4
+		if pos+size > len(block) {
+			break
+		}
+		key, value, _ := strings.Cut(string(block[pos:pos+size]), "=")
+		switch strings.ToUpper(key) {
+		case "TITLE":
+			title = value
+		case "ARTIST":
+			if artist == "" {
+				artist = value
+			} else {
+				artist += " / " + value
+			}
+		}
+		pos += size
+	}
+	return title, artist
+}
+
+func syncsafe(b []byte) int {
+	return int(b[0]&0x7F)<<21 | int(b[1]&0x7F)<<14 | int(b[2]&0x7F)<<7 | int(b[3]&0x7F)
+}
+
+// decodeText 解码 ID3v2 文本帧，首字节为编码：0 ISO-8859-1、1 带 BOM 的 UTF-16、2 UTF-16BE、3 UTF-8
+func decodeText(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	text := body[1:]
+	switch body[0] {
+	case 1, 2:
+		order := binary.ByteOrder(binary.BigEndian)
+		if body[0] == 1 && len(text) >= 2 {
+			if text[0] == 0xFF && text[1] == 0xFE {
+				order = binary.LittleEndian
+			}
+			text = text[2:]
+		}
+		units := make([]uint16, 0, len(text)/2)
+		for i := 0; i+1 < len(text); i += 2 {
+			units = append(units, order.Uint16(text[i:]))
+		}
+		return strings.TrimRight(string(utf16.Decode(units)), "\x00")
+	case 3:
+		return strings.TrimRight(string(text), "\x00")
+	}
+	return decodeLatin(bytes.TrimRight(text, "\x00"))
+}
+
+// decodeLatin 许多中文软件在 ISO-8859-1 位置写入 GBK 编码的文本
+func decodeLatin(b []byte) string {
+	if utf8.Valid(b) {
+		return string(b)
+	}
+	if decoded, err := simplifiedchinese.GBK.NewDecoder().Bytes(b); err == nil {
+		return string(decoded)
+	}
+	runes := make([]rune, 0, len(b))
+	for _, c := range b {
+		runes = append(runes, rune(c))
+	}
+	return string(runes)
+}
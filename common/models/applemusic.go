@@ -0,0 +1,37 @@
+package models
+
+type AppleMusicStorefront struct {
+	Data []struct {
+		Id         string `json:"id"`
+		Attributes struct {
+			DefaultLanguageTag    string   `json:"defaultLanguageTag"`
+			SupportedLanguageTags []string `json:"supportedLanguageTags"`
+		} `json:"attributes"`
+	} `json:"data"`
+}
+
+type AppleMusicSearch struct {
+	Results struct {
+		Songs struct {
+			Data []struct {
+				Id         string `json:"id"`
+				Attributes struct {
+					Name       string `json:"name"`
+					ArtistName string `json:"artistName"`
+				} `json:"attributes"`
+			} `json:"data"`
+		} `json:"songs"`
+	} `json:"results"`
+}
+
+type AppleMusicPlaylist struct {
+	Data []struct {
+		Id string `json:"id"`
+	} `json:"data"`
+}
+
+// AppleMusicTrack 添加到资料库歌单的曲目
+type AppleMusicTrack struct {
+	Id   string `json:"id"`
+	Type string `json:"type"`
+}
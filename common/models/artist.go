@@ -0,0 +1,80 @@
+package models
+
+// ArtistCatalog 歌手在各平台的热门歌曲
+type ArtistCatalog struct {
+	// 查询使用的歌手名，传入链接时为源平台上的歌手名
+	Query   string          `json:"query"`
+	Sources []*ArtistSource `json:"sources"`
+}
+
+type ArtistSource struct {
+	Platform string `json:"platform"`
+	ArtistId string `json:"artist_id"`
+	Name     string `json:"name"`
+	// 匹配置信度，0~1，源平台为 1
+	Confidence float64 `json:"confidence"`
+	// 平台上该歌手的歌曲总数，0 表示未知
+	SongsCount int      `json:"songs_count"`
+	Songs      []string `json:"songs"`
+	Error      string   `json:"error,omitempty"`
+}
+
+type NetEasyArtistSearch struct {
+	Code   int `json:"code"`
+	Result struct {
+		Artists []struct {
+			Id   int64  `json:"id"`
+			Name string `json:"name"`
+		} `json:"artists"`
+	} `json:"result"`
+}
+
+type NetEasyArtistTopSongs struct {
+	Code   int `json:"code"`
+	Artist struct {
+		Id        int64  `json:"id"`
+		Name      string `json:"name"`
+		MusicSize int    `json:"musicSize"`
+	} `json:"artist"`
+	HotSongs []struct {
+		Id   uint   `json:"id"`
+		Name string `json:"name"`
+		Ar   []struct {
+			Name string `json:"name"`
+		} `json:"ar"`
+	} `json:"hotSongs"`
+}
+
+type QQMusicSingerSearchResp struct {
+	Req0 struct {
+		Code int `json:"code"`
+		Data struct {
+			Body struct {
+				Singer struct {
+					List []struct {
+						SingerMID  string `json:"singerMID"`
+						SingerName string `json:"singerName"`
+					} `json:"list"`
+				} `json:"singer"`
+			} `json:"body"`
+		} `json:"data"`
+	} `json:"req_0"`
+}
+
+type QQMusicSingerSongsResp struct {
+	Req0 struct {
+		Code int `json:"code"`
+		Data struct {
+			TotalNum int `json:"totalNum"`
+			SongList []struct {
+				SongInfo struct {
+					Name   string `json:"name"`
+					Singer []struct {
+						Mid  string `json:"mid"`
+						Name string `json:"name"`
+					} `json:"singer"`
+				} `json:"songInfo"`
+			} `json:"songList"`
+		} `json:"data"`
+	} `json:"req_0"`
+}
@@ -0,0 +1,37 @@
+package models
+
+// BandcampPageData 粉丝主页 pagedata 的 data-blob 中的粉丝信息
+type BandcampPageData struct {
+	FanData struct {
+		FanId    int64  `json:"fan_id"`
+		Name     string `json:"name"`
+		Username string `json:"username"`
+	} `json:"fan_data"`
+	CollectionCount int `json:"collection_count"`
+}
+
+// BandcampCollection /api/fancollection/1/collection_items 返回的一页已购买的专辑与单曲
+type BandcampCollection struct {
+	Items []struct {
+		ItemType   string `json:"item_type"` // album 或 track
+		TralbumId  int64  `json:"tralbum_id"`
+		ItemTitle  string `json:"item_title"`
+		BandName   string `json:"band_name"`
+		AlbumTitle string `json:"album_title"` // 仅单曲有
+		ItemArtUrl string `json:"item_art_url"`
+	} `json:"items"`
+	// Tracklists 各条目的曲目，键为 item_type 首字母加 tralbum_id，如 a1234567
+	Tracklists    map[string][]*BandcampTrack `json:"tracklists"`
+	MoreAvailable bool                        `json:"more_available"`
+	LastToken     string                      `json:"last_token"`
+	Error         bool                        `json:"error"`
+	ErrorMessage  string                      `json:"error_message"`
+}
+
+// BandcampTrack 收藏条目中的曲目
+type BandcampTrack struct {
+	Id       int64   `json:"id"`
+	Title    string  `json:"title"`
+	Artist   string  `json:"artist"`
+	Duration float64 `json:"duration"` // 秒
+}
@@ -0,0 +1,61 @@
+package models
+
+import "sync"
+
+// SongCollector 并发安全地收集按 id 查询到的歌曲，最后按歌单顺序取出
+type SongCollector struct {
+	mu    sync.Mutex
+	songs map[uint]*CachedSong
+}
+
+func NewSongCollector(size int) *SongCollector {
+	return &SongCollector{songs: make(map[uint]*CachedSong, size)}
+}
+
+// Insert 收集一首歌曲，id 已存在时覆盖
+func (c *SongCollector) Insert(id uint, song *CachedSong) {
+	c.mu.Lock()
+	c.songs[id] = song
+	c.mu.Unlock()
+}
+
+// Merge 收集一批歌曲，id 已存在时覆盖
+func (c *SongCollector) Merge(songs map[uint]*CachedSong) {
+	c.mu.Lock()
+	for k, v := range songs {
+		c.songs[k] = v
+	}
+	c.mu.Unlock()
+}
+
+func (c *SongCollector) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.songs)
+}
+
+// Map 已收集歌曲的副本
+func (c *SongCollector) Map() map[uint]*CachedSong {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	songs := make(map[uint]*CachedSong, len(c.songs))
+	for k, v := range c.songs {
+		songs[k] = v
+	}
+	return songs
+}
+
+// Ordered 按 ids 的顺序取出已收集的歌曲及其 id，未收集到的 id 被跳过
+func (c *SongCollector) Ordered(ids []uint) ([]uint, []*CachedSong) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	found := make([]uint, 0, len(ids))
+	songs := make([]*CachedSong, 0, len(ids))
+	for _, v := range ids {
+		if song, ok := c.songs[v]; ok {
+			found = append(found, v)
+			songs = append(songs, song)
+		}
+	}
+	return found, songs
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSongCollector(t *testing.T) {
+	c := NewSongCollector(0)
+	wg := sync.WaitGroup{}
+	for i := uint(1); i <= 100; i++ {
+		wg.Add(1)
+		go func(id uint) {
+			defer wg.Done()
+			c.Insert(id, &CachedSong{Name: "song", Duration: int(id)})
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 100, c.Len())
+
+	c.Merge(map[uint]*CachedSong{2: {Name: "merged"}, 200: {Name: "new"}})
+	ids, songs := c.Ordered([]uint{200, 2, 300, 1})
+	assert.Equal(t, []uint{200, 2, 1}, ids)
+	assert.Equal(t, []*CachedSong{{Name: "new"}, {Name: "merged"}, {Name: "song", Duration: 1}}, songs)
+
+	// Map 返回副本，修改不影响收集的结果
+	m := c.Map()
+	delete(m, 1)
+	assert.Equal(t, 101, c.Len())
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// CredentialStatus 平台凭证的校验结果
+type CredentialStatus struct {
+	Platform string `json:"platform"`
+	// Source 校验的凭证来源：request 为请求携带，config 为服务端配置
+	Source string `json:"source"`
+	Valid  bool   `json:"valid"`
+	// Reason 凭证无效的原因
+	Reason   string `json:"reason,omitempty"`
+	UserId   int64  `json:"user_id,omitempty"`
+	Nickname string `json:"nickname,omitempty"`
+	// VipType 会员类型，0 为非会员
+	VipType   int       `json:"vip_type"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+const (
+	CredentialSourceRequest = "request"
+	CredentialSourceConfig  = "config"
+)
@@ -7,4 +7,13 @@ type NetEasySong struct {
 	Id    uint   `gorm:"column:id"`
 	Name  string `gorm:"column:name;type:varchar(512);unique:true"`
 	Exist byte   `gorm:"column:exist;default:1"`
+	// 时长（毫秒）
+	Duration uint `gorm:"column:duration;default:0"`
+	// Explicit 是否含露骨内容，为空表示记录早于该字段
+	Explicit *bool `gorm:"column:explicit"`
+	// Album 所属专辑，为空表示记录早于该字段
+	Album *string `gorm:"column:album;type:varchar(512)"`
+	// RawName 原始歌名，Artists 为歌手的 JSON 数组，Name 为格式化后的“歌名 - 歌手”；为空表示记录早于该字段
+	RawName *string `gorm:"column:raw_name;type:varchar(512)"`
+	Artists *string `gorm:"column:artists;type:text"`
 }
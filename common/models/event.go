@@ -0,0 +1,10 @@
+package models
+
+// DiscoveryResult 获取歌单完成事件的内容，SongsCount 为平台上的歌曲数，Resolved 为实际获取到的歌曲数
+type DiscoveryResult struct {
+	Provider   string `json:"provider"`
+	Link       string `json:"link"`
+	Name       string `json:"name"`
+	SongsCount int    `json:"songs_count"`
+	Resolved   int    `json:"resolved"`
+}
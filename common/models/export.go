@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// 导入目标平台时新建歌单的可见性
+const (
+	PrivacyPrivate  = "private"
+	PrivacyUnlisted = "unlisted"
+	PrivacyPublic   = "public"
+)
+
+// TransferOptions 导入目标平台的选项
+type TransferOptions struct {
+	// Privacy 新建歌单的可见性，默认 private；不支持 unlisted 的平台按 private 创建
+	Privacy string `json:"privacy,omitempty"`
+	// Playlist 追加到的已有歌单的 id 或链接，为空时新建歌单；已在歌单中的歌曲不会重复添加
+	Playlist string `json:"playlist,omitempty"`
+	// Market 在目标平台哪个地区的曲库中匹配歌曲，ISO 3166-1 两位地区代码，如 TW；为空时使用账号所在地区
+	Market string `json:"market,omitempty"`
+}
+
+// TargetPlaylist 用户在目标平台已有的歌单，供选择追加到的歌单
+type TargetPlaylist struct {
+	Id         string `json:"id"`
+	Name       string `json:"name"`
+	SongsCount int    `json:"songs_count"`
+}
+
+// ExportCandidate 目标平台搜索到的歌曲
+type ExportCandidate struct {
+	Id     string
+	Title  string
+	Artist string
+}
+
+// ExportReport 将歌单导入目标平台的结果
+type ExportReport struct {
+	// Id 导入记录的 id，可在 JobTTL 内通过 GraphQL transfer(id) 再次查询
+	Id          string `json:"id"`
+	Platform    string `json:"platform"`
+	PlaylistId  string `json:"playlist_id"`
+	PlaylistUrl string `json:"playlist_url"`
+	Total       int    `json:"total"`
+	Matched     int    `json:"matched"`
+	// Existing 追加到已有歌单时，已在歌单中而跳过的歌曲数
+	Existing int `json:"existing,omitempty"`
+	// Unmatched 未在目标平台找到的歌曲，按歌单顺序
+	Unmatched []string `json:"unmatched"`
+	// Missing 未找到的歌曲的结构化信息，含专辑，便于用户补充自建曲库；平台未返回结构化歌曲时为空
+	Missing    []*Song   `json:"missing,omitempty"`
+	FinishedAt time.Time `json:"finished_at"`
+}
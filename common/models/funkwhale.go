@@ -0,0 +1,68 @@
+package models
+
+// FunkwhaleArtist 1.4 之前的版本返回 artist，之后改为 artist_credit
+type FunkwhaleArtist struct {
+	Name string `json:"name"`
+}
+
+type FunkwhaleTrack struct {
+	Id           int              `json:"id"`
+	Title        string           `json:"title"`
+	Artist       *FunkwhaleArtist `json:"artist"`
+	ArtistCredit []struct {
+		Credit     string `json:"credit"`
+		JoinPhrase string `json:"joinphrase"`
+	} `json:"artist_credit"`
+	Album *struct {
+		Title string `json:"title"`
+	} `json:"album"`
+	Uploads []struct {
+		Duration int `json:"duration"` // 秒
+	} `json:"uploads"`
+}
+
+// FunkwhalePlaylist /api/v1/playlists/{id}/ 返回的歌单信息，也用于新建歌单的响应
+type FunkwhalePlaylist struct {
+	Id          int    `json:"id"`
+	Name        string `json:"name"`
+	TracksCount int    `json:"tracks_count"`
+	Duration    int    `json:"duration"` // 秒
+}
+
+// FunkwhalePlaylistTracks /api/v1/playlists/{id}/tracks/ 的分页结果，Next 为下一页的完整地址
+type FunkwhalePlaylistTracks struct {
+	Count   int     `json:"count"`
+	Next    *string `json:"next"`
+	Results []struct {
+		Track *FunkwhaleTrack `json:"track"`
+	} `json:"results"`
+}
+
+// FunkwhaleTracks /api/v1/tracks/ 的搜索结果
+type FunkwhaleTracks struct {
+	Results []*FunkwhaleTrack `json:"results"`
+}
+
+// FunkwhaleApp /api/v1/oauth/apps/ 动态注册的 OAuth 应用
+type FunkwhaleApp struct {
+	ClientId     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+}
+
+// FunkwhaleAuthState 授权期间暂存的待导入歌单、实例信息与发起请求时的偏好语言、网易云 cookie
+type FunkwhaleAuthState struct {
+	Link         string `json:"link"`
+	Server       string `json:"server"`
+	ClientId     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	Locale       string `json:"locale,omitempty"`
+	MusicU       string `json:"music_u,omitempty"`
+	// Transfer 发起请求时指定的导入选项
+	Transfer *TransferOptions `json:"transfer,omitempty"`
+}
+
+type FunkwhaleToken struct {
+	AccessToken string `json:"access_token"`
+	Error       string `json:"error"`
+	Description string `json:"error_description"`
+}
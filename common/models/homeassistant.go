@@ -0,0 +1,12 @@
+package models
+
+// HomeAssistantPlaylist 转换结果，MediaId 可直接作为 Music Assistant mass.play_media 的 media_id
+type HomeAssistantPlaylist struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+	// MediaPlayer、Enqueue 原样返回请求中的值，自动化中无需再次指定
+	MediaPlayer string   `json:"media_player,omitempty"`
+	Enqueue     string   `json:"enqueue,omitempty"`
+	MediaId     []string `json:"media_id"` // 歌手 - 歌名
+	Published   bool     `json:"published"`
+}
@@ -0,0 +1,25 @@
+package models
+
+// JellyfinAuth /Users/AuthenticateByName 的响应
+type JellyfinAuth struct {
+	AccessToken string `json:"AccessToken"`
+	User        struct {
+		Id string `json:"Id"`
+	} `json:"User"`
+}
+
+// JellyfinItems /Items 的搜索结果，也用于歌单中的歌曲与用户的歌单列表
+type JellyfinItems struct {
+	Items []struct {
+		Id          string   `json:"Id"`
+		Name        string   `json:"Name"`
+		Artists     []string `json:"Artists"`
+		AlbumArtist string   `json:"AlbumArtist"`
+		ChildCount  int      `json:"ChildCount"` // 歌单中的歌曲数
+	} `json:"Items"`
+}
+
+// JellyfinPlaylist POST /Playlists 返回新建的歌单
+type JellyfinPlaylist struct {
+	Id string `json:"Id"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// 后台任务的状态
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+// 组合任务按顺序执行的步骤
+const (
+	JobStepDiscover = "discover"
+	JobStepMerge    = "merge"
+	JobStepTransfer = "transfer"
+)
+
+// Job 后台转换任务，Resolved/Total 为已处理与总歌曲数，完成后 SongList 为转换结果。
+// 组合任务依次获取 Links 中的歌单、合并去重并导入目标平台，Step 为执行中的步骤，导入结果为 Transfer
+type Job struct {
+	Id        string        `json:"id"`
+	Link      string        `json:"url"`
+	Links     []string      `json:"urls,omitempty"`
+	Status    string        `json:"status"`
+	Step      string        `json:"step,omitempty"`
+	Resolved  int           `json:"resolved"`
+	Total     int           `json:"total"`
+	Error     string        `json:"error,omitempty"`
+	SongList  *SongList     `json:"songlist,omitempty"`
+	Transfer  *ExportReport `json:"transfer,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
@@ -0,0 +1,29 @@
+package models
+
+// JooxPlaylist openjoox /playlist/{id} 返回的歌单信息及首页歌曲
+type JooxPlaylist struct {
+	Id     string `json:"id"`
+	Name   string `json:"name"`
+	Images []struct {
+		Width int    `json:"width"`
+		Url   string `json:"url"`
+	} `json:"images"`
+	Tracks JooxTracks `json:"tracks"`
+	// ErrorCode 歌单不存在或地区不可用时非 0
+	ErrorCode int `json:"error_code"`
+}
+
+// JooxTracks 一页歌曲，NextIndex 为下一页的起始位置
+type JooxTracks struct {
+	Items []struct {
+		Id         string `json:"id"`
+		Name       string `json:"name"`
+		AlbumName  string `json:"album_name"`
+		ArtistList []struct {
+			Name string `json:"name"`
+		} `json:"artist_list"`
+		PlayDuration int `json:"play_duration"` // 秒
+	} `json:"items"`
+	TotalCount int `json:"total_count"`
+	NextIndex  int `json:"next_index"`
+}
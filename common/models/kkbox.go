@@ -0,0 +1,37 @@
+package models
+
+// KKBOXToken client credentials 模式获取的应用令牌
+type KKBOXToken struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"` // 秒
+	Error       string `json:"error"`
+}
+
+// KKBOXPlaylist /shared-playlists/{id} 返回的歌单信息
+type KKBOXPlaylist struct {
+	Id          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Images      []struct {
+		Url string `json:"url"`
+	} `json:"images"` // 由小到大
+	Tracks KKBOXTracks `json:"tracks"`
+}
+
+// KKBOXTracks /shared-playlists/{id}/tracks 返回的一页歌曲
+type KKBOXTracks struct {
+	Data []struct {
+		Id       string `json:"id"`
+		Name     string `json:"name"`
+		Duration int    `json:"duration"` // 毫秒
+		Album    struct {
+			Name   string `json:"name"`
+			Artist struct {
+				Name string `json:"name"`
+			} `json:"artist"`
+		} `json:"album"`
+	} `json:"data"`
+	Summary struct {
+		Total int `json:"total"`
+	} `json:"summary"`
+}
@@ -0,0 +1,37 @@
+package models
+
+// KugouSpecialInfo 酷狗歌单信息
+type KugouSpecialInfo struct {
+	Status int `json:"status"`
+	Data   struct {
+		SpecialName string `json:"specialname"`
+		ImgUrl      string `json:"imgurl"` // 含 {size} 占位符
+		Intro       string `json:"intro"`
+		SongCount   int    `json:"songcount"`
+		Tags        []struct {
+			TagName string `json:"tagname"`
+		} `json:"tags"`
+	} `json:"data"`
+}
+
+// KugouSongs 酷狗歌单中的一页歌曲
+type KugouSongs struct {
+	Status    int `json:"status"`
+	ErrorCode int `json:"error_code"`
+	Data      struct {
+		Count int          `json:"count"`
+		Info  []*KugouSong `json:"info"`
+	} `json:"data"`
+}
+
+type KugouSong struct {
+	Hash      string `json:"hash"`
+	Name      string `json:"name"`    // 歌手 - 歌名
+	Timelen   int    `json:"timelen"` // 时长（毫秒）
+	AlbumInfo struct {
+		Name string `json:"name"`
+	} `json:"albuminfo"`
+	SingerInfo []struct {
+		Name string `json:"name"`
+	} `json:"singerinfo"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "encoding/json"
+
+// KuwoPlaylist 酷我歌单信息及其中的一页歌曲
+type KuwoPlaylist struct {
+	Result    string      `json:"result"` // 成功时为 ok
+	Title     string      `json:"title"`
+	Pic       string      `json:"pic"`
+	Info      string      `json:"info"`
+	Tag       string      `json:"tag"` // 以逗号分隔
+	Total     int         `json:"total"`
+	MusicList []*KuwoSong `json:"musiclist"`
+}
+
+type KuwoSong struct {
+	Id       json.Number `json:"id"`
+	Name     string      `json:"name"`
+	Album    string      `json:"album"`
+	Artist   string      `json:"artist"`   // 多位歌手以 & 分隔
+	Duration json.Number `json:"duration"` // 时长（秒）
+}
@@ -4,9 +4,38 @@ import "fmt"
 
 type SongList struct {
 	// 歌单名
-	Name       string   `json:"name"`
+	Name string `json:"name"`
+	// Songs 格式化后的“歌名 - 歌手”，兼容只需要文本的调用方
 	Songs      []string `json:"songs"`
 	SongsCount int      `json:"songs_count"`
+	// Tracks 与 Songs 一一对应的结构化歌曲；所有已注册的平台均会提供（流式获取时经 TrackSink 写入），
+	// 排序、过滤等改变 Songs 顺序的操作后为空，调用方应在为空时回退到 Songs
+	Tracks []*Song `json:"tracks,omitempty"`
+	// 歌单封面，可经 /cover 代理访问
+	Cover string `json:"cover"`
+	// 歌单简介与标签
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	Summary     *Summary `json:"summary"`
+
+	// 以下字段与 Songs 一一对应，供导出时过滤使用，平台未提供时为空
+	// Durations 时长（毫秒），0 表示未知
+	Durations []int `json:"-"`
+	// Explicit 是否含露骨内容
+	Explicit []bool `json:"-"`
+}
+
+// Summary 歌单统计信息
+type Summary struct {
+	// 歌单总时长，无法获取时长的歌曲不计入
+	DurationMs int `json:"duration_ms"`
+	// 各歌手的歌曲数，按歌曲数降序
+	Artists []*ArtistCount `json:"artists"`
+}
+
+type ArtistCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
 }
 
 type SongId struct {
@@ -23,10 +52,14 @@ func (r *SongId) String() string {
 type NetEasySongId struct {
 	Code     int `json:"code"`
 	Playlist struct {
-		Id         int64      `json:"id"`
-		Name       string     `json:"name"`
-		TrackIds   []*TrackId `json:"trackIds"`
-		TrackCount int        `json:"trackCount"`
+		Id          int64      `json:"id"`
+		Name        string     `json:"name"`
+		CoverImgUrl string     `json:"coverImgUrl"`
+		Description string     `json:"description"`
+		Tags        []string   `json:"tags"`
+		TrackIds    []*TrackId `json:"trackIds"`
+		TrackCount  int        `json:"trackCount"`
+		UpdateTime  int64      `json:"updateTime"` // 歌单最后修改时间（毫秒），增删歌曲或修改歌单信息时变化
 	} `json:"playlist"`
 }
 
@@ -34,13 +67,58 @@ type TrackId struct {
 	Id uint `json:"id"`
 }
 
+// NetEasyMarkExplicit 歌曲 mark 字段中表示含露骨内容（客户端中显示为 E）的标记位
+const NetEasyMarkExplicit = 1 << 20
+
 type Songs struct {
 	Songs []struct {
 		Id   uint   `json:"id"`
 		Name string `json:"name"`
+		Dt   int    `json:"dt"` // 时长（毫秒）
+		Mark int64  `json:"mark"`
 		Ar   []struct {
 			Id   int64  `json:"id"`
 			Name string `json:"name"`
 		} `json:"ar"`
+		Al struct {
+			Name string `json:"name"`
+		} `json:"al"`
 	} `json:"songs"`
+	// 播放权限，st < 0 表示歌曲已下架（客户端中显示为灰色）
+	Privileges []struct {
+		Id uint `json:"id"`
+		St int  `json:"st"`
+	} `json:"privileges"`
+}
+
+// NetEasyAccount 当前登录的网易云账号，cookie 无效或已过期时 Account 与 Profile 为 null
+type NetEasyAccount struct {
+	Code    int `json:"code"`
+	Account *struct {
+		Id     int64 `json:"id"`
+		Status int   `json:"status"` // 非 0 表示账号被封禁
+	} `json:"account"`
+	Profile *struct {
+		UserId   int64  `json:"userId"`
+		Nickname string `json:"nickname"`
+		VipType  int    `json:"vipType"`
+	} `json:"profile"`
+}
+
+type NetEasyArtistAlbums struct {
+	Code      int `json:"code"`
+	HotAlbums []struct {
+		Id          int64  `json:"id"`
+		Name        string `json:"name"`
+		PublishTime int64  `json:"publishTime"` // 毫秒
+	} `json:"hotAlbums"`
+}
+
+// CachedSong 缓存中的结构化歌曲，输出时再格式化为“歌名 - 歌手”，调整输出格式无需清空缓存
+type CachedSong struct {
+	Name     string   `codec:"n"`
+	Artists  []string `codec:"a"`
+	Duration int      `codec:"d"` // 时长（毫秒）
+	Explicit bool     `codec:"e,omitempty"`
+	Album    string   `codec:"al,omitempty"`
 }
@@ -0,0 +1,22 @@
+package models
+
+// PlexResponse Plex Media Server 的 JSON 响应（Accept: application/json），各接口的结果均位于 MediaContainer 中
+type PlexResponse struct {
+	MediaContainer struct {
+		MachineIdentifier string `json:"machineIdentifier"` // /identity 返回的服务器标识，用于拼接歌单的 uri
+		// Directory /library/sections 返回的资料库，音乐资料库的 type 为 artist
+		Directory []struct {
+			Key   string `json:"key"`
+			Type  string `json:"type"`
+			Title string `json:"title"`
+		} `json:"Directory"`
+		// Metadata 搜索返回的歌曲、歌单中的歌曲、新建的歌单或用户的歌单列表
+		Metadata []struct {
+			RatingKey        string `json:"ratingKey"`
+			Title            string `json:"title"`
+			LeafCount        int    `json:"leafCount"`        // 歌单中的歌曲数
+			GrandparentTitle string `json:"grandparentTitle"` // 专辑歌手
+			OriginalTitle    string `json:"originalTitle"`    // 歌曲歌手，与专辑歌手不同时返回
+		} `json:"Metadata"`
+	} `json:"MediaContainer"`
+}
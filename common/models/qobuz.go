@@ -0,0 +1,33 @@
+package models
+
+// QobuzLogin /user/login 的响应
+type QobuzLogin struct {
+	UserAuthToken string `json:"user_auth_token"`
+	User          struct {
+		Id int64 `json:"id"`
+	} `json:"user"`
+}
+
+// QobuzSearch /track/search 的搜索结果
+type QobuzSearch struct {
+	Tracks struct {
+		Items []struct {
+			Id        int64  `json:"id"`
+			Title     string `json:"title"`
+			Performer struct {
+				Name string `json:"name"`
+			} `json:"performer"`
+		} `json:"items"`
+	} `json:"tracks"`
+}
+
+// QobuzPlaylist /playlist/create 返回新建的歌单，/playlist/get?extra=tracks 同时返回一页歌曲
+type QobuzPlaylist struct {
+	Id     int64 `json:"id"`
+	Tracks struct {
+		Total int `json:"total"`
+		Items []struct {
+			Id int64 `json:"id"`
+		} `json:"items"`
+	} `json:"tracks"`
+}
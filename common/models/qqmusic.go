@@ -23,7 +23,8 @@ type QQMusicReq struct {
 	} `json:"comm"`
 }
 
-func NewQQMusicReq(disstid int, platform string) *QQMusicReq {
+// NewQQMusicReq 获取歌单信息及从 songBegin 开始的 songNum 首歌曲
+func NewQQMusicReq(disstid int, platform string, songBegin, songNum int) *QQMusicReq {
 	return &QQMusicReq{
 		Req0: struct {
 			Module string `json:"module"`
@@ -51,8 +52,8 @@ func NewQQMusicReq(disstid int, platform string) *QQMusicReq {
 				EncHostUin: "",
 				Tag:        1,
 				Userinfo:   1,
-				SongBegin:  0,
-				SongNum:    1024,
+				SongBegin:  songBegin,
+				SongNum:    songNum,
 			},
 		},
 		Comm: struct {
@@ -69,8 +70,8 @@ func NewQQMusicReq(disstid int, platform string) *QQMusicReq {
 	}
 }
 
-func GetQQMusicReqString(disstid int, platform string) string {
-	param := NewQQMusicReq(disstid, platform)
+func GetQQMusicReqString(disstid int, platform string, songBegin, songNum int) string {
+	param := NewQQMusicReq(disstid, platform, songBegin, songNum)
 	marshal, _ := json.Marshal(param)
 	return string(marshal)
 }
@@ -82,14 +83,35 @@ type QQMusicResp struct {
 		Data struct {
 			Dirinfo struct {
 				Title   string `json:"title"`
+				Picurl  string `json:"picurl"`
 				Songnum int    `json:"songnum"`
-			} `json:"dirinfo"`
-			Songlist []struct {
-				Name   string `json:"name"`
-				Singer []struct {
+				Desc    string `json:"desc"`
+				Tag     []struct {
 					Name string `json:"name"`
-				} `json:"singer"`
-			} `json:"songlist"`
+				} `json:"tag"`
+			} `json:"dirinfo"`
+			Songlist []*QQMusicSong `json:"songlist"`
 		} `json:"data"`
 	} `json:"req_0"`
 }
+
+type QQMusicSong struct {
+	Mid      string `json:"mid"`
+	Name     string `json:"name"`
+	Interval int    `json:"interval"` // 时长（秒）
+	Singer   []struct {
+		Name string `json:"name"`
+	} `json:"singer"`
+	Album struct {
+		Name string `json:"name"`
+	} `json:"album"`
+}
+
+// GetQQMusicModuleReqString 构建只调用一个模块的请求参数
+func GetQQMusicModuleReqString(module, method string, param any) string {
+	marshal, _ := json.Marshal(map[string]any{
+		"req_0": map[string]any{"module": module, "method": method, "param": param},
+		"comm":  map[string]any{"g_tk": 5381, "uin": 0, "format": "json", "platform": "h5"},
+	})
+	return string(marshal)
+}
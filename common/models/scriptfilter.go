@@ -0,0 +1,19 @@
+package models
+
+// ScriptFilter Alfred Script Filter 的 JSON 输出，Raycast 的脚本命令同样可以解析
+// https://www.alfredapp.com/help/workflows/inputs/script-filter/json/
+type ScriptFilter struct {
+	Items []*ScriptFilterItem `json:"items"`
+}
+
+type ScriptFilterItem struct {
+	Uid      string `json:"uid,omitempty"`
+	Title    string `json:"title"`
+	Subtitle string `json:"subtitle"`
+	// Arg 选中后传给下一步的值
+	Arg  string `json:"arg"`
+	Text struct {
+		Copy      string `json:"copy"`
+		LargeType string `json:"largetype"`
+	} `json:"text"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "gorm.io/gorm"
+
+// ShortLink 短链接，保存歌单链接与导出参数
+type ShortLink struct {
+	gorm.Model
+	Code string `gorm:"column:code;type:varchar(16);uniqueIndex"`
+	Link string `gorm:"column:link;type:varchar(512)"`
+	// Options 导出参数，URL 查询字符串格式
+	Options string `gorm:"column:options;type:text"`
+}
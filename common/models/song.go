@@ -0,0 +1,11 @@
+package models
+
+// Song 结构化的歌曲，歌名与歌手均为平台返回的原始值，输出文本时再格式化
+type Song struct {
+	Name       string   `json:"name"`
+	Artists    []string `json:"artists"`
+	Album      string   `json:"album,omitempty"`
+	DurationMs int      `json:"duration_ms"` // 0 表示未知
+	// SourceId 歌曲在来源平台的 id，如网易云歌曲 id、QQ 音乐 songmid
+	SourceId string `json:"source_id,omitempty"`
+}
@@ -0,0 +1,45 @@
+package models
+
+// SoundCloudUser 歌曲上传者或歌单所属用户
+type SoundCloudUser struct {
+	Id       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+// SoundCloudTrack api-v2 返回的歌曲，歌单中靠后的歌曲仅含 id，需通过 /tracks?ids= 补全
+type SoundCloudTrack struct {
+	Id                int64                `json:"id"`
+	Title             string               `json:"title"`
+	Duration          int                  `json:"duration"` // 毫秒
+	User              SoundCloudUser       `json:"user"`
+	PublisherMetadata *SoundCloudPublisher `json:"publisher_metadata"`
+}
+
+// SoundCloudPublisher 发行方提供的元数据，仅部分歌曲有
+type SoundCloudPublisher struct {
+	Artist     string `json:"artist"`
+	AlbumTitle string `json:"album_title"`
+	Explicit   bool   `json:"explicit"`
+}
+
+// SoundCloudResolve /resolve 返回的资源，Kind 为 playlist、user 或 track
+type SoundCloudResolve struct {
+	Kind        string             `json:"kind"`
+	Id          int64              `json:"id"`
+	Title       string             `json:"title"`
+	Username    string             `json:"username"`
+	Description string             `json:"description"`
+	ArtworkUrl  string             `json:"artwork_url"`
+	AvatarUrl   string             `json:"avatar_url"`
+	TagList     string             `json:"tag_list"`
+	TrackCount  int                `json:"track_count"`
+	Tracks      []*SoundCloudTrack `json:"tracks"`
+}
+
+// SoundCloudLikes /users/{id}/likes 返回的一页喜欢，NextHref 为空时已是最后一页
+type SoundCloudLikes struct {
+	Collection []struct {
+		Track *SoundCloudTrack `json:"track"` // 喜欢的是歌单时为 nil
+	} `json:"collection"`
+	NextHref string `json:"next_href"`
+}
@@ -0,0 +1,49 @@
+package models
+
+// SpotifyAuthState 授权期间暂存的待导入歌单与发起请求时的偏好语言、网易云 cookie，回调时据此还原请求上下文
+type SpotifyAuthState struct {
+	Link   string `json:"link"`
+	Locale string `json:"locale,omitempty"`
+	MusicU string `json:"music_u,omitempty"`
+	// Transfer 发起请求时指定的导入选项
+	Transfer *TransferOptions `json:"transfer,omitempty"`
+}
+
+type SpotifyToken struct {
+	AccessToken string `json:"access_token"`
+	Error       string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+type SpotifyUser struct {
+	Id string `json:"id"`
+}
+
+type SpotifySearch struct {
+	Tracks struct {
+		Items []struct {
+			Uri     string `json:"uri"`
+			Name    string `json:"name"`
+			Artists []struct {
+				Name string `json:"name"`
+			} `json:"artists"`
+		} `json:"items"`
+	} `json:"tracks"`
+}
+
+// SpotifyPlaylistTracks /playlists/{id}/tracks 的分页结果，仅请求歌曲的 uri
+type SpotifyPlaylistTracks struct {
+	Total int `json:"total"`
+	Items []struct {
+		Track *struct {
+			Uri string `json:"uri"`
+		} `json:"track"`
+	} `json:"items"`
+}
+
+type SpotifyPlaylist struct {
+	Id           string `json:"id"`
+	ExternalUrls struct {
+		Spotify string `json:"spotify"`
+	} `json:"external_urls"`
+}
@@ -0,0 +1,34 @@
+package models
+
+// SubsonicResponse Subsonic API 的 JSON 响应（f=json），各接口的结果均位于 subsonic-response 中
+type SubsonicResponse struct {
+	Response struct {
+		Status string `json:"status"` // ok 或 failed
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+		SearchResult3 struct {
+			Song []struct {
+				Id     string `json:"id"`
+				Title  string `json:"title"`
+				Artist string `json:"artist"`
+			} `json:"song"`
+		} `json:"searchResult3"`
+		// Playlist createPlaylist 在 API 1.14 及以上返回新建的歌单，getPlaylist 同时返回歌单中的歌曲
+		Playlist struct {
+			Id    string `json:"id"`
+			Entry []struct {
+				Id string `json:"id"`
+			} `json:"entry"`
+		} `json:"playlist"`
+		// Playlists getPlaylists 返回的用户歌单
+		Playlists struct {
+			Playlist []struct {
+				Id        string `json:"id"`
+				Name      string `json:"name"`
+				SongCount int    `json:"songCount"`
+			} `json:"playlist"`
+		} `json:"playlists"`
+	} `json:"subsonic-response"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WatchedPlaylist 定期检查下架歌曲的歌单
+type WatchedPlaylist struct {
+	gorm.Model
+	// Token 管理该订阅的凭证，创建时随机生成
+	Token   string `gorm:"column:token;type:varchar(64);uniqueIndex"`
+	Link    string `gorm:"column:link;type:varchar(512)"`
+	Webhook string `gorm:"column:webhook;type:varchar(512)"`
+	Email   string `gorm:"column:email;type:varchar(256)"`
+	// Unavailable 上次检查时已下架的歌曲 id，逗号分隔
+	Unavailable string `gorm:"column:unavailable;type:text"`
+	CheckedAt   *time.Time
+}
+
+// Availability 歌单中歌曲的可播放情况
+type Availability struct {
+	Name        string             `json:"name"`
+	Total       int                `json:"total"`
+	Unavailable []*UnavailableSong `json:"unavailable"`
+}
+
+type UnavailableSong struct {
+	Id   uint   `json:"id"`
+	Song string `json:"song"`
+}
+
+const (
+	ReportDelisted   = "delisted"
+	ReportNewRelease = "new_release"
+)
+
+// HealthReport 歌单健康检查报告
+type HealthReport struct {
+	Type          string             `json:"type"`
+	Link          string             `json:"link"`
+	Name          string             `json:"name"`
+	Total         int                `json:"total"`
+	Unavailable   int                `json:"unavailable"`
+	NewlyDelisted []*UnavailableSong `json:"newly_delisted"`
+	CheckedAt     time.Time          `json:"checked_at"`
+}
+
+// FollowedArtist 订阅歌单中关注的歌手
+type FollowedArtist struct {
+	gorm.Model
+	WatchId  uint   `gorm:"column:watch_id;index"`
+	ArtistId int64  `gorm:"column:artist_id"`
+	Name     string `gorm:"column:name;type:varchar(256)"`
+	// LatestPublishTime 已通知过的最新专辑发行时间（毫秒）
+	LatestPublishTime int64 `gorm:"column:latest_publish_time"`
+}
+
+// ReleaseReport 关注歌手的新专辑
+type ReleaseReport struct {
+	Type     string        `json:"type"`
+	Link     string        `json:"link"`
+	Releases []*NewRelease `json:"releases"`
+}
+
+type NewRelease struct {
+	Artist      string    `json:"artist"`
+	Album       string    `json:"album"`
+	Link        string    `json:"link"`
+	PublishTime time.Time `json:"publish_time"`
+}
@@ -0,0 +1,141 @@
+package models
+
+// YouTubeMusicBrowseReq InnerTube browse 请求，首页使用 BrowseId，后续页使用 Continuation
+type YouTubeMusicBrowseReq struct {
+	Context struct {
+		Client struct {
+			ClientName    string `json:"clientName"`
+			ClientVersion string `json:"clientVersion"`
+			Hl            string `json:"hl"`
+		} `json:"client"`
+	} `json:"context"`
+	BrowseId     string `json:"browseId,omitempty"`
+	Continuation string `json:"continuation,omitempty"`
+}
+
+// YouTubeMusicBrowse InnerTube browse 响应，仅保留歌单需要的字段；新旧两种页面布局均可解析
+type YouTubeMusicBrowse struct {
+	Header struct {
+		MusicDetailHeaderRenderer *YouTubeMusicHeader `json:"musicDetailHeaderRenderer"`
+	} `json:"header"`
+	Contents struct {
+		SingleColumnBrowseResultsRenderer *struct {
+			Tabs []*YouTubeMusicTab `json:"tabs"`
+		} `json:"singleColumnBrowseResultsRenderer"`
+		TwoColumnBrowseResultsRenderer *struct {
+			Tabs              []*YouTubeMusicTab      `json:"tabs"`
+			SecondaryContents YouTubeMusicSectionList `json:"secondaryContents"`
+		} `json:"twoColumnBrowseResultsRenderer"`
+	} `json:"contents"`
+	// ContinuationContents 旧版布局的后续页
+	ContinuationContents struct {
+		MusicPlaylistShelfContinuation *YouTubeMusicShelf `json:"musicPlaylistShelfContinuation"`
+	} `json:"continuationContents"`
+	// OnResponseReceivedActions 新版布局的后续页
+	OnResponseReceivedActions []struct {
+		AppendContinuationItemsAction struct {
+			ContinuationItems []*YouTubeMusicShelfItem `json:"continuationItems"`
+		} `json:"appendContinuationItemsAction"`
+	} `json:"onResponseReceivedActions"`
+}
+
+type YouTubeMusicTab struct {
+	TabRenderer struct {
+		Content YouTubeMusicSectionList `json:"content"`
+	} `json:"tabRenderer"`
+}
+
+type YouTubeMusicSectionList struct {
+	SectionListRenderer struct {
+		Contents []struct {
+			MusicPlaylistShelfRenderer    *YouTubeMusicShelf  `json:"musicPlaylistShelfRenderer"`
+			MusicResponsiveHeaderRenderer *YouTubeMusicHeader `json:"musicResponsiveHeaderRenderer"`
+		} `json:"contents"`
+	} `json:"sectionListRenderer"`
+}
+
+// YouTubeMusicHeader 歌单信息，旧版为 musicDetailHeaderRenderer，新版为 musicResponsiveHeaderRenderer
+type YouTubeMusicHeader struct {
+	Title       YouTubeMusicText        `json:"title"`
+	Description YouTubeMusicDescription `json:"description"`
+	Thumbnail   struct {
+		CroppedSquareThumbnailRenderer *YouTubeMusicThumbnail `json:"croppedSquareThumbnailRenderer"`
+		MusicThumbnailRenderer         *YouTubeMusicThumbnail `json:"musicThumbnailRenderer"`
+	} `json:"thumbnail"`
+}
+
+// YouTubeMusicDescription 歌单简介，新版布局嵌套在 musicDescriptionShelfRenderer 中
+type YouTubeMusicDescription struct {
+	YouTubeMusicText
+	MusicDescriptionShelfRenderer *struct {
+		Description YouTubeMusicText `json:"description"`
+	} `json:"musicDescriptionShelfRenderer"`
+}
+
+func (d YouTubeMusicDescription) String() string {
+	if d.MusicDescriptionShelfRenderer != nil {
+		return d.MusicDescriptionShelfRenderer.Description.String()
+	}
+	return d.YouTubeMusicText.String()
+}
+
+type YouTubeMusicThumbnail struct {
+	Thumbnail struct {
+		Thumbnails []struct {
+			Url string `json:"url"`
+		} `json:"thumbnails"`
+	} `json:"thumbnail"`
+}
+
+type YouTubeMusicShelf struct {
+	Contents      []*YouTubeMusicShelfItem `json:"contents"`
+	Continuations []struct {
+		NextContinuationData struct {
+			Continuation string `json:"continuation"`
+		} `json:"nextContinuationData"`
+	} `json:"continuations"`
+}
+
+// YouTubeMusicShelfItem 歌单中的一首歌曲，新版布局的最后一项为下一页的 continuationItemRenderer
+type YouTubeMusicShelfItem struct {
+	MusicResponsiveListItemRenderer *struct {
+		PlaylistItemData struct {
+			VideoId string `json:"videoId"`
+		} `json:"playlistItemData"`
+		// FlexColumns 依次为歌名、歌手、专辑
+		FlexColumns []struct {
+			Renderer struct {
+				Text YouTubeMusicText `json:"text"`
+			} `json:"musicResponsiveListItemFlexColumnRenderer"`
+		} `json:"flexColumns"`
+		// FixedColumns 时长，如 3:45
+		FixedColumns []struct {
+			Renderer struct {
+				Text YouTubeMusicText `json:"text"`
+			} `json:"musicResponsiveListItemFixedColumnRenderer"`
+		} `json:"fixedColumns"`
+	} `json:"musicResponsiveListItemRenderer"`
+	ContinuationItemRenderer *struct {
+		ContinuationEndpoint struct {
+			ContinuationCommand struct {
+				Token string `json:"token"`
+			} `json:"continuationCommand"`
+		} `json:"continuationEndpoint"`
+	} `json:"continuationItemRenderer"`
+}
+
+// YouTubeMusicText 由若干段文本组成，如歌手列表 [A, " & ", B]
+type YouTubeMusicText struct {
+	Runs []struct {
+		Text string `json:"text"`
+	} `json:"runs"`
+}
+
+// String 拼接所有文本段
+func (t YouTubeMusicText) String() string {
+	s := ""
+	for _, v := range t.Runs {
+		s += v.Text
+	}
+	return s
+}
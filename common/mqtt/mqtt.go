@@ -0,0 +1,161 @@
+// Package mqtt 最小的 MQTT 3.1.1 发布客户端，仅支持 QoS 0，每次发布建立一次连接
+package mqtt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	packetConnect    = 0x10
+	packetConnack    = 0x20
+	packetPublish    = 0x30
+	packetDisconnect = 0xE0
+
+	// keepAlive 连接仅用于一次发布，保持较短的心跳间隔即可
+	keepAlive = 30
+	// maxRemainingLength 协议允许的最大剩余长度
+	maxRemainingLength = 268435455
+)
+
+// Client 连接参数，Broker 为 host:port 或 tcp://host:port，缺省端口 1883
+type Client struct {
+	Broker   string
+	ClientId string
+	Username string
+	Password string
+}
+
+// Message 待发布的消息
+type Message struct {
+	Topic   string
+	Payload []byte
+	// Retain 代理保留最后一条消息，新的订阅者立即收到
+	Retain bool
+}
+
+// Publish 连接代理、发布消息后断开
+func (c *Client) Publish(ctx context.Context, message *Message) error {
+	if message.Topic == "" {
+		return errors.New("mqtt: empty topic")
+	}
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", c.address())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else {
+		_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+	}
+
+	if err = writePacket(conn, packetConnect, c.connectBody()); err != nil {
+		return err
+	}
+	if err = readConnack(conn); err != nil {
+		return err
+	}
+	header := byte(packetPublish)
+	if message.Retain {
+		header |= 0x01
+	}
+	body := &bytes.Buffer{}
+	writeString(body, message.Topic)
+	body.Write(message.Payload)
+	if err = writePacket(conn, header, body.Bytes()); err != nil {
+		return err
+	}
+	return writePacket(conn, packetDisconnect, nil)
+}
+
+func (c *Client) address() string {
+	address := strings.TrimPrefix(c.Broker, "tcp://")
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "1883")
+	}
+	return address
+}
+
+func (c *Client) connectBody() []byte {
+	body := &bytes.Buffer{}
+	writeString(body, "MQTT")
+	body.WriteByte(4)   // 协议级别 3.1.1
+	flags := byte(0x02) // clean session
+	if c.Username != "" {
+		flags |= 0x80
+		if c.Password != "" {
+			flags |= 0x40
+		}
+	}
+	body.WriteByte(flags)
+	body.Write([]byte{0, keepAlive})
+	writeString(body, c.ClientId)
+	if c.Username != "" {
+		writeString(body, c.Username)
+		if c.Password != "" {
+			writeString(body, c.Password)
+		}
+	}
+	return body.Bytes()
+}
+
+// connackErrors CONNACK 返回码对应的错误
+var connackErrors = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+func readConnack(r io.Reader) error {
+	var packet [4]byte
+	if _, err := io.ReadFull(r, packet[:]); err != nil {
+		return fmt.Errorf("mqtt: read connack: %w", err)
+	}
+	if packet[0] != packetConnack || packet[1] != 2 {
+		return fmt.Errorf("mqtt: unexpected packet %#x", packet[0])
+	}
+	if code := packet[3]; code != 0 {
+		if msg, ok := connackErrors[code]; ok {
+			return fmt.Errorf("mqtt: connection refused: %v", msg)
+		}
+		return fmt.Errorf("mqtt: connection refused: code %d", code)
+	}
+	return nil
+}
+
+func writePacket(w io.Writer, header byte, body []byte) error {
+	if len(body) > maxRemainingLength {
+		return errors.New("mqtt: packet too large")
+	}
+	packet := make([]byte, 0, len(body)+5)
+	packet = append(packet, header)
+	// 剩余长度为变长编码，每字节 7 位，最高位表示后面还有字节
+	for n := len(body); ; {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		packet = append(packet, b)
+		if n == 0 {
+			break
+		}
+	}
+	_, err := w.Write(append(packet, body...))
+	return err
+}
+
+// writeString 写入 2 字节长度前缀的 UTF-8 字符串
+func writeString(buf *bytes.Buffer, s string) {
+	buf.Write([]byte{byte(len(s) >> 8), byte(len(s))})
+	buf.WriteString(s)
+}
@@ -0,0 +1,99 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readPacket 读取一个完整的报文，返回首字节与剩余部分
+func readPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, multiplier := 0, 1
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(b&0x7f) * multiplier
+		multiplier *= 128
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	body := make([]byte, length)
+	_, err = io.ReadFull(r, body)
+	return header, body, err
+}
+
+// fakeBroker 接受一个连接，以 code 响应 CONNACK，返回收到的所有报文
+func fakeBroker(t *testing.T, code byte) (string, <-chan [][]byte) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	packets := make(chan [][]byte, 1)
+	go func() {
+		defer listener.Close()
+		conn, err := listener.Accept()
+		if err != nil {
+			packets <- nil
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		var received [][]byte
+		for {
+			header, body, err := readPacket(r)
+			if err != nil {
+				break
+			}
+			received = append(received, append([]byte{header}, body...))
+			if header == packetConnect {
+				_, _ = conn.Write([]byte{packetConnack, 2, 0, code})
+			}
+			if header == packetDisconnect || code != 0 {
+				break
+			}
+		}
+		packets <- received
+	}()
+	return listener.Addr().String(), packets
+}
+
+func TestPublish(t *testing.T) {
+	address, packets := fakeBroker(t, 0)
+	client := &Client{Broker: "tcp://" + address, ClientId: "gomusic", Username: "user", Password: "pass"}
+	payload := strings.Repeat("歌", 100) // 超过 127 字节，剩余长度需两个字节
+	err := client.Publish(context.Background(), &Message{Topic: "gomusic/playlist", Payload: []byte(payload), Retain: true})
+	assert.NoError(t, err)
+
+	received := <-packets
+	assert.Len(t, received, 3)
+	connect := received[0]
+	assert.Equal(t, byte(packetConnect), connect[0])
+	assert.Equal(t, "\x00\x04MQTT\x04\xc2\x00\x1e\x00\x07gomusic\x00\x04user\x00\x04pass", string(connect[1:]))
+	publish := received[1]
+	assert.Equal(t, byte(packetPublish|0x01), publish[0])
+	assert.Equal(t, "\x00\x10gomusic/playlist"+payload, string(publish[1:]))
+	assert.Equal(t, []byte{packetDisconnect}, received[2])
+}
+
+func TestPublishRefused(t *testing.T) {
+	address, packets := fakeBroker(t, 5)
+	client := &Client{Broker: address, ClientId: "gomusic"}
+	err := client.Publish(context.Background(), &Message{Topic: "t", Payload: []byte("x")})
+	assert.EqualError(t, err, "mqtt: connection refused: not authorized")
+	<-packets
+}
+
+func TestAddress(t *testing.T) {
+	assert.Equal(t, "broker:1883", (&Client{Broker: "broker"}).address())
+	assert.Equal(t, "broker:8883", (&Client{Broker: "tcp://broker:8883"}).address())
+}
@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Adaptive 根据上游延迟与错误自动调整的并发上限（AIMD）：
+// 连续 Limit 次请求成功且延迟不超过 Target 时上限加一，失败或超时时上限减半，始终在 [Min, Max] 之间
+type Adaptive struct {
+	Min    int
+	Max    int
+	Target time.Duration
+
+	mu           sync.Mutex
+	limit        int
+	inflight     int
+	successes    int
+	lastDecrease time.Time
+	wake         chan struct{}
+}
+
+// AdaptiveStats 当前的并发状态
+type AdaptiveStats struct {
+	Limit    int `json:"limit"`
+	Inflight int `json:"inflight"`
+}
+
+// NewAdaptive 初始上限为 max，即上游正常时与不限流一致
+func NewAdaptive(min, max int, target time.Duration) *Adaptive {
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+	return &Adaptive{Min: min, Max: max, Target: target, limit: max, wake: make(chan struct{})}
+}
+
+// Acquire 等待并占用一个并发名额，ctx 被取消时返回其错误
+func (a *Adaptive) Acquire(ctx context.Context) error {
+	for {
+		a.mu.Lock()
+		if a.inflight < a.limit {
+			a.inflight++
+			a.mu.Unlock()
+			return nil
+		}
+		wake := a.wake
+		a.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wake:
+		}
+	}
+}
+
+// Release 释放名额并按本次请求的耗时与结果调整上限，ctx 被取消导致的失败不计入
+func (a *Adaptive) Release(latency time.Duration, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.inflight--
+	switch {
+	case errors.Is(err, context.Canceled):
+	case err != nil || (a.Target > 0 && latency > a.Target):
+		a.successes = 0
+		// 同一批并发请求先后失败时只减半一次
+		if now := time.Now(); now.Sub(a.lastDecrease) > a.Target {
+			a.lastDecrease = now
+			if a.limit /= 2; a.limit < a.Min {
+				a.limit = a.Min
+			}
+		}
+	default:
+		if a.successes++; a.successes >= a.limit && a.limit < a.Max {
+			a.limit++
+			a.successes = 0
+		}
+	}
+	close(a.wake)
+	a.wake = make(chan struct{})
+}
+
+// Do 在并发名额内执行 fn
+func (a *Adaptive) Do(ctx context.Context, fn func() error) error {
+	if err := a.Acquire(ctx); err != nil {
+		return err
+	}
+	start := time.Now()
+	err := fn()
+	a.Release(time.Since(start), err)
+	return err
+}
+
+func (a *Adaptive) Stats() *AdaptiveStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return &AdaptiveStats{Limit: a.limit, Inflight: a.inflight}
+}
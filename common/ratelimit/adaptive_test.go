@@ -0,0 +1,52 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdaptive(t *testing.T) {
+	a := NewAdaptive(1, 4, time.Millisecond)
+	assert.Equal(t, 4, a.Stats().Limit)
+
+	// 失败时减半，不低于下限
+	a.Release(0, errors.New("upstream"))
+	assert.Equal(t, 2, a.Stats().Limit)
+	time.Sleep(2 * time.Millisecond)
+	a.Release(time.Second, nil) // 超过目标延迟
+	assert.Equal(t, 1, a.Stats().Limit)
+	time.Sleep(2 * time.Millisecond)
+	a.Release(0, errors.New("upstream"))
+	assert.Equal(t, 1, a.Stats().Limit)
+
+	// 客户端取消不计入
+	a.Release(0, context.Canceled)
+	assert.Equal(t, 1, a.Stats().Limit)
+
+	// 连续成功 limit 次后加一
+	a.Release(0, nil)
+	assert.Equal(t, 2, a.Stats().Limit)
+	a.Release(0, nil)
+	assert.Equal(t, 2, a.Stats().Limit)
+	a.Release(0, nil)
+	assert.Equal(t, 3, a.Stats().Limit)
+}
+
+func TestAdaptiveAcquire(t *testing.T) {
+	a := NewAdaptive(1, 1, time.Second)
+	assert.NoError(t, a.Acquire(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, a.Acquire(ctx), context.DeadlineExceeded)
+
+	done := make(chan error)
+	go func() { done <- a.Acquire(context.Background()) }()
+	a.Release(0, nil)
+	assert.NoError(t, <-done)
+	assert.Equal(t, 1, a.Stats().Inflight)
+}
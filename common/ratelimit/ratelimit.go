@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepEvery 每处理多少次请求清理一次已回满的令牌桶
+const sweepEvery = 1024
+
+// Limiter 按 key（通常为客户端 IP）限流的令牌桶，令牌以 Rate 个每秒的速度恢复，最多积累 Burst 个
+type Limiter struct {
+	Rate  float64
+	Burst int
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	calls   int
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Result 本次请求的限流状态
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset 令牌桶回满所需的时间
+	Reset time.Duration
+	// RetryAfter 被拒绝时距离下一个令牌恢复的时间
+	RetryAfter time.Duration
+}
+
+func New(rate float64, burst int) *Limiter {
+	return &Limiter{Rate: rate, Burst: burst, buckets: make(map[string]*bucket)}
+}
+
+// Allow 消耗 key 的一个令牌
+func (l *Limiter) Allow(key string, now time.Time) *Result {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.calls++; l.calls%sweepEvery == 0 {
+		l.sweep(now)
+	}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.Burst), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = l.refill(b, now)
+	b.last = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return l.Result(allowed, b.tokens)
+}
+
+// Result 根据是否放行与消耗后剩余的令牌数计算限流状态，供保存在其他位置的令牌桶复用
+func (l *Limiter) Result(allowed bool, tokens float64) *Result {
+	result := &Result{Allowed: allowed, Limit: l.Burst}
+	if !allowed {
+		result.RetryAfter = l.duration(1 - tokens)
+	}
+	result.Remaining = int(math.Floor(tokens))
+	result.Reset = l.duration(float64(l.Burst) - tokens)
+	return result
+}
+
+func (l *Limiter) refill(b *bucket, now time.Time) float64 {
+	return math.Min(float64(l.Burst), b.tokens+now.Sub(b.last).Seconds()*l.Rate)
+}
+
+func (l *Limiter) duration(tokens float64) time.Duration {
+	return time.Duration(math.Ceil(tokens / l.Rate * float64(time.Second)))
+}
+
+// sweep 已回满的令牌桶与新建的等价，删除以控制内存
+func (l *Limiter) sweep(now time.Time) {
+	for k, b := range l.buckets {
+		if l.refill(b, now) >= float64(l.Burst) {
+			delete(l.buckets, k)
+		}
+	}
+}
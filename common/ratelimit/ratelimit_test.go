@@ -0,0 +1,42 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	l := New(1, 2) // 每秒恢复 1 个，最多 2 个
+	now := time.Unix(0, 0)
+
+	r := l.Allow("a", now)
+	assert.True(t, r.Allowed)
+	assert.Equal(t, 2, r.Limit)
+	assert.Equal(t, 1, r.Remaining)
+	assert.Equal(t, time.Second, r.Reset)
+
+	assert.True(t, l.Allow("a", now).Allowed)
+	r = l.Allow("a", now)
+	assert.False(t, r.Allowed)
+	assert.Equal(t, 0, r.Remaining)
+	assert.Equal(t, time.Second, r.RetryAfter)
+	assert.Equal(t, 2*time.Second, r.Reset)
+
+	// 其他 key 不受影响
+	assert.True(t, l.Allow("b", now).Allowed)
+
+	// 恢复一个令牌
+	r = l.Allow("a", now.Add(time.Second))
+	assert.True(t, r.Allowed)
+	assert.Equal(t, 0, r.Remaining)
+}
+
+func TestLimiterSweep(t *testing.T) {
+	l := New(1, 1)
+	now := time.Unix(0, 0)
+	l.Allow("a", now)
+	l.sweep(now.Add(time.Second))
+	assert.Empty(t, l.buckets)
+}
@@ -0,0 +1,145 @@
+package schema
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// SongListV1 歌单结果的 JSON Schema，GET /schema/v1/songlist.json。
+// v1 内只新增可选字段，不删除、不重命名字段，也不改变已有字段的类型；不兼容的修改发布为 v2
+//
+//go:embed v1/songlist.json
+var SongListV1 []byte
+
+var songListV1 = mustParse(SongListV1)
+
+// ValidateSongList 按 v1 schema 校验歌单结果序列化后的 JSON，供测试保证响应与公开的 schema 一致
+func ValidateSongList(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var doc any
+	if err = json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	return songListV1.validate(songListV1.root, doc, "$")
+}
+
+// document 已解析的 schema，仅支持歌单 schema 用到的关键字：type、properties、required、items、anyOf、minimum 与 $defs 内的 $ref
+type document struct {
+	root map[string]any
+}
+
+func mustParse(data []byte) *document {
+	root := map[string]any{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		panic(fmt.Sprintf("invalid schema: %v", err))
+	}
+	return &document{root: root}
+}
+
+func (d *document) validate(schema map[string]any, v any, path string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		def, ok := d.resolve(ref)
+		if !ok {
+			return fmt.Errorf("%v: 无法解析 %v", path, ref)
+		}
+		return d.validate(def, v, path)
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		var errs []string
+		for _, s := range anyOf {
+			err := d.validate(s.(map[string]any), v, path)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, err.Error())
+		}
+		return fmt.Errorf("%v: 不满足任一 schema（%v）", path, strings.Join(errs, "；"))
+	}
+	if t, ok := schema["type"]; ok && !matchType(t, v) {
+		return fmt.Errorf("%v: 类型应为 %v，实际为 %v", path, t, typeOf(v))
+	}
+	if minimum, ok := schema["minimum"].(float64); ok {
+		if n, ok := v.(float64); ok && n < minimum {
+			return fmt.Errorf("%v: %v 小于最小值 %v", path, n, minimum)
+		}
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		required, _ := schema["required"].([]any)
+		for _, key := range required {
+			if _, ok := v[key.(string)]; !ok {
+				return fmt.Errorf("%v: 缺少字段 %v", path, key)
+			}
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		for key, value := range v {
+			// 未声明的字段视为后续版本新增的可选字段
+			if property, ok := properties[key].(map[string]any); ok {
+				if err := d.validate(property, value, path+"."+key); err != nil {
+					return err
+				}
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, value := range v {
+				if err := d.validate(items, value, fmt.Sprintf("%v[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (d *document) resolve(ref string) (map[string]any, bool) {
+	name, ok := strings.CutPrefix(ref, "#/$defs/")
+	if !ok {
+		return nil, false
+	}
+	defs, _ := d.root["$defs"].(map[string]any)
+	def, ok := defs[name].(map[string]any)
+	return def, ok
+}
+
+func matchType(t any, v any) bool {
+	switch t := t.(type) {
+	case string:
+		actual := typeOf(v)
+		return actual == t || t == "number" && actual == "integer"
+	case []any:
+		for _, v2 := range t {
+			if matchType(v2, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func typeOf(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
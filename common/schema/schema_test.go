@@ -0,0 +1,73 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/format"
+	"GoMusic/common/models"
+)
+
+func TestValidateSongList(t *testing.T) {
+	tracks := []*models.Song{
+		{Name: "晴天", Artists: []string{"周杰伦"}, Album: "叶惠美", DurationMs: 269000, SourceId: "1"},
+		{Name: "Unknown", Artists: nil},
+	}
+	songs := format.Tracks(tracks)
+	songList := &models.SongList{
+		Name: "歌单", Songs: songs, SongsCount: 3, Tracks: tracks,
+		Tags: []string{"华语"}, Summary: format.Summarize(songs, 269000),
+	}
+	assert.NoError(t, ValidateSongList(songList))
+	// 平台未提供标签与统计时为 null
+	assert.NoError(t, ValidateSongList(&models.SongList{Name: "歌单", Songs: make([]string, 0)}))
+
+	tests := []struct {
+		name string
+		doc  string
+		err  string
+	}{
+		{"missing field", `{"name":"a","songs":[],"songs_count":0,"cover":"","description":"","tags":null}`, "缺少字段 summary"},
+		{"wrong type", `{"name":"a","songs":[1],"songs_count":0,"cover":"","description":"","tags":null,"summary":null}`, "$.songs[0]: 类型应为 string"},
+		{"negative", `{"name":"a","songs":[],"songs_count":-1,"cover":"","description":"","tags":null,"summary":null}`, "小于最小值"},
+		{"fraction", `{"name":"a","songs":[],"songs_count":1.5,"cover":"","description":"","tags":null,"summary":null}`, "类型应为 integer"},
+		{"bad track", `{"name":"a","songs":[],"songs_count":0,"tracks":[{"name":"b","artists":[]}],"cover":"","description":"","tags":null,"summary":null}`, "$.tracks[0]: 缺少字段 duration_ms"},
+		{"bad summary", `{"name":"a","songs":[],"songs_count":0,"cover":"","description":"","tags":null,"summary":{"duration_ms":0}}`, "不满足任一 schema"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSongList(json.RawMessage(tt.doc))
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.err)
+			}
+		})
+	}
+	// 未声明的字段视为新增的可选字段
+	assert.NoError(t, ValidateSongList(json.RawMessage(`{"name":"a","songs":[],"songs_count":0,"cover":"","description":"","tags":null,"summary":null,"extra":1}`)))
+}
+
+// 模型新增字段时须同步到 schema，否则公开的 schema 与实际响应不一致
+func TestSchemaCoversModels(t *testing.T) {
+	defs := songListV1.root["$defs"].(map[string]any)
+	summary := defs["summary"].(map[string]any)
+	artists := summary["properties"].(map[string]any)["artists"].(map[string]any)
+	for typ, schema := range map[reflect.Type]map[string]any{
+		reflect.TypeOf(models.SongList{}):    songListV1.root,
+		reflect.TypeOf(models.Song{}):        defs["song"].(map[string]any),
+		reflect.TypeOf(models.Summary{}):     summary,
+		reflect.TypeOf(models.ArtistCount{}): artists["items"].(map[string]any),
+	} {
+		properties := schema["properties"].(map[string]any)
+		for i := 0; i < typ.NumField(); i++ {
+			name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			assert.Contains(t, properties, name, "%v.%v", typ.Name(), typ.Field(i).Name)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResize(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	for x := 0; x < 400; x++ {
+		for y := 0; y < 200; y++ {
+			src.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+	dst := Resize(src, 100)
+	assert.Equal(t, image.Rect(0, 0, 100, 50), dst.Bounds())
+	r, g, _, a := dst.At(50, 25).RGBA()
+	assert.Equal(t, uint32(0xffff), r)
+	assert.Equal(t, uint32(0), g)
+	assert.Equal(t, uint32(0xffff), a)
+
+	// 不放大
+	assert.Equal(t, src, Resize(src, 1000))
+}
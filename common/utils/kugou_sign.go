@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"sort"
+	"strings"
+)
+
+// kugouSalt 酷狗安卓客户端的签名盐值
+const kugouSalt = "OIlwieks28dk2k092lksi2UIkp"
+
+// KugouSignature 计算酷狗接口签名：参数排序后拼接，首尾加盐取 md5
+func KugouSignature(query string) string {
+	params := strings.Split(query, "&")
+	sort.Strings(params)
+	sum := md5.Sum([]byte(kugouSalt + strings.Join(params, "") + kugouSalt))
+	return hex.EncodeToString(sum[:])
+}
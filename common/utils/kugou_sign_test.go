@@ -0,0 +1,16 @@
+package utils
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKugouSignature(t *testing.T) {
+	sum := md5.Sum([]byte(kugouSalt + "appid=1005page=1specialid=1" + kugouSalt))
+	assert.Equal(t, hex.EncodeToString(sum[:]), KugouSignature("specialid=1&page=1&appid=1005"))
+	// 参数顺序不影响签名
+	assert.Equal(t, KugouSignature("a=1&b=2"), KugouSignature("b=2&a=1"))
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+)
+
+var (
+	netEasySongIdRegex  = regexp.MustCompile(`^\d{1,20}$`)
+	qqMusicSongMidRegex = regexp.MustCompile(`^[0-9A-Za-z]{10,20}$`)
+
+	ErrInvalidSongId = errors.New("无效的歌曲 id")
+	ErrPlatform      = errors.New("不支持的平台")
+)
+
+// SongLink 歌曲在源平台的网页与客户端链接
+type SongLink struct {
+	Id  string `json:"id"`
+	Web string `json:"web"`
+	App string `json:"app"`
+}
+
+// GetSongLink 根据平台与歌曲 id 生成链接，QQ 音乐使用 songmid
+func GetSongLink(platform, id string) (*SongLink, error) {
+	switch platform {
+	case "netease":
+		if !netEasySongIdRegex.MatchString(id) {
+			return nil, ErrInvalidSongId
+		}
+		return &SongLink{
+			Id:  id,
+			Web: "https://music.163.com/song?id=" + id,
+			App: "orpheus://song/" + id,
+		}, nil
+	case "qqmusic":
+		if !qqMusicSongMidRegex.MatchString(id) {
+			return nil, ErrInvalidSongId
+		}
+		return &SongLink{
+			Id:  id,
+			Web: "https://y.qq.com/n/ryqq/songDetail/" + id,
+			App: "https://i.y.qq.com/v8/playsong.html?songmid=" + url.QueryEscape(id),
+		}, nil
+	}
+	return nil, ErrPlatform
+}
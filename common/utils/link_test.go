@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSongLink(t *testing.T) {
+	link, err := GetSongLink("netease", "5241457")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://music.163.com/song?id=5241457", link.Web)
+	assert.Equal(t, "orpheus://song/5241457", link.App)
+
+	link, err = GetSongLink("qqmusic", "0039MnYb0qxYhV")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://y.qq.com/n/ryqq/songDetail/0039MnYb0qxYhV", link.Web)
+
+	_, err = GetSongLink("netease", "abc")
+	assert.ErrorIs(t, err, ErrInvalidSongId)
+	_, err = GetSongLink("kugou", "1")
+	assert.ErrorIs(t, err, ErrPlatform)
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"strings"
+
+	"GoMusic/common/models"
+)
+
+// MergeSongLists 按出现顺序合并多个歌单并去重，歌名与歌手忽略大小写及多余空白
+func MergeSongLists(name string, songLists ...*models.SongList) *models.SongList {
+	seen := make(map[string]struct{})
+	songs := make([]string, 0)
+	durations := make([]int, 0)
+	explicit := make([]bool, 0)
+	tracks := make([]*models.Song, 0)
+	// 任一歌单未提供结构化歌曲时，合并结果同样不提供
+	hasTracks := true
+	cover := ""
+	for _, songList := range songLists {
+		if songList == nil {
+			continue
+		}
+		if cover == "" {
+			cover = songList.Cover
+		}
+		for i, song := range songList.Songs {
+			key := strings.ToLower(strings.Join(strings.Fields(song), " "))
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			songs = append(songs, song)
+			duration := 0
+			if i < len(songList.Durations) {
+				duration = songList.Durations[i]
+			}
+			durations = append(durations, duration)
+			explicit = append(explicit, i < len(songList.Explicit) && songList.Explicit[i])
+			if i < len(songList.Tracks) {
+				tracks = append(tracks, songList.Tracks[i])
+			} else {
+				hasTracks = false
+			}
+		}
+	}
+	if !hasTracks {
+		tracks = nil
+	}
+	return &models.SongList{
+		Name:       name,
+		Songs:      songs,
+		Tracks:     tracks,
+		Durations:  durations,
+		Explicit:   explicit,
+		SongsCount: len(songs),
+		Cover:      cover,
+	}
+}
@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/models"
+)
+
+func TestMergeSongLists(t *testing.T) {
+	merged := MergeSongLists("合并",
+		&models.SongList{Songs: []string{"江南 - 林俊杰", "Hello - Adele"}, Cover: "a"},
+		nil,
+		&models.SongList{Songs: []string{"hello  -  adele", "星河万里 - 王大毛"}, Cover: "b"},
+	)
+	assert.Equal(t, []string{"江南 - 林俊杰", "Hello - Adele", "星河万里 - 王大毛"}, merged.Songs)
+	assert.Equal(t, 3, merged.SongsCount)
+	assert.Equal(t, "a", merged.Cover)
+	assert.Nil(t, merged.Tracks)
+
+	jiangnan, adele := &models.Song{Name: "江南", Artists: []string{"林俊杰"}}, &models.Song{Name: "Hello", Artists: []string{"Adele"}}
+	merged = MergeSongLists("合并",
+		&models.SongList{Songs: []string{"江南 - 林俊杰"}, Tracks: []*models.Song{jiangnan}},
+		&models.SongList{Songs: []string{"江南 - 林俊杰", "Hello - Adele"}, Tracks: []*models.Song{jiangnan, adele}},
+	)
+	assert.Equal(t, []*models.Song{jiangnan, adele}, merged.Tracks)
+}
@@ -1,11 +1,11 @@
 package utils
 
 import (
+	"context"
 	"net/url"
 	"regexp"
-	"sync"
+	"strings"
 
-	"GoMusic/common/models"
 	"GoMusic/httputil"
 	"GoMusic/initialize/log"
 )
@@ -14,16 +14,12 @@ const (
 	bracketsPattern = `（|）`     // 去除特殊符号
 	miscPattern     = `\s?【.*】` // 去除特殊符号
 	netEasyV2       = "163cn"   // 短链接
-	shardModel      = `http[s]?://[^ ]+`
-	restfulModel    = `playlist/(\d+)`
+	restfulModel    = "playlist/"
 )
 
 var (
-	bracketsRegex    = regexp.MustCompile(bracketsPattern)
-	miscRegex        = regexp.MustCompile(miscPattern)
-	netEasyV2Regex   = regexp.MustCompile(netEasyV2)
-	shardModelRegex  = regexp.MustCompile(shardModel)
-	restfulModeRegex = regexp.MustCompile(restfulModel)
+	bracketsRegex = regexp.MustCompile(bracketsPattern)
+	miscRegex     = regexp.MustCompile(miscPattern)
 )
 
 func GetQQMusicParam(link string) (string, string, error) {
@@ -45,44 +41,153 @@ func GetQQMusicParam(link string) (string, string, error) {
 	return id, platform, nil
 }
 
-func GetNetEasyParam(link string) (string, error) {
-	link, err := standardUrl(link)
+// GetNetEasyParam 解析网易云歌单 id，常见链接直接截取字符串，不经过正则与 url 解析
+func GetNetEasyParam(ctx context.Context, link string) (string, error) {
+	link, err := standardUrl(ctx, link)
 	if err != nil {
-		log.Errorf("fail to standard url: %v", err)
+		log.WithContext(ctx).Errorf("fail to standard url: %v", err)
 		return "", err
 	}
+	if id, ok := playlistPathId(link); ok {
+		return id, nil
+	}
+	if id, ok := queryId(link); ok {
+		return id, nil
+	}
 	parse, err := url.ParseRequestURI(link)
 	if err != nil {
-		log.Errorf("fail to parse url: %v", err)
+		log.WithContext(ctx).Errorf("fail to parse url: %v", err)
 		return "", err
 	}
 	query, err := url.ParseQuery(parse.RawQuery)
 	if err != nil {
-		log.Errorf("fail to parse query: %v", err)
+		log.WithContext(ctx).Errorf("fail to parse query: %v", err)
 		return "", err
 	}
 	return query.Get("id"), nil
 }
 
-func standardUrl(link string) (string, error) {
+func standardUrl(ctx context.Context, link string) (string, error) {
 	// 格式化带中文的分享链接
-	link = shardModelRegex.FindString(link)
+	link = ExtractLink(link)
 	// 短链转换
-	if netEasyV2Regex.MatchString(link) {
-		return httputil.GetRedirectLocation(link)
-	}
-	// 匹配 restful 链接
-	if match := restfulModeRegex.FindStringSubmatch(link); len(match) > 1 {
-		link = "https://music.163.com/playlist?id=" + match[1]
+	if strings.Contains(link, netEasyV2) {
+		return httputil.GetRedirectLocation(ctx, link)
 	}
 	return link, nil
 }
 
+// ExtractLink 截取文本（如 App 的分享文案）中的第一个链接，返回原字符串的子串，不分配内存
+func ExtractLink(s string) string {
+	for i := strings.Index(s, "http"); i >= 0; {
+		rest := s[i+len("http"):]
+		if strings.HasPrefix(rest, "://") || strings.HasPrefix(rest, "s://") {
+			link := s[i:]
+			if end := strings.IndexByte(link, ' '); end >= 0 {
+				link = link[:end]
+			}
+			return link
+		}
+		next := strings.Index(rest, "http")
+		if next < 0 {
+			break
+		}
+		i += len("http") + next
+	}
+	return ""
+}
+
+// playlistPathId 截取 restful 链接中的歌单 id，如 music.163.com/playlist/2275447155
+func playlistPathId(link string) (string, bool) {
+	i := strings.Index(link, restfulModel)
+	if i < 0 {
+		return "", false
+	}
+	id := link[i+len(restfulModel):]
+	end := 0
+	for end < len(id) && id[end] >= '0' && id[end] <= '9' {
+		end++
+	}
+	return id[:end], end > 0
+}
+
+// queryId 截取查询参数中的纯数字 id，其他情况交由 url 解析处理
+func queryId(link string) (string, bool) {
+	q := strings.IndexByte(link, '?')
+	if q < 0 {
+		return "", false
+	}
+	for rest := link[q+1:]; rest != ""; {
+		param := rest
+		if i := strings.IndexByte(rest, '&'); i >= 0 {
+			param, rest = rest[:i], rest[i+1:]
+		} else {
+			rest = ""
+		}
+		if !strings.HasPrefix(param, "id=") {
+			continue
+		}
+		id := param[len("id="):]
+		if i := strings.IndexByte(id, '#'); i >= 0 {
+			id = id[:i]
+		}
+		for j := 0; j < len(id); j++ {
+			if id[j] < '0' || id[j] > '9' {
+				return "", false
+			}
+		}
+		return id, id != ""
+	}
+	return "", false
+}
+
 // StandardSongName 获取标准化歌名
 func StandardSongName(songName string) string {
 	return miscRegex.ReplaceAllString(replaceCNBrackets(songName), "")
 }
 
+var (
+	featClauseRegex = regexp.MustCompile(`(?i)\s*[(\[（]\s*(?:feat\.?|ft\.?|featuring)\s+([^)\]）]+)[)\]）]|\s+(?:feat\.?|ft\.?|featuring)\s+(.+)$`)
+	featSepRegex    = regexp.MustCompile(`(?i)\s+(?:feat\.?|ft\.?|featuring)\s+`)
+	artistSepRegex  = regexp.MustCompile(`\s*[,，&、]\s*`)
+)
+
+// CollapseArtists 合并重复的歌手：拆分“A feat. B”形式的歌手并去重，
+// 歌名中 feat. 段落的歌手均已出现在歌手列表中时去除该段落
+func CollapseArtists(songName string, artists []string) (string, []string) {
+	seen := make(map[string]struct{}, len(artists))
+	collapsed := make([]string, 0, len(artists))
+	add := func(name string) {
+		name = strings.TrimSpace(name)
+		key := strings.ToLower(name)
+		if _, ok := seen[key]; ok || name == "" {
+			return
+		}
+		seen[key] = struct{}{}
+		collapsed = append(collapsed, name)
+	}
+	for _, v := range artists {
+		parts := featSepRegex.Split(v, 2)
+		add(parts[0])
+		if len(parts) > 1 {
+			for _, feat := range artistSepRegex.Split(parts[1], -1) {
+				add(feat)
+			}
+		}
+	}
+
+	songName = featClauseRegex.ReplaceAllStringFunc(songName, func(clause string) string {
+		m := featClauseRegex.FindStringSubmatch(clause)
+		for _, feat := range artistSepRegex.Split(m[1]+m[2], -1) {
+			if _, ok := seen[strings.ToLower(strings.TrimSpace(feat))]; !ok {
+				return clause
+			}
+		}
+		return ""
+	})
+	return songName, collapsed
+}
+
 // 将中文括号替换为英文括号
 func replaceCNBrackets(s string) string {
 	return bracketsRegex.ReplaceAllStringFunc(s, func(m string) string {
@@ -92,14 +197,3 @@ func replaceCNBrackets(s string) string {
 		return ")"
 	})
 }
-
-func SyncMapToSortedSlice(trackIds []*models.TrackId, sm sync.Map) []string {
-	strings := make([]string, 0, len(trackIds))
-	for _, v := range trackIds {
-		value, _ := sm.Load(v.Id)
-		if elems, ok := value.(string); ok {
-			strings = append(strings, elems)
-		}
-	}
-	return strings
-}
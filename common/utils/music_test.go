@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollapseArtists(t *testing.T) {
+	cases := []struct {
+		name        string
+		artists     []string
+		wantName    string
+		wantArtists []string
+	}{
+		{"Stay", []string{"The Kid LAROI", "The Kid LAROI feat. Justin Bieber"}, "Stay", []string{"The Kid LAROI", "Justin Bieber"}},
+		{"Señorita", []string{"Shawn Mendes", "shawn mendes"}, "Señorita", []string{"Shawn Mendes"}},
+		{"Bad Guy (feat. Justin Bieber)", []string{"Billie Eilish", "Justin Bieber"}, "Bad Guy", []string{"Billie Eilish", "Justin Bieber"}},
+		{"Lovers (Feat. A & B)", []string{"C", "A", "B"}, "Lovers", []string{"C", "A", "B"}},
+		{"Lovers (feat. A & D)", []string{"C", "A"}, "Lovers (feat. A & D)", []string{"C", "A"}},
+		{"Old Town Road ft. Billy Ray Cyrus", []string{"Lil Nas X", "Billy Ray Cyrus"}, "Old Town Road", []string{"Lil Nas X", "Billy Ray Cyrus"}},
+		{"江南", []string{"林俊杰"}, "江南", []string{"林俊杰"}},
+	}
+	for _, c := range cases {
+		name, artists := CollapseArtists(c.name, c.artists)
+		assert.Equal(t, c.wantName, name, c.name)
+		assert.Equal(t, c.wantArtists, artists, c.name)
+	}
+}
+
+func TestGetNetEasyParam(t *testing.T) {
+	for link, want := range map[string]string{
+		"http://music.163.com/playlist/2275447155/434174568/?userid=440609461":                            "2275447155",
+		"https://music.163.com/#/playlist?app_version=8.10.81&id=8725919816&dlt=0846&creatorId=341246998": "8725919816",
+		"https://music.163.com/playlist?id=477577176&userid=341246998":                                    "477577176",
+		"分享歌单《喜欢的音乐》https://music.163.com/playlist?id=477577176&userid=341246998 (@网易云音乐)":                "477577176",
+		"https://music.163.com/playlist?userid=341246998&id=477577176#comments":                           "477577176",
+		"https://music.163.com/playlist?id=%34%37%37":                                                     "477",
+		"https://music.163.com/discover":                                                                  "",
+	} {
+		id, err := GetNetEasyParam(context.Background(), link)
+		assert.NoError(t, err, link)
+		assert.Equal(t, want, id, link)
+	}
+}
+
+func TestExtractLink(t *testing.T) {
+	assert.Equal(t, "https://a.com/x", ExtractLink("分享 https://a.com/x (@网易云音乐)"))
+	assert.Equal(t, "http://a.com", ExtractLink("httpx http://a.com"))
+	assert.Equal(t, "", ExtractLink("no link"))
+}
+
+func BenchmarkGetNetEasyParam(b *testing.B) {
+	links := []string{
+		"https://music.163.com/playlist?id=477577176&userid=341246998",
+		"http://music.163.com/playlist/2275447155/434174568/?userid=440609461",
+		"https://music.163.com/#/playlist?app_version=8.10.81&id=8725919816&dlt=0846&creatorId=341246998",
+	}
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = GetNetEasyParam(ctx, links[i%len(links)])
+	}
+}
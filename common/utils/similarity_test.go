@@ -0,0 +1,15 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNameSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, NameSimilarity("Taylor Swift", "taylor  swift"))
+	assert.Equal(t, 1.0, NameSimilarity("周杰伦", "周杰伦"))
+	assert.InDelta(t, 2.0/3, NameSimilarity("周杰伦", "周杰"), 1e-9)
+	assert.Equal(t, 0.0, NameSimilarity("周杰伦", "Adele"))
+	assert.Equal(t, 0.0, NameSimilarity("", "Adele"))
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"GoMusic/common/models"
+	"GoMusic/httputil"
+	"GoMusic/initialize/config"
+	"GoMusic/initialize/trace"
+	"GoMusic/logic"
+	"GoMusic/repo/cache"
+)
+
+// AdminAuth 校验 Authorization: Bearer <GOMUSIC_ADMIN_TOKEN>，未配置令牌时拒绝所有请求
+func AdminAuth(c *gin.Context) {
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if config.Conf.AdminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(config.Conf.AdminToken)) != 1 {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, &models.Result{Code: -1, Msg: "unauthorized", Data: nil})
+		return
+	}
+	c.Next()
+}
+
+// PurgeHandler 立即按保留规则清理数据
+func PurgeHandler(c *gin.Context) {
+	purged, err := logic.Purge(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+	c.JSON(http.StatusOK, &models.Result{Code: 1, Msg: SUCCESS, Data: gin.H{"purged": purged}})
+}
+
+// InvalidateCacheHandler 删除旧版本的缓存，应在所有副本升级完成后调用
+func InvalidateCacheHandler(c *gin.Context) {
+	deleted, err := logic.InvalidateCache()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, &models.Result{Code: -1, Msg: err.Error(), Data: gin.H{"deleted": deleted}})
+		return
+	}
+	c.JSON(http.StatusOK, &models.Result{Code: 1, Msg: SUCCESS, Data: gin.H{"deleted": deleted}})
+}
+
+// StatsHandler 运行状态统计
+func StatsHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, &models.Result{Code: 1, Msg: SUCCESS, Data: gin.H{
+		"neteasy_backend":   logic.NetEasyBackendStats(),
+		"chunk_concurrency": logic.ChunkConcurrencyStats(),
+		"circuits":          httputil.Circuits(),
+		"cache":             cache.Stats(),
+	}})
+}
+
+// TracesHandler 最近的慢请求链路
+func TracesHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, &models.Result{Code: 1, Msg: SUCCESS, Data: trace.Recent()})
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"GoMusic/common/format"
+	"GoMusic/common/models"
+	"GoMusic/common/utils"
+)
+
+const maxAggregateLinks = 10
+
+// SourceResult 单个链接的获取结果，失败不影响其他链接
+type SourceResult struct {
+	Url      string           `json:"url"`
+	Platform string           `json:"platform"`
+	Data     *models.SongList `json:"data"`
+	Error    string           `json:"error,omitempty"`
+}
+
+type AggregateResult struct {
+	Sources []*SourceResult `json:"sources"`
+	// 合并去重后的歌单，仅在 merge=true 时返回
+	Merged *models.SongList `json:"merged,omitempty"`
+}
+
+// AggregateHandler 并发获取多个平台的歌单，POST /songlists，表单 url 可重复，merge=true 时附带合并结果
+func AggregateHandler(c *gin.Context) {
+	links := c.PostFormArray("url")
+	if len(links) == 0 || len(links) > maxAggregateLinks {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: "歌单链接数量需在 1 到 10 之间", Data: nil})
+		return
+	}
+
+	// gin 解析参数时写入的缓存不是并发安全的，需在启动 goroutine 前构造 ctx
+	ctx := requestContext(c)
+	sources := make([]*SourceResult, len(links))
+	wg := sync.WaitGroup{}
+	for i, link := range links {
+		i, link := i, link
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			source := &SourceResult{Url: link, Platform: platform(link)}
+			songList, err := discover(ctx, link)
+			if err != nil {
+				source.Error = err.Error()
+			}
+			source.Data = songList
+			sources[i] = source
+		}()
+	}
+	wg.Wait()
+
+	result := &AggregateResult{Sources: sources}
+	if c.PostForm("merge") == "true" {
+		songLists := make([]*models.SongList, 0, len(sources))
+		for _, v := range sources {
+			songLists = append(songLists, v.Data)
+		}
+		result.Merged = utils.MergeSongLists("合并歌单", songLists...)
+		totalDuration := 0
+		for _, v := range result.Merged.Durations {
+			totalDuration += v
+		}
+		result.Merged.Summary = format.Summarize(result.Merged.Songs, totalDuration)
+	}
+	c.JSON(http.StatusOK, &models.Result{Code: 1, Msg: SUCCESS, Data: result})
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/models"
+	"GoMusic/logic"
+)
+
+// aggregateProvider 以链接本身作为歌单名与歌曲，不访问网络
+type aggregateProvider struct{}
+
+func (aggregateProvider) Name() string { return "aggregate-test" }
+
+func (aggregateProvider) Match(link string) bool { return strings.HasPrefix(link, "aggregate-test://") }
+
+func (aggregateProvider) Discover(_ context.Context, link string) (*models.SongList, error) {
+	return &models.SongList{Name: link, Songs: []string{link + " - artist"}, Durations: []int{1000}}, nil
+}
+
+func init() {
+	logic.RegisterProvider(aggregateProvider{})
+}
+
+// 多个链接并发获取时共用同一个 ctx，配合 go test -race 检查并发读取请求参数
+func TestAggregateHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/songlists", AggregateHandler)
+
+	form := url.Values{"merge": {"true"}}
+	for _, v := range []string{"1", "2", "3", "4", "5"} {
+		form.Add("url", "aggregate-test://"+v)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/songlists?locale=ja", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	result := &struct {
+		Data *AggregateResult `json:"data"`
+	}{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), result))
+	if assert.Len(t, result.Data.Sources, 5) {
+		for i, v := range result.Data.Sources {
+			assert.Equal(t, form["url"][i], v.Data.Name)
+		}
+	}
+	assert.Len(t, result.Data.Merged.Songs, 5)
+	assert.Equal(t, 5000, result.Data.Merged.Summary.DurationMs)
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"GoMusic/common/models"
+	"GoMusic/logic"
+)
+
+// AppleMusicExportHandler 将 url 指定的歌单导入用户的 Apple Music 资料库，
+// Music User Token 可通过 music_user_token 参数或 Music-User-Token 请求头传入
+func AppleMusicExportHandler(c *gin.Context) {
+	userToken := c.PostForm("music_user_token")
+	if userToken == "" {
+		userToken = c.GetHeader("Music-User-Token")
+	}
+	ctx, err := transferContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+	report, err := logic.AppleMusicExport(ctx, c.PostForm("url"), c.PostForm("developer_token"), userToken)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+	c.JSON(http.StatusOK, &models.Result{Code: 1, Msg: SUCCESS, Data: report})
+}
@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"GoMusic/common/models"
+	"GoMusic/logic"
+)
+
+// ArtistCatalogHandler 汇总歌手在各平台的热门歌曲，GET /artists?name= 或 GET /artists?url=
+func ArtistCatalogHandler(c *gin.Context) {
+	catalog, err := logic.ArtistCatalog(c.Request.Context(), c.Query("name"), c.Query("url"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+	c.JSON(http.StatusOK, &models.Result{Code: 1, Msg: SUCCESS, Data: catalog})
+}
@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"GoMusic/common/models"
+	"GoMusic/logic"
+)
+
+// CoverHandler 代理歌单/专辑封面，GET /cover?url=...&size=300&format=webp
+func CoverHandler(c *gin.Context) {
+	size, _ := strconv.Atoi(c.Query("size"))
+	data, contentType, err := logic.Cover(c.Request.Context(), c.Query("url"), size, c.DefaultQuery("format", logic.CoverJPEG))
+	switch {
+	case errors.Is(err, logic.ErrCoverHost):
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadGateway, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=604800")
+	c.Data(http.StatusOK, contentType, data)
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"GoMusic/common/models"
+	"GoMusic/logic"
+)
+
+// VerifyCredentialHandler 校验平台凭证是否仍然有效，POST /v1/credentials/{platform}:verify；
+// 网易云校验请求头 X-NetEase-Cookie 携带的 MUSIC_U，未携带时校验 GOMUSIC_NETEASY_MUSIC_U
+func VerifyCredentialHandler(c *gin.Context) {
+	platform, ok := strings.CutSuffix(c.Param("platform"), ":verify")
+	if !ok {
+		c.JSON(http.StatusNotFound, &models.Result{Code: -1, Msg: "未知的操作", Data: nil})
+		return
+	}
+	status, err := logic.VerifyCredential(requestContext(c), platform)
+	switch {
+	case errors.Is(err, logic.ErrCredentialPlatform):
+		c.JSON(http.StatusNotFound, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+	case err != nil:
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+	default:
+		c.JSON(http.StatusOK, &models.Result{Code: 1, Msg: SUCCESS, Data: status})
+	}
+}
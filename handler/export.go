@@ -0,0 +1,367 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"GoMusic/common/format"
+	"GoMusic/common/models"
+	"GoMusic/initialize/log"
+	"GoMusic/logic"
+)
+
+// ExportHandler 按导出配置（profile，或 format）将歌单导出为第三方工具可直接导入的文件，可选：
+//   - 过滤：exclude_programs（有声书、电台节目）、exclude_instrumental（伴奏）、
+//     exclude_explicit（含露骨内容，仅网易云提供标记）、min_duration、max_duration（如 60s、10m）
+//   - 规范化歌名：title_case=title|sentence，collapse_space、unify_brackets
+//   - 处理 emoji 与控制字符：symbols=strip|transliterate
+//   - 排序：sort=title|artist，collation=pinyin|stroke|binary
+//   - 流式导出：stream=true 时边获取边写入响应，超大歌单的内存占用不随歌曲数增长，不支持排序与条件请求
+func ExportHandler(c *gin.Context) {
+	// GET 时从查询参数读取，便于定期备份的脚本使用条件请求
+	form := c.PostForm
+	if c.Request.Method == http.MethodGet {
+		form = c.Query
+	}
+	link := form("url")
+	// format 为 profile 的别名
+	name := form("profile")
+	if name == "" {
+		name = form("format")
+	}
+	profile, err := format.GetProfile(name)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+	encoding, err := format.GetEncoding(form("encoding"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+	if profile.UTF8Only && encoding.Charset != "utf-8" {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: fmt.Sprintf("%v 格式仅支持 UTF-8 编码", profile.Name), Data: nil})
+		return
+	}
+
+	minDuration, err := parseDuration(form("min_duration"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+	maxDuration, err := parseDuration(form("max_duration"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+	options := &transformOptions{
+		rules: format.TitleRules{
+			Case:          form("title_case"),
+			CollapseSpace: form("collapse_space") == "true",
+			UnifyBrackets: form("unify_brackets") == "true",
+		},
+		symbols:             form("symbols"),
+		excludePrograms:     form("exclude_programs") == "true",
+		excludeInstrumental: form("exclude_instrumental") == "true",
+		excludeExplicit:     form("exclude_explicit") == "true",
+		minDuration:         minDuration,
+		maxDuration:         maxDuration,
+		sort:                form("sort"),
+		collation:           form("collation"),
+	}
+	if err = options.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+
+	if form("stream") == "true" {
+		if options.sort != format.SortNone {
+			c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: "流式导出不支持排序", Data: nil})
+			return
+		}
+		exportStream(c, link, form("filename"), profile, encoding, options)
+		return
+	}
+
+	songList, err := discover(requestContext(c), link)
+	switch {
+	case errors.Is(err, errUnsupportedLink):
+		c.JSON(http.StatusBadRequest, nil)
+		return
+	case err != nil:
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+
+	if songList, err = transform(songList, options); err != nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+
+	// 内容未变化时 GET 请求返回 304，定期备份的脚本可据此跳过写入与推送
+	etag := fmt.Sprintf(`"%v-%v-%v"`, format.Hash(songList)[:32], profile.Name, encoding.Name)
+	c.Header("ETag", etag)
+	if c.Request.Method == http.MethodGet && ifNoneMatch(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	if err = encode(buf, profile, encoding, songList); err != nil {
+		log.WithContext(c.Request.Context()).Errorf("fail to encode songlist: %v", err)
+		c.JSON(http.StatusInternalServerError, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+	filename := format.Filename(form("filename"), platform(link), songList.Name, profile.Name, profile.Extension, time.Now())
+	c.Header("Content-Disposition", format.ContentDisposition(filename))
+	c.Data(http.StatusOK, profile.ContentType+"; charset="+encoding.Charset, buf.Bytes())
+}
+
+func ifNoneMatch(header, etag string) bool {
+	for _, v := range strings.Split(header, ",") {
+		if v = strings.TrimSpace(v); v == etag || v == "*" || v == "W/"+etag {
+			return true
+		}
+	}
+	return false
+}
+
+// transformOptions 导出前对歌单的处理，零值不做任何处理
+type transformOptions struct {
+	rules               format.TitleRules
+	symbols             string
+	excludePrograms     bool
+	excludeInstrumental bool
+	excludeExplicit     bool
+	// minDuration、maxDuration 时长范围，0 表示不限制，时长未知的歌曲不过滤
+	minDuration time.Duration
+	maxDuration time.Duration
+	sort        string
+	collation   string
+}
+
+func (o *transformOptions) validate() error {
+	if err := o.rules.Validate(); err != nil {
+		return err
+	}
+	if o.maxDuration > 0 && o.minDuration > o.maxDuration {
+		return fmt.Errorf("最短时长 %v 不能大于最长时长 %v", o.minDuration, o.maxDuration)
+	}
+	return format.ValidateSymbols(o.symbols)
+}
+
+// transform 过滤歌曲、规范化歌名与符号后排序，并发请求共享同一份结果，因此在副本上修改
+func transform(songList *models.SongList, options *transformOptions) (*models.SongList, error) {
+	if *options == (transformOptions{}) {
+		return songList, nil
+	}
+	copied := *songList
+	copied.Name = format.ApplySymbols(songList.Name, options.symbols)
+	copied.Songs = make([]string, 0, len(songList.Songs))
+	copied.Durations = make([]int, 0, len(songList.Songs))
+	copied.Explicit = make([]bool, 0, len(songList.Songs))
+	if songList.Tracks != nil {
+		copied.Tracks = make([]*models.Song, 0, len(songList.Tracks))
+	}
+	totalDuration := 0
+	for i, v := range songList.Songs {
+		duration, explicit := at(songList.Durations, i), at(songList.Explicit, i)
+		if !options.keep(v, duration, explicit) {
+			continue
+		}
+		copied.Songs = append(copied.Songs, options.rules.Apply(format.ApplySymbols(v, options.symbols)))
+		copied.Durations = append(copied.Durations, duration)
+		copied.Explicit = append(copied.Explicit, explicit)
+		if i < len(songList.Tracks) {
+			copied.Tracks = append(copied.Tracks, options.track(songList.Tracks[i]))
+		}
+		totalDuration += duration
+	}
+	if len(copied.Songs) != len(songList.Songs) {
+		copied.SongsCount = len(copied.Songs)
+		copied.Summary = format.Summarize(copied.Songs, totalDuration)
+	}
+	// 排序后逐首的属性不再与歌曲对应
+	if options.sort != format.SortNone {
+		copied.Tracks, copied.Durations, copied.Explicit = nil, nil, nil
+	}
+	if err := format.SortSongs(copied.Songs, options.sort, options.collation); err != nil {
+		return nil, err
+	}
+	return &copied, nil
+}
+
+// keep 歌曲是否通过过滤条件
+func (o *transformOptions) keep(song string, durationMs int, explicit bool) bool {
+	if o.excludePrograms && format.IsProgram(song, durationMs) {
+		return false
+	}
+	if o.excludeInstrumental && format.IsInstrumental(song) {
+		return false
+	}
+	if o.excludeExplicit && explicit {
+		return false
+	}
+	duration := time.Duration(durationMs) * time.Millisecond
+	if duration > 0 && (duration < o.minDuration || (o.maxDuration > 0 && duration > o.maxDuration)) {
+		return false
+	}
+	return true
+}
+
+// track 对结构化歌曲应用与“歌名 - 歌手”相同的规范化规则
+func (o *transformOptions) track(t *models.Song) *models.Song {
+	t = format.NormalizeTrack(t)
+	t.Name = o.rules.ApplyTitle(format.ApplySymbols(t.Name, o.symbols))
+	artists := make([]string, 0, len(t.Artists))
+	for _, v := range t.Artists {
+		artists = append(artists, format.ApplySymbols(v, o.symbols))
+	}
+	t.Artists, t.Album = artists, format.ApplySymbols(t.Album, o.symbols)
+	return t
+}
+
+// at 读取逐首属性，平台未提供时返回零值
+func at[T any](values []T, i int) T {
+	var zero T
+	if i < len(values) {
+		return values[i]
+	}
+	return zero
+}
+
+// parseDuration 解析 60s、10m 形式的时长，纯数字按秒处理，为空时返回 0
+func parseDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	value := s
+	if _, err := strconv.Atoi(s); err == nil {
+		value += "s"
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("无效的时长：%v", s)
+	}
+	return d, nil
+}
+
+func encode(w io.Writer, profile *format.Profile, encoding *format.Encoding, songList *models.SongList) error {
+	writer, err := encoding.NewWriter(w)
+	if err != nil {
+		return err
+	}
+	if err = profile.Encode(writer, songList); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// exportStream 流式导出，歌单信息获取成功后才写入响应头，此后的错误只能中断响应
+func exportStream(c *gin.Context, link, filename string, profile *format.Profile, encoding *format.Encoding, options *transformOptions) {
+	provider := logic.MatchProvider(link)
+	if provider == nil {
+		c.JSON(http.StatusBadRequest, nil)
+		return
+	}
+	sink := &exportSink{c: c, link: link, filename: filename, profile: profile, encoding: encoding, options: options}
+	err := logic.Stream(requestContext(c), provider, link, sink)
+	if err == nil {
+		err = sink.close()
+	}
+	switch {
+	case err != nil && sink.writer == nil:
+		log.WithContext(c.Request.Context()).Errorf("fail to get %v discover: %v", provider.Name(), err)
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+	case err != nil:
+		log.WithContext(c.Request.Context()).Errorf("fail to stream songlist: %v", err)
+		c.Abort()
+	}
+}
+
+// exportSink 将流式获取的歌曲过滤、规范化后写入响应
+type exportSink struct {
+	c        *gin.Context
+	link     string
+	filename string
+	profile  *format.Profile
+	encoding *format.Encoding
+	options  *transformOptions
+
+	writer  io.WriteCloser
+	encoder format.SongEncoder
+	// pending 等待结构化歌曲的上一首歌，平台在 Song 之后紧接着写入 Track
+	pending *pendingSong
+}
+
+type pendingSong struct {
+	song       string
+	durationMs int
+}
+
+func (s *exportSink) Begin(songList *models.SongList) error {
+	name := format.ApplySymbols(songList.Name, s.options.symbols)
+	writer, err := s.encoding.NewWriter(s.c.Writer)
+	if err != nil {
+		return err
+	}
+	filename := format.Filename(s.filename, platform(s.link), name, s.profile.Name, s.profile.Extension, time.Now())
+	s.c.Header("Content-Disposition", format.ContentDisposition(filename))
+	s.c.Header("Content-Type", s.profile.ContentType+"; charset="+s.encoding.Charset)
+	s.c.Status(http.StatusOK)
+	s.writer = writer
+	s.encoder = s.profile.NewEncoder(writer, name)
+	return nil
+}
+
+func (s *exportSink) Song(song string, durationMs int, explicit bool) error {
+	if err := s.writePending(); err != nil {
+		return err
+	}
+	if !s.options.keep(song, durationMs, explicit) {
+		return nil
+	}
+	song = s.options.rules.Apply(format.ApplySymbols(song, s.options.symbols))
+	if _, ok := s.encoder.(format.TrackEncoder); ok {
+		s.pending = &pendingSong{song: song, durationMs: durationMs}
+		return nil
+	}
+	return s.encoder.WriteSong(song, durationMs)
+}
+
+// Track 编码器支持时按结构化歌曲写入上一首歌，被过滤的歌曲没有待写入的内容
+func (s *exportSink) Track(t *models.Song) error {
+	if s.pending == nil {
+		return nil
+	}
+	s.pending = nil
+	return s.encoder.(format.TrackEncoder).WriteTrack(s.options.track(t))
+}
+
+// writePending 平台没有紧接着写入结构化歌曲时按“歌名 - 歌手”写入
+func (s *exportSink) writePending() error {
+	if s.pending == nil {
+		return nil
+	}
+	pending := s.pending
+	s.pending = nil
+	return s.encoder.WriteSong(pending.song, pending.durationMs)
+}
+
+func (s *exportSink) close() error {
+	if err := s.writePending(); err != nil {
+		return err
+	}
+	if err := s.encoder.Flush(); err != nil {
+		return err
+	}
+	return s.writer.Close()
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/format"
+	"GoMusic/common/models"
+	"GoMusic/logic"
+)
+
+// exportProvider 返回带有结构化歌曲的歌单，其中一首歌手名含“ - ”
+type exportProvider struct{}
+
+func (exportProvider) Name() string { return "export-test" }
+
+func (exportProvider) Match(link string) bool { return strings.HasPrefix(link, "export-test://") }
+
+func (exportProvider) Discover(context.Context, string) (*models.SongList, error) {
+	tracks := []*models.Song{
+		{Name: "晴天", Artists: []string{"周杰伦"}, Album: "叶惠美", DurationMs: 269000},
+		{Name: "Song", Artists: []string{"Artist - Jr."}, DurationMs: 1000},
+	}
+	songs := format.Tracks(tracks)
+	return &models.SongList{Name: "歌单", Songs: songs, SongsCount: 2, Tracks: tracks, Durations: []int{269000, 1000}}, nil
+}
+
+func init() {
+	logic.RegisterProvider(exportProvider{})
+}
+
+func TestExportTracks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/export", ExportHandler)
+
+	// 流式与非流式导出均按结构化歌曲写入专辑
+	for _, query := range []string{"", "&stream=true", "&title_case=title"} {
+		req := httptest.NewRequest(http.MethodGet, "/export?profile=csv&url=export-test://1"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, query)
+		assert.Equal(t, "title,artist,album\n晴天,周杰伦,叶惠美\nSong,Artist - Jr.,\n", w.Body.String(), query)
+	}
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"GoMusic/common/format"
+	"GoMusic/common/models"
+	"GoMusic/initialize/config"
+	"GoMusic/initialize/log"
+)
+
+// ExtensionAuth 校验浏览器扩展的 X-Extension-Token 请求头，未配置令牌时不校验
+func ExtensionAuth(c *gin.Context) {
+	if len(config.Conf.ExtensionTokens) == 0 {
+		c.Next()
+		return
+	}
+	token := []byte(c.GetHeader("X-Extension-Token"))
+	for _, v := range config.Conf.ExtensionTokens {
+		if subtle.ConstantTimeCompare(token, []byte(v)) == 1 {
+			c.Next()
+			return
+		}
+	}
+	c.AbortWithStatusJSON(http.StatusUnauthorized, &models.Result{Code: -1, Msg: "unauthorized", Data: nil})
+}
+
+// ExtensionMatchHandler 判断当前标签页是否为支持的歌单页面，不请求上游，GET /ext/match?url=
+func ExtensionMatchHandler(c *gin.Context) {
+	name := platform(c.Query("url"))
+	c.JSON(http.StatusOK, &models.Result{Code: 1, Msg: SUCCESS, Data: gin.H{"supported": name != "", "platform": name}})
+}
+
+// ExtensionConvertHandler 获取歌单并返回可直接复制的文本，GET /ext/convert?url=&profile=；
+// 未指定 profile 时每行一首“歌名 - 歌手”
+func ExtensionConvertHandler(c *gin.Context) {
+	var profile *format.Profile
+	if name := c.Query("profile"); name != "" {
+		var err error
+		if profile, err = format.GetProfile(name); err != nil {
+			c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+			return
+		}
+	}
+	songList, err := discover(requestContext(c), c.Query("url"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+
+	text := strings.Join(songList.Songs, "\n")
+	if profile != nil {
+		buf := &bytes.Buffer{}
+		if err = profile.Encode(buf, songList); err != nil {
+			log.WithContext(c.Request.Context()).Errorf("fail to encode songlist: %v", err)
+			c.JSON(http.StatusInternalServerError, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+			return
+		}
+		text = buf.String()
+	}
+	// 仅返回扩展需要的字段，保持响应体积小
+	c.JSON(http.StatusOK, &models.Result{Code: 1, Msg: SUCCESS, Data: gin.H{"name": songList.Name, "count": len(songList.Songs), "text": text}})
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"GoMusic/common/models"
+	"GoMusic/logic"
+)
+
+// FunkwhaleAuthorizeHandler 跳转至 server 指定的 Funkwhale 实例授权页，授权后将 url 指定的歌单导入该实例
+func FunkwhaleAuthorizeHandler(c *gin.Context) {
+	ctx, err := transferContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+	link, err := logic.FunkwhaleAuthorizeUrl(ctx, c.Query("url"), c.Query("server"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+	c.Redirect(http.StatusFound, link)
+}
+
+// FunkwhaleCallbackHandler Funkwhale 授权回调，创建歌单并返回匹配结果
+func FunkwhaleCallbackHandler(c *gin.Context) {
+	if reason := c.Query("error"); reason != "" {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: "Funkwhale 授权被拒绝：" + reason, Data: nil})
+		return
+	}
+	report, err := logic.FunkwhaleCallback(requestContext(c), c.Query("code"), c.Query("state"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+	c.JSON(http.StatusOK, &models.Result{Code: 1, Msg: SUCCESS, Data: report})
+}
@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"GoMusic/common/format"
+	"GoMusic/common/graphql"
+	"GoMusic/common/models"
+	"GoMusic/initialize/config"
+	"GoMusic/logic"
+)
+
+var artistCountType = &graphql.Object{
+	Name: "ArtistCount",
+	Fields: map[string]*graphql.FieldDef{
+		"name": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.ArtistCount).Name, nil
+		}},
+		"count": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.ArtistCount).Count, nil
+		}},
+	},
+}
+
+var trackType = &graphql.Object{
+	Name: "Track",
+	Fields: map[string]*graphql.FieldDef{
+		"name": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.Song).Name, nil
+		}},
+		"artists": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.Song).Artists, nil
+		}},
+		"album": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.Song).Album, nil
+		}},
+		"durationMs": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.Song).DurationMs, nil
+		}},
+	},
+}
+
+var playlistType = &graphql.Object{
+	Name: "Playlist",
+	Fields: map[string]*graphql.FieldDef{
+		"name": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.SongList).Name, nil
+		}},
+		"songsCount": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.SongList).SongsCount, nil
+		}},
+		"cover": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.SongList).Cover, nil
+		}},
+		"description": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.SongList).Description, nil
+		}},
+		"tags": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.SongList).Tags, nil
+		}},
+		// 部分平台或旧的缓存歌单没有统计信息
+		"durationMs": {Resolve: func(source any, _ map[string]any) (any, error) {
+			if summary := source.(*models.SongList).Summary; summary != nil {
+				return summary.DurationMs, nil
+			}
+			return 0, nil
+		}},
+		"artists": {Type: artistCountType, Resolve: func(source any, _ map[string]any) (any, error) {
+			if summary := source.(*models.SongList).Summary; summary != nil {
+				return summary.Artists, nil
+			}
+			return []*models.ArtistCount{}, nil
+		}},
+		// songs(offset: Int = 0, limit: Int): [String]
+		"songs": {Resolve: func(source any, args map[string]any) (any, error) {
+			return page(source.(*models.SongList).Songs, args)
+		}},
+		// tracks(offset: Int = 0, limit: Int): [Track]，旧的缓存歌单没有专辑信息
+		"tracks": {Type: trackType, Resolve: func(source any, args map[string]any) (any, error) {
+			return page(format.ListTracks(source.(*models.SongList)), args)
+		}},
+	},
+}
+
+var jobType = &graphql.Object{
+	Name: "Job",
+	Fields: map[string]*graphql.FieldDef{
+		"id": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.Job).Id, nil
+		}},
+		"url": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.Job).Link, nil
+		}},
+		"status": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.Job).Status, nil
+		}},
+		// 组合任务依次获取的歌单链接，单个歌单的任务为 null
+		"urls": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.Job).Links, nil
+		}},
+		"step": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.Job).Step, nil
+		}},
+		"resolved": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.Job).Resolved, nil
+		}},
+		"total": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.Job).Total, nil
+		}},
+		"error": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.Job).Error, nil
+		}},
+		// 任务完成前为 null
+		"playlist": {Type: playlistType, Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.Job).SongList, nil
+		}},
+		// 未指定目标平台或导入完成前为 null
+		"transfer": {Type: transferType, Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.Job).Transfer, nil
+		}},
+		"createdAt": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.Job).CreatedAt.Format(time.RFC3339), nil
+		}},
+		"updatedAt": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.Job).UpdatedAt.Format(time.RFC3339), nil
+		}},
+	},
+}
+
+var transferType = &graphql.Object{
+	Name: "Transfer",
+	Fields: map[string]*graphql.FieldDef{
+		"id": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.ExportReport).Id, nil
+		}},
+		"platform": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.ExportReport).Platform, nil
+		}},
+		"playlistId": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.ExportReport).PlaylistId, nil
+		}},
+		"playlistUrl": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.ExportReport).PlaylistUrl, nil
+		}},
+		"total": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.ExportReport).Total, nil
+		}},
+		"matched": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.ExportReport).Matched, nil
+		}},
+		"unmatched": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.ExportReport).Unmatched, nil
+		}},
+		// 平台未返回结构化歌曲时为空列表
+		"missing": {Type: trackType, Resolve: func(source any, _ map[string]any) (any, error) {
+			if missing := source.(*models.ExportReport).Missing; missing != nil {
+				return missing, nil
+			}
+			return []*models.Song{}, nil
+		}},
+		"finishedAt": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return source.(*models.ExportReport).FinishedAt.Format(time.RFC3339), nil
+		}},
+	},
+}
+
+// page 按 offset、limit 参数截取列表，limit 默认为全部
+func page[T any](items []T, args map[string]any) ([]T, error) {
+	offset := graphql.IntArg(args, "offset", 0)
+	limit := graphql.IntArg(args, "limit", len(items))
+	if offset < 0 || limit < 0 {
+		return nil, errors.New("offset and limit must be non-negative")
+	}
+	if offset > len(items) {
+		offset = len(items)
+	}
+	end := offset + limit
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[offset:end], nil
+}
+
+var schema = &graphql.Schema{
+	Query: &graphql.Object{
+		Name: "Query",
+		Fields: map[string]*graphql.FieldDef{
+			// playlist(url: String!): Playlist
+			"playlist": {Type: playlistType, Resolve: func(source any, args map[string]any) (any, error) {
+				link, ok := graphql.StringArg(args, "url")
+				if !ok || link == "" {
+					return nil, errors.New("argument \"url\" is required")
+				}
+				return discover(source.(context.Context), link)
+			}},
+			// job(id: String!): Job
+			"job": {Type: jobType, Resolve: func(_ any, args map[string]any) (any, error) {
+				id, ok := graphql.StringArg(args, "id")
+				if !ok || id == "" {
+					return nil, errors.New("argument \"id\" is required")
+				}
+				return logic.GetJob(id)
+			}},
+			// transfer(id: String!): Transfer，id 为导入目标平台时返回的报告 id
+			"transfer": {Type: transferType, Resolve: func(_ any, args map[string]any) (any, error) {
+				id, ok := graphql.StringArg(args, "id")
+				if !ok || id == "" {
+					return nil, errors.New("argument \"id\" is required")
+				}
+				return logic.GetTransfer(id)
+			}},
+		},
+	},
+	MaxFields:     config.Conf.GraphQLMaxFields,
+	MaxRootFields: config.Conf.GraphQLMaxRootFields,
+}
+
+// GraphQLHandler 支持 GET 查询参数与 POST JSON 两种请求方式
+func GraphQLHandler(c *gin.Context) {
+	req := &graphql.Request{}
+	if c.Request.Method == http.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				c.JSON(http.StatusBadRequest, &graphql.Response{Errors: []*graphql.Error{{Message: err.Error()}}})
+				return
+			}
+		}
+	} else if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, &graphql.Response{Errors: []*graphql.Error{{Message: err.Error()}}})
+		return
+	}
+	c.JSON(http.StatusOK, schema.Execute(requestContext(c), req))
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/graphql"
+)
+
+func TestGraphQL(t *testing.T) {
+	execute := func(query string) string {
+		data, err := json.Marshal(schema.Execute(context.Background(), &graphql.Request{Query: query}))
+		assert.NoError(t, err)
+		return string(data)
+	}
+	// 没有结构化歌曲的歌单由歌名拆分
+	assert.Equal(t,
+		`{"data":{"playlist":{"tracks":[{"name":"aggregate-test://1","artists":["artist"],"album":"","durationMs":1000}]}}}`,
+		execute(`{ playlist(url: "aggregate-test://1") { tracks { name artists album durationMs } } }`))
+
+	// 通过别名重复根字段时拒绝执行
+	assert.Contains(t,
+		execute(`{ a: playlist(url: "aggregate-test://1") { name } b: playlist(url: "aggregate-test://2") { name } c: playlist(url: "aggregate-test://3") { name } d: playlist(url: "aggregate-test://4") { name } e: playlist(url: "aggregate-test://5") { name } f: playlist(url: "aggregate-test://6") { name } }`),
+		"exceeds the limit of 5")
+	assert.Contains(t, execute(`{ job(id: "") { id } }`), `argument \"id\" is required`)
+}
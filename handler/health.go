@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"GoMusic/common/models"
+	"GoMusic/repo/cache"
+)
+
+// HealthHandler 健康检查，GET /healthz；Redis 不可用时服务仍可用，状态为 degraded
+func HealthHandler(c *gin.Context) {
+	status := "ok"
+	if !cache.Available() {
+		status = "degraded"
+	}
+	c.JSON(http.StatusOK, &models.Result{Code: 1, Msg: SUCCESS, Data: gin.H{"status": status, "cache": cache.Stats()}})
+}
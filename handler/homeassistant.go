@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"GoMusic/logic"
+)
+
+type homeAssistantRequest struct {
+	Url         string `json:"url"`
+	MediaPlayer string `json:"media_player"`
+	Enqueue     string `json:"enqueue"`
+	// Publish 同时发布到 MQTT 主题
+	Publish bool `json:"publish"`
+}
+
+// HomeAssistantHandler 供 Home Assistant rest_command 调用，POST /homeassistant；
+// 响应不包裹在 Result 中，模板可直接使用 value_json.media_id，出错时返回 {"error": 错误信息}
+func HomeAssistantHandler(c *gin.Context) {
+	req := &homeAssistantRequest{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	songList, err := discover(requestContext(c), req.Url)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	playlist, err := logic.NewHomeAssistantPlaylist(songList, req.MediaPlayer, req.Enqueue)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Publish {
+		if err = logic.PublishHomeAssistant(c.Request.Context(), playlist); err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, playlist)
+}
@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"GoMusic/common/models"
+	"GoMusic/logic"
+)
+
+// JellyfinExportHandler 将 url 指定的歌单导入 Jellyfin 服务器，POST /jellyfin/export，表单：url、server、username、password
+func JellyfinExportHandler(c *gin.Context) {
+	ctx, err := transferContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+	report, err := logic.JellyfinExport(ctx, c.PostForm("url"), c.PostForm("server"), c.PostForm("username"), c.PostForm("password"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+	c.JSON(http.StatusOK, &models.Result{Code: 1, Msg: SUCCESS, Data: report})
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"GoMusic/common/models"
+	"GoMusic/initialize/config"
+	"GoMusic/logic"
+)
+
+// jobWaitDefault 未指定 timeout 时的等待时间
+const jobWaitDefault = 30 * time.Second
+
+// SubmitJobHandler 提交后台转换任务，POST /api/jobs，表单：url 可重复，多个歌单按顺序获取后合并去重；
+// 指定 target（subsonic、jellyfin、plex）与 server、username、password 或 token 时再导入目标平台。
+// 返回任务 id，通过 GET /api/jobs/:id 查询进度
+func SubmitJobHandler(c *gin.Context) {
+	ctx, err := transferContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+	job, err := logic.SubmitJob(ctx, c.PostFormArray("url"), formTarget(c))
+	switch {
+	case errors.Is(err, logic.ErrJobQueueFull):
+		c.JSON(http.StatusServiceUnavailable, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+	case err != nil:
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+	default:
+		c.JSON(http.StatusAccepted, &models.Result{Code: 1, Msg: SUCCESS, Data: job})
+	}
+}
+
+// JobHandler 查询任务进度，完成后返回歌单，GET /api/jobs/:id
+func JobHandler(c *gin.Context) {
+	job, err := logic.GetJob(c.Param("id"))
+	jobResult(c, job, err)
+}
+
+// WaitJobHandler 等待任务完成或失败后返回，超时返回当前进度，GET /api/jobs/:id/wait?timeout=30s；
+// timeout 不超过 GOMUSIC_JOB_WAIT_MAX
+func WaitJobHandler(c *gin.Context) {
+	timeout := jobWaitDefault
+	if v := c.Query("timeout"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: "timeout 格式错误，如 30s", Data: nil})
+			return
+		}
+		timeout = d
+	}
+	if timeout > config.Conf.JobWaitMax {
+		timeout = config.Conf.JobWaitMax
+	}
+	job, err := logic.WaitJob(c.Request.Context(), c.Param("id"), timeout)
+	jobResult(c, job, err)
+}
+
+func jobResult(c *gin.Context, job *models.Job, err error) {
+	switch {
+	case errors.Is(err, logic.ErrJobNotFound):
+		c.JSON(http.StatusNotFound, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+	default:
+		c.JSON(http.StatusOK, &models.Result{Code: 1, Msg: SUCCESS, Data: job})
+	}
+}
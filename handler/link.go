@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"GoMusic/common/models"
+	"GoMusic/common/utils"
+)
+
+const maxBatchLinks = 500
+
+// SongLinkHandler GET /v1/:platform/songs/:id/link
+func SongLinkHandler(c *gin.Context) {
+	link, err := utils.GetSongLink(c.Param("platform"), c.Param("id"))
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, utils.ErrPlatform) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+	c.JSON(http.StatusOK, &models.Result{Code: 1, Msg: SUCCESS, Data: link})
+}
+
+// SongLinksHandler GET /v1/:platform/songs/links?ids=1,2,3，无效的 id 不影响其他 id
+func SongLinksHandler(c *gin.Context) {
+	ids := strings.Split(c.Query("ids"), ",")
+	if len(ids) > maxBatchLinks {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: "单次最多查询 500 首歌曲", Data: nil})
+		return
+	}
+	links := make([]*utils.SongLink, 0, len(ids))
+	for _, id := range ids {
+		link, err := utils.GetSongLink(c.Param("platform"), strings.TrimSpace(id))
+		if errors.Is(err, utils.ErrPlatform) {
+			c.JSON(http.StatusNotFound, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+			return
+		}
+		if err != nil {
+			continue
+		}
+		links = append(links, link)
+	}
+	c.JSON(http.StatusOK, &models.Result{Code: 1, Msg: SUCCESS, Data: links})
+}
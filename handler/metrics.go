@@ -0,0 +1,13 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"GoMusic/initialize/metrics"
+)
+
+// MetricsHandler 以 Prometheus 文本格式输出运行指标，GET /metrics
+func MetricsHandler(c *gin.Context) {
+	c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	metrics.Write(c.Writer)
+}
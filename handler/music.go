@@ -1,66 +1,103 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"net/http"
-	"regexp"
 
 	"GoMusic/initialize/log"
 
 	"github.com/gin-gonic/gin"
 
 	"GoMusic/common/models"
+	"GoMusic/httputil"
 	"GoMusic/logic"
 )
 
 const (
-	netEasy = `(163cn)|(.163.)`
-	qqMusic = `.qq.`
 	SUCCESS = "success"
+	// netEasyCookieHeader 携带用户网易云 MUSIC_U 的请求头，用于导出私密歌单
+	netEasyCookieHeader = "X-NetEase-Cookie"
+
+	platformNetEasy = "netease"
 )
 
 var (
-	netEasyRegx, _ = regexp.Compile(netEasy)
-	qqMusicRegx, _ = regexp.Compile(qqMusic)
-	requestCount   = 1
+	requestCount = 1
+
+	errUnsupportedLink = errors.New("不支持的歌单链接")
+	// errUpstreamUnavailable 上游连续失败已熔断
+	errUpstreamUnavailable = errors.New("音乐平台暂时无法访问，请稍后重试")
 )
 
 func MusicHandler(c *gin.Context) {
 
 	link := c.PostForm("url")
 
-	log.Infof("第 %v 次歌单请求：%v", requestCount, link)
+	log.WithContext(c.Request.Context()).Infof("第 %v 次歌单请求：%v", requestCount, link)
 	requestCount++
 
+	songList, err := discover(requestContext(c), link)
 	switch {
-	// 1、网易云
-	case netEasyRegx.MatchString(link):
-		songList, err := logic.NetEasyDiscover(link)
-		if err != nil {
-			log.Errorf("fail to get neteasy discover: %v", err)
-			c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
-			return
-		}
-		c.JSON(200, &models.Result{
-			Code: 1,
-			Msg:  SUCCESS,
-			Data: songList,
-		})
-		return
-	// 2、QQ 音乐
-	case qqMusicRegx.MatchString(link):
-		songList, err := logic.QQMusicDiscover(link)
-		if err != nil {
-			log.Errorf("fail to get qqmusic discover: %v", err)
-			c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
-		}
+	case errors.Is(err, errUnsupportedLink):
+		c.JSON(http.StatusBadRequest, nil)
+	case err != nil:
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+	default:
 		c.JSON(200, &models.Result{
 			Code: 1,
 			Msg:  SUCCESS,
 			Data: songList,
 		})
-		return
-	// 3、都不是
-	default:
-		c.JSON(http.StatusBadRequest, nil)
 	}
 }
+
+// requestContext 请求的 ctx，携带请求头中用户的网易云 MUSIC_U 与参数 locale 指定的偏好语言
+func requestContext(c *gin.Context) context.Context {
+	ctx := logic.WithNetEasyCookie(c.Request.Context(), c.GetHeader(netEasyCookieHeader))
+	return logic.WithLocale(ctx, formValue(c, "locale"))
+}
+
+// transferContext 导入目标平台的请求的 ctx，在 requestContext 的基础上附带导入选项：
+// 参数 privacy 指定新建歌单的可见性，playlist 指定追加到的已有歌单，market 指定匹配歌曲的地区
+func transferContext(c *gin.Context) (context.Context, error) {
+	opts := &models.TransferOptions{Privacy: formValue(c, "privacy"), Playlist: formValue(c, "playlist"), Market: formValue(c, "market")}
+	return logic.WithTransferOptions(requestContext(c), opts)
+}
+
+// formValue 依次读取查询参数与表单参数
+func formValue(c *gin.Context, key string) string {
+	if v := c.Query(key); v != "" {
+		return v
+	}
+	return c.PostForm(key)
+}
+
+// platform 识别链接所属平台，无法识别时返回空字符串
+func platform(link string) string {
+	if provider := logic.MatchProvider(link); provider != nil {
+		return provider.Name()
+	}
+	return ""
+}
+
+// Discover 根据链接获取歌单，供命令行使用
+func Discover(ctx context.Context, link string) (*models.SongList, error) {
+	return discover(ctx, link)
+}
+
+// discover 根据链接所属平台获取歌单，ctx 被取消（如客户端断开）时中止上游请求
+func discover(ctx context.Context, link string) (*models.SongList, error) {
+	provider := logic.MatchProvider(link)
+	if provider == nil {
+		return nil, errUnsupportedLink
+	}
+	songList, err := provider.Discover(ctx, link)
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to get %v discover: %v", provider.Name(), err)
+	}
+	if errors.Is(err, httputil.ErrCircuitOpen) {
+		return nil, errUpstreamUnavailable
+	}
+	return songList, err
+}
@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"GoMusic/common/models"
+	"GoMusic/logic"
+)
+
+// PlexExportHandler 将 url 指定的歌单导入 Plex 音乐资料库，POST /plex/export，表单：url、server、token
+func PlexExportHandler(c *gin.Context) {
+	ctx, err := transferContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+	report, err := logic.PlexExport(ctx, c.PostForm("url"), c.PostForm("server"), c.PostForm("token"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+	c.JSON(http.StatusOK, &models.Result{Code: 1, Msg: SUCCESS, Data: report})
+}
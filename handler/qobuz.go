@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"GoMusic/common/models"
+	"GoMusic/logic"
+)
+
+// QobuzExportHandler 将 url 指定的歌单导入 Qobuz，POST /qobuz/export，表单：url，email 与 password 或 user_auth_token
+func QobuzExportHandler(c *gin.Context) {
+	ctx, err := transferContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+	report, err := logic.QobuzExport(ctx, c.PostForm("url"), c.PostForm("email"), c.PostForm("password"), c.PostForm("user_auth_token"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+	c.JSON(http.StatusOK, &models.Result{Code: 1, Msg: SUCCESS, Data: report})
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"GoMusic/common/models"
+	"GoMusic/common/ratelimit"
+	"GoMusic/initialize/config"
+	"GoMusic/logic"
+)
+
+// RateLimit 按客户端 IP 限流，并通过 X-RateLimit-* 响应头返回配额，客户端可据此自行降速；
+// redis 协调模式下所有副本共用计数，否则每个副本单独计数
+func RateLimit() gin.HandlerFunc {
+	if config.Conf.RateLimit <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	burst := config.Conf.RateBurst
+	if burst <= 0 {
+		burst = config.Conf.RateLimit
+	}
+	limiter := ratelimit.New(float64(config.Conf.RateLimit)/60, burst)
+	return func(c *gin.Context) {
+		result := logic.Allow(limiter, c.ClientIP())
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-RateLimit-Reset", seconds(result.Reset))
+		if !result.Allowed {
+			c.Header("Retry-After", seconds(result.RetryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, &models.Result{Code: -1, Msg: "too many requests", Data: nil})
+			return
+		}
+		c.Next()
+	}
+}
+
+// seconds 向上取整为秒
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
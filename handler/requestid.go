@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+
+	"GoMusic/initialize/log"
+)
+
+// requestIdHeader 请求 id，沿用调用方（如反向代理）传入的值，并在响应中返回
+const requestIdHeader = "X-Request-Id"
+
+var requestIdRegx = regexp.MustCompile(`^[\w.-]{1,64}$`)
+
+// RequestId 为每个请求分配 id 并附加到 ctx 的日志字段中，并发导出时可据此区分各请求的日志
+func RequestId() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIdHeader)
+		if !requestIdRegx.MatchString(id) {
+			b := make([]byte, 8)
+			_, _ = rand.Read(b)
+			id = hex.EncodeToString(b)
+		}
+		c.Request = c.Request.WithContext(log.WithFields(c.Request.Context(), "request_id", id))
+		c.Header(requestIdHeader, id)
+		c.Next()
+	}
+}
@@ -0,0 +1,15 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	jsonschema "GoMusic/common/schema"
+)
+
+// SongListSchemaHandler 歌单结果的 JSON Schema，GET /schema/v1/songlist.json
+func SongListSchemaHandler(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Data(http.StatusOK, "application/schema+json", jsonschema.SongListV1)
+}
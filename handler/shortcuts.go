@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"GoMusic/common/format"
+	"GoMusic/common/utils"
+)
+
+// ShortcutsHandler 供快捷指令、Tasker 等自动化工具使用，GET /shortcuts?url=<分享文案或链接>&format=text|json；
+// text（默认）每行一首“歌名 - 歌手”，json 为 {"name","count","songs"}，alfred（或 raycast）为 Script Filter JSON，
+// 出错时返回纯文本错误信息
+func ShortcutsHandler(c *gin.Context) {
+	text := c.Query("url")
+	// 分享文案中除链接外还有歌单名等文字
+	link := utils.ExtractLink(text)
+	if link == "" {
+		link = strings.TrimSpace(text)
+	}
+	songList, err := discover(requestContext(c), link)
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	switch c.DefaultQuery("format", "text") {
+	case "json":
+		c.JSON(http.StatusOK, gin.H{"name": songList.Name, "count": len(songList.Songs), "songs": songList.Songs})
+	case "alfred", "raycast":
+		c.JSON(http.StatusOK, format.ScriptFilter(songList))
+	case "text":
+		c.String(http.StatusOK, strings.Join(songList.Songs, "\n"))
+	default:
+		c.String(http.StatusBadRequest, "不支持的格式，可选：text, json, alfred, raycast")
+	}
+}
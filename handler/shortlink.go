@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"GoMusic/common/models"
+	"GoMusic/logic"
+)
+
+// ShortLinkHandler 为歌单链接与导出参数生成短链接，POST /p，表单：url 及 /export 的导出参数
+func ShortLinkHandler(c *gin.Context) {
+	// PostForm 解析表单后 Request.PostForm 才可用
+	link := c.PostForm("url")
+	code, err := logic.CreateShortLink(link, c.Request.PostForm)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+	c.JSON(http.StatusOK, &models.Result{Code: 1, Msg: SUCCESS, Data: gin.H{"code": code, "path": "/p/" + code}})
+}
+
+// ResolveShortLinkHandler 跳转到短链接对应的导出地址，GET /p/:code
+func ResolveShortLinkHandler(c *gin.Context) {
+	query, err := logic.ResolveShortLink(c.Param("code"))
+	switch {
+	case errors.Is(err, logic.ErrShortLinkNotFound):
+		c.JSON(http.StatusNotFound, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+	default:
+		c.Redirect(http.StatusFound, "/export?"+query.Encode())
+	}
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"GoMusic/common/models"
+	"GoMusic/logic"
+)
+
+// SpotifyAuthorizeHandler 跳转至 Spotify 授权页，授权后将 url 指定的歌单导入用户的 Spotify 资料库
+func SpotifyAuthorizeHandler(c *gin.Context) {
+	ctx, err := transferContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+	link, err := logic.SpotifyAuthorizeUrl(ctx, c.Query("url"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+	c.Redirect(http.StatusFound, link)
+}
+
+// SpotifyCallbackHandler Spotify 授权回调，创建歌单并返回匹配结果
+func SpotifyCallbackHandler(c *gin.Context) {
+	if reason := c.Query("error"); reason != "" {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: "Spotify 授权被拒绝：" + reason, Data: nil})
+		return
+	}
+	report, err := logic.SpotifyCallback(requestContext(c), c.Query("code"), c.Query("state"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+	c.JSON(http.StatusOK, &models.Result{Code: 1, Msg: SUCCESS, Data: report})
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"GoMusic/common/format"
+	"GoMusic/common/models"
+	"GoMusic/initialize/log"
+	"GoMusic/logic"
+)
+
+// StreamHandler 以 Server-Sent Events 逐批推送歌单，GET /songlist/stream?url=<歌单链接>。
+// 依次发送 begin（歌单信息）、若干 songs（一批歌曲与进度）与 summary（统计）事件，出错时发送 error 事件后结束；
+// 支持分批获取的平台（如网易云）每获取一批即推送，其余平台获取完整歌单后一次推送
+func StreamHandler(c *gin.Context) {
+	link := c.Query("url")
+	provider := logic.MatchProvider(link)
+	if provider == nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: errUnsupportedLink.Error(), Data: nil})
+		return
+	}
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	// 避免 nginx 缓冲整个响应
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	sink := &sseSink{c: c}
+	err := logic.Stream(requestContext(c), provider, link, sink)
+	// 推送未调用 Chunk 的平台剩余的歌曲
+	if err == nil {
+		err = sink.Chunk(sink.total)
+	}
+	if err != nil {
+		log.WithContext(c.Request.Context()).Errorf("fail to stream %v songlist: %v", provider.Name(), err)
+		sink.event("error", gin.H{"msg": err.Error()})
+		return
+	}
+	sink.event("summary", gin.H{"count": len(sink.songs), "summary": format.Summarize(sink.songs, sink.duration)})
+}
+
+// sseSink 缓存一批歌曲，在 Chunk 时作为一个 songs 事件推送；为生成统计保留全部歌名
+type sseSink struct {
+	c     *gin.Context
+	total int
+
+	songs     []string
+	duration  int
+	batch     []string
+	durations []int
+}
+
+func (s *sseSink) Begin(songList *models.SongList) error {
+	s.total = songList.SongsCount
+	s.event("begin", gin.H{
+		"name":        songList.Name,
+		"songs_count": songList.SongsCount,
+		"cover":       songList.Cover,
+		"description": songList.Description,
+		"tags":        songList.Tags,
+	})
+	return s.c.Request.Context().Err()
+}
+
+func (s *sseSink) Song(song string, durationMs int, _ bool) error {
+	s.songs = append(s.songs, song)
+	s.duration += durationMs
+	s.batch = append(s.batch, song)
+	s.durations = append(s.durations, durationMs)
+	return nil
+}
+
+func (s *sseSink) Chunk(resolved int) error {
+	if len(s.batch) > 0 {
+		s.event("songs", gin.H{"songs": s.batch, "durations": s.durations, "resolved": resolved, "total": s.total})
+		s.batch, s.durations = s.batch[:0], s.durations[:0]
+	}
+	// 客户端断开后停止获取后续批次
+	return s.c.Request.Context().Err()
+}
+
+func (s *sseSink) event(name string, data any) {
+	s.c.SSEvent(name, data)
+	s.c.Writer.Flush()
+}
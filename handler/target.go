@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"GoMusic/common/models"
+	"GoMusic/logic"
+)
+
+// formTarget 表单中使用账号登录的目标平台：target（subsonic、jellyfin、plex）、server、username、password 与 token，未指定 target 时返回 nil
+func formTarget(c *gin.Context) *logic.JobTarget {
+	platform := c.PostForm("target")
+	if platform == "" {
+		return nil
+	}
+	return &logic.JobTarget{
+		Platform: platform, Server: c.PostForm("server"),
+		Username: c.PostForm("username"), Password: c.PostForm("password"), Token: c.PostForm("token"),
+	}
+}
+
+// TargetPlaylistsHandler 列出用户在目标平台已有的歌单，POST /api/targets/playlists，表单同 formTarget；
+// 歌单 id 可作为导入参数 playlist，将歌曲追加到该歌单
+func TargetPlaylistsHandler(c *gin.Context) {
+	target := formTarget(c)
+	if target == nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: "请指定目标平台 target", Data: nil})
+		return
+	}
+	playlists, err := logic.TargetPlaylists(requestContext(c), target)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+	c.JSON(http.StatusOK, &models.Result{Code: 1, Msg: SUCCESS, Data: playlists})
+}
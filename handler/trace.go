@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"GoMusic/initialize/trace"
+)
+
+// traceIdHeader 响应中的链路 id，用户反馈慢请求时可据此在日志中查找
+const traceIdHeader = "X-Trace-Id"
+
+// Trace 为每个请求开始一条链路，沿用调用方 traceparent 请求头中的 trace id
+func Trace() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := trace.WithTraceparent(c.Request.Context(), c.GetHeader("traceparent"))
+		ctx, span := trace.Start(ctx, c.Request.Method+" "+c.FullPath())
+		c.Request = c.Request.WithContext(ctx)
+		c.Header(traceIdHeader, span.TraceId())
+		c.Next()
+		if len(c.Errors) > 0 {
+			span.End(c.Errors.Last())
+			return
+		}
+		span.End(nil)
+	}
+}
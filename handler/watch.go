@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"GoMusic/common/models"
+	"GoMusic/logic"
+)
+
+// WatchHandler 订阅网易云歌单的下架提醒，POST /watches，表单：url、webhook、email
+func WatchHandler(c *gin.Context) {
+	link := c.PostForm("url")
+	if platform(link) != platformNetEasy {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: "目前仅支持订阅网易云歌单", Data: nil})
+		return
+	}
+	watch, err := logic.Watch(c.Request.Context(), link, c.PostForm("webhook"), c.PostForm("email"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+		return
+	}
+	// token 仅在创建时返回，用于查询与取消订阅
+	c.JSON(http.StatusOK, &models.Result{Code: 1, Msg: SUCCESS, Data: gin.H{"token": watch.Token}})
+}
+
+// CheckWatchHandler 立即检查一次，POST /watches/:token/check
+func CheckWatchHandler(c *gin.Context) {
+	report, err := logic.CheckWatchNow(c.Request.Context(), c.Param("token"))
+	switch {
+	case errors.Is(err, logic.ErrWatchNotFound):
+		c.JSON(http.StatusNotFound, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+	case err != nil:
+		c.JSON(http.StatusBadGateway, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+	default:
+		c.JSON(http.StatusOK, &models.Result{Code: 1, Msg: SUCCESS, Data: report})
+	}
+}
+
+// UnwatchHandler 取消订阅，DELETE /watches/:token
+func UnwatchHandler(c *gin.Context) {
+	err := logic.Unwatch(c.Param("token"))
+	switch {
+	case errors.Is(err, logic.ErrWatchNotFound):
+		c.JSON(http.StatusNotFound, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+	default:
+		c.JSON(http.StatusOK, &models.Result{Code: 1, Msg: SUCCESS, Data: nil})
+	}
+}
+
+// FollowArtistsHandler 关注订阅歌单中的歌手，有新专辑时通知，POST /watches/:token/artists，表单：min_songs（默认 2）
+func FollowArtistsHandler(c *gin.Context) {
+	minSongs, err := strconv.Atoi(c.DefaultPostForm("min_songs", "2"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: "无效的 min_songs", Data: nil})
+		return
+	}
+	artists, err := logic.FollowArtists(c.Request.Context(), c.Param("token"), minSongs)
+	switch {
+	case errors.Is(err, logic.ErrWatchNotFound):
+		c.JSON(http.StatusNotFound, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+	case err != nil:
+		c.JSON(http.StatusBadRequest, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+	default:
+		names := make([]string, 0, len(artists))
+		for _, v := range artists {
+			names = append(names, v.Name)
+		}
+		c.JSON(http.StatusOK, &models.Result{Code: 1, Msg: SUCCESS, Data: gin.H{"artists": names}})
+	}
+}
+
+// UnfollowArtistsHandler 取消关注歌手，DELETE /watches/:token/artists
+func UnfollowArtistsHandler(c *gin.Context) {
+	err := logic.UnfollowArtists(c.Param("token"))
+	switch {
+	case errors.Is(err, logic.ErrWatchNotFound):
+		c.JSON(http.StatusNotFound, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, &models.Result{Code: -1, Msg: err.Error(), Data: nil})
+	default:
+		c.JSON(http.StatusOK, &models.Result{Code: 1, Msg: SUCCESS, Data: nil})
+	}
+}
@@ -0,0 +1,152 @@
+package httputil
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen 上游连续失败，熔断期间不再发起请求
+var ErrCircuitOpen = errors.New("upstream circuit open")
+
+// breakerTransport 按域名熔断：连续失败 threshold 次后熔断 cooldown，期间直接返回 ErrCircuitOpen；
+// 到期后放行一个探测请求（半开），成功则恢复，失败则重新熔断
+type breakerTransport struct {
+	threshold int
+	cooldown  time.Duration
+	next      http.RoundTripper
+	now       func() time.Time
+
+	mu    sync.Mutex
+	hosts map[string]*hostBreaker
+	swept time.Time
+}
+
+type hostBreaker struct {
+	failures  int
+	openUntil time.Time
+	probing   bool // 半开状态下已有探测请求
+	opened    int64
+	last      time.Time // 最近一次请求的时间
+}
+
+// CircuitStats 熔断器的状态
+type CircuitStats struct {
+	Host     string    `json:"host"`
+	State    string    `json:"state"` // closed、open 或 half-open
+	Failures int       `json:"failures"`
+	Opened   int64     `json:"opened"` // 累计熔断次数
+	Until    time.Time `json:"until,omitempty"`
+}
+
+// circuitBreaker 共用 transport 中的熔断器，未启用时为 nil
+var circuitBreaker *breakerTransport
+
+func newBreakerTransport(threshold int, cooldown time.Duration, next http.RoundTripper) *breakerTransport {
+	return &breakerTransport{threshold: threshold, cooldown: cooldown, next: next, now: time.Now, hosts: make(map[string]*hostBreaker)}
+}
+
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	if err := t.allow(host); err != nil {
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(req)
+	// 调用方取消的请求不代表上游故障
+	if err != nil && req.Context().Err() != nil {
+		t.release(host)
+		return nil, err
+	}
+	t.record(host, err == nil && resp.StatusCode < http.StatusInternalServerError && resp.StatusCode != http.StatusTooManyRequests)
+	return resp, err
+}
+
+func (t *breakerTransport) allow(host string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b, ok := t.hosts[host]
+	if !ok {
+		t.sweep()
+		b = &hostBreaker{}
+		t.hosts[host] = b
+	}
+	b.last = t.now()
+	if b.openUntil.IsZero() {
+		return nil
+	}
+	if t.now().Before(b.openUntil) || b.probing {
+		return fmt.Errorf("%w: %v", ErrCircuitOpen, host)
+	}
+	b.probing = true
+	return nil
+}
+
+// release 探测请求被取消时允许下一个请求探测
+func (t *breakerTransport) release(host string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.hosts[host].probing = false
+}
+
+func (t *breakerTransport) record(host string, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := t.hosts[host]
+	b.probing = false
+	if ok {
+		b.failures, b.openUntil = 0, time.Time{}
+		return
+	}
+	b.failures++
+	// 半开状态下探测失败立即重新熔断
+	if b.failures >= t.threshold || !b.openUntil.IsZero() {
+		b.openUntil = t.now().Add(t.cooldown)
+		b.opened++
+	}
+}
+
+// sweep 清理空闲且未处于熔断中的域名，每个 hostIdleTTL 最多执行一次，调用方需持有 mu
+func (t *breakerTransport) sweep() {
+	now := t.now()
+	if now.Sub(t.swept) < hostIdleTTL {
+		return
+	}
+	t.swept = now
+	for host, b := range t.hosts {
+		// 熔断已到期的域名清理后下次请求直接放行，与半开时的探测等价
+		if !b.probing && !now.Before(b.openUntil) && now.Sub(b.last) >= hostIdleTTL {
+			delete(t.hosts, host)
+		}
+	}
+}
+
+func (t *breakerTransport) stats() []*CircuitStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	stats := make([]*CircuitStats, 0, len(t.hosts))
+	for host, b := range t.hosts {
+		s := &CircuitStats{Host: host, State: "closed", Failures: b.failures, Opened: b.opened}
+		switch {
+		case b.openUntil.IsZero():
+		case now.Before(b.openUntil):
+			s.State, s.Until = "open", b.openUntil
+		default:
+			s.State = "half-open"
+		}
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Host < stats[j].Host })
+	return stats
+}
+
+// Circuits 各上游域名的熔断状态，未启用熔断时为空
+func Circuits() []*CircuitStats {
+	if circuitBreaker == nil {
+		return make([]*CircuitStats, 0)
+	}
+	return circuitBreaker.stats()
+}
@@ -0,0 +1,105 @@
+package httputil
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreakerTransport(t *testing.T) {
+	status := http.StatusBadGateway
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	now := time.Now()
+	breaker := newBreakerTransport(2, time.Minute, http.DefaultTransport)
+	breaker.now = func() time.Time { return now }
+	client := &http.Client{Transport: breaker}
+	get := func() error {
+		resp, err := client.Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	// 连续失败 2 次后熔断，不再请求上游
+	assert.NoError(t, get())
+	assert.NoError(t, get())
+	assert.ErrorIs(t, get(), ErrCircuitOpen)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, "open", breaker.stats()[0].State)
+
+	// 到期后半开，探测失败重新熔断
+	now = now.Add(time.Minute)
+	assert.Equal(t, "half-open", breaker.stats()[0].State)
+	assert.NoError(t, get())
+	assert.ErrorIs(t, get(), ErrCircuitOpen)
+	assert.Equal(t, 3, requests)
+
+	// 探测成功后恢复
+	now = now.Add(time.Minute)
+	status = http.StatusOK
+	assert.NoError(t, get())
+	assert.NoError(t, get())
+	assert.Equal(t, 5, requests)
+	stats := breaker.stats()[0]
+	assert.Equal(t, "closed", stats.State)
+	assert.Equal(t, int64(2), stats.Opened)
+}
+
+func TestBreakerTransportCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	breaker := newBreakerTransport(1, time.Minute, http.DefaultTransport)
+	client := &http.Client{Transport: breaker}
+
+	// 调用方取消的请求不计入失败
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	_, err := client.Do(req)
+	assert.ErrorIs(t, err, context.Canceled)
+	resp, err := client.Get(server.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+}
+
+func TestBreakerTransportSweep(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	idle, other := httptest.NewServer(handler), httptest.NewServer(handler)
+	defer idle.Close()
+	defer other.Close()
+	now := time.Now()
+	breaker := newBreakerTransport(1, 2*hostIdleTTL, http.DefaultTransport)
+	breaker.now = func() time.Time { return now }
+	client := &http.Client{Transport: breaker}
+	for _, v := range []string{failing.URL, idle.URL} {
+		resp, err := client.Get(v)
+		assert.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	// 超过 hostIdleTTL 后访问新域名时清理空闲的域名，熔断中的域名保留
+	now = now.Add(hostIdleTTL)
+	resp, err := client.Get(other.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	stats := breaker.stats()
+	if assert.Len(t, stats, 2) {
+		hosts := []string{stats[0].Host, stats[1].Host}
+		assert.Contains(t, hosts, failing.Listener.Addr().String())
+		assert.NotContains(t, hosts, idle.Listener.Addr().String())
+	}
+}
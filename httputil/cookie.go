@@ -0,0 +1,28 @@
+package httputil
+
+import (
+	"context"
+	"net/http"
+)
+
+type cookiesKey struct{}
+
+// WithCookies 返回携带 cookies 的 ctx，使用该 ctx 发出的请求均附带这些 cookie
+func WithCookies(ctx context.Context, cookies ...*http.Cookie) context.Context {
+	if len(cookies) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, cookiesKey{}, append(Cookies(ctx), cookies...))
+}
+
+// Cookies 返回 ctx 中携带的 cookie
+func Cookies(ctx context.Context) []*http.Cookie {
+	cookies, _ := ctx.Value(cookiesKey{}).([]*http.Cookie)
+	return cookies
+}
+
+func addCookies(req *http.Request) {
+	for _, v := range Cookies(req.Context()) {
+		req.AddCookie(v)
+	}
+}
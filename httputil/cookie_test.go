@@ -0,0 +1,31 @@
+package httputil
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithCookies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("Cookie")))
+	}))
+	defer server.Close()
+
+	read := func(ctx context.Context) string {
+		resp, err := Get(ctx, server.URL)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+	assert.Equal(t, "", read(context.Background()))
+	ctx := WithCookies(context.Background(), &http.Cookie{Name: "MUSIC_U", Value: "abc"})
+	assert.Equal(t, "MUSIC_U=abc", read(ctx))
+	ctx = WithCookies(ctx, &http.Cookie{Name: "os", Value: "pc"})
+	assert.Equal(t, "MUSIC_U=abc; os=pc", read(ctx))
+}
@@ -0,0 +1,79 @@
+package httputil
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"GoMusic/initialize/config"
+)
+
+var ErrInjectedFault = errors.New("injected fault")
+
+// faultTransport 按配置为上游请求注入延迟、失败与响应截断
+type faultTransport struct {
+	fault  config.Fault
+	next   http.RoundTripper
+	random func() float64
+}
+
+func newFaultTransport(fault config.Fault, next http.RoundTripper) *faultTransport {
+	return &faultTransport{fault: fault, next: next, random: rand.Float64}
+}
+
+func (t *faultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.matchHost(req.URL.Hostname()) {
+		return t.next.RoundTrip(req)
+	}
+	if t.fault.Latency > 0 {
+		select {
+		case <-time.After(t.fault.Latency):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+	if t.random() < t.fault.ErrorRate {
+		return nil, ErrInjectedFault
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if t.random() < t.fault.TruncateRate {
+		resp.Body = &truncatedBody{ReadCloser: resp.Body, remain: 64}
+		resp.ContentLength = -1
+	}
+	return resp, nil
+}
+
+func (t *faultTransport) matchHost(host string) bool {
+	if len(t.fault.Hosts) == 0 {
+		return true
+	}
+	for _, v := range t.fault.Hosts {
+		if v == host {
+			return true
+		}
+	}
+	return false
+}
+
+// truncatedBody 读取 remain 字节后返回 io.ErrUnexpectedEOF，模拟连接中断
+type truncatedBody struct {
+	io.ReadCloser
+	remain int
+}
+
+func (b *truncatedBody) Read(p []byte) (int, error) {
+	if b.remain <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if len(p) > b.remain {
+		p = p[:b.remain]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remain -= n
+	return n, err
+}
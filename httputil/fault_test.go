@@ -0,0 +1,50 @@
+package httputil
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/initialize/config"
+)
+
+func TestFaultTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("a", 1024)))
+	}))
+	defer server.Close()
+
+	t.Run("error", func(t *testing.T) {
+		transport := newFaultTransport(config.Fault{ErrorRate: 1}, http.DefaultTransport)
+		_, err := (&http.Client{Transport: transport}).Get(server.URL)
+		assert.ErrorIs(t, err, ErrInjectedFault)
+	})
+	t.Run("truncate", func(t *testing.T) {
+		transport := newFaultTransport(config.Fault{TruncateRate: 1}, http.DefaultTransport)
+		resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		assert.Len(t, body, 64)
+	})
+	t.Run("latency", func(t *testing.T) {
+		transport := newFaultTransport(config.Fault{Latency: 50 * time.Millisecond}, http.DefaultTransport)
+		start := time.Now()
+		resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	})
+	t.Run("other host", func(t *testing.T) {
+		transport := newFaultTransport(config.Fault{ErrorRate: 1, Hosts: []string{"music.163.com"}}, http.DefaultTransport)
+		resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+		assert.NoError(t, err)
+		resp.Body.Close()
+	})
+}
@@ -1,8 +1,11 @@
 package httputil
 
 import (
+	"context"
 	"io"
 	"net/http"
+
+	"GoMusic/initialize/config"
 )
 
 const (
@@ -13,25 +16,102 @@ var client *http.Client
 var clientNoRedirect *http.Client
 
 func init() {
-	client = &http.Client{}
+	transport := newTransport()
+	client = &http.Client{Transport: transport}
 	clientNoRedirect = &http.Client{
+		Transport: transport,
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			return http.ErrUseLastResponse // 返回此错误阻止重定向
 		},
 	}
 }
 
-func Post(link string, data io.Reader) (*http.Response, error) {
-	req, _ := http.NewRequest("POST", link, data)
+func newTransport() http.RoundTripper {
+	var transport http.RoundTripper = http.DefaultTransport
+	switch config.Conf.HTTPMode {
+	case config.HTTPModeRecord:
+		transport = newReplayTransport(config.Conf.HTTPFixtures, true, transport)
+	case config.HTTPModeReplay:
+		transport = newReplayTransport(config.Conf.HTTPFixtures, false, transport)
+	}
+	transport = &metricsTransport{next: transport}
+	// 故障注入位于大小限制之外，回放模式下同样生效
+	if config.Conf.UpstreamMaxBody > 0 {
+		transport = newLimitTransport(config.Conf.UpstreamMaxBody, transport)
+	}
+	if config.Conf.Fault.Enabled() {
+		transport = newFaultTransport(config.Conf.Fault, transport)
+	}
+	// 限流位于最外层，注入的故障同样计入并发
+	if config.Conf.UpstreamRate > 0 || config.Conf.UpstreamConcurrency > 0 {
+		transport = newThrottleTransport(config.Conf.UpstreamRate, config.Conf.UpstreamBurst, config.Conf.UpstreamConcurrency, transport)
+	}
+	// 熔断位于限流之外，熔断期间的请求无需排队即可失败
+	if config.Conf.CircuitThreshold > 0 {
+		circuitBreaker = newBreakerTransport(config.Conf.CircuitThreshold, config.Conf.CircuitCooldown, transport)
+		transport = circuitBreaker
+	}
+	return &traceTransport{next: transport}
+}
+
+// Post 发送表单请求，ctx 被取消（如客户端断开）时中止请求，ctx 中的 cookie 随请求发送
+func Post(ctx context.Context, link string, data io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", link, data)
+	if err != nil {
+		return nil, err
+	}
 	//req.Header.Add("User-Agent", UserAgent)
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	addCookies(req)
+	return client.Do(req)
+}
+
+func PostJSON(ctx context.Context, link string, data io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", link, data)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Content-Type", "application/json")
+	addCookies(req)
 	return client.Do(req)
 }
 
-func GetRedirectLocation(link string) (string, error) {
-	resp, err := clientNoRedirect.Get(link)
+func Get(ctx context.Context, link string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", link, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("User-Agent", UserAgent)
+	addCookies(req)
+	return client.Do(req)
+}
+
+// GetWithHeader 使用自定义请求头发送 GET 请求，如酷狗等需要模拟客户端请求头的接口
+func GetWithHeader(ctx context.Context, link string, header http.Header) (*http.Response, error) {
+	return Do(ctx, "GET", link, header, nil)
+}
+
+// Do 使用自定义请求头发送请求，如需要 Authorization 的开放平台接口
+func Do(ctx context.Context, method, link string, header http.Header, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, link, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header.Clone()
+	addCookies(req)
+	return client.Do(req)
+}
+
+func GetRedirectLocation(ctx context.Context, link string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", link, nil)
+	if err != nil {
+		return "", err
+	}
+	addCookies(req)
+	resp, err := clientNoRedirect.Do(req)
 	if err != nil {
 		return "", err
 	}
+	resp.Body.Close()
 	return resp.Header.Get("Location"), nil
 }
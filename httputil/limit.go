@@ -0,0 +1,59 @@
+package httputil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ErrBodyTooLarge 上游响应体超过 UpstreamMaxBody
+var ErrBodyTooLarge = errors.New("upstream response body too large")
+
+// limitTransport 限制上游响应体的大小，超出时读取返回 ErrBodyTooLarge 而不是静默截断
+type limitTransport struct {
+	max  int64
+	next http.RoundTripper
+}
+
+func newLimitTransport(max int64, next http.RoundTripper) *limitTransport {
+	return &limitTransport{max: max, next: next}
+}
+
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	// 声明的长度已超出时不再读取
+	if resp.ContentLength > t.max {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %v declares %d bytes", ErrBodyTooLarge, req.URL.Host, resp.ContentLength)
+	}
+	resp.Body = &limitedBody{ReadCloser: resp.Body, remain: t.max}
+	return resp, nil
+}
+
+// limitedBody 最多读取 remain 字节，仍有剩余数据时返回 ErrBodyTooLarge
+type limitedBody struct {
+	io.ReadCloser
+	remain int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remain <= 0 {
+		// 恰好读完时上游可能已无数据，多读 1 字节确认
+		var probe [1]byte
+		n, err := b.ReadCloser.Read(probe[:])
+		if n > 0 {
+			return 0, ErrBodyTooLarge
+		}
+		return 0, err
+	}
+	if int64(len(p)) > b.remain {
+		p = p[:b.remain]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remain -= int64(n)
+	return n, err
+}
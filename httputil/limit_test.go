@@ -0,0 +1,44 @@
+package httputil
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		size, _ := strconv.Atoi(r.URL.Query().Get("size"))
+		if r.URL.Query().Get("chunked") == "true" {
+			w.Header().Set("Transfer-Encoding", "chunked")
+			w.(http.Flusher).Flush()
+		}
+		_, _ = w.Write([]byte(strings.Repeat("a", size)))
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: newLimitTransport(64, http.DefaultTransport)}
+	read := func(query string) ([]byte, error) {
+		resp, err := client.Get(server.URL + "?" + query)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		return io.ReadAll(resp.Body)
+	}
+
+	body, err := read("size=64")
+	assert.NoError(t, err)
+	assert.Len(t, body, 64)
+	_, err = read("size=65")
+	assert.ErrorIs(t, err, ErrBodyTooLarge)
+	body, err = read("size=64&chunked=true")
+	assert.NoError(t, err)
+	assert.Len(t, body, 64)
+	_, err = read("size=65&chunked=true")
+	assert.ErrorIs(t, err, ErrBodyTooLarge)
+}
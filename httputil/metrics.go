@@ -0,0 +1,23 @@
+package httputil
+
+import (
+	"net/http"
+	"time"
+
+	"GoMusic/initialize/metrics"
+)
+
+var upstreamLatency = metrics.NewHistogramVec("gomusic_upstream_request_duration_seconds",
+	"Latency of upstream requests until response headers, by host.", metrics.DefaultBuckets, "host")
+
+// metricsTransport 记录每个上游域名的请求耗时，位于限流与熔断之内，不含排队时间
+type metricsTransport struct {
+	next http.RoundTripper
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	upstreamLatency.Observe(time.Since(start).Seconds(), req.URL.Host)
+	return resp, err
+}
@@ -0,0 +1,85 @@
+package httputil
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	stdhttputil "net/http/httputil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 每次请求都会变化的参数不参与匹配，如 QQ 音乐的时间戳
+var volatileParams = []string{"_"}
+
+// replayTransport 按请求内容录制或回放上游响应，录制文件为原始 HTTP 响应报文，便于查看与手动编辑
+type replayTransport struct {
+	dir    string
+	record bool
+	next   http.RoundTripper
+}
+
+func newReplayTransport(dir string, record bool, next http.RoundTripper) *replayTransport {
+	return &replayTransport{dir: dir, record: record, next: next}
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(t.dir, req.URL.Hostname(), fixtureKey(req, body)+".http")
+
+	if !t.record {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("no recorded response for %v %v: %w", req.Method, req.URL, err)
+		}
+		return http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), req)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	dump, err := stdhttputil.DumpResponse(resp, true)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	if err = os.WriteFile(path, dump, 0o644); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	_ = req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func fixtureKey(req *http.Request, body []byte) string {
+	u := *req.URL
+	query := u.Query()
+	for _, v := range volatileParams {
+		query.Del(v)
+	}
+	u.RawQuery = query.Encode()
+	sum := sha1.Sum([]byte(strings.Join([]string{req.Method, u.String(), string(body)}, "\n")))
+	return hex.EncodeToString(sum[:])
+}
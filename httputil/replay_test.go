@@ -0,0 +1,48 @@
+package httputil
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplayTransport(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write([]byte("echo:" + string(body)))
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+
+	post := func(c *http.Client, link, body string) (string, error) {
+		resp, err := c.Post(link, "text/plain", strings.NewReader(body))
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		return string(data), err
+	}
+
+	record := &http.Client{Transport: newReplayTransport(dir, true, http.DefaultTransport)}
+	rs, err := post(record, server.URL+"/api?_=1", "id=1")
+	assert.NoError(t, err)
+	assert.Equal(t, "echo:id=1", rs)
+
+	replay := &http.Client{Transport: newReplayTransport(dir, false, http.DefaultTransport)}
+	// 时间戳参数不参与匹配
+	rs, err = post(replay, server.URL+"/api?_=2", "id=1")
+	assert.NoError(t, err)
+	assert.Equal(t, "echo:id=1", rs)
+	assert.Equal(t, 1, hits)
+
+	// 未录制的请求直接报错
+	_, err = post(replay, server.URL+"/api", "id=2")
+	assert.Error(t, err)
+}
@@ -0,0 +1,130 @@
+package httputil
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// hostIdleTTL 域名超过该时间没有请求后，其限流与熔断状态被清理；域名可来自用户提交的链接，不清理会无限增长
+const hostIdleTTL = 10 * time.Minute
+
+// throttleTransport 按域名限制上游请求的速率与并发数，所有平台共用，避免大歌单的分批请求触发上游风控
+type throttleTransport struct {
+	rate        rate.Limit
+	burst       int
+	concurrency int
+	next        http.RoundTripper
+	now         func() time.Time
+
+	mu    sync.Mutex
+	hosts map[string]*hostThrottle
+	swept time.Time
+}
+
+type hostThrottle struct {
+	limiter *rate.Limiter // 为 nil 时不限速
+	slots   chan struct{} // 为 nil 时不限并发
+	active  int           // 等待中与进行中的请求数
+	last    time.Time     // 最近一次请求结束的时间
+}
+
+func newThrottleTransport(limit float64, burst, concurrency int, next http.RoundTripper) *throttleTransport {
+	r := rate.Inf
+	if limit > 0 {
+		r = rate.Limit(limit)
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &throttleTransport{rate: r, burst: burst, concurrency: concurrency, next: next, now: time.Now, hosts: make(map[string]*hostThrottle)}
+}
+
+// acquire 返回域名的限流状态并计入一个请求，请求结束后需调用 done
+func (t *throttleTransport) acquire(host string) *hostThrottle {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h, ok := t.hosts[host]
+	if !ok {
+		t.sweep()
+		h = &hostThrottle{}
+		if t.rate != rate.Inf {
+			h.limiter = rate.NewLimiter(t.rate, t.burst)
+		}
+		if t.concurrency > 0 {
+			h.slots = make(chan struct{}, t.concurrency)
+		}
+		t.hosts[host] = h
+	}
+	h.active++
+	return h
+}
+
+func (t *throttleTransport) done(h *hostThrottle) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h.active--
+	h.last = t.now()
+}
+
+// sweep 清理空闲的域名，每个 hostIdleTTL 最多执行一次，调用方需持有 mu
+func (t *throttleTransport) sweep() {
+	now := t.now()
+	if now.Sub(t.swept) < hostIdleTTL {
+		return
+	}
+	t.swept = now
+	for host, h := range t.hosts {
+		if h.active == 0 && now.Sub(h.last) >= hostIdleTTL {
+			delete(t.hosts, host)
+		}
+	}
+}
+
+func (t *throttleTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	h := t.acquire(req.URL.Host)
+	ctx := req.Context()
+	if h.limiter != nil {
+		if err := h.limiter.Wait(ctx); err != nil {
+			t.done(h)
+			return nil, err
+		}
+	}
+	if h.slots == nil {
+		defer t.done(h)
+		return t.next.RoundTrip(req)
+	}
+	select {
+	case h.slots <- struct{}{}:
+	case <-ctx.Done():
+		t.done(h)
+		return nil, ctx.Err()
+	}
+	release := func() {
+		<-h.slots
+		t.done(h)
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	// 读取响应体同样占用连接，关闭响应体时才释放名额
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+type releaseBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
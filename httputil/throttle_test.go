@@ -0,0 +1,95 @@
+package httputil
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThrottleTransportConcurrency(t *testing.T) {
+	var active, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: newThrottleTransport(0, 0, 2, http.DefaultTransport)}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(server.URL)
+			if assert.NoError(t, err) {
+				resp.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&peak))
+}
+
+func TestThrottleTransportRate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	// 每秒 20 次、突发 1 次：第 2、3 次请求各需等待约 50ms
+	client := &http.Client{Transport: newThrottleTransport(20, 1, 0, http.DefaultTransport)}
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL)
+		assert.NoError(t, err)
+		resp.Body.Close()
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+
+	// 等待期间 ctx 被取消时立即返回
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	_, err := client.Do(req)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestThrottleTransportSweep(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	idle, busy, other := httptest.NewServer(handler), httptest.NewServer(handler), httptest.NewServer(handler)
+	defer idle.Close()
+	defer busy.Close()
+	defer other.Close()
+	now := time.Now()
+	throttle := newThrottleTransport(0, 0, 1, http.DefaultTransport)
+	throttle.now = func() time.Time { return now }
+	client := &http.Client{Transport: throttle}
+
+	resp, err := client.Get(idle.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	// 未关闭响应体的请求仍在进行中，不被清理
+	held, err := client.Get(busy.URL)
+	assert.NoError(t, err)
+	defer held.Body.Close()
+
+	// 超过 hostIdleTTL 后访问新域名时清理空闲的域名
+	now = now.Add(hostIdleTTL)
+	resp, err = client.Get(other.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	throttle.mu.Lock()
+	defer throttle.mu.Unlock()
+	assert.Len(t, throttle.hosts, 2)
+	assert.NotContains(t, throttle.hosts, idle.Listener.Addr().String())
+}
@@ -0,0 +1,29 @@
+package httputil
+
+import (
+	"fmt"
+	"net/http"
+
+	"GoMusic/initialize/trace"
+)
+
+// traceTransport 为每个上游请求记录 span 并携带 traceparent 请求头，位于最外层，耗时包含限流排队
+type traceTransport struct {
+	next http.RoundTripper
+}
+
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if trace.FromContext(req.Context()) == nil {
+		return t.next.RoundTrip(req)
+	}
+	ctx, span := trace.Start(req.Context(), "http.request", "method", req.Method, "host", req.URL.Host)
+	// RoundTripper 不应修改调用方的请求
+	req = req.Clone(ctx)
+	req.Header.Set("traceparent", trace.Traceparent(ctx))
+	resp, err := t.next.RoundTrip(req)
+	if err == nil {
+		span.SetAttr("status", fmt.Sprint(resp.StatusCode))
+	}
+	span.End(err)
+	return resp, err
+}
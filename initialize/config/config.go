@@ -0,0 +1,430 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 所有配置项均可通过环境变量覆盖，便于在 Helm/Terraform 中为每个副本注入
+
+const (
+	CoordinationLocal = "local" // 进程内合并并发请求，限流与后台任务均在本副本内，适用于单实例部署
+	CoordinationRedis = "redis" // 通过 Redis 在多个副本间合并并发请求、共用限流令牌桶与后台任务队列
+
+	HTTPModeLive   = ""       // 直接请求上游
+	HTTPModeRecord = "record" // 请求上游并将响应录制到磁盘
+	HTTPModeReplay = "replay" // 仅从磁盘回放录制的响应，不访问网络
+
+	NetEasyBackendDirect   = "direct"   // 直连网易云官方接口
+	NetEasyBackendNCMApi   = "ncmapi"   // 经由自建的 NeteaseCloudMusicApi 访问
+	NetEasyBackendFailover = "failover" // 优先直连，失败时自动切换至 NeteaseCloudMusicApi
+)
+
+type Config struct {
+	Port          int
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+	MySQLDSN      string
+	// AutoMigrate 启动时自动执行数据库迁移
+	AutoMigrate bool
+	// Coordination 多副本协调方式：local 或 redis
+	Coordination string
+	// LockTTL 分布式锁的过期时间，持有者异常退出后锁会在此时间后自动释放；持有期间每 LockTTL/3 续期一次，至少为 1 秒
+	LockTTL time.Duration
+	// CacheTTL 歌曲缓存的过期时间，0 表示永不过期；CacheTTLs 按平台覆盖，如 netease=24h
+	CacheTTL  time.Duration
+	CacheTTLs map[string]time.Duration
+	// CacheJitter 过期时间的随机浮动比例，避免同一批写入的缓存同时过期
+	CacheJitter float64
+	// PlaylistCacheTTL 组装好的网易云与 QQ 音乐歌单的缓存时间，歌单未变化时重复导出无需重新组装，0 表示不缓存
+	PlaylistCacheTTL time.Duration
+	// CacheFailureThreshold Redis 连续失败多少次后绕过缓存，0 表示不绕过；CacheRetryInterval 绕过期间探测 Redis 的间隔
+	CacheFailureThreshold int
+	CacheRetryInterval    time.Duration
+	// LocalCacheSize 进程内一级缓存的歌曲数上限，0 表示不使用；LocalCacheTTL 一级缓存的过期时间
+	LocalCacheSize int
+	LocalCacheTTL  time.Duration
+	// AdminToken 管理接口的访问令牌，为空时禁用管理接口
+	AdminToken string
+	// ExtensionTokens 浏览器扩展接口的访问令牌，为空时不校验
+	ExtensionTokens []string
+	// CoverHosts 封面代理额外允许的图片域名后缀，如自建 Funkwhale 实例的域名
+	CoverHosts []string
+	// SongRetention 歌曲数据在数据库中的保留时长，0 表示永久保留
+	SongRetention time.Duration
+	// SongMaxRows 数据库中歌曲数据的最大行数，超出时删除最久未更新的数据，0 表示不限制
+	SongMaxRows int
+	// RetentionInterval 后台清理任务的执行间隔，小于 1 秒时使用默认值
+	RetentionInterval time.Duration
+	// HTTPMode 上游请求模式：空、record 或 replay，用于无网络环境下的开发调试
+	HTTPMode string
+	// HTTPFixtures 录制响应的存放目录
+	HTTPFixtures string
+	// UpstreamMaxBody 上游响应体的最大字节数，超出时请求失败，0 表示不限制
+	UpstreamMaxBody int64
+	// UpstreamRate 每个上游域名每秒最多发起的请求数，0 表示不限制；UpstreamBurst 允许的突发请求数
+	UpstreamRate  float64
+	UpstreamBurst int
+	// UpstreamConcurrency 每个上游域名同时进行的请求数上限，0 表示不限制；应不低于 ChunkConcurrencyMax
+	UpstreamConcurrency int
+	// CircuitThreshold 同一上游域名连续失败多少次后熔断，0 表示不熔断；CircuitCooldown 熔断时长
+	CircuitThreshold int
+	CircuitCooldown  time.Duration
+	// Fault 上游请求故障注入，仅用于预发环境演练
+	Fault Fault
+	// Upstream 上游接口地址，可指向自建的反向代理
+	Upstream Upstream
+	// HealthCheckInterval 订阅歌单的下架检查间隔
+	HealthCheckInterval time.Duration
+	// MPDPathMap 生成 MPD 播放列表时的路径映射，from=to 将扫描到的路径前缀替换为 MPD 所见的路径
+	MPDPathMap []string
+	// SMTP 邮件通知配置，Host 为空时不发送邮件
+	SMTP SMTP
+	// EventWebhooks 接收全部生命周期事件（获取歌单、导入完成、订阅歌单变化）的 webhook 地址
+	EventWebhooks []string
+	// EventAudit 是否将生命周期事件写入审计日志
+	EventAudit bool
+	// MQTT 向 Home Assistant 等订阅者发布转换结果，Broker 为空时不发布
+	MQTT MQTT
+	// TraceSlow 耗时超过此值的请求输出完整链路至日志，0 表示不输出
+	TraceSlow time.Duration
+	// RateLimit 每个客户端 IP 每分钟允许的请求数，0 表示不限流
+	RateLimit int
+	// RateBurst 允许的突发请求数，0 时与 RateLimit 相同
+	RateBurst int
+	// GraphQLMaxFields 单个 GraphQL 查询最多选择的字段数；GraphQLMaxRootFields 最多选择的根字段数，0 表示不限制
+	GraphQLMaxFields     int
+	GraphQLMaxRootFields int
+	// Spotify 开放平台应用，ClientId 为空时不提供导入 Spotify 的功能
+	Spotify Spotify
+	// KKBOX 开放平台应用，ClientId 为空时不支持 KKBOX 歌单
+	KKBOX KKBOX
+	// SoundCloudClientId SoundCloud api-v2 的 client_id，为空时从网页版脚本中获取
+	SoundCloudClientId string
+	// FunkwhaleRedirectUrl Funkwhale 授权回调地址，指向 /funkwhale/callback，为空时不提供导入 Funkwhale 的功能
+	FunkwhaleRedirectUrl string
+	// DefaultLocale 请求未指定 locale 时的偏好语言，如 zh-CN，为空时使用各平台的默认语言
+	DefaultLocale string
+	// QobuzAppId Qobuz 应用 id，为空时不提供导入 Qobuz 的功能
+	QobuzAppId string
+	// AppleMusicDeveloperToken MusicKit 开发者令牌（JWT），请求未携带时使用
+	AppleMusicDeveloperToken string
+	// ChunkConcurrencyMin、ChunkConcurrencyMax 每个平台分片请求的并发范围，根据上游表现在此范围内自动调整
+	ChunkConcurrencyMin int
+	ChunkConcurrencyMax int
+	// ChunkLatencyTarget 分片请求的目标延迟，超过时视为上游过载并降低并发
+	ChunkLatencyTarget time.Duration
+	// ChunkWorkers 单个歌单同时运行的分片 goroutine 数上限，ChunkWorkersByPlatform 按平台覆盖
+	ChunkWorkers           int
+	ChunkWorkersByPlatform map[string]int
+	// JobWorkers 同时执行的后台转换任务数
+	JobWorkers int
+	// JobQueueSize 排队中的后台任务上限，队列已满时拒绝提交
+	JobQueueSize int
+	// JobTimeout 单个后台任务的最长执行时间
+	JobTimeout time.Duration
+	// JobTTL 任务进度与结果的保留时间
+	JobTTL time.Duration
+	// JobWaitMax 等待任务完成接口的最长等待时间，应小于反向代理的读超时
+	JobWaitMax time.Duration
+}
+
+type SMTP struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+type MQTT struct {
+	Broker   string // host:port 或 tcp://host:port
+	Username string
+	Password string
+	Topic    string
+}
+
+type KKBOX struct {
+	ClientId     string
+	ClientSecret string
+}
+
+type Spotify struct {
+	ClientId     string
+	ClientSecret string
+	// RedirectUrl 授权回调地址，需与应用设置中的 Redirect URI 一致，指向 /spotify/callback
+	RedirectUrl string
+}
+
+type Upstream struct {
+	NetEasyPlaylist     string // 网易云歌单详情
+	NetEasySongDetail   string // 网易云歌曲详情
+	NetEasyArtistAlbums string // 网易云歌手专辑，请求时拼接 /{歌手 id}
+	NetEasyArtist       string // 网易云歌手热门歌曲，请求时拼接 /{歌手 id}
+	NetEasySearch       string // 网易云搜索
+	NetEasyAccount      string // 网易云当前登录账号，用于校验 MUSIC_U
+	QQMusic             string // QQ 音乐统一请求入口
+	KugouSpecial        string // 酷狗歌单信息
+	KugouSongs          string // 酷狗歌单歌曲
+	Kuwo                string // 酷我歌单详情
+	YouTubeMusic        string // YouTube Music InnerTube 接口，请求时拼接 /browse
+	KKBOXAccounts       string // KKBOX 授权服务
+	KKBOXApi            string // KKBOX Open API
+	Joox                string // JOOX openjoox 接口
+	SoundCloud          string // SoundCloud 网页版，用于获取 client_id
+	SoundCloudApi       string // SoundCloud api-v2
+	Bandcamp            string // Bandcamp 粉丝主页与收藏接口
+	NetEasyBackend      string // 网易云接口访问方式：direct、ncmapi 或 failover
+	NCMApi              string // NeteaseCloudMusicApi 服务地址
+	SpotifyAccounts     string // Spotify 授权服务
+	SpotifyApi          string // Spotify Web API
+	AppleMusic          string // Apple Music API
+	QobuzApi            string // Qobuz API
+	// NetEasyMusicU 访问网易云歌单时默认携带的 MUSIC_U cookie，用于导出该账号的私密歌单
+	NetEasyMusicU string
+	// FailoverThreshold 直连连续失败多少次后切换至代理
+	FailoverThreshold int
+	// FailoverCooldown 切换至代理后多久重新尝试直连
+	FailoverCooldown time.Duration
+}
+
+type Fault struct {
+	Latency      time.Duration // 每个请求附加的延迟
+	ErrorRate    float64       // 请求直接失败的概率
+	TruncateRate float64       // 响应体被截断的概率
+	Hosts        []string      // 仅对这些域名注入，为空时对所有域名注入
+}
+
+// Enabled 是否配置了任一故障
+func (f *Fault) Enabled() bool {
+	return f.Latency > 0 || f.ErrorRate > 0 || f.TruncateRate > 0
+}
+
+// SongCacheTTL 平台歌曲缓存的过期时间，未单独配置时使用 CacheTTL
+func (c *Config) SongCacheTTL(platform string) time.Duration {
+	if v, ok := c.CacheTTLs[platform]; ok {
+		return v
+	}
+	return c.CacheTTL
+}
+
+// PlatformChunkWorkers 平台单个歌单的分片 goroutine 数上限，未单独配置时使用 ChunkWorkers
+func (c *Config) PlatformChunkWorkers(platform string) int {
+	if v, ok := c.ChunkWorkersByPlatform[platform]; ok {
+		return v
+	}
+	return c.ChunkWorkers
+}
+
+var Conf = Load()
+
+// Load 从环境变量读取配置，未设置时使用默认值
+func Load() *Config {
+	return &Config{
+		Port:          Int("GOMUSIC_PORT", 8081),
+		RedisAddr:     String("GOMUSIC_REDIS_ADDR", "127.0.0.1:6379"),
+		RedisPassword: String("GOMUSIC_REDIS_PASSWORD", ""),
+		RedisDB:       Int("GOMUSIC_REDIS_DB", 0),
+		MySQLDSN:      String("GOMUSIC_MYSQL_DSN", "root:12345678@tcp(127.0.0.1:3306)/go_music?charset=utf8mb4&parseTime=True&loc=Local"),
+		AutoMigrate:   Bool("GOMUSIC_AUTO_MIGRATE", true),
+		Coordination:  String("GOMUSIC_COORDINATION", CoordinationLocal),
+		LockTTL:       MinDuration("GOMUSIC_LOCK_TTL", 30*time.Second, time.Second),
+		CacheTTL:      Duration("GOMUSIC_CACHE_TTL", 72*time.Hour),
+		CacheTTLs:     Durations("GOMUSIC_CACHE_TTLS"),
+		CacheJitter:   Float("GOMUSIC_CACHE_JITTER", 0.1),
+
+		PlaylistCacheTTL:      Duration("GOMUSIC_PLAYLIST_CACHE_TTL", 10*time.Minute),
+		CacheFailureThreshold: Int("GOMUSIC_CACHE_FAILURE_THRESHOLD", 3),
+		CacheRetryInterval:    Duration("GOMUSIC_CACHE_RETRY_INTERVAL", 10*time.Second),
+		LocalCacheSize:        Int("GOMUSIC_LOCAL_CACHE_SIZE", 10000),
+		LocalCacheTTL:         Duration("GOMUSIC_LOCAL_CACHE_TTL", 5*time.Minute),
+
+		AdminToken:        String("GOMUSIC_ADMIN_TOKEN", ""),
+		ExtensionTokens:   Strings("GOMUSIC_EXTENSION_TOKENS", nil),
+		CoverHosts:        Strings("GOMUSIC_COVER_HOSTS", nil),
+		SongRetention:     Duration("GOMUSIC_SONG_RETENTION", 0),
+		SongMaxRows:       Int("GOMUSIC_SONG_MAX_ROWS", 0),
+		RetentionInterval: MinDuration("GOMUSIC_RETENTION_INTERVAL", time.Hour, time.Second),
+
+		HTTPMode:            String("GOMUSIC_HTTP_MODE", HTTPModeLive),
+		HTTPFixtures:        String("GOMUSIC_HTTP_FIXTURES", "testdata/fixtures"),
+		UpstreamMaxBody:     int64(Int("GOMUSIC_UPSTREAM_MAX_BODY", 32<<20)),
+		UpstreamRate:        Float("GOMUSIC_UPSTREAM_RATE", 20),
+		UpstreamBurst:       Int("GOMUSIC_UPSTREAM_BURST", 20),
+		UpstreamConcurrency: Int("GOMUSIC_UPSTREAM_CONCURRENCY", 16),
+		CircuitThreshold:    Int("GOMUSIC_CIRCUIT_THRESHOLD", 5),
+		CircuitCooldown:     Duration("GOMUSIC_CIRCUIT_COOLDOWN", 30*time.Second),
+		Fault: Fault{
+			Latency:      Duration("GOMUSIC_FAULT_LATENCY", 0),
+			ErrorRate:    Float("GOMUSIC_FAULT_ERROR_RATE", 0),
+			TruncateRate: Float("GOMUSIC_FAULT_TRUNCATE_RATE", 0),
+			Hosts:        Strings("GOMUSIC_FAULT_HOSTS", nil),
+		},
+		Upstream: Upstream{
+			NetEasyPlaylist:     String("GOMUSIC_NETEASY_PLAYLIST_URL", "https://music.163.com/api/v6/playlist/detail"),
+			NetEasySongDetail:   String("GOMUSIC_NETEASY_SONG_DETAIL_URL", "https://music.163.com/api/v3/song/detail"),
+			NetEasyArtistAlbums: String("GOMUSIC_NETEASY_ARTIST_ALBUMS_URL", "https://music.163.com/api/artist/albums"),
+			NetEasyArtist:       String("GOMUSIC_NETEASY_ARTIST_URL", "https://music.163.com/api/artist"),
+			NetEasySearch:       String("GOMUSIC_NETEASY_SEARCH_URL", "https://music.163.com/api/search/get"),
+			NetEasyAccount:      String("GOMUSIC_NETEASY_ACCOUNT_URL", "https://music.163.com/api/nuser/account/get"),
+			QQMusic:             String("GOMUSIC_QQMUSIC_URL", "https://u6.y.qq.com/cgi-bin/musics.fcg"),
+			KugouSpecial:        String("GOMUSIC_KUGOU_SPECIAL_URL", "http://mobilecdnbj.kugou.com/api/v5/special/info"),
+			KugouSongs:          String("GOMUSIC_KUGOU_SONGS_URL", "http://gatewayretry.kugou.com/v2/get_other_list_file"),
+			Kuwo:                String("GOMUSIC_KUWO_URL", "http://nplserver.kuwo.cn/pl.svc"),
+			YouTubeMusic:        String("GOMUSIC_YOUTUBE_MUSIC_URL", "https://music.youtube.com/youtubei/v1"),
+			KKBOXAccounts:       String("GOMUSIC_KKBOX_ACCOUNTS_URL", "https://account.kkbox.com/oauth2"),
+			KKBOXApi:            String("GOMUSIC_KKBOX_API_URL", "https://api.kkbox.com/v1.1"),
+			Joox:                String("GOMUSIC_JOOX_URL", "https://api-jooxtt.sanook.com/openjoox/v1"),
+			SoundCloud:          String("GOMUSIC_SOUNDCLOUD_URL", "https://soundcloud.com"),
+			SoundCloudApi:       String("GOMUSIC_SOUNDCLOUD_API_URL", "https://api-v2.soundcloud.com"),
+			Bandcamp:            String("GOMUSIC_BANDCAMP_URL", "https://bandcamp.com"),
+			NetEasyBackend:      String("GOMUSIC_NETEASY_BACKEND", NetEasyBackendDirect),
+			NCMApi:              String("GOMUSIC_NCMAPI_URL", "http://127.0.0.1:3000"),
+			SpotifyAccounts:     String("GOMUSIC_SPOTIFY_ACCOUNTS_URL", "https://accounts.spotify.com"),
+			SpotifyApi:          String("GOMUSIC_SPOTIFY_API_URL", "https://api.spotify.com/v1"),
+			AppleMusic:          String("GOMUSIC_APPLE_MUSIC_API_URL", "https://api.music.apple.com/v1"),
+			QobuzApi:            String("GOMUSIC_QOBUZ_API_URL", "https://www.qobuz.com/api.json/0.2"),
+			NetEasyMusicU:       String("GOMUSIC_NETEASY_MUSIC_U", ""),
+			FailoverThreshold:   Int("GOMUSIC_FAILOVER_THRESHOLD", 3),
+			FailoverCooldown:    Duration("GOMUSIC_FAILOVER_COOLDOWN", 5*time.Minute),
+		},
+		HealthCheckInterval: Duration("GOMUSIC_HEALTH_CHECK_INTERVAL", 7*24*time.Hour),
+		MPDPathMap:          Strings("GOMUSIC_MPD_PATH_MAP", nil),
+		SMTP: SMTP{
+			Host:     String("GOMUSIC_SMTP_HOST", ""),
+			Port:     Int("GOMUSIC_SMTP_PORT", 587),
+			Username: String("GOMUSIC_SMTP_USERNAME", ""),
+			Password: String("GOMUSIC_SMTP_PASSWORD", ""),
+			From:     String("GOMUSIC_SMTP_FROM", ""),
+		},
+		EventWebhooks: Strings("GOMUSIC_EVENT_WEBHOOKS", nil),
+		EventAudit:    Bool("GOMUSIC_EVENT_AUDIT", false),
+		MQTT: MQTT{
+			Broker:   String("GOMUSIC_MQTT_BROKER", ""),
+			Username: String("GOMUSIC_MQTT_USERNAME", ""),
+			Password: String("GOMUSIC_MQTT_PASSWORD", ""),
+			Topic:    String("GOMUSIC_MQTT_TOPIC", "gomusic/playlist"),
+		},
+		TraceSlow:                Duration("GOMUSIC_TRACE_SLOW", 5*time.Second),
+		RateLimit:                Int("GOMUSIC_RATE_LIMIT", 0),
+		RateBurst:                Int("GOMUSIC_RATE_BURST", 0),
+		GraphQLMaxFields:         Int("GOMUSIC_GRAPHQL_MAX_FIELDS", 200),
+		GraphQLMaxRootFields:     Int("GOMUSIC_GRAPHQL_MAX_ROOT_FIELDS", 5),
+		AppleMusicDeveloperToken: String("GOMUSIC_APPLE_MUSIC_DEVELOPER_TOKEN", ""),
+		QobuzAppId:               String("GOMUSIC_QOBUZ_APP_ID", ""),
+		DefaultLocale:            String("GOMUSIC_DEFAULT_LOCALE", ""),
+		KKBOX: KKBOX{
+			ClientId:     String("GOMUSIC_KKBOX_CLIENT_ID", ""),
+			ClientSecret: String("GOMUSIC_KKBOX_CLIENT_SECRET", ""),
+		},
+		SoundCloudClientId:   String("GOMUSIC_SOUNDCLOUD_CLIENT_ID", ""),
+		FunkwhaleRedirectUrl: String("GOMUSIC_FUNKWHALE_REDIRECT_URL", ""),
+		Spotify: Spotify{
+			ClientId:     String("GOMUSIC_SPOTIFY_CLIENT_ID", ""),
+			ClientSecret: String("GOMUSIC_SPOTIFY_CLIENT_SECRET", ""),
+			RedirectUrl:  String("GOMUSIC_SPOTIFY_REDIRECT_URL", ""),
+		},
+
+		ChunkConcurrencyMin:    Int("GOMUSIC_CHUNK_CONCURRENCY_MIN", 2),
+		ChunkConcurrencyMax:    Int("GOMUSIC_CHUNK_CONCURRENCY_MAX", 16),
+		ChunkLatencyTarget:     Duration("GOMUSIC_CHUNK_LATENCY_TARGET", 3*time.Second),
+		ChunkWorkers:           Int("GOMUSIC_CHUNK_WORKERS", 16),
+		ChunkWorkersByPlatform: Ints("GOMUSIC_CHUNK_WORKERS_BY_PLATFORM"),
+		JobWorkers:             Int("GOMUSIC_JOB_WORKERS", 4),
+		JobQueueSize:           Int("GOMUSIC_JOB_QUEUE_SIZE", 100),
+		JobTimeout:             Duration("GOMUSIC_JOB_TIMEOUT", 30*time.Minute),
+		JobTTL:                 Duration("GOMUSIC_JOB_TTL", time.Hour),
+		JobWaitMax:             MinDuration("GOMUSIC_JOB_WAIT_MAX", time.Minute, time.Second),
+	}
+}
+
+func String(key, defaultValue string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return defaultValue
+}
+
+func Int(key string, defaultValue int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return v
+	}
+	return defaultValue
+}
+
+func Float(key string, defaultValue float64) float64 {
+	if v, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return v
+	}
+	return defaultValue
+}
+
+// Strings 读取以逗号分隔的列表
+func Strings(key string, defaultValue []string) []string {
+	v := os.Getenv(key)
+	if v == "" {
+		return defaultValue
+	}
+	list := make([]string, 0)
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// Durations 读取以逗号分隔的 key=duration 列表，无法解析的项被忽略
+func Durations(key string) map[string]time.Duration {
+	result := make(map[string]time.Duration)
+	for _, item := range Strings(key, nil) {
+		k, v, ok := strings.Cut(item, "=")
+		if !ok {
+			continue
+		}
+		if d, err := time.ParseDuration(strings.TrimSpace(v)); err == nil {
+			result[strings.TrimSpace(k)] = d
+		}
+	}
+	return result
+}
+
+// Ints 读取以逗号分隔的 key=int 列表，无法解析的项被忽略
+func Ints(key string) map[string]int {
+	result := make(map[string]int)
+	for _, item := range Strings(key, nil) {
+		k, v, ok := strings.Cut(item, "=")
+		if !ok {
+			continue
+		}
+		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			result[strings.TrimSpace(k)] = n
+		}
+	}
+	return result
+}
+
+func Bool(key string, defaultValue bool) bool {
+	if v, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return v
+	}
+	return defaultValue
+}
+
+func Duration(key string, defaultValue time.Duration) time.Duration {
+	if v, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return v
+	}
+	return defaultValue
+}
+
+// MinDuration 读取时长，小于 minimum 时使用默认值，用于定时器间隔等不能为 0 或负数的配置
+func MinDuration(key string, defaultValue, minimum time.Duration) time.Duration {
+	if v := Duration(key, defaultValue); v >= minimum {
+		return v
+	}
+	return defaultValue
+}
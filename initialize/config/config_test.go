@@ -0,0 +1,35 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDurations(t *testing.T) {
+	t.Setenv("GOMUSIC_TEST_TTLS", "netease=24h, qqmusic = 12h,kugou,kuwo=x")
+	ttls := Durations("GOMUSIC_TEST_TTLS")
+	assert.Equal(t, map[string]time.Duration{"netease": 24 * time.Hour, "qqmusic": 12 * time.Hour}, ttls)
+
+	c := &Config{CacheTTL: time.Hour, CacheTTLs: ttls}
+	assert.Equal(t, 24*time.Hour, c.SongCacheTTL("netease"))
+	assert.Equal(t, time.Hour, c.SongCacheTTL("kugou"))
+}
+
+func TestInts(t *testing.T) {
+	t.Setenv("GOMUSIC_TEST_WORKERS", "netease=8, qqmusic = 4,kugou,kuwo=x")
+	workers := Ints("GOMUSIC_TEST_WORKERS")
+	assert.Equal(t, map[string]int{"netease": 8, "qqmusic": 4}, workers)
+
+	c := &Config{ChunkWorkers: 16, ChunkWorkersByPlatform: workers}
+	assert.Equal(t, 8, c.PlatformChunkWorkers("netease"))
+	assert.Equal(t, 16, c.PlatformChunkWorkers("kugou"))
+}
+
+func TestMinDuration(t *testing.T) {
+	for v, want := range map[string]time.Duration{"": 30 * time.Second, "1ns": 30 * time.Second, "-1s": 30 * time.Second, "0": 30 * time.Second, "2s": 2 * time.Second} {
+		t.Setenv("GOMUSIC_TEST_TTL", v)
+		assert.Equal(t, want, MinDuration("GOMUSIC_TEST_TTL", 30*time.Second, time.Second), v)
+	}
+}
@@ -0,0 +1,52 @@
+package log
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type fieldsKey struct{}
+
+// WithFields returns a copy of ctx carrying the given fields; loggers obtained
+// via WithContext attach them to every line. A key that is already present is
+// overwritten in place, so nested calls do not repeat fields.
+func WithFields(ctx context.Context, keysAndValues ...interface{}) context.Context {
+	prev, _ := ctx.Value(fieldsKey{}).([]interface{})
+	fields := make([]interface{}, len(prev), len(prev)+len(keysAndValues))
+	copy(fields, prev)
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		replaced := false
+		for j := 0; j+1 < len(fields); j += 2 {
+			if fields[j] == keysAndValues[i] {
+				fields[j+1], replaced = keysAndValues[i+1], true
+				break
+			}
+		}
+		if !replaced {
+			fields = append(fields, keysAndValues[i], keysAndValues[i+1])
+		}
+	}
+	return context.WithValue(ctx, fieldsKey{}, fields)
+}
+
+// Field returns the value of a field set by WithFields, or nil.
+func Field(ctx context.Context, key string) interface{} {
+	fields, _ := ctx.Value(fieldsKey{}).([]interface{})
+	for i := 0; i+1 < len(fields); i += 2 {
+		if fields[i] == key {
+			return fields[i+1]
+		}
+	}
+	return nil
+}
+
+// WithContext returns a logger that attaches the fields carried by ctx, such
+// as the request ID, playlist ID and provider.
+func WithContext(ctx context.Context) *zap.SugaredLogger {
+	fields, _ := ctx.Value(fieldsKey{}).([]interface{})
+	if len(fields) == 0 {
+		return logger
+	}
+	return logger.With(fields...)
+}
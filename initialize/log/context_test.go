@@ -0,0 +1,32 @@
+package log
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestWithContext(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	prev := logger
+	logger = zap.New(core).Sugar()
+	defer func() { logger = prev }()
+
+	ctx := WithFields(context.Background(), "request_id", "r1", "provider", "kuwo")
+	child := WithFields(ctx, "provider", "kugou", "playlist", "kugou:1")
+	WithContext(child).Errorf("fail: %v", 1)
+	WithContext(context.Background()).Info("plain")
+
+	entries := logs.All()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "fail: 1", entries[0].Message)
+	assert.Equal(t, map[string]interface{}{"request_id": "r1", "provider": "kugou", "playlist": "kugou:1"}, entries[0].ContextMap())
+	assert.Empty(t, entries[1].Context)
+	// 子 ctx 的字段不影响父 ctx
+	assert.Equal(t, "kuwo", Field(ctx, "provider"))
+	assert.Nil(t, Field(ctx, "playlist"))
+}
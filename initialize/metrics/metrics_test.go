@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	counter := NewCounterVec("test_requests_total", "Requests.", "provider", "result")
+	counter.Inc("netease", "ok")
+	counter.Inc("netease", "ok")
+	counter.Inc("kuwo", "error")
+	histogram := NewHistogramVec("test_latency_seconds", "Latency.", []float64{0.1, 1}, "host")
+	histogram.Observe(0.05, "a.com")
+	histogram.Observe(0.5, "a.com")
+	NewGaugeFunc("test_up", "Up.", func() float64 { return 1 })
+	assert.Panics(t, func() { NewGaugeFunc("test_up", "Up.", nil) })
+	assert.Equal(t, float64(2), counter.Value("netease", "ok"))
+
+	var b strings.Builder
+	Write(&b)
+	assert.Equal(t, `# HELP test_latency_seconds Latency.
+# TYPE test_latency_seconds histogram
+test_latency_seconds_bucket{host="a.com",le="0.1"} 1
+test_latency_seconds_bucket{host="a.com",le="1"} 2
+test_latency_seconds_bucket{host="a.com",le="+Inf"} 2
+test_latency_seconds_sum{host="a.com"} 0.55
+test_latency_seconds_count{host="a.com"} 2
+# HELP test_requests_total Requests.
+# TYPE test_requests_total counter
+test_requests_total{provider="kuwo",result="error"} 1
+test_requests_total{provider="netease",result="ok"} 2
+# HELP test_up Up.
+# TYPE test_up gauge
+test_up 1
+`, b.String())
+}
@@ -9,11 +9,67 @@ import (
 
 func NewRouter() *gin.Engine {
 	router := gin.Default()
-	// 允许所有跨域请求
-	router.Use(cors.Default())
+	// 允许所有跨域请求（含浏览器扩展），允许携带网易云 cookie、Apple Music 用户令牌、扩展令牌、traceparent 与请求 id 请求头，并向前端暴露配额、链路 id 与请求 id 响应头
+	corsConfig := cors.DefaultConfig()
+	corsConfig.AllowAllOrigins = true
+	corsConfig.AddAllowHeaders("X-NetEase-Cookie", "Music-User-Token", "X-Extension-Token", "traceparent", "X-Request-Id")
+	corsConfig.ExposeHeaders = []string{"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "Retry-After", "ETag", "X-Trace-Id", "X-Request-Id"}
+	router.Use(cors.New(corsConfig))
+	// 分配请求 id，附加到该请求的所有日志中
+	router.Use(handler.RequestId())
+	// 请求链路追踪，慢请求输出至日志
+	router.Use(handler.Trace())
+	// 按客户端 IP 限流
+	router.Use(handler.RateLimit())
 	// 加载静态资源
 	router.StaticFile("/", "./static")
+	router.GET("/healthz", handler.HealthHandler)
+	router.GET("/metrics", handler.MetricsHandler)
+	router.GET("/schema/v1/songlist.json", handler.SongListSchemaHandler)
 	// 绑定路由
 	router.POST("/songlist", handler.MusicHandler)
+	router.POST("/songlists", handler.AggregateHandler)
+	router.GET("/songlist/stream", handler.StreamHandler)
+	router.POST("/api/jobs", handler.SubmitJobHandler)
+	router.GET("/api/jobs/:id", handler.JobHandler)
+	router.GET("/api/jobs/:id/wait", handler.WaitJobHandler)
+	router.POST("/api/targets/playlists", handler.TargetPlaylistsHandler)
+	router.POST("/export", handler.ExportHandler)
+	router.GET("/export", handler.ExportHandler)
+	router.GET("/cover", handler.CoverHandler)
+	router.GET("/artists", handler.ArtistCatalogHandler)
+	router.GET("/v1/:platform/songs/links", handler.SongLinksHandler)
+	router.GET("/v1/:platform/songs/:id/link", handler.SongLinkHandler)
+	router.POST("/v1/credentials/:platform", handler.VerifyCredentialHandler)
+	router.POST("/watches", handler.WatchHandler)
+	router.POST("/watches/:token/check", handler.CheckWatchHandler)
+	router.DELETE("/watches/:token", handler.UnwatchHandler)
+	router.POST("/watches/:token/artists", handler.FollowArtistsHandler)
+	router.DELETE("/watches/:token/artists", handler.UnfollowArtistsHandler)
+	router.GET("/spotify/authorize", handler.SpotifyAuthorizeHandler)
+	router.GET("/spotify/callback", handler.SpotifyCallbackHandler)
+	router.POST("/applemusic/export", handler.AppleMusicExportHandler)
+	router.POST("/subsonic/export", handler.SubsonicExportHandler)
+	router.POST("/plex/export", handler.PlexExportHandler)
+	router.POST("/jellyfin/export", handler.JellyfinExportHandler)
+	router.POST("/qobuz/export", handler.QobuzExportHandler)
+	router.GET("/funkwhale/authorize", handler.FunkwhaleAuthorizeHandler)
+	router.GET("/funkwhale/callback", handler.FunkwhaleCallbackHandler)
+	router.POST("/p", handler.ShortLinkHandler)
+	router.GET("/p/:code", handler.ResolveShortLinkHandler)
+	router.GET("/shortcuts", handler.ShortcutsHandler)
+	router.POST("/homeassistant", handler.HomeAssistantHandler)
+	router.GET("/graphql", handler.GraphQLHandler)
+	router.POST("/graphql", handler.GraphQLHandler)
+
+	ext := router.Group("/ext", handler.ExtensionAuth)
+	ext.GET("/match", handler.ExtensionMatchHandler)
+	ext.GET("/convert", handler.ExtensionConvertHandler)
+
+	admin := router.Group("/admin", handler.AdminAuth)
+	admin.POST("/purge", handler.PurgeHandler)
+	admin.POST("/cache/invalidate", handler.InvalidateCacheHandler)
+	admin.GET("/stats", handler.StatsHandler)
+	admin.GET("/traces", handler.TracesHandler)
 	return router
 }
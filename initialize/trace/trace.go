@@ -0,0 +1,240 @@
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"GoMusic/initialize/config"
+	"GoMusic/initialize/log"
+)
+
+// 轻量的请求链路追踪：span 随 ctx 传递，经 httputil 以 W3C traceparent 请求头传给上游；
+// 耗时超过 GOMUSIC_TRACE_SLOW 的请求输出完整链路至日志，并保留最近的若干条供 /admin/traces 查看
+
+const (
+	// maxSpans 单条链路最多记录的 span 数，超大歌单的分片请求不会无限增长
+	maxSpans = 1000
+	// maxRecent 保留的慢请求链路数
+	maxRecent = 20
+)
+
+var traceparentRegx = regexp.MustCompile(`^00-([0-9a-f]{32})-([0-9a-f]{16})-[0-9a-f]{2}$`)
+
+// Span 链路中的一次操作
+type Span struct {
+	Name       string            `json:"name"`
+	SpanId     string            `json:"span_id"`
+	ParentId   string            `json:"parent_id,omitempty"`
+	Start      time.Time         `json:"start"`
+	DurationMs float64           `json:"duration_ms"`
+	Attrs      map[string]string `json:"attrs,omitempty"`
+	Error      string            `json:"error,omitempty"`
+
+	trace *Trace
+	root  bool
+	ended bool
+}
+
+// Trace 一次请求的完整链路
+type Trace struct {
+	TraceId string  `json:"trace_id"`
+	Spans   []*Span `json:"spans"`
+	Dropped int     `json:"dropped,omitempty"` // 超出 maxSpans 未记录的 span 数
+
+	mu sync.Mutex
+}
+
+type spanKey struct{}
+
+// remote 上游传入的链路信息
+type remote struct {
+	traceId string
+	spanId  string
+}
+
+type remoteKey struct{}
+
+var (
+	recentMu sync.Mutex
+	recent   []*Trace
+)
+
+// Start 开始一个 span，ctx 中没有 span 时开始新的链路；attrs 为交替的 key、value
+func Start(ctx context.Context, name string, attrs ...string) (context.Context, *Span) {
+	span := &Span{Name: name, SpanId: newId(8), Start: time.Now()}
+	if len(attrs) > 1 {
+		span.Attrs = make(map[string]string, len(attrs)/2)
+		for i := 0; i+1 < len(attrs); i += 2 {
+			span.Attrs[attrs[i]] = attrs[i+1]
+		}
+	}
+	if parent := FromContext(ctx); parent != nil {
+		span.ParentId, span.trace = parent.SpanId, parent.trace
+		t := span.trace
+		t.mu.Lock()
+		if len(t.Spans) < maxSpans {
+			t.Spans = append(t.Spans, span)
+		} else {
+			t.Dropped++
+		}
+		t.mu.Unlock()
+	} else {
+		span.root = true
+		span.trace = &Trace{TraceId: newId(16)}
+		if r, ok := ctx.Value(remoteKey{}).(*remote); ok {
+			span.trace.TraceId, span.ParentId = r.traceId, r.spanId
+		}
+		span.trace.Spans = []*Span{span}
+	}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SetAttr 添加属性
+func (s *Span) SetAttr(key, value string) {
+	s.trace.mu.Lock()
+	defer s.trace.mu.Unlock()
+	if s.Attrs == nil {
+		s.Attrs = make(map[string]string)
+	}
+	s.Attrs[key] = value
+}
+
+// End 结束 span，err 不为 nil 时记录错误；链路的首个 span 结束时按耗时决定是否记录整条链路
+func (s *Span) End(err error) {
+	s.trace.mu.Lock()
+	if s.ended {
+		s.trace.mu.Unlock()
+		return
+	}
+	s.ended = true
+	duration := time.Since(s.Start)
+	s.DurationMs = float64(duration.Microseconds()) / 1000
+	if err != nil {
+		s.Error = err.Error()
+	}
+	s.trace.mu.Unlock()
+	if s.root && config.Conf.TraceSlow > 0 && duration >= config.Conf.TraceSlow {
+		record(s.trace)
+	}
+}
+
+// TraceId 链路 id
+func (s *Span) TraceId() string {
+	return s.trace.TraceId
+}
+
+// FromContext 返回 ctx 中当前的 span，没有时返回 nil
+func FromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// Traceparent 当前 span 的 W3C traceparent 请求头，ctx 中没有 span 时返回空字符串
+func Traceparent(ctx context.Context) string {
+	span := FromContext(ctx)
+	if span == nil {
+		return ""
+	}
+	return fmt.Sprintf("00-%v-%v-01", span.trace.TraceId, span.SpanId)
+}
+
+// WithTraceparent 解析调用方传入的 traceparent，之后开始的链路沿用其 trace id；格式无效时忽略
+func WithTraceparent(ctx context.Context, header string) context.Context {
+	m := traceparentRegx.FindStringSubmatch(strings.TrimSpace(header))
+	if m == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteKey{}, &remote{traceId: m[1], spanId: m[2]})
+}
+
+// Recent 最近的慢请求链路，最新的在前
+func Recent() []*Trace {
+	recentMu.Lock()
+	defer recentMu.Unlock()
+	traces := make([]*Trace, 0, len(recent))
+	for i := len(recent) - 1; i >= 0; i-- {
+		traces = append(traces, recent[i])
+	}
+	return traces
+}
+
+func record(t *Trace) {
+	t.mu.Lock()
+	snapshot := &Trace{TraceId: t.TraceId, Dropped: t.Dropped, Spans: make([]*Span, 0, len(t.Spans))}
+	for _, v := range t.Spans {
+		span := *v
+		span.Attrs = make(map[string]string, len(v.Attrs))
+		for k, v := range v.Attrs {
+			span.Attrs[k] = v
+		}
+		snapshot.Spans = append(snapshot.Spans, &span)
+	}
+	t.mu.Unlock()
+
+	log.Warnf("slow trace %v:\n%v", snapshot.TraceId, snapshot.String())
+	recentMu.Lock()
+	defer recentMu.Unlock()
+	recent = append(recent, snapshot)
+	if len(recent) > maxRecent {
+		recent = recent[len(recent)-maxRecent:]
+	}
+}
+
+// String 按调用层级逐行输出 span 及其耗时
+func (t *Trace) String() string {
+	children := make(map[string][]*Span)
+	ids := make(map[string]bool, len(t.Spans))
+	for _, v := range t.Spans {
+		ids[v.SpanId] = true
+	}
+	roots := make([]*Span, 0, 1)
+	for _, v := range t.Spans {
+		if ids[v.ParentId] {
+			children[v.ParentId] = append(children[v.ParentId], v)
+		} else {
+			roots = append(roots, v)
+		}
+	}
+	b := &strings.Builder{}
+	var write func(spans []*Span, depth int)
+	write = func(spans []*Span, depth int) {
+		sort.SliceStable(spans, func(i, j int) bool { return spans[i].Start.Before(spans[j].Start) })
+		for _, v := range spans {
+			fmt.Fprintf(b, "%v%v %.1fms", strings.Repeat("  ", depth), v.Name, v.DurationMs)
+			keys := make([]string, 0, len(v.Attrs))
+			for k := range v.Attrs {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				fmt.Fprintf(b, " %v=%v", k, v.Attrs[k])
+			}
+			if !v.ended {
+				b.WriteString(" (unfinished)")
+			}
+			if v.Error != "" {
+				fmt.Fprintf(b, " error=%q", v.Error)
+			}
+			b.WriteString("\n")
+			write(children[v.SpanId], depth+1)
+		}
+	}
+	write(roots, 0)
+	if t.Dropped > 0 {
+		fmt.Fprintf(b, "... %d spans dropped\n", t.Dropped)
+	}
+	return b.String()
+}
+
+func newId(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
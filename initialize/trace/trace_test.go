@@ -0,0 +1,48 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/initialize/config"
+)
+
+func TestTrace(t *testing.T) {
+	slow := config.Conf.TraceSlow
+	config.Conf.TraceSlow = time.Nanosecond
+	defer func() { config.Conf.TraceSlow = slow }()
+
+	ctx := WithTraceparent(context.Background(), "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	ctx, root := Start(ctx, "GET /songlist")
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", root.TraceId())
+	assert.Equal(t, "b7ad6b7169203331", root.ParentId)
+
+	childCtx, child := Start(ctx, "provider.discover", "provider", "netease")
+	assert.Equal(t, "00-0af7651916cd43dd8448eb211c80319c-"+child.SpanId+"-01", Traceparent(childCtx))
+	_, chunk := Start(childCtx, "chunk", "platform", "netease")
+	chunk.End(errors.New("timeout"))
+	child.End(nil)
+	root.End(nil)
+
+	traces := Recent()
+	assert.NotEmpty(t, traces)
+	assert.Equal(t, root.TraceId(), traces[0].TraceId)
+	lines := strings.Split(strings.TrimSpace(traces[0].String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[0], "GET /songlist "))
+	assert.True(t, strings.HasPrefix(lines[1], "  provider.discover "))
+	assert.Contains(t, lines[1], "provider=netease")
+	assert.True(t, strings.HasPrefix(lines[2], "    chunk "))
+	assert.Contains(t, lines[2], `error="timeout"`)
+
+	// 无效的 traceparent 被忽略，开始新的链路
+	_, other := Start(WithTraceparent(context.Background(), "invalid"), "GET /export")
+	assert.NotEqual(t, root.TraceId(), other.TraceId())
+	assert.Equal(t, "", other.ParentId)
+	assert.Equal(t, "", Traceparent(context.Background()))
+}
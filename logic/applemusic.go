@@ -0,0 +1,128 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"GoMusic/common/models"
+	"GoMusic/initialize/config"
+)
+
+const (
+	platformAppleMusic = "applemusic"
+	// appleMusicAddLimit 每次添加到歌单的曲目数
+	appleMusicAddLimit = 100
+)
+
+var errAppleMusicToken = errors.New("请提供 Apple Music 的 Music User Token")
+
+// AppleMusicExport 在用户的 Apple Music 资料库中创建歌单；developerToken 为空时使用配置的开发者令牌，
+// userToken 为通过 MusicKit JS 授权获得的 Music User Token
+func AppleMusicExport(ctx context.Context, link, developerToken, userToken string) (*models.ExportReport, error) {
+	if developerToken == "" {
+		developerToken = config.Conf.AppleMusicDeveloperToken
+	}
+	if developerToken == "" || userToken == "" {
+		return nil, errAppleMusicToken
+	}
+	provider := MatchProvider(link)
+	if provider == nil {
+		return nil, errors.New("不支持的歌单链接")
+	}
+	exporter := &appleMusicExporter{developerToken: developerToken, userToken: userToken}
+	// 先确认令牌有效，避免获取歌单后才发现无法创建
+	if err := exporter.loadStorefront(ctx); err != nil {
+		return nil, err
+	}
+	songList, err := provider.Discover(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	return Export(ctx, platformAppleMusic, exporter, songList)
+}
+
+// appleMusicExporter 使用开发者令牌与用户令牌访问 Apple Music API
+type appleMusicExporter struct {
+	developerToken string
+	userToken      string
+	// storefront 在该地区的曲库中搜索，未指定 market 时为用户所在地区
+	storefront string
+	// language 搜索结果的语言，取地区支持的语言中与请求偏好最接近的一个，为空时使用地区默认语言
+	language string
+}
+
+func (e *appleMusicExporter) loadStorefront(ctx context.Context) error {
+	storefront := &models.AppleMusicStorefront{}
+	if err := e.request(ctx, "GET", "/me/storefront", nil, storefront); err != nil {
+		return err
+	}
+	if len(storefront.Data) == 0 {
+		return errors.New("无法获取 Apple Music 账号所在地区")
+	}
+	// 指定地区时改用该地区的曲库与语言
+	if market := transferOptions(ctx).Market; market != "" {
+		storefront = &models.AppleMusicStorefront{}
+		if err := e.request(ctx, "GET", "/storefronts/"+strings.ToLower(market), nil, storefront); err != nil {
+			return err
+		}
+		if len(storefront.Data) == 0 {
+			return fmt.Errorf("Apple Music 不支持地区 %v", market)
+		}
+	}
+	e.storefront = storefront.Data[0].Id
+	e.language = matchLocale(requestLocale(ctx), storefront.Data[0].Attributes.SupportedLanguageTags)
+	return nil
+}
+
+func (e *appleMusicExporter) Search(ctx context.Context, title, artist string) ([]*models.ExportCandidate, error) {
+	term := strings.TrimSpace(title + " " + strings.ReplaceAll(artist, " / ", " "))
+	result := &models.AppleMusicSearch{}
+	path := "/catalog/" + url.PathEscape(e.storefront) + "/search?types=songs&limit=5&term=" + url.QueryEscape(term)
+	if e.language != "" {
+		path += "&l=" + url.QueryEscape(e.language)
+	}
+	if err := e.request(ctx, "GET", path, nil, result); err != nil {
+		return nil, err
+	}
+	candidates := make([]*models.ExportCandidate, 0, len(result.Results.Songs.Data))
+	for _, v := range result.Results.Songs.Data {
+		candidates = append(candidates, &models.ExportCandidate{Id: v.Id, Title: v.Attributes.Name, Artist: v.Attributes.ArtistName})
+	}
+	return candidates, nil
+}
+
+// CreatePlaylist 资料库歌单没有公开链接，返回 music.apple.com 中的资料库地址；歌单仅用户可见，不支持设置可见性
+func (e *appleMusicExporter) CreatePlaylist(ctx context.Context, name, description string, ids []string) (string, string, error) {
+	playlist := &models.AppleMusicPlaylist{}
+	body := map[string]any{"attributes": map[string]string{"name": name, "description": description}}
+	if err := e.request(ctx, "POST", "/me/library/playlists", body, playlist); err != nil {
+		return "", "", err
+	}
+	if len(playlist.Data) == 0 {
+		return "", "", errors.New("创建 Apple Music 歌单失败")
+	}
+	id := playlist.Data[0].Id
+	for i := 0; i < len(ids); i += appleMusicAddLimit {
+		end := i + appleMusicAddLimit
+		if end > len(ids) {
+			end = len(ids)
+		}
+		tracks := make([]*models.AppleMusicTrack, 0, end-i)
+		for _, v := range ids[i:end] {
+			tracks = append(tracks, &models.AppleMusicTrack{Id: v, Type: "songs"})
+		}
+		if err := e.request(ctx, "POST", "/me/library/playlists/"+url.PathEscape(id)+"/tracks", map[string]any{"data": tracks}, nil); err != nil {
+			return "", "", err
+		}
+	}
+	return id, "https://music.apple.com/library/playlist/" + id, nil
+}
+
+func (e *appleMusicExporter) request(ctx context.Context, method, path string, body, v any) error {
+	header := http.Header{"Authorization": {"Bearer " + e.developerToken}, "Music-User-Token": {e.userToken}}
+	return exportRequest(ctx, "Apple Music", method, config.Conf.Upstream.AppleMusic+path, header, body, v)
+}
@@ -0,0 +1,147 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"GoMusic/common/event"
+	"GoMusic/common/models"
+	"GoMusic/common/utils"
+	"GoMusic/initialize/log"
+	"GoMusic/repo/db"
+)
+
+// maxFollowedArtists 每个订阅最多关注的歌手数，每次检查需为每位歌手请求一次网易云
+const maxFollowedArtists = 100
+
+// FollowArtists 关注订阅歌单中至少有 minSongs 首歌曲的歌手，之后发行的新专辑会随定期检查通知；
+// 重复调用会以新的歌手列表替换，并重新以当前时间为基线
+func FollowArtists(ctx context.Context, token string, minSongs int) ([]*models.FollowedArtist, error) {
+	watch, err := db.GetWatch(token)
+	if err != nil {
+		return nil, err
+	}
+	if watch == nil {
+		return nil, ErrWatchNotFound
+	}
+	if minSongs < 1 {
+		minSongs = 1
+	}
+
+	songListId, err := utils.GetNetEasyParam(ctx, watch.Link)
+	if err != nil {
+		return nil, err
+	}
+	SongIdsResp, err := getSongsInfo(ctx, songListId)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(SongIdsResp.Playlist.TrackIds))
+	for _, v := range SongIdsResp.Playlist.TrackIds {
+		ids = append(ids, v.Id)
+	}
+
+	counts := make(map[int64]int)
+	names := make(map[int64]string)
+	err = eachSongDetail(ctx, ids, func(_ []*models.SongId, songs *models.Songs) {
+		for _, song := range songs.Songs {
+			for _, v := range song.Ar {
+				if v.Id == 0 { // 未入驻的歌手没有 id
+					continue
+				}
+				counts[v.Id]++
+				names[v.Id] = v.Name
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	artistIds := make([]int64, 0, len(counts))
+	for id, count := range counts {
+		if count >= minSongs {
+			artistIds = append(artistIds, id)
+		}
+	}
+	if len(artistIds) == 0 {
+		return nil, fmt.Errorf("歌单中没有歌曲数不少于 %d 首的歌手", minSongs)
+	}
+	sort.Slice(artistIds, func(i, j int) bool {
+		if counts[artistIds[i]] != counts[artistIds[j]] {
+			return counts[artistIds[i]] > counts[artistIds[j]]
+		}
+		return artistIds[i] < artistIds[j]
+	})
+	if len(artistIds) > maxFollowedArtists {
+		artistIds = artistIds[:maxFollowedArtists]
+	}
+
+	now := time.Now().UnixMilli()
+	artists := make([]*models.FollowedArtist, 0, len(artistIds))
+	for _, id := range artistIds {
+		artists = append(artists, &models.FollowedArtist{WatchId: watch.ID, ArtistId: id, Name: names[id], LatestPublishTime: now})
+	}
+	return artists, db.ReplaceFollowedArtists(watch.ID, artists)
+}
+
+// UnfollowArtists 取消关注订阅中的所有歌手
+func UnfollowArtists(token string) error {
+	watch, err := db.GetWatch(token)
+	if err != nil {
+		return err
+	}
+	if watch == nil {
+		return ErrWatchNotFound
+	}
+	return db.DeleteFollowedArtists(watch.ID)
+}
+
+// checkArtists 检查关注歌手的新专辑，有新专辑时发送通知
+func checkArtists(ctx context.Context, watch *models.WatchedPlaylist) (*models.ReleaseReport, error) {
+	artists, err := db.ListFollowedArtists(watch.ID)
+	if err != nil {
+		return nil, err
+	}
+	report := &models.ReleaseReport{Type: models.ReportNewRelease, Link: watch.Link, Releases: make([]*models.NewRelease, 0)}
+	var errs []error
+	for _, artist := range artists {
+		albums, err := netEasyApi().artistAlbums(ctx, artist.ArtistId)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		latest := artist.LatestPublishTime
+		for _, v := range albums.HotAlbums {
+			if v.PublishTime <= artist.LatestPublishTime {
+				continue
+			}
+			report.Releases = append(report.Releases, &models.NewRelease{
+				Artist:      artist.Name,
+				Album:       v.Name,
+				Link:        fmt.Sprintf("https://music.163.com/#/album?id=%v", v.Id),
+				PublishTime: time.UnixMilli(v.PublishTime),
+			})
+			if v.PublishTime > latest {
+				latest = v.PublishTime
+			}
+		}
+		if latest != artist.LatestPublishTime {
+			artist.LatestPublishTime = latest
+			if err := db.UpdateFollowedArtist(artist); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(report.Releases) > 0 {
+		notify(watch, releaseReportNotification(report))
+		event.Publish(ctx, event.SyncDiffDetected, report)
+	}
+	if len(errs) > 0 {
+		log.WithContext(ctx).Errorf("fail to check %d artists of %v", len(errs), watch.Link)
+	}
+	return report, errors.Join(errs...)
+}
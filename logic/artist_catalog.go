@@ -0,0 +1,181 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"GoMusic/common/models"
+	"GoMusic/common/utils"
+)
+
+const (
+	// catalogTopSongs 每个平台返回的热门歌曲数
+	catalogTopSongs = 50
+)
+
+var (
+	netEasyArtistRegx = regexp.MustCompile(`artist\?id=(\d+)`)
+	qqMusicSingerRegx = regexp.MustCompile(`singer/(\w+)`)
+
+	errArtistNotFound = errors.New("未找到该歌手")
+)
+
+// ArtistCatalog 汇总歌手在各平台的热门歌曲，可传入歌手名或网易云、QQ 音乐的歌手链接；
+// 传入链接时以源平台上的歌手名在其他平台搜索，置信度为歌手名的相似度
+func ArtistCatalog(ctx context.Context, name, link string) (*models.ArtistCatalog, error) {
+	sources := make(map[string]*models.ArtistSource, 2)
+	if link != "" {
+		source, err := artistSourceFromLink(ctx, link)
+		if err != nil {
+			return nil, err
+		}
+		if source.Error != "" {
+			return nil, errors.New(source.Error)
+		}
+		sources[source.Platform] = source
+		name = source.Name
+	}
+	if strings.TrimSpace(name) == "" {
+		return nil, errors.New("请填写歌手名或歌手链接")
+	}
+
+	searchers := map[string]func(context.Context, string) *models.ArtistSource{
+		platformNetEasy: netEasyArtistSearch,
+		platformQQMusic: qqMusicArtistSearch,
+	}
+	mu := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	for platform, search := range searchers {
+		if _, ok := sources[platform]; ok {
+			continue
+		}
+		search := search
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			source := search(ctx, name)
+			mu.Lock()
+			sources[source.Platform] = source
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	catalog := &models.ArtistCatalog{Query: name, Sources: make([]*models.ArtistSource, 0, len(sources))}
+	for _, v := range sources {
+		catalog.Sources = append(catalog.Sources, v)
+	}
+	// 歌曲总数多的平台排在前面
+	sort.Slice(catalog.Sources, func(i, j int) bool {
+		if catalog.Sources[i].SongsCount != catalog.Sources[j].SongsCount {
+			return catalog.Sources[i].SongsCount > catalog.Sources[j].SongsCount
+		}
+		return catalog.Sources[i].Platform < catalog.Sources[j].Platform
+	})
+	return catalog, nil
+}
+
+func artistSourceFromLink(ctx context.Context, link string) (*models.ArtistSource, error) {
+	if m := netEasyArtistRegx.FindStringSubmatch(link); m != nil && strings.Contains(link, "163") {
+		id, _ := strconv.ParseInt(m[1], 10, 64)
+		return netEasyArtistSongs(ctx, id, 1), nil
+	}
+	if m := qqMusicSingerRegx.FindStringSubmatch(link); m != nil && strings.Contains(link, "qq.com") {
+		return qqMusicArtistSongs(ctx, m[1], "", 1), nil
+	}
+	return nil, errors.New("不支持的歌手链接")
+}
+
+func netEasyArtistSearch(ctx context.Context, name string) *models.ArtistSource {
+	result, err := netEasyApi().searchArtist(ctx, name)
+	if err != nil {
+		return &models.ArtistSource{Platform: platformNetEasy, Error: err.Error()}
+	}
+	best, confidence := int64(0), 0.0
+	for _, v := range result.Result.Artists {
+		if similarity := utils.NameSimilarity(name, v.Name); similarity > confidence {
+			best, confidence = v.Id, similarity
+		}
+	}
+	if best == 0 {
+		return &models.ArtistSource{Platform: platformNetEasy, Error: errArtistNotFound.Error()}
+	}
+	return netEasyArtistSongs(ctx, best, confidence)
+}
+
+func netEasyArtistSongs(ctx context.Context, artistId int64, confidence float64) *models.ArtistSource {
+	source := &models.ArtistSource{Platform: platformNetEasy, ArtistId: strconv.FormatInt(artistId, 10), Confidence: confidence}
+	result, err := netEasyApi().artistTopSongs(ctx, artistId)
+	if err != nil {
+		source.Error = err.Error()
+		return source
+	}
+	source.Name = result.Artist.Name
+	source.SongsCount = result.Artist.MusicSize
+	source.Songs = make([]string, 0, len(result.HotSongs))
+	for _, v := range result.HotSongs {
+		if len(source.Songs) == catalogTopSongs {
+			break
+		}
+		authors := make([]string, 0, len(v.Ar))
+		for _, v := range v.Ar {
+			authors = append(authors, v.Name)
+		}
+		name, authors := utils.CollapseArtists(v.Name, authors)
+		source.Songs = append(source.Songs, utils.StandardSongName(name)+" - "+strings.Join(authors, " / "))
+	}
+	return source
+}
+
+func qqMusicArtistSearch(ctx context.Context, name string) *models.ArtistSource {
+	paramString := models.GetQQMusicModuleReqString("music.search.SearchCgiService", "DoSearchForQQMusicDesktop",
+		map[string]any{"query": name, "search_type": 1, "num_per_page": 5, "page_num": 1})
+	result := &models.QQMusicSingerSearchResp{}
+	if err := qqMusicRequest(ctx, paramString, result); err != nil {
+		return &models.ArtistSource{Platform: platformQQMusic, Error: err.Error()}
+	}
+	best, bestName, confidence := "", "", 0.0
+	for _, v := range result.Req0.Data.Body.Singer.List {
+		if similarity := utils.NameSimilarity(name, v.SingerName); similarity > confidence {
+			best, bestName, confidence = v.SingerMID, v.SingerName, similarity
+		}
+	}
+	if best == "" {
+		return &models.ArtistSource{Platform: platformQQMusic, Error: errArtistNotFound.Error()}
+	}
+	return qqMusicArtistSongs(ctx, best, bestName, confidence)
+}
+
+// qqMusicArtistSongs 获取 QQ 音乐歌手的热门歌曲，name 为空时从歌曲的歌手信息中获取
+func qqMusicArtistSongs(ctx context.Context, mid, name string, confidence float64) *models.ArtistSource {
+	source := &models.ArtistSource{Platform: platformQQMusic, ArtistId: mid, Name: name, Confidence: confidence}
+	paramString := models.GetQQMusicModuleReqString("musichall.song_list_server", "GetSingerSongList",
+		map[string]any{"singerMid": mid, "begin": 0, "num": catalogTopSongs, "order": 1})
+	result := &models.QQMusicSingerSongsResp{}
+	if err := qqMusicRequest(ctx, paramString, result); err != nil {
+		source.Error = err.Error()
+		return source
+	}
+	source.SongsCount = result.Req0.Data.TotalNum
+	source.Songs = make([]string, 0, len(result.Req0.Data.SongList))
+	for _, v := range result.Req0.Data.SongList {
+		authors := make([]string, 0, len(v.SongInfo.Singer))
+		for _, singer := range v.SongInfo.Singer {
+			authors = append(authors, singer.Name)
+			if source.Name == "" && singer.Mid == mid {
+				source.Name = singer.Name
+			}
+		}
+		name, authors := utils.CollapseArtists(v.SongInfo.Name, authors)
+		source.Songs = append(source.Songs, utils.StandardSongName(name)+" - "+strings.Join(authors, " / "))
+	}
+	if source.Name == "" && source.Error == "" {
+		source.Error = errArtistNotFound.Error()
+	}
+	return source
+}
@@ -0,0 +1,87 @@
+package logic
+
+import (
+	"context"
+	"strings"
+
+	"GoMusic/common/models"
+	"GoMusic/common/utils"
+	"GoMusic/repo/db"
+)
+
+// NetEasyAvailability 检查网易云歌单中的下架歌曲，不使用缓存以获取最新的播放权限
+func NetEasyAvailability(ctx context.Context, link string) (*models.Availability, error) {
+	songListId, err := utils.GetNetEasyParam(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	SongIdsResp, err := getSongsInfo(ctx, songListId)
+	if err != nil {
+		return nil, err
+	}
+	trackIds := SongIdsResp.Playlist.TrackIds
+
+	unavailable := make([]*models.UnavailableSong, 0)
+	missing := make([]uint, 0) // 网易云已不返回的歌曲
+	ids := make([]uint, 0, len(trackIds))
+	for _, v := range trackIds {
+		ids = append(ids, v.Id)
+	}
+	err = eachSongDetail(ctx, ids, func(chunk []*models.SongId, songs *models.Songs) {
+		names := make(map[uint]string, len(songs.Songs))
+		for _, v := range songs.Songs {
+			authors := make([]string, 0, len(v.Ar))
+			for _, v := range v.Ar {
+				authors = append(authors, v.Name)
+			}
+			name, authors := utils.CollapseArtists(v.Name, authors)
+			names[v.Id] = utils.StandardSongName(name) + " - " + strings.Join(authors, " / ")
+		}
+		for _, v := range songs.Privileges {
+			if v.St < 0 {
+				unavailable = append(unavailable, &models.UnavailableSong{Id: v.Id, Song: names[v.Id]})
+			}
+		}
+		for _, v := range chunk {
+			if _, ok := names[v.Id]; !ok {
+				missing = append(missing, v.Id)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 已不返回的歌曲尝试从数据库中找回歌名
+	if len(missing) > 0 {
+		dbNames, _ := db.BatchGetSongById(missing)
+		for _, v := range missing {
+			unavailable = append(unavailable, &models.UnavailableSong{Id: v, Song: dbNames[v]})
+		}
+	}
+	return &models.Availability{
+		Name:        SongIdsResp.Playlist.Name,
+		Total:       len(trackIds),
+		Unavailable: unavailable,
+	}, nil
+}
+
+// eachSongDetail 按 chunkSize 分批顺序查询歌曲详情，不经过缓存
+func eachSongDetail(ctx context.Context, ids []uint, fn func(chunk []*models.SongId, songs *models.Songs)) error {
+	for i := 0; i < len(ids); i += chunkSize {
+		end := i + chunkSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		chunk := make([]*models.SongId, 0, end-i)
+		for _, v := range ids[i:end] {
+			chunk = append(chunk, &models.SongId{Id: v})
+		}
+		songs, err := netEasyApi().songDetail(ctx, chunk)
+		if err != nil {
+			return err
+		}
+		fn(chunk, songs)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package logic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"GoMusic/common/format"
+	"GoMusic/common/models"
+	"GoMusic/httputil"
+	"GoMusic/initialize/config"
+	"GoMusic/initialize/log"
+)
+
+const (
+	bandcampList = "bandcamp:%v"
+
+	platformBandcamp = "bandcamp"
+	// bandcampPageSize 每次请求获取的收藏数
+	bandcampPageSize = 100
+	// bandcampMaxPages 最多获取的页数
+	bandcampMaxPages = 100
+)
+
+var (
+	bandcampUsernameRegx = regexp.MustCompile(`^[\w-]+$`)
+	bandcampPageDataRegx = regexp.MustCompile(`id="pagedata"[^>]*data-blob="([^"]*)"`)
+
+	errUnsupportedBandcampLink = errors.New("暂不支持该 Bandcamp 链接，请使用 bandcamp.com/{用户名} 粉丝主页链接")
+)
+
+func init() {
+	RegisterProvider(&linkProvider{name: platformBandcamp, hosts: []string{"bandcamp.com"}, discover: BandcampDiscover})
+}
+
+// BandcampDiscover 获取 Bandcamp 粉丝已购买的专辑与单曲，如 https://bandcamp.com/someone，专辑展开为其中的曲目；
+// 仅包含公开可见的收藏
+func BandcampDiscover(ctx context.Context, link string) (*models.SongList, error) {
+	username, err := getBandcampUsername(link)
+	if err != nil {
+		return nil, err
+	}
+	// 同一粉丝的并发请求只向 Bandcamp 转发一次
+	return shared(ctx, fmt.Sprintf(bandcampList, username), func(ctx context.Context) (*models.SongList, error) {
+		return bandcampDiscover(ctx, username)
+	})
+}
+
+// getBandcampUsername 解析粉丝用户名，艺人子域名下的专辑链接不是粉丝收藏
+func getBandcampUsername(link string) (string, error) {
+	parse, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || (parse.Host != "bandcamp.com" && parse.Host != "www.bandcamp.com") {
+		return "", errUnsupportedBandcampLink
+	}
+	username := strings.Trim(parse.Path, "/")
+	if !bandcampUsernameRegx.MatchString(username) {
+		return "", errUnsupportedBandcampLink
+	}
+	return strings.ToLower(username), nil
+}
+
+func bandcampDiscover(ctx context.Context, username string) (*models.SongList, error) {
+	fan, err := getBandcampFan(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	tracks := make([]*models.Song, 0, fan.CollectionCount)
+	durations := make([]int, 0, fan.CollectionCount)
+	totalDuration := 0
+	cover := ""
+	// 游标为“时间戳::类型::”，从当前时间开始向前翻页
+	token := strconv.FormatInt(time.Now().Unix(), 10) + "::a::"
+	for page := 0; page < bandcampMaxPages; page++ {
+		collection, err := getBandcampCollection(ctx, fan.FanData.FanId, token)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range collection.Items {
+			if v.ItemType == "" {
+				continue
+			}
+			if cover == "" {
+				cover = v.ItemArtUrl
+			}
+			album := v.ItemTitle
+			if v.ItemType == "track" {
+				album = v.AlbumTitle
+			}
+			for _, track := range collection.Tracklists[v.ItemType[:1]+strconv.FormatInt(v.TralbumId, 10)] {
+				artist := track.Artist
+				if artist == "" {
+					artist = v.BandName
+				}
+				duration := int(track.Duration * 1000)
+				totalDuration += duration
+				durations = append(durations, duration)
+				tracks = append(tracks, &models.Song{
+					Name: track.Title, Artists: []string{artist}, Album: album, DurationMs: duration, SourceId: strconv.FormatInt(track.Id, 10),
+				})
+			}
+		}
+		if !collection.MoreAvailable || collection.LastToken == "" {
+			break
+		}
+		token = collection.LastToken
+	}
+	songsString := format.Tracks(tracks)
+	name := fan.FanData.Name
+	if name == "" {
+		name = fan.FanData.Username
+	}
+	return &models.SongList{
+		Name:       name + " 的 Bandcamp 收藏",
+		Songs:      songsString,
+		Tracks:     tracks,
+		Durations:  durations,
+		SongsCount: len(tracks),
+		Cover:      cover,
+		Tags:       make([]string, 0),
+		Summary:    format.Summarize(songsString, totalDuration),
+	}, nil
+}
+
+// getBandcampFan 从粉丝主页的 pagedata 中获取 fan_id
+func getBandcampFan(ctx context.Context, username string) (*models.BandcampPageData, error) {
+	resp, err := httputil.Get(ctx, config.Conf.Upstream.Bandcamp+"/"+username)
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to get bandcamp fan page: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.WithContext(ctx).Errorf("fail to get bandcamp fan %v: %v", username, resp.StatusCode)
+		return nil, errors.New("获取 Bandcamp 收藏失败，请检查用户名是否正确")
+	}
+	page, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to read body: %v", err)
+		return nil, err
+	}
+	m := bandcampPageDataRegx.FindSubmatch(page)
+	if m == nil {
+		log.WithContext(ctx).Errorf("fail to find bandcamp pagedata of %v", username)
+		return nil, errors.New("获取 Bandcamp 收藏失败，请检查链接是否为粉丝主页")
+	}
+	data := &models.BandcampPageData{}
+	if err = json.Unmarshal([]byte(html.UnescapeString(string(m[1]))), data); err != nil {
+		log.WithContext(ctx).Errorf("fail to unmarshal bandcamp pagedata: %v", err)
+		return nil, err
+	}
+	if data.FanData.FanId == 0 {
+		return nil, errUnsupportedBandcampLink
+	}
+	return data, nil
+}
+
+func getBandcampCollection(ctx context.Context, fanId int64, token string) (*models.BandcampCollection, error) {
+	body, _ := json.Marshal(map[string]any{"fan_id": fanId, "older_than_token": token, "count": bandcampPageSize})
+	header := http.Header{"Content-Type": {"application/json"}}
+	resp, err := httputil.Do(ctx, "POST", config.Conf.Upstream.Bandcamp+"/api/fancollection/1/collection_items", header, bytes.NewReader(body))
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to get bandcamp collection: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	collection := &models.BandcampCollection{}
+	if err = decodeBody(resp.Body, collection); err != nil {
+		return nil, err
+	}
+	if collection.Error {
+		log.WithContext(ctx).Errorf("fail to get bandcamp collection of %v: %v", fanId, collection.ErrorMessage)
+		return nil, errors.New("获取 Bandcamp 收藏失败，请检查收藏是否公开")
+	}
+	return collection, nil
+}
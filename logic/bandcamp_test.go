@@ -0,0 +1,55 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/models"
+	"GoMusic/common/schema"
+	"GoMusic/initialize/config"
+)
+
+func TestBandcampDiscover(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/someone":
+			_, _ = w.Write([]byte(`<div id="pagedata" data-blob="{&quot;fan_data&quot;:{&quot;fan_id&quot;:7,&quot;name&quot;:&quot;Some One&quot;},&quot;collection_count&quot;:2}"></div>`))
+		case "/api/fancollection/1/collection_items":
+			body := map[string]any{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, float64(7), body["fan_id"])
+			if body["older_than_token"] != "next" {
+				_, _ = w.Write([]byte(`{"items":[{"item_type":"album","tralbum_id":1,"item_title":"Album","band_name":"Band","item_art_url":"art"}],
+					"tracklists":{"a1":[{"id":11,"title":"First","artist":"","duration":61.5},{"id":12,"title":"Second","artist":"Guest","duration":120}]},
+					"more_available":true,"last_token":"next"}`))
+				return
+			}
+			_, _ = fmt.Fprint(w, `{"items":[{"item_type":"track","tralbum_id":2,"item_title":"Single","band_name":"Other","album_title":"EP"}],
+				"tracklists":{"t2":[{"id":2,"title":"Single","artist":"Other","duration":200}]},"more_available":false}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	upstream := config.Conf.Upstream
+	config.Conf.Upstream.Bandcamp = server.URL
+	defer func() { config.Conf.Upstream = upstream }()
+
+	songList, err := BandcampDiscover(context.Background(), "https://bandcamp.com/Someone/")
+	assert.NoError(t, err)
+	assert.Equal(t, "Some One 的 Bandcamp 收藏", songList.Name)
+	assert.Equal(t, "art", songList.Cover)
+	assert.Equal(t, []string{"First - Band", "Second - Guest", "Single - Other"}, songList.Songs)
+	assert.Equal(t, []int{61500, 120000, 200000}, songList.Durations)
+	assert.Equal(t, &models.Song{Name: "Single", Artists: []string{"Other"}, Album: "EP", DurationMs: 200000, SourceId: "2"}, songList.Tracks[2])
+	assert.NoError(t, schema.ValidateSongList(songList))
+
+	_, err = BandcampDiscover(context.Background(), "https://artist.bandcamp.com/album/xyz")
+	assert.ErrorIs(t, err, errUnsupportedBandcampLink)
+}
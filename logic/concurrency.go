@@ -0,0 +1,55 @@
+package logic
+
+import (
+	"context"
+
+	"golang.org/x/sync/errgroup"
+
+	"GoMusic/common/ratelimit"
+	"GoMusic/initialize/config"
+	"GoMusic/initialize/metrics"
+	"GoMusic/initialize/trace"
+)
+
+var (
+	// chunkLimits 各平台分片/分页请求的并发上限，根据上游延迟与错误率在配置范围内自动调整
+	chunkLimits = map[string]*ratelimit.Adaptive{}
+
+	chunkFailures = metrics.NewCounterVec("gomusic_chunk_failures_total", "Failed chunk or page requests by platform.", "platform")
+)
+
+func init() {
+	for _, v := range []string{platformNetEasy, platformQQMusic, platformKugou, platformKuwo, platformKKBOX, platformSoundCloud} {
+		chunkLimits[v] = ratelimit.NewAdaptive(config.Conf.ChunkConcurrencyMin, config.Conf.ChunkConcurrencyMax, config.Conf.ChunkLatencyTarget)
+	}
+}
+
+// chunkGroup 创建获取单个歌单分片的 errgroup，同时运行的 goroutine 数不超过平台配置的上限，
+// 超出时 Go 阻塞直到有 goroutine 结束，避免数万首的歌单一次创建上百个 goroutine
+func chunkGroup(ctx context.Context, platform string) (*errgroup.Group, context.Context) {
+	group, groupCtx := errgroup.WithContext(ctx)
+	if n := config.Conf.PlatformChunkWorkers(platform); n > 0 {
+		group.SetLimit(n)
+	}
+	return group, groupCtx
+}
+
+// limitChunk 在平台的并发上限内执行一次分片请求，fn 应使用传入的 ctx 发起请求，以便归入分片的 span
+func limitChunk(ctx context.Context, platform string, fn func(ctx context.Context) error) error {
+	ctx, span := trace.Start(ctx, "chunk", "platform", platform)
+	err := chunkLimits[platform].Do(ctx, func() error { return fn(ctx) })
+	span.End(err)
+	if err != nil {
+		chunkFailures.Inc(platform)
+	}
+	return err
+}
+
+// ChunkConcurrencyStats 各平台当前的分片并发上限
+func ChunkConcurrencyStats() map[string]*ratelimit.AdaptiveStats {
+	stats := make(map[string]*ratelimit.AdaptiveStats, len(chunkLimits))
+	for k, v := range chunkLimits {
+		stats[k] = v.Stats()
+	}
+	return stats
+}
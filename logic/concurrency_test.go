@@ -0,0 +1,37 @@
+package logic
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/initialize/config"
+)
+
+func TestChunkGroup(t *testing.T) {
+	workers, byPlatform := config.Conf.ChunkWorkers, config.Conf.ChunkWorkersByPlatform
+	config.Conf.ChunkWorkers, config.Conf.ChunkWorkersByPlatform = 16, map[string]int{platformNetEasy: 3}
+	defer func() { config.Conf.ChunkWorkers, config.Conf.ChunkWorkersByPlatform = workers, byPlatform }()
+
+	var running, peak int32
+	group, _ := chunkGroup(context.Background(), platformNetEasy)
+	for i := 0; i < 20; i++ {
+		group.Go(func() error {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return nil
+		})
+	}
+	assert.NoError(t, group.Wait())
+	assert.Equal(t, int32(3), peak)
+}
@@ -0,0 +1,133 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"GoMusic/common/models"
+	"GoMusic/common/ratelimit"
+	"GoMusic/initialize/config"
+	"GoMusic/initialize/log"
+	"GoMusic/repo/cache"
+)
+
+const (
+	lockRedis      = "lock:%v"
+	rateLimitRedis = "ratelimit:%v"
+	// jobQueueRedis 各副本共用的后台任务队列
+	jobQueueRedis = "job:queue"
+)
+
+var discoverGroup singleflight.Group
+
+// shared 合并同一歌单的并发请求；redis 模式下通过分布式锁在多个副本间合并。
+// 合并后的请求使用首个请求的 ctx，其客户端断开时，其余仍在等待的请求重新发起获取
+func shared(ctx context.Context, key string, fn func(ctx context.Context) (*models.SongList, error)) (*models.SongList, error) {
+	ctx = log.WithFields(ctx, "playlist", key)
+	for {
+		ch := discoverGroup.DoChan(key, func() (any, error) {
+			if config.Conf.Coordination == config.CoordinationRedis {
+				return withLock(ctx, key, fn)
+			}
+			return fn(ctx)
+		})
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case result := <-ch:
+			switch {
+			case errors.Is(result.Err, context.Canceled) && ctx.Err() == nil:
+				continue
+			case result.Err != nil:
+				return nil, result.Err
+			}
+			return result.Val.(*models.SongList), nil
+		}
+	}
+}
+
+func withLock(ctx context.Context, key string, fn func(ctx context.Context) (*models.SongList, error)) (*models.SongList, error) {
+	lockKey := fmt.Sprintf(lockRedis, key)
+	var (
+		songList *models.SongList
+		fnErr    error
+	)
+	// 持有期间自动续期，超大歌单的获取耗时超过 LockTTL 也不会被其他副本重复执行
+	err := cache.RunWithLease(ctx, lockKey, config.Conf.LockTTL, func(ctx context.Context) error {
+		songList, fnErr = fn(ctx)
+		return fnErr
+	})
+	switch {
+	case errors.Is(err, cache.ErrLeaseHeld):
+		// 其他副本正在获取该歌单，等待其写入缓存后再执行，届时歌曲基本都能命中缓存
+		cache.WaitUnlock(lockKey, config.Conf.LockTTL)
+		return fn(ctx)
+	case err != nil && fnErr == nil: // Redis 不可用时退化为进程内合并
+		return fn(ctx)
+	}
+	return songList, fnErr
+}
+
+// Allow 消耗 key 的一个令牌；redis 模式下令牌桶保存在 Redis 中由所有副本共用，Redis 不可用时退化为本副本计数
+func Allow(limiter *ratelimit.Limiter, key string) *ratelimit.Result {
+	if config.Conf.Coordination == config.CoordinationRedis {
+		result, err := cache.Allow(fmt.Sprintf(rateLimitRedis, key), limiter)
+		if err == nil {
+			return result
+		}
+		if !errors.Is(err, cache.ErrUnavailable) {
+			log.Warnf("fail to rate limit %v in redis: %v", key, err)
+		}
+	}
+	return limiter.Allow(key, time.Now())
+}
+
+// enqueueJob redis 模式下写入共用队列，Redis 不可用时退化为本副本执行
+func enqueueJob(task *jobTask) error {
+	if config.Conf.Coordination == config.CoordinationRedis {
+		data, _ := json.Marshal(task)
+		ok, err := cache.Push(jobQueueRedis, data, config.Conf.JobQueueSize)
+		switch {
+		case err == nil && !ok:
+			return ErrJobQueueFull
+		case err == nil:
+			return nil
+		case !errors.Is(err, cache.ErrUnavailable):
+			log.Warnf("fail to push job %v, run locally: %v", task.Id, err)
+		}
+	}
+	select {
+	case jobQueue <- task:
+		return nil
+	default:
+		return ErrJobQueueFull
+	}
+}
+
+// pollJobs 从 Redis 队列取出任务执行，Redis 不可用期间每秒重试一次
+func pollJobs() {
+	for {
+		data, err := cache.Pop(context.Background(), jobQueueRedis, time.Second)
+		if err != nil {
+			if !errors.Is(err, cache.ErrUnavailable) {
+				log.Errorf("fail to pop job: %v", err)
+			}
+			time.Sleep(time.Second)
+			continue
+		}
+		if data == nil {
+			continue
+		}
+		task := &jobTask{}
+		if err = json.Unmarshal(data, task); err != nil {
+			log.Errorf("fail to decode job task: %v", err)
+			continue
+		}
+		runJob(task)
+	}
+}
@@ -0,0 +1,168 @@
+package logic
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	_ "image/gif"
+
+	"GoMusic/common/utils"
+	"GoMusic/httputil"
+	"GoMusic/initialize/config"
+	"GoMusic/initialize/log"
+	"GoMusic/repo/cache"
+)
+
+const (
+	coverTTL      = 7 * 24 * time.Hour
+	coverMaxBytes = 10 << 20
+	coverMaxSize  = 1024
+	// coverMaxPixels 解码前按图片头部校验的像素数上限，避免小文件解码出超大图片耗尽内存
+	coverMaxPixels = 4096 * 4096
+
+	CoverJPEG = "jpeg"
+	CoverPNG  = "png"
+	CoverWebP = "webp"
+)
+
+// 仅代理各平台的图片 CDN 与 GOMUSIC_COVER_HOSTS 中的域名，避免被用作任意地址的开放代理
+var coverHosts = []string{
+	".music.126.net", ".gtimg.cn", ".qpic.cn", ".kugou.com", ".kuwo.cn",
+	".ytimg.com", ".googleusercontent.com", // YouTube Music
+	".sndcdn.com",   // SoundCloud
+	".bcbits.com",   // Bandcamp
+	".kfs.io",       // KKBOX
+	".joox.com",     // JOOX
+	".mzstatic.com", // Apple Music
+	".scdn.co",      // Spotify
+}
+
+var (
+	coverCache = cache.NewSchema("cover", 1)
+
+	ErrCoverHost = errors.New("不支持代理该图片地址")
+)
+
+// Cover 获取并缩放封面图片，结果缓存在 Redis 中
+func Cover(ctx context.Context, link string, size int, format string) ([]byte, string, error) {
+	parse, err := url.Parse(link)
+	if err != nil || (parse.Scheme != "http" && parse.Scheme != "https") || !allowedCoverHost(parse.Hostname()) {
+		return nil, "", ErrCoverHost
+	}
+	if size <= 0 || size > coverMaxSize {
+		size = coverMaxSize
+	}
+	// 网易云 CDN 支持服务端缩放与 WebP 转码；其他平台本地缩放，WebP 退化为 JPEG
+	upstreamWebP := format == CoverWebP && strings.HasSuffix(parse.Hostname(), ".music.126.net")
+	if format == CoverWebP && !upstreamWebP {
+		format = CoverJPEG
+	}
+
+	sum := sha1.Sum([]byte(fmt.Sprintf("%v|%v|%v", link, size, format)))
+	key := coverCache.Key(hex.EncodeToString(sum[:]))
+	if data, _ := cache.GetBytes(key); len(data) > 0 {
+		return data, http.DetectContentType(data), nil
+	}
+
+	var data []byte
+	if upstreamWebP {
+		query := parse.Query()
+		query.Set("param", fmt.Sprintf("%vy%v", size, size))
+		query.Set("type", "webp")
+		parse.RawQuery = query.Encode()
+		data, err = fetchCover(ctx, parse.String())
+	} else {
+		data, err = resizeCover(ctx, link, size, format)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	_ = cache.SetBytes(key, data, coverTTL)
+	return data, http.DetectContentType(data), nil
+}
+
+func allowedCoverHost(host string) bool {
+	for _, v := range coverHosts {
+		if strings.HasSuffix(host, v) {
+			return true
+		}
+	}
+	for _, v := range config.Conf.CoverHosts {
+		// 配置的域名本身及其子域名均允许
+		if v = strings.TrimPrefix(v, "."); v != "" && (host == v || strings.HasSuffix(host, "."+v)) {
+			return true
+		}
+	}
+	return false
+}
+
+func fetchCover(ctx context.Context, link string) ([]byte, error) {
+	resp, err := httputil.Get(ctx, link)
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to get cover: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("获取封面失败，状态码：%d", resp.StatusCode)
+	}
+	// 多读 1 字节判断是否超出上限，避免截断后的图片解码失败
+	data, err := io.ReadAll(io.LimitReader(resp.Body, coverMaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > coverMaxBytes {
+		return nil, fmt.Errorf("封面超过 %d 字节", coverMaxBytes)
+	}
+	return data, nil
+}
+
+func resizeCover(ctx context.Context, link string, size int, format string) ([]byte, error) {
+	data, err := fetchCover(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	img, err := decodeCover(data)
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to decode cover: %v", err)
+		return nil, err
+	}
+	img = utils.Resize(img, size)
+
+	buf := &bytes.Buffer{}
+	if format == CoverPNG {
+		err = png.Encode(buf, img)
+	} else {
+		err = jpeg.Encode(buf, img, &jpeg.Options{Quality: 85})
+	}
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to encode cover: %v", err)
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeCover 先读取图片头部校验尺寸再解码
+func decodeCover(data []byte) (image.Image, error) {
+	conf, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if conf.Width <= 0 || conf.Height <= 0 || conf.Width*conf.Height > coverMaxPixels {
+		return nil, fmt.Errorf("封面尺寸 %dx%d 超出限制", conf.Width, conf.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
@@ -0,0 +1,41 @@
+package logic
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/initialize/config"
+)
+
+func TestAllowedCoverHost(t *testing.T) {
+	hosts := config.Conf.CoverHosts
+	defer func() { config.Conf.CoverHosts = hosts }()
+	config.Conf.CoverHosts = []string{"music.example.com"}
+
+	assert.True(t, allowedCoverHost("p1.music.126.net"))
+	assert.True(t, allowedCoverHost("i.ytimg.com"))
+	assert.True(t, allowedCoverHost("f4.bcbits.com"))
+	assert.True(t, allowedCoverHost("music.example.com"))
+	assert.True(t, allowedCoverHost("media.music.example.com"))
+	assert.False(t, allowedCoverHost("evilmusic.example.com"))
+	assert.False(t, allowedCoverHost("example.com"))
+}
+
+func TestDecodeCover(t *testing.T) {
+	encode := func(w, h int) []byte {
+		buf := &bytes.Buffer{}
+		assert.NoError(t, png.Encode(buf, image.NewGray(image.Rect(0, 0, w, h))))
+		return buf.Bytes()
+	}
+	img, err := decodeCover(encode(10, 20))
+	assert.NoError(t, err)
+	assert.Equal(t, 20, img.Bounds().Dy())
+
+	// 压缩后很小的超大图片在解码前即被拒绝
+	_, err = decodeCover(encode(5000, 5000))
+	assert.Error(t, err)
+}
@@ -0,0 +1,52 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"GoMusic/common/models"
+	"GoMusic/httputil"
+	"GoMusic/initialize/config"
+	"GoMusic/initialize/trace"
+)
+
+var (
+	ErrCredentialPlatform = errors.New("暂仅支持校验网易云（netease）的 MUSIC_U")
+	errNoNetEasyCookie    = errors.New("未配置网易云 MUSIC_U，可通过 GOMUSIC_NETEASY_MUSIC_U 配置或在请求头 X-NetEase-Cookie 中携带")
+)
+
+// VerifyCredential 以一次轻量的登录态请求校验平台凭证，请求携带凭证时校验该凭证，否则校验服务端配置的凭证；
+// 凭证无效时 Valid 为 false，无法访问平台时返回错误
+func VerifyCredential(ctx context.Context, platform string) (*models.CredentialStatus, error) {
+	if platform != platformNetEasy {
+		return nil, ErrCredentialPlatform
+	}
+	status := &models.CredentialStatus{Platform: platform, Source: models.CredentialSourceRequest}
+	musicU := netEasyMusicU(ctx)
+	if musicU == "" {
+		musicU, status.Source = config.Conf.Upstream.NetEasyMusicU, models.CredentialSourceConfig
+	}
+	if musicU == "" {
+		return nil, errNoNetEasyCookie
+	}
+	ctx = httputil.WithCookies(ctx, &http.Cookie{Name: "MUSIC_U", Value: musicU})
+	ctx, span := trace.Start(ctx, "netease.account")
+	account, err := netEasyApi().account(ctx)
+	span.End(err)
+	if err != nil {
+		return nil, err
+	}
+	status.CheckedAt = time.Now()
+	switch {
+	case account.Code != 200 || account.Account == nil || account.Profile == nil:
+		status.Reason = "MUSIC_U 无效或已过期，请重新登录网易云后更新"
+	case account.Account.Status != 0:
+		status.Reason = "账号已被封禁"
+	default:
+		status.Valid = true
+		status.UserId, status.Nickname, status.VipType = account.Profile.UserId, account.Profile.Nickname, account.Profile.VipType
+	}
+	return status, nil
+}
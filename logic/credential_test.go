@@ -0,0 +1,54 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/models"
+	"GoMusic/initialize/config"
+)
+
+func TestVerifyCredential(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("MUSIC_U")
+		if err != nil || cookie.Value != "valid" {
+			_, _ = fmt.Fprint(w, `{"code":200,"account":null,"profile":null}`)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"code":200,"account":{"id":1,"status":0},"profile":{"userId":1,"nickname":"Mad_Cat_","vipType":11}}`)
+	}))
+	defer server.Close()
+	upstream := config.Conf.Upstream
+	config.Conf.Upstream.NetEasyAccount = server.URL
+	config.Conf.Upstream.NetEasyBackend = config.NetEasyBackendDirect
+	config.Conf.Upstream.NetEasyMusicU = ""
+	defer func() { config.Conf.Upstream = upstream }()
+
+	ctx := context.Background()
+	_, err := VerifyCredential(ctx, "spotify")
+	assert.ErrorIs(t, err, ErrCredentialPlatform)
+	_, err = VerifyCredential(ctx, platformNetEasy)
+	assert.ErrorIs(t, err, errNoNetEasyCookie)
+
+	// 未携带凭证时校验服务端配置的 MUSIC_U
+	config.Conf.Upstream.NetEasyMusicU = "expired"
+	status, err := VerifyCredential(ctx, platformNetEasy)
+	assert.NoError(t, err)
+	assert.Equal(t, models.CredentialSourceConfig, status.Source)
+	assert.False(t, status.Valid)
+	assert.NotEmpty(t, status.Reason)
+
+	status, err = VerifyCredential(WithNetEasyCookie(ctx, "os=pc; MUSIC_U=valid"), platformNetEasy)
+	assert.NoError(t, err)
+	assert.Equal(t, models.CredentialSourceRequest, status.Source)
+	assert.True(t, status.Valid)
+	assert.Equal(t, int64(1), status.UserId)
+	assert.Equal(t, "Mad_Cat_", status.Nickname)
+	assert.Equal(t, 11, status.VipType)
+	assert.False(t, status.CheckedAt.IsZero())
+}
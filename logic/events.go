@@ -0,0 +1,34 @@
+package logic
+
+import (
+	"context"
+
+	"GoMusic/common/event"
+	"GoMusic/initialize/config"
+	"GoMusic/initialize/log"
+	"GoMusic/initialize/metrics"
+)
+
+var lifecycleEvents = metrics.NewCounterVec("gomusic_events_total", "Lifecycle events published by type.", "type")
+
+// 内置的事件订阅者，新的通知渠道（如聊天机器人）只需订阅事件，无需修改发布事件的逻辑
+func init() {
+	event.Subscribe("metrics", func(_ context.Context, e *event.Event) {
+		lifecycleEvents.Inc(string(e.Type))
+	})
+	event.Subscribe("audit", func(ctx context.Context, e *event.Event) {
+		if config.Conf.EventAudit {
+			log.WithContext(ctx).Infow("audit", "data", e.Data)
+		}
+	})
+	event.Subscribe("webhook", sendEventWebhooks)
+}
+
+// sendEventWebhooks 将事件发送至 GOMUSIC_EVENT_WEBHOOKS，任一地址失败不影响其他地址
+func sendEventWebhooks(ctx context.Context, e *event.Event) {
+	for _, v := range config.Conf.EventWebhooks {
+		if err := sendWebhook(ctx, v, e); err != nil {
+			log.WithContext(ctx).Errorf("fail to send event webhook: %v", err)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/event"
+	"GoMusic/initialize/config"
+)
+
+func TestSendEventWebhooks(t *testing.T) {
+	received := make(chan map[string]any, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]any{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		received <- body
+	}))
+	defer server.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	webhooks := config.Conf.EventWebhooks
+	config.Conf.EventWebhooks = []string{failing.URL, server.URL}
+	defer func() { config.Conf.EventWebhooks = webhooks }()
+
+	// 前一个地址失败时仍发送至其余地址
+	sendEventWebhooks(context.Background(), &event.Event{Type: event.TransferFinished, Time: time.Now(), RequestId: "abc", Data: map[string]int{"matched": 3}})
+	body := <-received
+	assert.Equal(t, "transfer.finished", body["type"])
+	assert.Equal(t, "abc", body["request_id"])
+	assert.Equal(t, map[string]any{"matched": float64(3)}, body["data"])
+}
@@ -0,0 +1,247 @@
+package logic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"GoMusic/common/event"
+	"GoMusic/common/format"
+	"GoMusic/common/models"
+	"GoMusic/common/utils"
+	"GoMusic/httputil"
+	"GoMusic/initialize/log"
+)
+
+const (
+	// exportMatchThreshold 候选歌曲的相似度低于此值时视为未找到
+	exportMatchThreshold = 0.6
+	// exportSearchConcurrency 同时搜索的歌曲数
+	exportSearchConcurrency = 8
+)
+
+// Exporter 将歌单导入目标平台的用户资料库
+type Exporter interface {
+	// Search 在目标平台搜索歌曲，返回候选歌曲，由 Export 按相似度选择
+	Search(ctx context.Context, title, artist string) ([]*models.ExportCandidate, error)
+	// CreatePlaylist 在用户资料库中创建歌单并按顺序添加歌曲，返回歌单 id 与链接
+	CreatePlaylist(ctx context.Context, name, description string, ids []string) (id, link string, err error)
+}
+
+// PlaylistAppender 可选实现，支持向用户已有的歌单追加歌曲
+type PlaylistAppender interface {
+	// PlaylistTracks 歌单中已有的歌曲 id，与 Search 返回的 id 一致，用于跳过已在歌单中的歌曲
+	PlaylistTracks(ctx context.Context, id string) ([]string, error)
+	// AppendPlaylist 按顺序向歌单末尾追加歌曲，返回歌单链接
+	AppendPlaylist(ctx context.Context, id string, ids []string) (link string, err error)
+}
+
+// PlaylistLister 可选实现，列出用户在目标平台的歌单
+type PlaylistLister interface {
+	Playlists(ctx context.Context) ([]*models.TargetPlaylist, error)
+}
+
+var errAppendUnsupported = errors.New("该平台不支持追加到已有歌单")
+
+// Export 逐首搜索歌单中的歌曲并在目标平台创建歌单，未找到的歌曲列入报告；
+// 导入选项指定已有歌单时追加到该歌单，跳过已在歌单中的歌曲
+func Export(ctx context.Context, platform string, exporter Exporter, songList *models.SongList) (*models.ExportReport, error) {
+	var (
+		appender PlaylistAppender
+		targetId string
+		existing map[string]bool
+	)
+	if playlist := transferOptions(ctx).Playlist; playlist != "" {
+		var ok bool
+		if appender, ok = exporter.(PlaylistAppender); !ok {
+			return nil, errAppendUnsupported
+		}
+		// 先读取已有歌单，歌单不存在时无需搜索
+		targetId = targetPlaylistId(playlist)
+		tracks, err := appender.PlaylistTracks(ctx, targetId)
+		if err != nil {
+			return nil, err
+		}
+		existing = make(map[string]bool, len(tracks))
+		for _, v := range tracks {
+			existing[v] = true
+		}
+	}
+
+	ids := make([]string, len(songList.Songs))
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(exportSearchConcurrency)
+	for i, v := range songList.Songs {
+		i := i
+		title, artist := format.SplitSong(v)
+		// 优先使用结构化歌曲，歌名中含“ - ”时也能正确拆分
+		if i < len(songList.Tracks) {
+			title, artist = songList.Tracks[i].Name, strings.Join(songList.Tracks[i].Artists, " / ")
+		}
+		group.Go(func() error {
+			candidates, err := exporter.Search(groupCtx, title, artist)
+			if err != nil {
+				return err
+			}
+			ids[i] = bestCandidate(title, artist, candidates)
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	report := &models.ExportReport{Platform: platform, Total: len(songList.Songs), Unmatched: make([]string, 0)}
+	matched := make([]string, 0, len(ids))
+	for i, v := range ids {
+		if v == "" {
+			report.Unmatched = append(report.Unmatched, songList.Songs[i])
+			if i < len(songList.Tracks) {
+				report.Missing = append(report.Missing, songList.Tracks[i])
+			}
+			continue
+		}
+		matched = append(matched, v)
+	}
+	report.Matched = len(matched)
+	var (
+		id, link string
+		err      error
+	)
+	if appender != nil {
+		added := make([]string, 0, len(matched))
+		for _, v := range matched {
+			if existing[v] {
+				report.Existing++
+				continue
+			}
+			// 歌单中重复的歌曲只追加一次
+			existing[v] = true
+			added = append(added, v)
+		}
+		id = targetId
+		link, err = appender.AppendPlaylist(ctx, id, added)
+	} else {
+		id, link, err = exporter.CreatePlaylist(ctx, songList.Name, songList.Description, matched)
+	}
+	if err != nil {
+		return nil, err
+	}
+	report.PlaylistId, report.PlaylistUrl = id, link
+	report.Id, report.FinishedAt = newWatchToken(), time.Now()
+	saveTransfer(report)
+	event.Publish(ctx, event.TransferFinished, report)
+	return report, nil
+}
+
+// TargetPlaylists 登录目标平台并列出用户已有的歌单，歌单 id 可作为导入选项 playlist
+func TargetPlaylists(ctx context.Context, target *JobTarget) ([]*models.TargetPlaylist, error) {
+	exporter, err := target.exporter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lister, ok := exporter.(PlaylistLister)
+	if !ok {
+		return nil, errors.New("该平台不支持列出歌单")
+	}
+	return lister.Playlists(ctx)
+}
+
+// bestCandidate 选择歌名与歌手最相似的候选歌曲，歌名权重更高，均不够相似时返回空字符串
+func bestCandidate(title, artist string, candidates []*models.ExportCandidate) string {
+	best, bestScore := "", exportMatchThreshold
+	for _, v := range candidates {
+		score := utils.NameSimilarity(title, v.Title) * 0.7
+		if artist != "" {
+			score += utils.NameSimilarity(artist, v.Artist) * 0.3
+		} else {
+			score /= 0.7
+		}
+		if score > bestScore || (best == "" && score == bestScore) {
+			best, bestScore = v.Id, score
+		}
+	}
+	return best
+}
+
+// targetPlaylistId 已有歌单的 id，也可以是歌单链接：依次取 spotify:playlist:<id> 的最后一段、
+// 查询参数 id 与路径的最后一段
+func targetPlaylistId(playlist string) string {
+	playlist = strings.TrimSpace(playlist)
+	parse, err := url.Parse(playlist)
+	switch {
+	case err != nil || parse.Scheme == "":
+		return playlist
+	case parse.Opaque != "":
+		return parse.Opaque[strings.LastIndex(parse.Opaque, ":")+1:]
+	}
+	if id := parse.Query().Get("id"); id != "" {
+		return id
+	}
+	// 单页应用的路由位于 # 之后，如 Jellyfin 的 #/details?id=、Navidrome 的 #/playlist/<id>
+	route, fragment, _ := strings.Cut(parse.Fragment, "?")
+	if query, err := url.ParseQuery(fragment); err == nil && query.Get("id") != "" {
+		return query.Get("id")
+	}
+	if route = strings.TrimSuffix(route, "/"); route != "" {
+		return path.Base(route)
+	}
+	return path.Base(strings.TrimSuffix(parse.Path, "/"))
+}
+
+// targetServer 校验用户自建服务（如 Navidrome、Plex）的地址，去掉末尾的 /
+func targetServer(server string) (string, error) {
+	parse, err := url.Parse(strings.TrimSpace(server))
+	if err != nil || (parse.Scheme != "http" && parse.Scheme != "https") || parse.Host == "" {
+		return "", fmt.Errorf("无效的服务器地址：%v，请填写 http(s)://host:port", server)
+	}
+	return strings.TrimSuffix(parse.String(), "/"), nil
+}
+
+// exportRequest 向目标平台发送 JSON 请求并解析响应，v 为 nil 时忽略响应体；被限流时按 Retry-After 等待后重试一次
+func exportRequest(ctx context.Context, platform, method, link string, header http.Header, body, v any) error {
+	var data []byte
+	if body != nil {
+		data, _ = json.Marshal(body)
+	}
+	header = header.Clone()
+	header.Set("Content-Type", "application/json")
+	for retried := false; ; retried = true {
+		resp, err := httputil.Do(ctx, method, link, header, bytes.NewReader(data))
+		if err != nil {
+			log.WithContext(ctx).Errorf("fail to request %v: %v", platform, err)
+			return err
+		}
+		if resp.StatusCode == http.StatusTooManyRequests && !retried {
+			resp.Body.Close()
+			wait, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(wait+1) * time.Second):
+			}
+			continue
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= http.StatusBadRequest {
+			msg, _ := io.ReadAll(resp.Body)
+			log.WithContext(ctx).Errorf("fail to request %v %v %v: %v %s", platform, method, link, resp.StatusCode, msg)
+			return fmt.Errorf("%v 请求失败，状态码：%d", platform, resp.StatusCode)
+		}
+		if v == nil {
+			return nil
+		}
+		return decodeBody(resp.Body, v)
+	}
+}
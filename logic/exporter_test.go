@@ -0,0 +1,427 @@
+package logic
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/models"
+	"GoMusic/initialize/config"
+)
+
+type fakeExporter struct {
+	catalog map[string][]*models.ExportCandidate
+	created []string
+}
+
+func (e *fakeExporter) Search(_ context.Context, title, _ string) ([]*models.ExportCandidate, error) {
+	return e.catalog[title], nil
+}
+
+func (e *fakeExporter) CreatePlaylist(_ context.Context, _, _ string, ids []string) (string, string, error) {
+	e.created = ids
+	return "p1", "https://example.com/p1", nil
+}
+
+func TestExport(t *testing.T) {
+	exporter := &fakeExporter{catalog: map[string][]*models.ExportCandidate{
+		"晴天":  {{Id: "1", Title: "晴天", Artist: "周杰伦"}},
+		"稻香":  {{Id: "x", Title: "完全不同的歌", Artist: "别人"}, {Id: "2", Title: "稻香", Artist: "Jay Chou / 周杰伦"}},
+		"七里香": {{Id: "y", Title: "Something Else", Artist: "Nobody"}},
+	}}
+	songList := &models.SongList{Name: "歌单", Songs: []string{"晴天 - 周杰伦", "七里香 - 周杰伦", "稻香 - 周杰伦", "未收录 - 某人"}}
+	report, err := Export(context.Background(), "example", exporter, songList)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, exporter.created)
+	assert.Equal(t, 4, report.Total)
+	assert.Equal(t, 2, report.Matched)
+	assert.Equal(t, []string{"七里香 - 周杰伦", "未收录 - 某人"}, report.Unmatched)
+	assert.Equal(t, "https://example.com/p1", report.PlaylistUrl)
+	assert.Nil(t, report.Missing)
+	// 导入结果可再次查询
+	transfer, err := GetTransfer(report.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, report, transfer)
+
+	// 结构化歌曲中的专辑随报告返回
+	songList.Tracks = []*models.Song{
+		{Name: "晴天", Artists: []string{"周杰伦"}}, {Name: "七里香", Artists: []string{"周杰伦"}, Album: "七里香"},
+		{Name: "稻香", Artists: []string{"周杰伦"}}, {Name: "未收录", Artists: []string{"某人"}},
+	}
+	report, err = Export(context.Background(), "example", exporter, songList)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.Song{songList.Tracks[1], songList.Tracks[3]}, report.Missing)
+}
+
+// fakeAppender 支持追加到已有歌单 p2 的目标平台
+type fakeAppender struct {
+	fakeExporter
+	tracks   []string
+	appended []string
+}
+
+func (e *fakeAppender) PlaylistTracks(_ context.Context, id string) ([]string, error) {
+	if id != "p2" {
+		return nil, errors.New("歌单不存在")
+	}
+	return e.tracks, nil
+}
+
+func (e *fakeAppender) AppendPlaylist(_ context.Context, id string, ids []string) (string, error) {
+	e.appended = ids
+	return "https://example.com/" + id, nil
+}
+
+func TestExportAppend(t *testing.T) {
+	exporter := &fakeAppender{fakeExporter: fakeExporter{catalog: map[string][]*models.ExportCandidate{
+		"晴天":  {{Id: "1", Title: "晴天", Artist: "周杰伦"}},
+		"稻香":  {{Id: "2", Title: "稻香", Artist: "周杰伦"}},
+		"七里香": {{Id: "3", Title: "七里香", Artist: "周杰伦"}},
+	}}, tracks: []string{"1"}}
+	songList := &models.SongList{Name: "歌单", Songs: []string{"晴天 - 周杰伦", "稻香 - 周杰伦", "七里香 - 周杰伦", "稻香 - 周杰伦"}}
+	ctx, err := WithTransferOptions(context.Background(), &models.TransferOptions{Playlist: "https://example.com/playlist/p2/"})
+	assert.NoError(t, err)
+	report, err := Export(ctx, "example", exporter, songList)
+	assert.NoError(t, err)
+	// 已在歌单中的与重复的歌曲只添加一次
+	assert.Equal(t, []string{"2", "3"}, exporter.appended)
+	assert.Nil(t, exporter.created)
+	assert.Equal(t, 4, report.Matched)
+	assert.Equal(t, 2, report.Existing)
+	assert.Equal(t, "p2", report.PlaylistId)
+	assert.Equal(t, "https://example.com/p2", report.PlaylistUrl)
+
+	ctx, _ = WithTransferOptions(context.Background(), &models.TransferOptions{Playlist: "p3"})
+	_, err = Export(ctx, "example", exporter, songList)
+	assert.Error(t, err)
+	_, err = Export(ctx, "example", &exporter.fakeExporter, songList)
+	assert.ErrorIs(t, err, errAppendUnsupported)
+}
+
+func TestTargetPlaylistId(t *testing.T) {
+	assert.Equal(t, "p1", targetPlaylistId(" p1 "))
+	assert.Equal(t, "37i9dQ", targetPlaylistId("https://open.spotify.com/playlist/37i9dQ?si=abc"))
+	assert.Equal(t, "37i9dQ", targetPlaylistId("spotify:playlist:37i9dQ"))
+	assert.Equal(t, "12", targetPlaylistId("https://open.audio/library/playlists/12/"))
+	assert.Equal(t, "abc", targetPlaylistId("http://jellyfin:8096/web/#/details?id=abc&serverId=1"))
+	assert.Equal(t, "7", targetPlaylistId("https://navidrome.example/app/#/playlist/7"))
+}
+
+func TestSpotifyExporter(t *testing.T) {
+	added := make([]string, 0)
+	public := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/search":
+			assert.Equal(t, "track:晴天 artist:周杰伦", r.URL.Query().Get("q"))
+			assert.Equal(t, "from_token", r.URL.Query().Get("market"))
+			_, _ = w.Write([]byte(`{"tracks":{"items":[{"uri":"spotify:track:1","name":"晴天","artists":[{"name":"Jay Chou"}]}]}}`))
+		case "/me":
+			_, _ = w.Write([]byte(`{"id":"user"}`))
+		case "/users/user/playlists":
+			body := map[string]any{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			assert.Equal(t, public, body["public"])
+			_, _ = w.Write([]byte(`{"id":"p1","external_urls":{"spotify":"https://open.spotify.com/playlist/p1"}}`))
+		case "/playlists/p1/tracks":
+			body := struct {
+				Uris []string `json:"uris"`
+			}{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			assert.LessOrEqual(t, len(body.Uris), spotifyAddLimit)
+			added = append(added, body.Uris...)
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	upstream := config.Conf.Upstream.SpotifyApi
+	config.Conf.Upstream.SpotifyApi = server.URL
+	defer func() { config.Conf.Upstream.SpotifyApi = upstream }()
+
+	exporter := &spotifyExporter{token: "token"}
+	candidates, err := exporter.Search(context.Background(), "晴天", "周杰伦")
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.ExportCandidate{{Id: "spotify:track:1", Title: "晴天", Artist: "Jay Chou"}}, candidates)
+
+	uris := make([]string, 150)
+	for i := range uris {
+		uris[i] = "spotify:track:1"
+	}
+	id, link, err := exporter.CreatePlaylist(context.Background(), "歌单", "", uris)
+	assert.NoError(t, err)
+	assert.Equal(t, "p1", id)
+	assert.Equal(t, "https://open.spotify.com/playlist/p1", link)
+	assert.Len(t, added, 150)
+
+	// 指定 public 时创建公开歌单
+	public = true
+	ctx, err := WithTransferOptions(context.Background(), &models.TransferOptions{Privacy: models.PrivacyPublic})
+	assert.NoError(t, err)
+	_, _, err = exporter.CreatePlaylist(ctx, "歌单", "", nil)
+	assert.NoError(t, err)
+}
+
+func TestAppleMusicExporter(t *testing.T) {
+	added := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer developer", r.Header.Get("Authorization"))
+		assert.Equal(t, "user", r.Header.Get("Music-User-Token"))
+		switch r.URL.Path {
+		case "/me/storefront":
+			_, _ = w.Write([]byte(`{"data":[{"id":"cn","attributes":{"defaultLanguageTag":"zh-Hans-CN","supportedLanguageTags":["zh-Hans-CN","en-GB"]}}]}`))
+		case "/storefronts/tw":
+			_, _ = w.Write([]byte(`{"data":[{"id":"tw","attributes":{"defaultLanguageTag":"zh-Hant-TW","supportedLanguageTags":["zh-Hant-TW","en-GB"]}}]}`))
+		case "/catalog/cn/search":
+			assert.Equal(t, "晴天 周杰伦", r.URL.Query().Get("term"))
+			assert.Equal(t, "en-GB", r.URL.Query().Get("l"))
+			_, _ = w.Write([]byte(`{"results":{"songs":{"data":[{"id":"1","attributes":{"name":"晴天","artistName":"周杰伦"}}]}}}`))
+		case "/me/library/playlists":
+			_, _ = w.Write([]byte(`{"data":[{"id":"p.1"}]}`))
+		case "/me/library/playlists/p.1/tracks":
+			body := struct {
+				Data []*models.AppleMusicTrack `json:"data"`
+			}{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			assert.Equal(t, "songs", body.Data[0].Type)
+			added += len(body.Data)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	upstream := config.Conf.Upstream.AppleMusic
+	config.Conf.Upstream.AppleMusic = server.URL
+	defer func() { config.Conf.Upstream.AppleMusic = upstream }()
+
+	exporter := &appleMusicExporter{developerToken: "developer", userToken: "user"}
+	// 地区不支持 en-US 时选择同语言的 en-GB
+	ctx := WithLocale(context.Background(), "en-US")
+	assert.NoError(t, exporter.loadStorefront(ctx))
+	assert.Equal(t, "en-GB", exporter.language)
+	candidates, err := exporter.Search(ctx, "晴天", "周杰伦")
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.ExportCandidate{{Id: "1", Title: "晴天", Artist: "周杰伦"}}, candidates)
+
+	id, link, err := exporter.CreatePlaylist(context.Background(), "歌单", "", make([]string, 120))
+	assert.NoError(t, err)
+	assert.Equal(t, "p.1", id)
+	assert.Equal(t, "https://music.apple.com/library/playlist/p.1", link)
+	assert.Equal(t, 120, added)
+
+	// 指定地区时使用该地区的曲库
+	ctx, err = WithTransferOptions(ctx, &models.TransferOptions{Market: "tw"})
+	assert.NoError(t, err)
+	assert.NoError(t, exporter.loadStorefront(ctx))
+	assert.Equal(t, "tw", exporter.storefront)
+}
+
+func TestSubsonicExporter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		// 令牌为 md5(密码 + salt)
+		sum := md5.Sum([]byte("secret" + r.PostForm.Get("s")))
+		if r.PostForm.Get("u") != "user" || r.PostForm.Get("t") != hex.EncodeToString(sum[:]) {
+			_, _ = w.Write([]byte(`{"subsonic-response":{"status":"failed","error":{"code":40,"message":"Wrong username or password"}}}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/music/rest/ping":
+			_, _ = w.Write([]byte(`{"subsonic-response":{"status":"ok"}}`))
+		case "/music/rest/search3":
+			assert.Equal(t, "晴天", r.PostForm.Get("query"))
+			_, _ = w.Write([]byte(`{"subsonic-response":{"status":"ok","searchResult3":{"song":[{"id":"s1","title":"晴天","artist":"周杰伦"}]}}}`))
+		case "/music/rest/createPlaylist":
+			assert.Equal(t, "歌单", r.PostForm.Get("name"))
+			assert.Equal(t, []string{"s1", "s2"}, r.PostForm["songId"])
+			_, _ = w.Write([]byte(`{"subsonic-response":{"status":"ok","playlist":{"id":"p1"}}}`))
+		case "/music/rest/getPlaylists":
+			_, _ = w.Write([]byte(`{"subsonic-response":{"status":"ok","playlists":{"playlist":[{"id":"p1","name":"歌单","songCount":2}]}}}`))
+		case "/music/rest/getPlaylist":
+			assert.Equal(t, "p1", r.PostForm.Get("id"))
+			_, _ = w.Write([]byte(`{"subsonic-response":{"status":"ok","playlist":{"id":"p1","entry":[{"id":"s1"},{"id":"s2"}]}}}`))
+		case "/music/rest/updatePlaylist":
+			assert.Equal(t, "p1", r.PostForm.Get("playlistId"))
+			assert.Equal(t, []string{"s3"}, r.PostForm["songIdToAdd"])
+			_, _ = w.Write([]byte(`{"subsonic-response":{"status":"ok"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	exporter := &subsonicExporter{server: server.URL + "/music", username: "user", password: "secret"}
+	assert.NoError(t, exporter.request(context.Background(), "ping", nil, nil))
+	candidates, err := exporter.Search(context.Background(), "晴天", "周杰伦")
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.ExportCandidate{{Id: "s1", Title: "晴天", Artist: "周杰伦"}}, candidates)
+	id, _, err := exporter.CreatePlaylist(context.Background(), "歌单", "", []string{"s1", "s2"})
+	assert.NoError(t, err)
+	assert.Equal(t, "p1", id)
+
+	// 列出并追加到已有歌单
+	playlists, err := exporter.Playlists(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.TargetPlaylist{{Id: "p1", Name: "歌单", SongsCount: 2}}, playlists)
+	tracks, err := exporter.PlaylistTracks(context.Background(), "p1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"s1", "s2"}, tracks)
+	_, err = exporter.AppendPlaylist(context.Background(), "p1", []string{"s3"})
+	assert.NoError(t, err)
+
+	exporter.password = "wrong"
+	assert.EqualError(t, exporter.request(context.Background(), "ping", nil, nil), "Subsonic 请求失败：Wrong username or password")
+}
+
+func TestTargetServer(t *testing.T) {
+	server, err := targetServer(" https://music.example.com/navidrome/ ")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://music.example.com/navidrome", server)
+	_, err = targetServer("ftp://music.example.com")
+	assert.Error(t, err)
+	_, err = targetServer("music.example.com")
+	assert.Error(t, err)
+}
+
+func TestPlexExporter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Plex-Token") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.Method + " " + r.URL.Path {
+		case "GET /identity":
+			_, _ = w.Write([]byte(`{"MediaContainer":{"machineIdentifier":"m1"}}`))
+		case "GET /library/sections":
+			_, _ = w.Write([]byte(`{"MediaContainer":{"Directory":[{"key":"1","type":"movie"},{"key":"2","type":"artist"}]}}`))
+		case "GET /library/sections/2/all":
+			assert.Equal(t, "晴天", r.URL.Query().Get("title"))
+			_, _ = w.Write([]byte(`{"MediaContainer":{"Metadata":[{"ratingKey":"11","title":"晴天","grandparentTitle":"周杰伦"},{"ratingKey":"12","title":"晴天","grandparentTitle":"群星","originalTitle":"五月天"}]}}`))
+		case "POST /playlists":
+			assert.Equal(t, "歌单", r.URL.Query().Get("title"))
+			assert.Equal(t, "server://m1/com.plexapp.plugins.library/library/metadata/11,12", r.URL.Query().Get("uri"))
+			_, _ = w.Write([]byte(`{"MediaContainer":{"Metadata":[{"ratingKey":"p1"}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	exporter := &plexExporter{server: server.URL, token: "token"}
+	assert.NoError(t, exporter.init(context.Background()))
+	assert.Equal(t, "2", exporter.section)
+	candidates, err := exporter.Search(context.Background(), "晴天", "周杰伦")
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.ExportCandidate{{Id: "11", Title: "晴天", Artist: "周杰伦"}, {Id: "12", Title: "晴天", Artist: "五月天"}}, candidates)
+	id, _, err := exporter.CreatePlaylist(context.Background(), "歌单", "", []string{"11", "12"})
+	assert.NoError(t, err)
+	assert.Equal(t, "p1", id)
+
+	exporter.token = "wrong"
+	assert.EqualError(t, exporter.init(context.Background()), "Plex 请求失败，状态码：401")
+}
+
+func TestJellyfinExporter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/Users/AuthenticateByName" {
+			body := map[string]string{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body["Username"] != "user" || body["Pw"] != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"AccessToken":"token","User":{"Id":"u1"}}`))
+			return
+		}
+		assert.Contains(t, r.Header.Get("Authorization"), `Token="token"`)
+		switch r.Method + " " + r.URL.Path {
+		case "GET /Items":
+			assert.Equal(t, "晴天", r.URL.Query().Get("searchTerm"))
+			assert.Equal(t, "u1", r.URL.Query().Get("userId"))
+			_, _ = w.Write([]byte(`{"Items":[{"Id":"s1","Name":"晴天","Artists":["周杰伦"]},{"Id":"s2","Name":"晴天","AlbumArtist":"群星"}]}`))
+		case "POST /Playlists":
+			body := struct {
+				Name string
+				Ids  []string
+			}{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			assert.Equal(t, "歌单", body.Name)
+			assert.Equal(t, []string{"s1"}, body.Ids)
+			_, _ = w.Write([]byte(`{"Id":"p1"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	exporter := &jellyfinExporter{server: server.URL}
+	assert.EqualError(t, exporter.login(context.Background(), "user", "wrong"), "Jellyfin 请求失败，状态码：401")
+	assert.NoError(t, exporter.login(context.Background(), "user", "secret"))
+	candidates, err := exporter.Search(context.Background(), "晴天", "周杰伦")
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.ExportCandidate{{Id: "s1", Title: "晴天", Artist: "周杰伦"}, {Id: "s2", Title: "晴天", Artist: "群星"}}, candidates)
+	id, link, err := exporter.CreatePlaylist(context.Background(), "歌单", "", []string{"s1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "p1", id)
+	assert.Equal(t, server.URL+"/web/#/details?id=p1", link)
+}
+
+func TestQobuzExporter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "app", r.Header.Get("X-App-Id"))
+		query := r.URL.Query()
+		if r.URL.Path == "/user/login" {
+			// 密码以 MD5 传递
+			if query.Get("email") != "user@example.com" || query.Get("password") != "5ebe2294ecd0e0f08eab7690d2a6ee69" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"user_auth_token":"token","user":{"id":1}}`))
+			return
+		}
+		assert.Equal(t, "token", r.Header.Get("X-User-Auth-Token"))
+		switch r.Method + " " + r.URL.Path {
+		case "GET /track/search":
+			assert.Equal(t, "晴天 周杰伦", query.Get("query"))
+			_, _ = w.Write([]byte(`{"tracks":{"items":[{"id":11,"title":"晴天","performer":{"name":"周杰伦"}}]}}`))
+		case "POST /playlist/create":
+			assert.Equal(t, "歌单", query.Get("name"))
+			assert.Equal(t, "0", query.Get("is_public"))
+			_, _ = w.Write([]byte(`{"id":99}`))
+		case "POST /playlist/addTracks":
+			assert.Equal(t, "99", query.Get("playlist_id"))
+			assert.Equal(t, "11,12", query.Get("track_ids"))
+			_, _ = w.Write([]byte(`{"id":99}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	upstream, appId := config.Conf.Upstream, config.Conf.QobuzAppId
+	config.Conf.Upstream.QobuzApi, config.Conf.QobuzAppId = server.URL, "app"
+	defer func() { config.Conf.Upstream, config.Conf.QobuzAppId = upstream, appId }()
+
+	exporter := &qobuzExporter{}
+	assert.EqualError(t, exporter.login(context.Background(), "user@example.com", "wrong"), "Qobuz 请求失败，状态码：401")
+	assert.NoError(t, exporter.login(context.Background(), "user@example.com", "secret"))
+	candidates, err := exporter.Search(context.Background(), "晴天", "周杰伦")
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.ExportCandidate{{Id: "11", Title: "晴天", Artist: "周杰伦"}}, candidates)
+	id, link, err := exporter.CreatePlaylist(context.Background(), "歌单", "", []string{"11", "12"})
+	assert.NoError(t, err)
+	assert.Equal(t, "99", id)
+	assert.Equal(t, "https://open.qobuz.com/playlist/99", link)
+
+	_, err = QobuzExport(context.Background(), "https://music.163.com/playlist?id=1", "", "", "")
+	assert.ErrorIs(t, err, errQobuzAccount)
+}
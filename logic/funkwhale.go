@@ -0,0 +1,317 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"GoMusic/common/format"
+	"GoMusic/common/models"
+	"GoMusic/httputil"
+	"GoMusic/initialize/config"
+	"GoMusic/initialize/log"
+	"GoMusic/repo/cache"
+)
+
+const (
+	funkwhaleList    = "funkwhale:%v:%v"
+	funkwhalePattern = `^(https?://[^/]+)/library/playlists/(\d+)`
+
+	platformFunkwhale = "funkwhale"
+	// funkwhaleScope 读取曲库用于匹配歌曲，写入歌单用于创建歌单
+	funkwhaleScope = "read:libraries read:playlists write:playlists"
+	// funkwhaleStateTTL 用户完成授权的时限
+	funkwhaleStateTTL = 10 * time.Minute
+	// funkwhaleMaxPages 歌单歌曲最多获取的页数
+	funkwhaleMaxPages = 100
+	// funkwhaleSearchLimit 每首歌曲的候选数
+	funkwhaleSearchLimit = 20
+)
+
+var (
+	funkwhaleRegx  = regexp.MustCompile(funkwhalePattern)
+	funkwhaleState = cache.NewSchema("funkwhale_state", 1)
+
+	errUnsupportedFunkwhaleLink = errors.New("暂不支持该 Funkwhale 链接，请使用 https://<实例>/library/playlists/<id> 链接")
+	errFunkwhaleDisabled        = errors.New("未配置 Funkwhale 授权回调地址，无法导入 Funkwhale")
+	errFunkwhaleState           = errors.New("授权已过期，请重新发起导入")
+)
+
+func init() {
+	// Funkwhale 为自建实例，无固定域名，按歌单页的路径匹配
+	RegisterProvider(&linkProvider{name: platformFunkwhale, hosts: []string{"/library/playlists/"}, discover: FunkwhaleDiscover})
+}
+
+// FunkwhaleDiscover 获取 Funkwhale 实例中的公开歌单，如 https://open.audio/library/playlists/123
+func FunkwhaleDiscover(ctx context.Context, link string) (*models.SongList, error) {
+	m := funkwhaleRegx.FindStringSubmatch(strings.TrimSpace(link))
+	if m == nil {
+		return nil, errUnsupportedFunkwhaleLink
+	}
+	// 同一歌单的并发请求只向实例转发一次
+	return shared(ctx, fmt.Sprintf(funkwhaleList, m[1], m[2]), func(ctx context.Context) (*models.SongList, error) {
+		return funkwhaleDiscover(ctx, m[1], m[2])
+	})
+}
+
+func funkwhaleDiscover(ctx context.Context, server, id string) (*models.SongList, error) {
+	playlist := &models.FunkwhalePlaylist{}
+	if err := getFunkwhale(ctx, server+"/api/v1/playlists/"+id+"/", playlist); err != nil {
+		return nil, err
+	}
+	tracks := make([]*models.Song, 0, playlist.TracksCount)
+	durations := make([]int, 0, playlist.TracksCount)
+	totalDuration := 0
+	// 后续页的地址由上一页返回，只能依次获取；仅跟随同一实例的地址
+	link := server + "/api/v1/playlists/" + id + "/tracks/"
+	for page := 0; link != "" && page < funkwhaleMaxPages; page++ {
+		result := &models.FunkwhalePlaylistTracks{}
+		if err := getFunkwhale(ctx, link, result); err != nil {
+			return nil, err
+		}
+		for _, v := range result.Results {
+			if v.Track == nil {
+				continue
+			}
+			song := funkwhaleSong(v.Track)
+			totalDuration += song.DurationMs
+			durations = append(durations, song.DurationMs)
+			tracks = append(tracks, song)
+		}
+		link = ""
+		if result.Next != nil && strings.HasPrefix(*result.Next, server+"/") {
+			link = *result.Next
+		}
+	}
+	songsString := format.Tracks(tracks)
+	return &models.SongList{
+		Name:       playlist.Name,
+		Songs:      songsString,
+		Tracks:     tracks,
+		Durations:  durations,
+		SongsCount: len(tracks),
+		Summary:    format.Summarize(songsString, totalDuration),
+	}, nil
+}
+
+func getFunkwhale(ctx context.Context, link string, v any) error {
+	resp, err := httputil.GetWithHeader(ctx, link, http.Header{"Accept": {"application/json"}})
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to get funkwhale playlist: %v", err)
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.WithContext(ctx).Errorf("fail to get funkwhale playlist %v: %v", link, resp.StatusCode)
+		return errors.New("获取 Funkwhale 歌单失败，请检查歌单是否存在或已设为私密")
+	}
+	return decodeBody(resp.Body, v)
+}
+
+// funkwhaleSong 优先使用 1.4 起的 artist_credit
+func funkwhaleSong(track *models.FunkwhaleTrack) *models.Song {
+	artists := make([]string, 0, len(track.ArtistCredit))
+	for _, v := range track.ArtistCredit {
+		artists = append(artists, v.Credit)
+	}
+	if len(artists) == 0 && track.Artist != nil {
+		artists = append(artists, track.Artist.Name)
+	}
+	song := &models.Song{Name: track.Title, Artists: artists, SourceId: strconv.Itoa(track.Id)}
+	if track.Album != nil {
+		song.Album = track.Album.Title
+	}
+	if len(track.Uploads) > 0 {
+		song.DurationMs = track.Uploads[0].Duration * 1000
+	}
+	return song
+}
+
+// funkwhalePrivacy 歌单可见性对应的 privacy_level，unlisted 为仅实例内可见
+var funkwhalePrivacy = map[string]string{
+	models.PrivacyPrivate:  "me",
+	models.PrivacyUnlisted: "instance",
+	models.PrivacyPublic:   "everyone",
+}
+
+// FunkwhaleAuthorizeUrl 在用户的 Funkwhale 实例上注册 OAuth 应用并记录待导入的歌单，返回实例的授权页地址；
+// 用户授权后跳转回 /funkwhale/callback
+func FunkwhaleAuthorizeUrl(ctx context.Context, link, server string) (string, error) {
+	if config.Conf.FunkwhaleRedirectUrl == "" {
+		return "", errFunkwhaleDisabled
+	}
+	server, err := targetServer(server)
+	if err != nil {
+		return "", err
+	}
+	if MatchProvider(link) == nil {
+		return "", errors.New("不支持的歌单链接")
+	}
+	// 各实例互相独立，每次授权时动态注册应用
+	app := &models.FunkwhaleApp{}
+	body := map[string]string{"name": "GoMusic", "redirect_uris": config.Conf.FunkwhaleRedirectUrl, "scopes": funkwhaleScope}
+	if err = exportRequest(ctx, "Funkwhale", "POST", server+"/api/v1/oauth/apps/", http.Header{}, body, app); err != nil {
+		return "", err
+	}
+	state := newWatchToken()
+	auth := &models.FunkwhaleAuthState{Link: link, Server: server, ClientId: app.ClientId, ClientSecret: app.ClientSecret, MusicU: netEasyMusicU(ctx)}
+	auth.Locale, _ = ctx.Value(localeKey{}).(string)
+	auth.Transfer, _ = ctx.Value(transferOptionsKey{}).(*models.TransferOptions)
+	data, _ := json.Marshal(auth)
+	if err = cache.SetBytes(funkwhaleState.Key(state), data, funkwhaleStateTTL); err != nil {
+		log.WithContext(ctx).Errorf("fail to save funkwhale state: %v", err)
+		return "", err
+	}
+	query := url.Values{
+		"response_type": {"code"},
+		"client_id":     {app.ClientId},
+		"scope":         {funkwhaleScope},
+		"redirect_uri":  {config.Conf.FunkwhaleRedirectUrl},
+		"state":         {state},
+	}
+	return server + "/authorize?" + query.Encode(), nil
+}
+
+// FunkwhaleCallback 用授权码换取令牌，获取歌单后在用户的 Funkwhale 实例中创建同名私密歌单
+func FunkwhaleCallback(ctx context.Context, code, state string) (*models.ExportReport, error) {
+	data, err := cache.GetDelBytes(funkwhaleState.Key(state))
+	switch {
+	case err != nil:
+		log.WithContext(ctx).Errorf("fail to get funkwhale state: %v", err)
+		return nil, err
+	case data == nil:
+		return nil, errFunkwhaleState
+	}
+	auth := &models.FunkwhaleAuthState{}
+	if err = json.Unmarshal(data, auth); err != nil {
+		return nil, errFunkwhaleState
+	}
+	ctx = withSavedTransferOptions(WithLocale(WithNetEasyCookie(ctx, auth.MusicU), auth.Locale), auth.Transfer)
+	token, err := funkwhaleToken(ctx, auth, code)
+	if err != nil {
+		return nil, err
+	}
+	provider := MatchProvider(auth.Link)
+	if provider == nil {
+		return nil, errors.New("不支持的歌单链接")
+	}
+	songList, err := provider.Discover(ctx, auth.Link)
+	if err != nil {
+		return nil, err
+	}
+	return Export(ctx, platformFunkwhale, &funkwhaleExporter{server: auth.Server, token: token}, songList)
+}
+
+// funkwhaleToken 授权码模式换取用户的访问令牌
+func funkwhaleToken(ctx context.Context, auth *models.FunkwhaleAuthState, code string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {config.Conf.FunkwhaleRedirectUrl},
+		"client_id":     {auth.ClientId},
+		"client_secret": {auth.ClientSecret},
+	}
+	header := http.Header{"Content-Type": {"application/x-www-form-urlencoded"}}
+	resp, err := httputil.Do(ctx, "POST", auth.Server+"/api/v1/oauth/token/", header, strings.NewReader(form.Encode()))
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to get funkwhale token: %v", err)
+		return "", err
+	}
+	defer resp.Body.Close()
+	token := &models.FunkwhaleToken{}
+	if err = decodeBody(resp.Body, token); err != nil {
+		return "", err
+	}
+	if token.AccessToken == "" {
+		log.WithContext(ctx).Errorf("fail to get funkwhale token: %v %v", token.Error, token.Description)
+		return "", fmt.Errorf("Funkwhale 授权失败：%v", token.Error)
+	}
+	return token.AccessToken, nil
+}
+
+// funkwhaleExporter 使用用户令牌访问 Funkwhale API
+type funkwhaleExporter struct {
+	server string
+	token  string
+}
+
+func (e *funkwhaleExporter) Search(ctx context.Context, title, _ string) ([]*models.ExportCandidate, error) {
+	query := url.Values{"q": {title}, "page_size": {strconv.Itoa(funkwhaleSearchLimit)}}
+	result := &models.FunkwhaleTracks{}
+	if err := e.request(ctx, "GET", "/api/v1/tracks/?"+query.Encode(), nil, result); err != nil {
+		return nil, err
+	}
+	candidates := make([]*models.ExportCandidate, 0, len(result.Results))
+	for _, v := range result.Results {
+		song := funkwhaleSong(v)
+		candidates = append(candidates, &models.ExportCandidate{Id: song.SourceId, Title: song.Name, Artist: strings.Join(song.Artists, " / ")})
+	}
+	return candidates, nil
+}
+
+func (e *funkwhaleExporter) CreatePlaylist(ctx context.Context, name, _ string, ids []string) (string, string, error) {
+	playlist := &models.FunkwhalePlaylist{}
+	body := map[string]any{"name": name, "privacy_level": funkwhalePrivacy[transferOptions(ctx).Privacy]}
+	if err := e.request(ctx, "POST", "/api/v1/playlists/", body, playlist); err != nil {
+		return "", "", err
+	}
+	id := strconv.Itoa(playlist.Id)
+	if err := e.addTracks(ctx, id, ids); err != nil {
+		return "", "", err
+	}
+	return id, e.server + "/library/playlists/" + id, nil
+}
+
+func (e *funkwhaleExporter) PlaylistTracks(ctx context.Context, id string) ([]string, error) {
+	ids := make([]string, 0)
+	// 与获取歌单相同，依次跟随同一实例的下一页地址
+	path := "/api/v1/playlists/" + url.PathEscape(id) + "/tracks/"
+	for page := 0; path != "" && page < funkwhaleMaxPages; page++ {
+		result := &models.FunkwhalePlaylistTracks{}
+		if err := e.request(ctx, "GET", path, nil, result); err != nil {
+			return nil, err
+		}
+		for _, v := range result.Results {
+			if v.Track != nil {
+				ids = append(ids, strconv.Itoa(v.Track.Id))
+			}
+		}
+		path = ""
+		if result.Next != nil && strings.HasPrefix(*result.Next, e.server+"/") {
+			path = strings.TrimPrefix(*result.Next, e.server)
+		}
+	}
+	return ids, nil
+}
+
+func (e *funkwhaleExporter) AppendPlaylist(ctx context.Context, id string, ids []string) (string, error) {
+	if err := e.addTracks(ctx, id, ids); err != nil {
+		return "", err
+	}
+	return e.server + "/library/playlists/" + id, nil
+}
+
+func (e *funkwhaleExporter) addTracks(ctx context.Context, id string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	tracks := make([]int, 0, len(ids))
+	for _, v := range ids {
+		if n, err := strconv.Atoi(v); err == nil {
+			tracks = append(tracks, n)
+		}
+	}
+	return e.request(ctx, "POST", "/api/v1/playlists/"+url.PathEscape(id)+"/add/", map[string]any{"tracks": tracks, "allow_duplicates": true}, nil)
+}
+
+func (e *funkwhaleExporter) request(ctx context.Context, method, path string, body, v any) error {
+	header := http.Header{"Authorization": {"Bearer " + e.token}, "Accept": {"application/json"}}
+	return exportRequest(ctx, "Funkwhale", method, e.server+path, header, body, v)
+}
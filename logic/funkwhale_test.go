@@ -0,0 +1,81 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/models"
+)
+
+func TestFunkwhaleDiscover(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.RequestURI() {
+		case "/api/v1/playlists/7/":
+			_, _ = w.Write([]byte(`{"id":7,"name":"测试歌单","tracks_count":2}`))
+		case "/api/v1/playlists/7/tracks/":
+			// 旧版本返回 artist
+			_, _ = fmt.Fprintf(w, `{"count":2,"next":"%v/api/v1/playlists/7/tracks/?page=2","results":[
+				{"track":{"id":1,"title":"晴天","artist":{"name":"周杰伦"},"album":{"title":"叶惠美"},"uploads":[{"duration":269}]}}]}`, server.URL)
+		case "/api/v1/playlists/7/tracks/?page=2":
+			_, _ = w.Write([]byte(`{"count":2,"next":null,"results":[
+				{"track":{"id":2,"title":"Song","artist_credit":[{"credit":"A","joinphrase":" & "},{"credit":"B","joinphrase":""}]}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider := MatchProvider(server.URL + "/library/playlists/7")
+	assert.NotNil(t, provider)
+	assert.Equal(t, platformFunkwhale, provider.Name())
+	songList, err := FunkwhaleDiscover(context.Background(), server.URL+"/library/playlists/7")
+	assert.NoError(t, err)
+	assert.Equal(t, "测试歌单", songList.Name)
+	assert.Equal(t, []string{"晴天 - 周杰伦", "Song - A / B"}, songList.Songs)
+	assert.Equal(t, []int{269000, 0}, songList.Durations)
+	assert.Equal(t, "叶惠美", songList.Tracks[0].Album)
+
+	_, err = FunkwhaleDiscover(context.Background(), server.URL+"/library/playlists/8")
+	assert.Error(t, err)
+	_, err = FunkwhaleDiscover(context.Background(), server.URL+"/library/albums/7")
+	assert.Equal(t, errUnsupportedFunkwhaleLink, err)
+}
+
+func TestFunkwhaleExporter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v1/tracks/":
+			assert.Equal(t, "晴天", r.URL.Query().Get("q"))
+			_, _ = w.Write([]byte(`{"results":[{"id":11,"title":"晴天","artist_credit":[{"credit":"周杰伦"}]}]}`))
+		case "POST /api/v1/playlists/":
+			_, _ = w.Write([]byte(`{"id":3,"name":"歌单"}`))
+		case "POST /api/v1/playlists/3/add/":
+			body := struct {
+				Tracks []int `json:"tracks"`
+			}{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			assert.Equal(t, []int{11, 12}, body.Tracks)
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	exporter := &funkwhaleExporter{server: server.URL, token: "token"}
+	candidates, err := exporter.Search(context.Background(), "晴天", "周杰伦")
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.ExportCandidate{{Id: "11", Title: "晴天", Artist: "周杰伦"}}, candidates)
+	id, link, err := exporter.CreatePlaylist(context.Background(), "歌单", "", []string{"11", "12"})
+	assert.NoError(t, err)
+	assert.Equal(t, "3", id)
+	assert.Equal(t, server.URL+"/library/playlists/3", link)
+}
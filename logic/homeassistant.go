@@ -0,0 +1,59 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"GoMusic/common/format"
+	"GoMusic/common/models"
+	"GoMusic/common/mqtt"
+	"GoMusic/initialize/config"
+	"GoMusic/initialize/log"
+)
+
+// homeAssistantEnqueue Music Assistant 支持的入队方式
+var homeAssistantEnqueue = map[string]bool{"": true, "play": true, "replace": true, "next": true, "replace_next": true, "add": true}
+
+var errMQTTDisabled = errors.New("未配置 MQTT 代理，无法发布")
+
+// NewHomeAssistantPlaylist 将歌单转换为 Music Assistant 可搜索的“歌手 - 歌名”列表
+func NewHomeAssistantPlaylist(songList *models.SongList, mediaPlayer, enqueue string) (*models.HomeAssistantPlaylist, error) {
+	if !homeAssistantEnqueue[enqueue] {
+		return nil, fmt.Errorf("不支持的入队方式：%v，可选：play, replace, next, replace_next, add", enqueue)
+	}
+	playlist := &models.HomeAssistantPlaylist{
+		Name:        songList.Name,
+		Count:       len(songList.Songs),
+		MediaPlayer: mediaPlayer,
+		Enqueue:     enqueue,
+		MediaId:     make([]string, 0, len(songList.Songs)),
+	}
+	for _, v := range songList.Songs {
+		title, artist := format.SplitSong(v)
+		if artist == "" {
+			playlist.MediaId = append(playlist.MediaId, title)
+			continue
+		}
+		playlist.MediaId = append(playlist.MediaId, artist+" - "+title)
+	}
+	return playlist, nil
+}
+
+// PublishHomeAssistant 将转换结果以 JSON 发布到 MQTT 主题
+func PublishHomeAssistant(ctx context.Context, playlist *models.HomeAssistantPlaylist) error {
+	conf := config.Conf.MQTT
+	if conf.Broker == "" {
+		return errMQTTDisabled
+	}
+	playlist.Published = true
+	payload, _ := json.Marshal(playlist)
+	client := &mqtt.Client{Broker: conf.Broker, ClientId: "gomusic-" + newWatchToken()[:8], Username: conf.Username, Password: conf.Password}
+	if err := client.Publish(ctx, &mqtt.Message{Topic: conf.Topic, Payload: payload}); err != nil {
+		playlist.Published = false
+		log.WithContext(ctx).Errorf("fail to publish mqtt message: %v", err)
+		return err
+	}
+	return nil
+}
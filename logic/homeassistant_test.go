@@ -0,0 +1,22 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/models"
+)
+
+func TestNewHomeAssistantPlaylist(t *testing.T) {
+	songList := &models.SongList{Name: "测试歌单", Songs: []string{"晴天 - 周杰伦", "无歌手"}}
+	playlist, err := NewHomeAssistantPlaylist(songList, "media_player.living_room", "add")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.HomeAssistantPlaylist{
+		Name: "测试歌单", Count: 2, MediaPlayer: "media_player.living_room", Enqueue: "add",
+		MediaId: []string{"周杰伦 - 晴天", "无歌手"},
+	}, playlist)
+
+	_, err = NewHomeAssistantPlaylist(songList, "", "shuffle")
+	assert.Error(t, err)
+}
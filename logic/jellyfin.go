@@ -0,0 +1,154 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"GoMusic/common/models"
+)
+
+const (
+	platformJellyfin = "jellyfin"
+	// jellyfinClient 请求头中的客户端信息，Jellyfin 据此在控制台中展示设备
+	jellyfinClient = `MediaBrowser Client="GoMusic", Device="GoMusic", DeviceId="gomusic", Version="1.0"`
+	// jellyfinSearchLimit 每首歌曲的候选数，本地曲库按歌名搜索，由相似度筛选歌手
+	jellyfinSearchLimit = 20
+	// jellyfinAddLimit 每次添加到歌单的歌曲数上限
+	jellyfinAddLimit = 100
+)
+
+var errJellyfinAccount = errors.New("请提供 Jellyfin 服务器地址、用户名与密码")
+
+// JellyfinExport 在 Jellyfin 服务器中按曲库匹配歌曲并创建歌单，报告中列出曲库中缺少的歌曲
+func JellyfinExport(ctx context.Context, link, server, username, password string) (*models.ExportReport, error) {
+	provider := MatchProvider(link)
+	if provider == nil {
+		return nil, errors.New("不支持的歌单链接")
+	}
+	exporter, err := newJellyfinExporter(ctx, server, username, password)
+	if err != nil {
+		return nil, err
+	}
+	songList, err := provider.Discover(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	return Export(ctx, platformJellyfin, exporter, songList)
+}
+
+// newJellyfinExporter 先登录，避免获取歌单后才发现无法创建
+func newJellyfinExporter(ctx context.Context, server, username, password string) (*jellyfinExporter, error) {
+	if server == "" || username == "" {
+		return nil, errJellyfinAccount
+	}
+	server, err := targetServer(server)
+	if err != nil {
+		return nil, err
+	}
+	exporter := &jellyfinExporter{server: server}
+	if err = exporter.login(ctx, username, password); err != nil {
+		return nil, err
+	}
+	return exporter, nil
+}
+
+// jellyfinExporter 使用用户名密码登录后的令牌访问 Jellyfin API
+type jellyfinExporter struct {
+	server string
+	token  string
+	userId string
+}
+
+func (e *jellyfinExporter) login(ctx context.Context, username, password string) error {
+	auth := &models.JellyfinAuth{}
+	if err := e.request(ctx, "POST", "/Users/AuthenticateByName", map[string]string{"Username": username, "Pw": password}, auth); err != nil {
+		return err
+	}
+	e.token, e.userId = auth.AccessToken, auth.User.Id
+	return nil
+}
+
+func (e *jellyfinExporter) Search(ctx context.Context, title, _ string) ([]*models.ExportCandidate, error) {
+	query := url.Values{
+		"userId":           {e.userId},
+		"searchTerm":       {title},
+		"includeItemTypes": {"Audio"},
+		"recursive":        {"true"},
+		"limit":            {fmt.Sprint(jellyfinSearchLimit)},
+	}
+	items := &models.JellyfinItems{}
+	if err := e.request(ctx, "GET", "/Items?"+query.Encode(), nil, items); err != nil {
+		return nil, err
+	}
+	candidates := make([]*models.ExportCandidate, 0, len(items.Items))
+	for _, v := range items.Items {
+		artist := strings.Join(v.Artists, " / ")
+		if artist == "" {
+			artist = v.AlbumArtist
+		}
+		candidates = append(candidates, &models.ExportCandidate{Id: v.Id, Title: v.Name, Artist: artist})
+	}
+	return candidates, nil
+}
+
+func (e *jellyfinExporter) CreatePlaylist(ctx context.Context, name, _ string, ids []string) (string, string, error) {
+	body := map[string]any{"Name": name, "Ids": ids, "UserId": e.userId, "MediaType": "Audio", "IsPublic": transferOptions(ctx).Privacy == models.PrivacyPublic}
+	playlist := &models.JellyfinPlaylist{}
+	if err := e.request(ctx, "POST", "/Playlists", body, playlist); err != nil {
+		return "", "", err
+	}
+	return playlist.Id, e.server + "/web/#/details?id=" + playlist.Id, nil
+}
+
+func (e *jellyfinExporter) PlaylistTracks(ctx context.Context, id string) ([]string, error) {
+	items := &models.JellyfinItems{}
+	if err := e.request(ctx, "GET", "/Playlists/"+url.PathEscape(id)+"/Items?"+url.Values{"userId": {e.userId}}.Encode(), nil, items); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(items.Items))
+	for _, v := range items.Items {
+		ids = append(ids, v.Id)
+	}
+	return ids, nil
+}
+
+func (e *jellyfinExporter) AppendPlaylist(ctx context.Context, id string, ids []string) (string, error) {
+	// ids 通过查询字符串传递，分批添加避免 URL 过长
+	for i := 0; i < len(ids); i += jellyfinAddLimit {
+		end := i + jellyfinAddLimit
+		if end > len(ids) {
+			end = len(ids)
+		}
+		query := url.Values{"ids": {strings.Join(ids[i:end], ",")}, "userId": {e.userId}}
+		if err := e.request(ctx, "POST", "/Playlists/"+url.PathEscape(id)+"/Items?"+query.Encode(), nil, nil); err != nil {
+			return "", err
+		}
+	}
+	return e.server + "/web/#/details?id=" + id, nil
+}
+
+func (e *jellyfinExporter) Playlists(ctx context.Context) ([]*models.TargetPlaylist, error) {
+	query := url.Values{"userId": {e.userId}, "includeItemTypes": {"Playlist"}, "recursive": {"true"}}
+	items := &models.JellyfinItems{}
+	if err := e.request(ctx, "GET", "/Items?"+query.Encode(), nil, items); err != nil {
+		return nil, err
+	}
+	playlists := make([]*models.TargetPlaylist, 0, len(items.Items))
+	for _, v := range items.Items {
+		playlists = append(playlists, &models.TargetPlaylist{Id: v.Id, Name: v.Name, SongsCount: v.ChildCount})
+	}
+	return playlists, nil
+}
+
+func (e *jellyfinExporter) request(ctx context.Context, method, path string, body, v any) error {
+	authorization := jellyfinClient
+	if e.token != "" {
+		authorization += fmt.Sprintf(`, Token="%v"`, e.token)
+	}
+	header := http.Header{"Authorization": {authorization}}
+	return exportRequest(ctx, "Jellyfin", method, e.server+path, header, body, v)
+}
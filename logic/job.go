@@ -0,0 +1,423 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"GoMusic/common/format"
+	"GoMusic/common/models"
+	"GoMusic/common/utils"
+	"GoMusic/initialize/config"
+	"GoMusic/initialize/log"
+	"GoMusic/repo/cache"
+)
+
+var (
+	// 任务状态保存为 Redis 哈希，进度按字段更新
+	jobSchema = cache.NewSchema("job", 2)
+
+	// jobQueue 本副本待执行的任务，由 StartJobWorkers 创建，未启动时不接受任务；
+	// redis 模式下任务写入各副本共用的 Redis 队列，仅在 Redis 不可用时由本副本执行
+	jobQueue chan *jobTask
+	// jobs 本副本提交或执行的任务，Redis 不可用时仍可查询；同时写入 Redis，供其他副本查询
+	jobs = struct {
+		sync.Mutex
+		m map[string]*models.Job
+	}{m: make(map[string]*models.Job)}
+
+	// jobWaitInterval 等待任务完成时查询进度的间隔，任务可能由其他副本执行，只能查询
+	jobWaitInterval = 200 * time.Millisecond
+
+	ErrJobNotFound  = errors.New("任务不存在或已过期")
+	ErrJobQueueFull = errors.New("任务队列已满，请稍后重试")
+)
+
+// JobMaxLinks 组合任务最多合并的歌单数
+const JobMaxLinks = 10
+
+// JobTarget 使用账号登录的目标平台，用于后台任务获取歌单后导入与列出已有歌单：
+// subsonic、jellyfin 使用 Username 与 Password，plex 使用 Token
+type JobTarget struct {
+	Platform string `json:"platform"`
+	Server   string `json:"server"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
+}
+
+// exporter 登录目标平台，账号无效时在获取歌单前返回错误
+func (t *JobTarget) exporter(ctx context.Context) (Exporter, error) {
+	switch t.Platform {
+	case platformSubsonic:
+		return newSubsonicExporter(ctx, t.Server, t.Username, t.Password)
+	case platformJellyfin:
+		return newJellyfinExporter(ctx, t.Server, t.Username, t.Password)
+	case platformPlex:
+		return newPlexExporter(ctx, t.Server, t.Token)
+	}
+	return nil, errors.New("不支持导入的目标平台")
+}
+
+// jobTask 待执行的任务，只沿用提交请求的日志字段、网易云 cookie 与偏好语言；
+// redis 模式下序列化后写入队列，由任意副本取出执行，MUSIC_U 与目标平台账号随任务保存，出队后即删除
+type jobTask struct {
+	Id        string                  `json:"id"`
+	Link      string                  `json:"url"`
+	Links     []string                `json:"urls,omitempty"`
+	Target    *JobTarget              `json:"target,omitempty"`
+	Transfer  *models.TransferOptions `json:"transfer,omitempty"`
+	RequestId string                  `json:"request_id,omitempty"`
+	MusicU    string                  `json:"music_u,omitempty"`
+	Locale    string                  `json:"locale,omitempty"`
+}
+
+// links 任务依次获取的歌单，单个歌单的任务只有 Link
+func (t *jobTask) links() []string {
+	if len(t.Links) > 0 {
+		return t.Links
+	}
+	return []string{t.Link}
+}
+
+// context 任务在请求结束后继续执行，不沿用请求的 ctx
+func (t *jobTask) context() context.Context {
+	ctx := log.WithFields(context.Background(), "job", t.Id)
+	if t.RequestId != "" {
+		ctx = log.WithFields(ctx, "request_id", t.RequestId)
+	}
+	ctx = withSavedTransferOptions(WithNetEasyCookie(ctx, t.MusicU), t.Transfer)
+	return WithLocale(ctx, t.Locale)
+}
+
+// StartJobWorkers 启动后台转换任务的 worker
+func StartJobWorkers() {
+	startJobWorkers(config.Conf.JobWorkers, config.Conf.JobQueueSize)
+}
+
+func startJobWorkers(workers, queueSize int) {
+	jobQueue = make(chan *jobTask, queueSize)
+	for i := 0; i < workers; i++ {
+		go func(queue <-chan *jobTask) {
+			for task := range queue {
+				runJob(task)
+			}
+		}(jobQueue)
+		if config.Conf.Coordination == config.CoordinationRedis {
+			go pollJobs()
+		}
+	}
+}
+
+// SubmitJob 提交后台转换任务，返回排队中的任务；任务不受请求超时限制，结果保留 JobTTL。
+// 提交多个歌单时按顺序获取后合并去重，target 不为空时再将结果导入目标平台，各步骤共用获取到的歌单
+func SubmitJob(ctx context.Context, links []string, target *JobTarget) (*models.Job, error) {
+	if len(links) == 0 || len(links) > JobMaxLinks {
+		return nil, fmt.Errorf("歌单链接数量需在 1 到 %d 之间", JobMaxLinks)
+	}
+	for _, v := range links {
+		if MatchProvider(v) == nil {
+			return nil, errors.New("不支持的歌单链接")
+		}
+	}
+	switch {
+	case target == nil:
+	case target.Platform != platformSubsonic && target.Platform != platformJellyfin && target.Platform != platformPlex:
+		return nil, errors.New("不支持导入的目标平台")
+	case target.Server == "":
+		return nil, errors.New("请提供目标平台的服务器地址")
+	}
+	now := time.Now()
+	job := &models.Job{Id: newWatchToken(), Link: links[0], Status: models.JobQueued, CreatedAt: now, UpdatedAt: now}
+	task := &jobTask{Id: job.Id, Link: links[0], Target: target, MusicU: netEasyMusicU(ctx)}
+	if len(links) > 1 {
+		job.Links, task.Links = links, links
+	}
+	task.RequestId, _ = log.Field(ctx, "request_id").(string)
+	task.Locale, _ = ctx.Value(localeKey{}).(string)
+	task.Transfer, _ = ctx.Value(transferOptionsKey{}).(*models.TransferOptions)
+
+	saveJob(job)
+	if err := enqueueJob(task); err != nil {
+		// 已保存的任务标记为失败，避免一直显示排队中
+		updateJob(job.Id, func(job *models.Job) { job.Status, job.Error = models.JobFailed, err.Error() })
+		return nil, err
+	}
+	return job, nil
+}
+
+// GetJob 查询任务进度与结果
+func GetJob(id string) (*models.Job, error) {
+	jobs.Lock()
+	job, ok := jobs.m[id]
+	if ok {
+		copied := *job
+		jobs.Unlock()
+		return &copied, nil
+	}
+	jobs.Unlock()
+	fields, err := cache.HGetAll(jobSchema.Key(id))
+	if err != nil && !errors.Is(err, cache.ErrUnavailable) {
+		log.Errorf("fail to get job %v: %v", id, err)
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, ErrJobNotFound
+	}
+	job, err = decodeJob(fields)
+	if err != nil {
+		log.Errorf("fail to decode job %v: %v", id, err)
+		return nil, err
+	}
+	return job, nil
+}
+
+// WaitJob 等待任务完成或失败，超过 timeout 或 ctx 结束时返回当前进度
+func WaitJob(ctx context.Context, id string, timeout time.Duration) (*models.Job, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(jobWaitInterval)
+	defer ticker.Stop()
+	for {
+		job, err := GetJob(id)
+		if err != nil || job.Status == models.JobDone || job.Status == models.JobFailed {
+			return job, err
+		}
+		select {
+		case <-ctx.Done():
+			return job, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func runJob(task *jobTask) {
+	ctx, cancel := context.WithTimeout(task.context(), config.Conf.JobTimeout)
+	defer cancel()
+	if err := runJobSteps(ctx, task); err != nil {
+		log.WithContext(ctx).Errorf("fail to run job: %v", err)
+		updateJob(task.Id, func(job *models.Job) { job.Status, job.Error = models.JobFailed, err.Error() })
+	}
+}
+
+// runJobSteps 依次获取歌单、合并去重并导入目标平台，任一步骤失败时停止
+func runJobSteps(ctx context.Context, task *jobTask) error {
+	updateJob(task.Id, func(job *models.Job) { job.Status, job.Step = models.JobRunning, models.JobStepDiscover })
+	var exporter Exporter
+	if task.Target != nil {
+		var err error
+		if exporter, err = task.Target.exporter(ctx); err != nil {
+			return err
+		}
+	}
+
+	links := task.links()
+	songLists := make([]*models.SongList, 0, len(links))
+	total := 0
+	for _, link := range links {
+		songList, err := jobDiscover(ctx, task.Id, link, total)
+		if err != nil {
+			return err
+		}
+		total += songList.SongsCount
+		songLists = append(songLists, songList)
+	}
+	songList := songLists[0]
+	if len(songLists) > 1 {
+		updateJob(task.Id, func(job *models.Job) { job.Step = models.JobStepMerge })
+		songList = mergeJobSongLists(songLists)
+	}
+
+	if exporter == nil {
+		updateJob(task.Id, func(job *models.Job) {
+			job.Status, job.Step, job.SongList = models.JobDone, "", songList
+			job.Total, job.Resolved = total, total
+		})
+		return nil
+	}
+	updateJob(task.Id, func(job *models.Job) {
+		job.Step, job.SongList = models.JobStepTransfer, songList
+		job.Total, job.Resolved = total, total
+	})
+	report, err := Export(ctx, task.Target.Platform, exporter, songList)
+	if err != nil {
+		return err
+	}
+	updateJob(task.Id, func(job *models.Job) { job.Status, job.Step, job.Transfer = models.JobDone, "", report })
+	return nil
+}
+
+// mergeJobSongLists 按提交顺序合并去重，与 POST /songlists 的合并结果一致
+func mergeJobSongLists(songLists []*models.SongList) *models.SongList {
+	merged := utils.MergeSongLists("合并歌单", songLists...)
+	totalDuration := 0
+	for _, v := range merged.Durations {
+		totalDuration += v
+	}
+	merged.Summary = format.Summarize(merged.Songs, totalDuration)
+	return merged
+}
+
+// jobDiscover 支持流式获取的平台逐批更新进度，其余平台获取完成后一次更新；offset 为此前歌单的歌曲数
+func jobDiscover(ctx context.Context, id, link string, offset int) (*models.SongList, error) {
+	provider := MatchProvider(link)
+	if _, ok := provider.(Streamer); !ok {
+		songList, err := provider.Discover(ctx, link)
+		if err == nil {
+			updateJob(id, func(job *models.Job) {
+				job.Total, job.Resolved = offset+songList.SongsCount, offset+songList.SongsCount
+			})
+		}
+		return songList, err
+	}
+	sink := &jobSink{id: id, offset: offset}
+	if err := Stream(ctx, provider, link, sink); err != nil {
+		return nil, err
+	}
+	return sink.songList(), nil
+}
+
+// jobSink 汇总流式获取的歌曲，每写完一批更新任务进度
+type jobSink struct {
+	id       string
+	offset   int
+	info     *models.SongList
+	songs    []string
+	duration []int
+	explicit []bool
+	tracks   []*models.Song
+}
+
+func (s *jobSink) Begin(songList *models.SongList) error {
+	s.info = songList
+	updateJob(s.id, func(job *models.Job) { job.Total = s.offset + songList.SongsCount })
+	return nil
+}
+
+func (s *jobSink) Song(song string, durationMs int, explicit bool) error {
+	s.songs = append(s.songs, song)
+	s.duration = append(s.duration, durationMs)
+	s.explicit = append(s.explicit, explicit)
+	return nil
+}
+
+func (s *jobSink) Track(track *models.Song) error {
+	s.tracks = append(s.tracks, track)
+	return nil
+}
+
+func (s *jobSink) Chunk(resolved int) error {
+	updateJob(s.id, func(job *models.Job) { job.Resolved = s.offset + resolved })
+	return nil
+}
+
+func (s *jobSink) songList() *models.SongList {
+	songList := *s.info
+	songList.Songs, songList.Durations, songList.Explicit = s.songs, s.duration, s.explicit
+	// 仅当每首歌曲都有结构化信息时才能与 Songs 一一对应
+	if len(s.tracks) == len(s.songs) {
+		songList.Tracks = s.tracks
+	}
+	if songList.Songs == nil {
+		songList.Songs = make([]string, 0)
+	}
+	totalDuration := 0
+	for _, v := range s.duration {
+		totalDuration += v
+	}
+	songList.Summary = format.Summarize(songList.Songs, totalDuration)
+	return &songList
+}
+
+// updateJob 修改任务并保存，任务由其他副本提交时从 Redis 读取；每个任务只由一个 worker 修改
+func updateJob(id string, fn func(job *models.Job)) {
+	job, err := GetJob(id)
+	if err != nil {
+		return
+	}
+	fn(job)
+	job.UpdatedAt = time.Now()
+	saveJob(job)
+}
+
+// saveJob 保存任务并清理本副本中过期的任务；Redis 写入失败时仅记录日志，本副本仍可查询
+func saveJob(job *models.Job) {
+	jobs.Lock()
+	jobs.m[job.Id] = job
+	for k, v := range jobs.m {
+		if time.Since(v.UpdatedAt) > config.Conf.JobTTL {
+			delete(jobs.m, k)
+		}
+	}
+	jobs.Unlock()
+	if err := cache.HSet(jobSchema.Key(job.Id), encodeJob(job), config.Conf.JobTTL); err != nil && !errors.Is(err, cache.ErrUnavailable) {
+		log.Warnf("fail to save job %v: %v", job.Id, err)
+	}
+}
+
+// encodeJob 任务的哈希字段，歌单与导入结果仅在对应步骤完成后写入
+func encodeJob(job *models.Job) map[string]any {
+	fields := map[string]any{
+		"id":         job.Id,
+		"url":        job.Link,
+		"status":     job.Status,
+		"step":       job.Step,
+		"resolved":   job.Resolved,
+		"total":      job.Total,
+		"error":      job.Error,
+		"created_at": job.CreatedAt.Format(time.RFC3339Nano),
+		"updated_at": job.UpdatedAt.Format(time.RFC3339Nano),
+	}
+	if len(job.Links) > 0 {
+		data, _ := json.Marshal(job.Links)
+		fields["urls"] = data
+	}
+	if job.SongList != nil {
+		data, _ := json.Marshal(job.SongList)
+		fields["songlist"] = data
+	}
+	if job.Transfer != nil {
+		data, _ := json.Marshal(job.Transfer)
+		fields["transfer"] = data
+	}
+	return fields
+}
+
+func decodeJob(fields map[string]string) (*models.Job, error) {
+	job := &models.Job{Id: fields["id"], Link: fields["url"], Status: fields["status"], Step: fields["step"], Error: fields["error"]}
+	var err error
+	if job.Resolved, err = strconv.Atoi(fields["resolved"]); err != nil {
+		return nil, err
+	}
+	if job.Total, err = strconv.Atoi(fields["total"]); err != nil {
+		return nil, err
+	}
+	if job.CreatedAt, err = time.Parse(time.RFC3339Nano, fields["created_at"]); err != nil {
+		return nil, err
+	}
+	if job.UpdatedAt, err = time.Parse(time.RFC3339Nano, fields["updated_at"]); err != nil {
+		return nil, err
+	}
+	if data, ok := fields["urls"]; ok {
+		if err = json.Unmarshal([]byte(data), &job.Links); err != nil {
+			return nil, err
+		}
+	}
+	if data, ok := fields["songlist"]; ok {
+		job.SongList = &models.SongList{}
+		if err = json.Unmarshal([]byte(data), job.SongList); err != nil {
+			return nil, err
+		}
+	}
+	if data, ok := fields["transfer"]; ok {
+		job.Transfer = &models.ExportReport{}
+		if err = json.Unmarshal([]byte(data), job.Transfer); err != nil {
+			return nil, err
+		}
+	}
+	return job, nil
+}
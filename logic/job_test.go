@@ -0,0 +1,189 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/format"
+	"GoMusic/common/models"
+	"GoMusic/common/schema"
+)
+
+// jobProvider 分两批写入歌曲的流式平台，第一批写完后等待 release
+type jobProvider struct {
+	linkProvider
+	release chan struct{}
+}
+
+func (p *jobProvider) Stream(ctx context.Context, link string, sink SongSink) error {
+	if err := sink.Begin(&models.SongList{Name: "歌单", SongsCount: 3}); err != nil {
+		return err
+	}
+	for i, v := range []string{"a - b", "c - d", "e - f"} {
+		if err := sink.Song(v, 1000, false); err != nil {
+			return err
+		}
+		name, artist := format.SplitSong(v)
+		if err := track(sink, &models.Song{Name: name, Artists: []string{artist}, DurationMs: 1000}); err != nil {
+			return err
+		}
+		if i == 1 {
+			if err := chunk(sink, 2); err != nil {
+				return err
+			}
+			<-p.release
+		}
+	}
+	return chunk(sink, 3)
+}
+
+func TestJob(t *testing.T) {
+	providerMu.Lock()
+	saved := providers
+	providerMu.Unlock()
+	defer func() {
+		providerMu.Lock()
+		providers = saved
+		providerMu.Unlock()
+	}()
+	provider := &jobProvider{linkProvider: linkProvider{name: "example", hosts: []string{"example.com"}}, release: make(chan struct{})}
+	RegisterProvider(provider)
+
+	_, err := SubmitJob(context.Background(), []string{"https://example.com/1"}, nil)
+	assert.ErrorIs(t, err, ErrJobQueueFull)
+
+	startJobWorkers(1, 1)
+	job, err := SubmitJob(context.Background(), []string{"https://example.com/1"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, models.JobQueued, job.Status)
+
+	// 第一批写完后可查询到进度
+	assert.Eventually(t, func() bool {
+		job, _ = GetJob(job.Id)
+		return job.Resolved == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, models.JobRunning, job.Status)
+	assert.Equal(t, 3, job.Total)
+	assert.Nil(t, job.SongList)
+	// 未完成时等待超时后返回当前进度
+	waited, err := WaitJob(context.Background(), job.Id, 50*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, models.JobRunning, waited.Status)
+
+	close(provider.release)
+	waited, err = WaitJob(context.Background(), job.Id, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, models.JobDone, waited.Status)
+	assert.Eventually(t, func() bool {
+		job, _ = GetJob(job.Id)
+		return job.Status == models.JobDone
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 3, job.Resolved)
+	assert.Equal(t, "歌单", job.SongList.Name)
+	assert.Equal(t, []string{"a - b", "c - d", "e - f"}, job.SongList.Songs)
+	assert.Equal(t, 3000, job.SongList.Summary.DurationMs)
+	assert.Equal(t, &models.Song{Name: "e", Artists: []string{"f"}, DurationMs: 1000}, job.SongList.Tracks[2])
+	assert.NoError(t, schema.ValidateSongList(job.SongList))
+
+	_, err = SubmitJob(context.Background(), []string{"https://unknown.example/1"}, nil)
+	assert.Error(t, err)
+	_, err = SubmitJob(context.Background(), []string{"https://example.com/1"}, &JobTarget{Platform: "unknown", Server: "https://example.com"})
+	assert.Error(t, err)
+}
+
+func TestCompositeJob(t *testing.T) {
+	providerMu.Lock()
+	saved := providers
+	providerMu.Unlock()
+	defer func() {
+		providerMu.Lock()
+		providers = saved
+		providerMu.Unlock()
+	}()
+	RegisterProvider(&linkProvider{name: "composite", hosts: []string{"composite.example"}, discover: func(_ context.Context, link string) (*models.SongList, error) {
+		if link == "https://composite.example/1" {
+			return &models.SongList{Name: "1", Songs: []string{"晴天 - 周杰伦", "a - b"}, SongsCount: 2}, nil
+		}
+		return &models.SongList{Name: "2", Songs: []string{"A - B", "c - d"}, SongsCount: 2}, nil
+	}})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		switch r.URL.Path {
+		case "/rest/ping":
+			_, _ = w.Write([]byte(`{"subsonic-response":{"status":"ok"}}`))
+		case "/rest/search3":
+			_, _ = w.Write([]byte(`{"subsonic-response":{"status":"ok","searchResult3":{"song":[{"id":"s1","title":"晴天","artist":"周杰伦"}]}}}`))
+		case "/rest/createPlaylist":
+			assert.Equal(t, "合并歌单", r.PostForm.Get("name"))
+			_, _ = w.Write([]byte(`{"subsonic-response":{"status":"ok","playlist":{"id":"p1"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	task := &jobTask{
+		Id: newWatchToken(), Link: "https://composite.example/1", Links: []string{"https://composite.example/1", "https://composite.example/2"},
+		Target: &JobTarget{Platform: platformSubsonic, Server: server.URL, Username: "user", Password: "secret"},
+	}
+	saveJob(&models.Job{Id: task.Id, Link: task.Link, Links: task.Links, Status: models.JobQueued, CreatedAt: now, UpdatedAt: now})
+	assert.NoError(t, runJobSteps(context.Background(), task))
+
+	job, err := GetJob(task.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, models.JobDone, job.Status)
+	assert.Equal(t, 4, job.Resolved)
+	// 合并时去重，导入使用合并后的歌单
+	assert.Equal(t, []string{"晴天 - 周杰伦", "a - b", "c - d"}, job.SongList.Songs)
+	if assert.NotNil(t, job.Transfer) {
+		assert.Equal(t, "p1", job.Transfer.PlaylistId)
+		assert.Equal(t, 3, job.Transfer.Total)
+		assert.Equal(t, 1, job.Transfer.Matched)
+	}
+
+	// 账号无效时在获取歌单前失败
+	task.Target.Server = "ftp://example.com"
+	assert.Error(t, runJobSteps(context.Background(), task))
+}
+
+func TestJobFields(t *testing.T) {
+	now := time.Now()
+	job := &models.Job{Id: "1", Link: "https://example.com/1", Status: models.JobRunning, Resolved: 2, Total: 3, CreatedAt: now, UpdatedAt: now}
+	// Redis 以字符串返回哈希字段
+	stringify := func(job *models.Job) map[string]string {
+		fields := map[string]string{}
+		for k, v := range encodeJob(job) {
+			if b, ok := v.([]byte); ok {
+				v = string(b)
+			}
+			fields[k] = fmt.Sprint(v)
+		}
+		return fields
+	}
+	decoded, err := decodeJob(stringify(job))
+	assert.NoError(t, err)
+	assert.True(t, now.Equal(decoded.UpdatedAt))
+	decoded.CreatedAt, decoded.UpdatedAt = now, now
+	assert.Equal(t, job, decoded)
+
+	// 完成后写入歌单
+	job.Status, job.SongList = models.JobDone, &models.SongList{Name: "歌单", Songs: []string{"a - b"}}
+	decoded, err = decodeJob(stringify(job))
+	assert.NoError(t, err)
+	assert.Equal(t, job.SongList, decoded.SongList)
+
+	// 组合任务的歌单链接、步骤与导入结果
+	job.Links, job.Step, job.Transfer = []string{"https://example.com/1", "https://example.com/2"}, models.JobStepTransfer, &models.ExportReport{Platform: "subsonic", Total: 1}
+	decoded, err = decodeJob(stringify(job))
+	assert.NoError(t, err)
+	assert.Equal(t, job.Links, decoded.Links)
+	assert.Equal(t, job.Step, decoded.Step)
+	assert.Equal(t, job.Transfer, decoded.Transfer)
+}
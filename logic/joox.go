@@ -0,0 +1,145 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"GoMusic/common/format"
+	"GoMusic/common/models"
+	"GoMusic/httputil"
+	"GoMusic/initialize/config"
+	"GoMusic/initialize/log"
+)
+
+const (
+	jooxList    = "joox:%v:%v:%v"
+	jooxPattern = `joox\.com/(\w{2})/(?:[\w-]+/)?playlist/([^/?#]+)`
+
+	platformJoox = "joox"
+	// jooxPageSize 每次请求获取的歌曲数
+	jooxPageSize = 50
+	// jooxMaxPages 最多获取的页数
+	jooxMaxPages = 100
+)
+
+var (
+	jooxRegx = regexp.MustCompile(jooxPattern)
+
+	errUnsupportedJooxLink = errors.New("暂不支持该 JOOX 链接，请使用 joox.com/hk/playlist 链接")
+)
+
+func init() {
+	RegisterProvider(&linkProvider{name: platformJoox, hosts: []string{"joox.com"}, discover: JooxDiscover})
+}
+
+// JooxDiscover 获取 JOOX 歌单，如 https://www.joox.com/hk/playlist/ahYb6Bd1EW3Ov+Br+5JZmw==，链接中的地区决定请求的曲库
+func JooxDiscover(ctx context.Context, link string) (*models.SongList, error) {
+	m := jooxRegx.FindStringSubmatch(link)
+	if m == nil {
+		return nil, errUnsupportedJooxLink
+	}
+	id, err := url.PathUnescape(m[2])
+	if err != nil {
+		return nil, errUnsupportedJooxLink
+	}
+	country := strings.ToLower(m[1])
+	// 同一歌单、同一语言的并发请求只向 JOOX 转发一次
+	return shared(ctx, fmt.Sprintf(jooxList, country, id, jooxLang(ctx, country)), func(ctx context.Context) (*models.SongList, error) {
+		return jooxDiscover(ctx, id, country)
+	})
+}
+
+func jooxDiscover(ctx context.Context, id, country string) (*models.SongList, error) {
+	playlist := &models.JooxPlaylist{}
+	if err := jooxRequest(ctx, "/playlist/"+url.PathEscape(id), country, nil, playlist); err != nil {
+		return nil, err
+	}
+	if playlist.ErrorCode != 0 || playlist.Id == "" {
+		log.WithContext(ctx).Errorf("fail to get joox playlist %v, error code: %v", id, playlist.ErrorCode)
+		return nil, errors.New("获取 JOOX 歌单失败，请检查歌单是否存在或在该地区可用")
+	}
+	total := playlist.Tracks.TotalCount
+	items := playlist.Tracks.Items
+	// 后续页的起始位置由上一页返回，只能依次获取
+	next := playlist.Tracks.NextIndex
+	for page := 1; next > 0 && next < total && page < jooxMaxPages; page++ {
+		result := &models.JooxPlaylist{}
+		query := url.Values{"index": {fmt.Sprint(next)}, "num": {fmt.Sprint(jooxPageSize)}}
+		if err := jooxRequest(ctx, "/playlist/"+url.PathEscape(id)+"/tracks", country, query, result); err != nil {
+			return nil, err
+		}
+		if len(result.Tracks.Items) == 0 {
+			break
+		}
+		items = append(items, result.Tracks.Items...)
+		next = result.Tracks.NextIndex
+	}
+
+	tracks := make([]*models.Song, 0, len(items))
+	durations := make([]int, 0, len(items))
+	totalDuration := 0
+	for _, v := range items {
+		artists := make([]string, 0, len(v.ArtistList))
+		for _, v := range v.ArtistList {
+			artists = append(artists, v.Name)
+		}
+		totalDuration += v.PlayDuration * 1000
+		durations = append(durations, v.PlayDuration*1000)
+		tracks = append(tracks, &models.Song{Name: v.Name, Artists: artists, Album: v.AlbumName, DurationMs: v.PlayDuration * 1000, SourceId: v.Id})
+	}
+	songsString := format.Tracks(tracks)
+	cover, width := "", 0
+	for _, v := range playlist.Images {
+		if v.Width >= width {
+			cover, width = v.Url, v.Width
+		}
+	}
+	return &models.SongList{
+		Name:       playlist.Name,
+		Songs:      songsString,
+		Tracks:     tracks,
+		Durations:  durations,
+		SongsCount: total,
+		Cover:      cover,
+		Summary:    format.Summarize(songsString, totalDuration),
+	}, nil
+}
+
+// jooxLang 请求指定的语言优先，JOOX 仅支持简繁中文、英文、泰文、印尼文与马来文；
+// 未指定或不支持时香港地区返回繁体中文，其他地区返回英文
+func jooxLang(ctx context.Context, country string) string {
+	locale := requestLocale(ctx)
+	lang, _, _ := strings.Cut(locale, "-")
+	switch lang {
+	case "zh":
+		if localeScript(locale) == "Hans" {
+			return "zh_CN"
+		}
+		return "zh_TW"
+	case "en", "th", "id", "ms":
+		return lang
+	}
+	if country == "hk" {
+		return "zh_TW"
+	}
+	return "en"
+}
+
+func jooxRequest(ctx context.Context, path, country string, query url.Values, v any) error {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("country", country)
+	query.Set("lang", jooxLang(ctx, country))
+	resp, err := httputil.Get(ctx, config.Conf.Upstream.Joox+path+"?"+query.Encode())
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to get joox playlist: %v", err)
+		return err
+	}
+	defer resp.Body.Close()
+	return decodeBody(resp.Body, v)
+}
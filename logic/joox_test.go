@@ -0,0 +1,47 @@
+package logic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/models"
+	"GoMusic/initialize/config"
+)
+
+func TestJooxDiscover(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "hk", r.URL.Query().Get("country"))
+		assert.Equal(t, "zh_TW", r.URL.Query().Get("lang"))
+		switch r.URL.Path {
+		case "/playlist/ahYb6Bd1EW3Ov+Br+5JZmw==":
+			_, _ = w.Write([]byte(`{"id":"ahYb6Bd1EW3Ov+Br+5JZmw==","name":"測試歌單","images":[{"width":300,"url":"large"},{"width":100,"url":"small"}],
+				"tracks":{"items":[{"id":"s1","name":"晴天","album_name":"葉惠美","artist_list":[{"name":"周杰倫"}],"play_duration":269}],"total_count":2,"next_index":1}}`))
+		case "/playlist/ahYb6Bd1EW3Ov+Br+5JZmw==/tracks":
+			assert.Equal(t, "1", r.URL.Query().Get("index"))
+			_, _ = w.Write([]byte(`{"tracks":{"items":[{"id":"s2","name":"Song","artist_list":[{"name":"A"},{"name":"B"}],"play_duration":60}],"total_count":2,"next_index":0}}`))
+		default:
+			_, _ = w.Write([]byte(`{"error_code":1}`))
+		}
+	}))
+	defer server.Close()
+	upstream := config.Conf.Upstream.Joox
+	config.Conf.Upstream.Joox = server.URL
+	defer func() { config.Conf.Upstream.Joox = upstream }()
+
+	songList, err := JooxDiscover(context.Background(), "https://www.joox.com/hk/playlist/ahYb6Bd1EW3Ov%2BBr%2B5JZmw==")
+	assert.NoError(t, err)
+	assert.Equal(t, "測試歌單", songList.Name)
+	assert.Equal(t, "large", songList.Cover)
+	assert.Equal(t, []string{"晴天 - 周杰倫", "Song - A / B"}, songList.Songs)
+	assert.Equal(t, []int{269000, 60000}, songList.Durations)
+	assert.Equal(t, &models.Song{Name: "晴天", Artists: []string{"周杰倫"}, Album: "葉惠美", DurationMs: 269000, SourceId: "s1"}, songList.Tracks[0])
+
+	_, err = JooxDiscover(context.Background(), "https://www.joox.com/hk/playlist/missing")
+	assert.Error(t, err)
+	_, err = JooxDiscover(context.Background(), "https://www.joox.com/hk/album/xyz")
+	assert.ErrorIs(t, err, errUnsupportedJooxLink)
+}
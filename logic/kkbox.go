@@ -0,0 +1,197 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"GoMusic/common/format"
+	"GoMusic/common/models"
+	"GoMusic/httputil"
+	"GoMusic/initialize/config"
+	"GoMusic/initialize/log"
+)
+
+const (
+	kkboxList    = "kkbox:%v:%v"
+	kkboxPattern = `playlist/([\w-]+)`
+
+	platformKKBOX = "kkbox"
+	// kkboxPageSize 每次请求获取的歌曲数，接口上限为 500
+	kkboxPageSize = 500
+	// kkboxDefaultTerritory 链接中不含地区时使用台湾区
+	kkboxDefaultTerritory = "TW"
+)
+
+var (
+	kkboxRegx = regexp.MustCompile(kkboxPattern)
+	// kkboxTerritories 开放平台支持的地区，链接形如 kkbox.com/tw/tc/playlist/xxx
+	kkboxTerritories = map[string]struct{}{"TW": {}, "HK": {}, "SG": {}, "MY": {}, "JP": {}}
+
+	errUnsupportedKKBOXLink = errors.New("暂不支持该 KKBOX 链接，请使用 kkbox.com/tw/tc/playlist 链接")
+	errKKBOXDisabled        = errors.New("未配置 KKBOX 应用，暂不支持 KKBOX 歌单")
+
+	// kkboxAppToken 应用令牌，过期前复用
+	kkboxAppToken struct {
+		sync.Mutex
+		token    string
+		expireAt time.Time
+	}
+)
+
+func init() {
+	RegisterProvider(&linkProvider{name: platformKKBOX, hosts: []string{"kkbox.com", "kkbox.fm"}, discover: KKBOXDiscover})
+}
+
+// KKBOXDiscover 通过 KKBOX Open API 获取公开歌单，如 https://www.kkbox.com/tw/tc/playlist/4nUZM-TY2aVxZ2xaA-，
+// 也支持 kkbox.fm 短链；歌曲较多时分页并发获取
+func KKBOXDiscover(ctx context.Context, link string) (*models.SongList, error) {
+	if config.Conf.KKBOX.ClientId == "" {
+		return nil, errKKBOXDisabled
+	}
+	if strings.Contains(link, "kkbox.fm") {
+		location, err := httputil.GetRedirectLocation(ctx, link)
+		if err != nil {
+			log.WithContext(ctx).Errorf("fail to get redirect location: %v", err)
+			return nil, err
+		}
+		link = location
+	}
+	id, territory, err := getKKBOXPlaylistId(link)
+	if err != nil {
+		return nil, err
+	}
+	// 同一歌单的并发请求只向 KKBOX 转发一次
+	return shared(ctx, fmt.Sprintf(kkboxList, territory, id), func(ctx context.Context) (*models.SongList, error) {
+		return kkboxDiscover(ctx, id, territory)
+	})
+}
+
+// getKKBOXPlaylistId 解析歌单 id 与地区
+func getKKBOXPlaylistId(link string) (string, string, error) {
+	m := kkboxRegx.FindStringSubmatch(link)
+	if m == nil {
+		return "", "", errUnsupportedKKBOXLink
+	}
+	territory := kkboxDefaultTerritory
+	if parse, err := url.Parse(strings.TrimSpace(link)); err == nil {
+		if segment, _, _ := strings.Cut(strings.TrimPrefix(parse.Path, "/"), "/"); segment != "" {
+			if _, ok := kkboxTerritories[strings.ToUpper(segment)]; ok {
+				territory = strings.ToUpper(segment)
+			}
+		}
+	}
+	return m[1], territory, nil
+}
+
+func kkboxDiscover(ctx context.Context, id, territory string) (*models.SongList, error) {
+	playlist := &models.KKBOXPlaylist{}
+	if err := kkboxRequest(ctx, fmt.Sprintf("/shared-playlists/%v?territory=%v", id, territory), playlist); err != nil {
+		return nil, err
+	}
+	total := playlist.Tracks.Summary.Total
+	pages := make([]*models.KKBOXTracks, (total+kkboxPageSize-1)/kkboxPageSize)
+	group, groupCtx := chunkGroup(ctx, platformKKBOX)
+	for i := range pages {
+		i := i
+		group.Go(func() error {
+			return limitChunk(groupCtx, platformKKBOX, func(ctx context.Context) error {
+				page := &models.KKBOXTracks{}
+				path := fmt.Sprintf("/shared-playlists/%v/tracks?territory=%v&offset=%d&limit=%d", id, territory, i*kkboxPageSize, kkboxPageSize)
+				if err := kkboxRequest(ctx, path, page); err != nil {
+					return err
+				}
+				pages[i] = page
+				return nil
+			})
+		})
+	}
+	if err := group.Wait(); err != nil {
+		log.WithContext(ctx).Errorf("fail to wait: %v", err)
+		return nil, err
+	}
+
+	tracks := make([]*models.Song, 0, total)
+	durations := make([]int, 0, total)
+	totalDuration := 0
+	for _, page := range pages {
+		for _, v := range page.Data {
+			totalDuration += v.Duration
+			durations = append(durations, v.Duration)
+			tracks = append(tracks, &models.Song{
+				Name: v.Name, Artists: []string{v.Album.Artist.Name}, Album: v.Album.Name, DurationMs: v.Duration, SourceId: v.Id,
+			})
+		}
+	}
+	songsString := format.Tracks(tracks)
+	cover := ""
+	if len(playlist.Images) > 0 {
+		cover = playlist.Images[len(playlist.Images)-1].Url
+	}
+	return &models.SongList{
+		Name:        playlist.Title,
+		Songs:       songsString,
+		Tracks:      tracks,
+		Durations:   durations,
+		SongsCount:  total,
+		Cover:       cover,
+		Description: playlist.Description,
+		Summary:     format.Summarize(songsString, totalDuration),
+	}, nil
+}
+
+func kkboxRequest(ctx context.Context, path string, v any) error {
+	token, err := kkboxToken(ctx)
+	if err != nil {
+		return err
+	}
+	resp, err := httputil.GetWithHeader(ctx, config.Conf.Upstream.KKBOXApi+path, http.Header{"Authorization": {"Bearer " + token}})
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to get kkbox playlist: %v", err)
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.WithContext(ctx).Errorf("fail to get kkbox %v: %v", path, resp.StatusCode)
+		return errors.New("获取 KKBOX 歌单失败，请检查歌单是否存在或已设为私密")
+	}
+	return decodeBody(resp.Body, v)
+}
+
+// kkboxToken client credentials 模式获取应用令牌，提前一分钟刷新
+func kkboxToken(ctx context.Context) (string, error) {
+	kkboxAppToken.Lock()
+	defer kkboxAppToken.Unlock()
+	if kkboxAppToken.token != "" && time.Now().Before(kkboxAppToken.expireAt) {
+		return kkboxAppToken.token, nil
+	}
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {config.Conf.KKBOX.ClientId},
+		"client_secret": {config.Conf.KKBOX.ClientSecret},
+	}
+	header := http.Header{"Content-Type": {"application/x-www-form-urlencoded"}}
+	resp, err := httputil.Do(ctx, "POST", config.Conf.Upstream.KKBOXAccounts+"/token", header, strings.NewReader(form.Encode()))
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to get kkbox token: %v", err)
+		return "", err
+	}
+	defer resp.Body.Close()
+	token := &models.KKBOXToken{}
+	if err = decodeBody(resp.Body, token); err != nil {
+		return "", err
+	}
+	if token.AccessToken == "" {
+		log.WithContext(ctx).Errorf("fail to get kkbox token: %v", token.Error)
+		return "", errors.New("KKBOX 应用授权失败，请检查 Client ID 与 Client Secret")
+	}
+	kkboxAppToken.token = token.AccessToken
+	kkboxAppToken.expireAt = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+	return token.AccessToken, nil
+}
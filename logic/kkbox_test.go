@@ -0,0 +1,73 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/models"
+	"GoMusic/initialize/config"
+)
+
+func TestKKBOXDiscover(t *testing.T) {
+	tokens := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth2/token" {
+			tokens++
+			assert.NoError(t, r.ParseForm())
+			assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+			_, _ = w.Write([]byte(`{"access_token":"token","expires_in":3600}`))
+			return
+		}
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, "HK", r.URL.Query().Get("territory"))
+		switch r.URL.Path {
+		case "/v1.1/shared-playlists/4nUZM-TY2aVxZ2xaA-":
+			_, _ = w.Write([]byte(`{"id":"4nUZM-TY2aVxZ2xaA-","title":"測試歌單","description":"簡介",
+				"images":[{"url":"small"},{"url":"large"}],"tracks":{"summary":{"total":501}}}`))
+		case "/v1.1/shared-playlists/4nUZM-TY2aVxZ2xaA-/tracks":
+			// 共 501 首，第二页仅剩一首
+			songs := `{"id":"t1","name":"晴天","duration":269000,"album":{"name":"葉惠美","artist":{"name":"周杰倫"}}}`
+			if r.URL.Query().Get("offset") == "0" {
+				for i := 1; i < kkboxPageSize; i++ {
+					songs += fmt.Sprintf(`,{"id":"t%d","name":"歌曲%d","duration":60000,"album":{"artist":{"name":"A"}}}`, i+1, i)
+				}
+			}
+			_, _ = fmt.Fprintf(w, `{"data":[%s]}`, songs)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	upstream, kkbox := config.Conf.Upstream, config.Conf.KKBOX
+	config.Conf.Upstream.KKBOXAccounts, config.Conf.Upstream.KKBOXApi = server.URL+"/oauth2", server.URL+"/v1.1"
+	config.Conf.KKBOX = config.KKBOX{ClientId: "id", ClientSecret: "secret"}
+	defer func() { config.Conf.Upstream, config.Conf.KKBOX = upstream, kkbox }()
+
+	songList, err := KKBOXDiscover(context.Background(), "https://www.kkbox.com/hk/tc/playlist/4nUZM-TY2aVxZ2xaA-")
+	assert.NoError(t, err)
+	assert.Equal(t, "測試歌單", songList.Name)
+	assert.Equal(t, "large", songList.Cover)
+	assert.Len(t, songList.Songs, 501)
+	assert.Equal(t, "晴天 - 周杰倫", songList.Songs[0])
+	assert.Equal(t, "晴天 - 周杰倫", songList.Songs[500])
+	assert.Equal(t, &models.Song{Name: "晴天", Artists: []string{"周杰倫"}, Album: "葉惠美", DurationMs: 269000, SourceId: "t1"}, songList.Tracks[0])
+	// 令牌在过期前复用
+	assert.Equal(t, 1, tokens)
+
+	_, err = KKBOXDiscover(context.Background(), "https://www.kkbox.com/tw/tc/artist/xyz")
+	assert.ErrorIs(t, err, errUnsupportedKKBOXLink)
+}
+
+func TestGetKKBOXPlaylistId(t *testing.T) {
+	id, territory, err := getKKBOXPlaylistId("https://play.kkbox.com/playlist/OsyOUl0kIHTUGQvpCD")
+	assert.NoError(t, err)
+	assert.Equal(t, "OsyOUl0kIHTUGQvpCD", id)
+	assert.Equal(t, "TW", territory)
+	_, territory, _ = getKKBOXPlaylistId("https://www.kkbox.com/sg/en/playlist/OsyOUl0kIHTUGQvpCD")
+	assert.Equal(t, "SG", territory)
+}
@@ -1,11 +1,21 @@
 package logic
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"net/url"
 	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
+	"GoMusic/common/format"
 	"GoMusic/common/models"
+	"GoMusic/common/utils"
 	"GoMusic/httputil"
+	"GoMusic/initialize/config"
 	"GoMusic/initialize/log"
 )
 
@@ -13,37 +23,195 @@ const (
 	KuGouShort = `t1\.kugou`
 	KuGouPC    = `wwwapi\.kugou`
 	KuGouPhone = `m\.kugou`
+
+	kugouList    = "kugou:%v"
+	kugouSpecial = `(?:special/single|plist/list)/(\d+)`
+
+	platformKugou = "kugou"
+	// kugouPageSize 每次请求获取的歌曲数，接口上限为 300
+	kugouPageSize = 300
 )
 
 var (
-	KuGouShortRegx = regexp.MustCompile(KuGouShort)
-	KuGouPCRegx    = regexp.MustCompile(KuGouPC)
-	KuGouPhoneRegx = regexp.MustCompile(KuGouPhone)
+	KuGouShortRegx   = regexp.MustCompile(KuGouShort)
+	KuGouPCRegx      = regexp.MustCompile(KuGouPC)
+	KuGouPhoneRegx   = regexp.MustCompile(KuGouPhone)
+	kugouSpecialRegx = regexp.MustCompile(kugouSpecial)
+
+	errUnsupportedKugouLink = errors.New("暂不支持该酷狗歌单链接，请使用网页版 www.kugou.com/yy/special 链接")
 )
 
+func init() {
+	RegisterProvider(&linkProvider{name: platformKugou, hosts: []string{"kugou.com"}, discover: KugouDiscover})
+}
+
+// 网页版：https://www.kugou.com/yy/special/single/546903.html
 // 短链：https://t1.kugou.com/aRgNRccBhV2
 // http://wwwapi.kugou.com/share/zlist.html
-// https://m.kugou.com/songlist/gcid_3zbivkavz4z072
+// https://m.kugou.com/plist/list/546903
+
+// KugouDiscover 获取酷狗歌单，歌曲较多时分页并发获取
+func KugouDiscover(ctx context.Context, link string) (*models.SongList, error) {
+	specialId, err := getKugouSpecialId(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	// 同一歌单的并发请求只向酷狗转发一次
+	return shared(ctx, fmt.Sprintf(kugouList, specialId), func(ctx context.Context) (*models.SongList, error) {
+		return kugouDiscover(ctx, specialId)
+	})
+}
+
+func kugouDiscover(ctx context.Context, specialId string) (*models.SongList, error) {
+	info, err := getKugouSpecialInfo(ctx, specialId)
+	if err != nil {
+		return nil, err
+	}
+	pages := make([][]*models.KugouSong, (info.Data.SongCount+kugouPageSize-1)/kugouPageSize)
+	group, groupCtx := chunkGroup(ctx, platformKugou)
+	for i := range pages {
+		i := i
+		group.Go(func() error {
+			return limitChunk(groupCtx, platformKugou, func(ctx context.Context) error {
+				page, err := getKugouPage(ctx, specialId, i+1)
+				if err != nil {
+					return err
+				}
+				pages[i] = page.Data.Info
+				return nil
+			})
+		})
+	}
+	if err = group.Wait(); err != nil {
+		log.WithContext(ctx).Errorf("fail to wait: %v", err)
+		return nil, err
+	}
+
+	tracks := make([]*models.Song, 0, info.Data.SongCount)
+	durations := make([]int, 0, info.Data.SongCount)
+	totalDuration := 0
+	for _, page := range pages {
+		for _, v := range page {
+			totalDuration += v.Timelen
+			durations = append(durations, v.Timelen)
+			name, authors := kugouSongName(v)
+			tracks = append(tracks, &models.Song{Name: name, Artists: authors, Album: v.AlbumInfo.Name, DurationMs: v.Timelen, SourceId: v.Hash})
+		}
+	}
+	songsString := format.Tracks(tracks)
+	tags := make([]string, 0, len(info.Data.Tags))
+	for _, v := range info.Data.Tags {
+		tags = append(tags, v.TagName)
+	}
+	return &models.SongList{
+		Name:        info.Data.SpecialName,
+		Songs:       songsString,
+		Tracks:      tracks,
+		Durations:   durations,
+		SongsCount:  info.Data.SongCount,
+		Cover:       strings.ReplaceAll(info.Data.ImgUrl, "{size}", "400"),
+		Description: info.Data.Intro,
+		Tags:        tags,
+		Summary:     format.Summarize(songsString, totalDuration),
+	}, nil
+}
+
+// kugouSongName 酷狗返回的歌名为“歌手 - 歌名”，优先使用 singerinfo 中的歌手
+func kugouSongName(song *models.KugouSong) (string, []string) {
+	singers, name, ok := strings.Cut(song.Name, " - ")
+	if !ok {
+		name, singers = song.Name, ""
+	}
+	authors := make([]string, 0, len(song.SingerInfo))
+	for _, v := range song.SingerInfo {
+		authors = append(authors, v.Name)
+	}
+	if len(authors) == 0 && singers != "" {
+		authors = strings.Split(singers, "、")
+	}
+	return name, authors
+}
+
+func getKugouSpecialInfo(ctx context.Context, specialId string) (*models.KugouSpecialInfo, error) {
+	resp, err := httputil.Get(ctx, config.Conf.Upstream.KugouSpecial+"?specialid="+specialId)
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to get kugou special info: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	info := &models.KugouSpecialInfo{}
+	if err = decodeBody(resp.Body, info); err != nil {
+		return nil, err
+	}
+	if info.Status != 1 {
+		log.WithContext(ctx).Errorf("fail to get kugou special %v, status: %v", specialId, info.Status)
+		return nil, errors.New("获取酷狗歌单失败，请检查歌单是否存在或已设为私密")
+	}
+	return info, nil
+}
 
-func KuGouDiscover(link string) (*models.SongList, error) {
+// getKugouPage 获取歌单的第 page 页歌曲，page 从 1 开始
+func getKugouPage(ctx context.Context, specialId string, page int) (*models.KugouSongs, error) {
+	query := fmt.Sprintf("specialid=%s&need_sort=1&module=CloudMusic&clientver=11239&pagesize=%d&userid=0&page=%d&type=0&area_code=1&appid=1005",
+		specialId, kugouPageSize, page)
+	header := http.Header{
+		"User-Agent": []string{"Android9-AndroidPhone-11239-18-0-playlist-wifi"},
+		"X-Router":   []string{"pubsongscdn.kugou.com"},
+		"Mid":        []string{"239526275778893399526700786998289824956"},
+		"Dfid":       []string{"-"},
+		"Clienttime": []string{strconv.FormatInt(time.Now().Unix(), 10)},
+	}
+	link := config.Conf.Upstream.KugouSongs + "?" + query + "&signature=" + utils.KugouSignature(query)
+	resp, err := httputil.GetWithHeader(ctx, link, header)
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to get kugou songs: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	songs := &models.KugouSongs{}
+	if err = decodeBody(resp.Body, songs); err != nil {
+		return nil, err
+	}
+	if songs.Status != 1 {
+		log.WithContext(ctx).Errorf("fail to get kugou songs %v, error code: %v", specialId, songs.ErrorCode)
+		return nil, fmt.Errorf("获取酷狗歌单失败，错误码：%d", songs.ErrorCode)
+	}
+	return songs, nil
+}
 
-	return nil, nil
+// getKugouSpecialId 解析歌单 id，分享链接先获取跳转地址
+func getKugouSpecialId(ctx context.Context, link string) (string, error) {
+	link, err := getRealUrl(ctx, link)
+	if err != nil {
+		return "", err
+	}
+	if m := kugouSpecialRegx.FindStringSubmatch(link); m != nil {
+		return m[1], nil
+	}
+	if parse, err := url.Parse(link); err == nil {
+		if id := parse.Query().Get("specialid"); id != "" {
+			if _, err = strconv.Atoi(id); err == nil {
+				return id, nil
+			}
+		}
+	}
+	return "", errUnsupportedKugouLink
 }
 
-func getRealUrl(link string) (string, error) {
+func getRealUrl(ctx context.Context, link string) (string, error) {
 	var err error
 
 	// 如果是长链附加短链的形式
 	if KuGouPCRegx.MatchString(link) {
 		query, err := url.ParseQuery(link)
 		if err != nil {
-			log.Error("fail to parse query: %v", err)
+			log.WithContext(ctx).Error("fail to parse query: %v", err)
 			return "", err
 		}
 
 		chain := query.Get("chain")
 		if chain == "" {
-			log.Error("error param, chain is empty")
+			log.WithContext(ctx).Error("error param, chain is empty")
 			return "", err
 		}
 		link = "https://t1.kugou.com/" + chain
@@ -51,9 +219,9 @@ func getRealUrl(link string) (string, error) {
 
 	// 如果是短链
 	if KuGouShortRegx.MatchString(link) {
-		link, err = httputil.GetRedirectLocation(link)
+		link, err = httputil.GetRedirectLocation(ctx, link)
 		if err != nil {
-			log.Error("fail to get redirect location: %v", err)
+			log.WithContext(ctx).Error("fail to get redirect location: %v", err)
 			return "", err
 		}
 	}
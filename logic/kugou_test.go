@@ -1,8 +1,13 @@
 package logic
 
 import (
+	"context"
 	"fmt"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/models"
 )
 
 // https://t1.kugou.com/aRgNRccBhV2
@@ -27,7 +32,35 @@ func TestKuGouRegex(t *testing.T) {
 }
 
 func TestGetRealUrl(t *testing.T) {
-	fmt.Println(getRealUrl(UrlShort))
-	fmt.Println(getRealUrl(UrlPc))
-	fmt.Println(getRealUrl(UrlPhone))
+	fmt.Println(getRealUrl(context.Background(), UrlShort))
+	fmt.Println(getRealUrl(context.Background(), UrlPc))
+	fmt.Println(getRealUrl(context.Background(), UrlPhone))
+}
+
+func TestGetKugouSpecialId(t *testing.T) {
+	for link, want := range map[string]string{
+		"https://www.kugou.com/yy/special/single/546903.html":          "546903",
+		"https://m.kugou.com/plist/list/546903?uid=1":                  "546903",
+		"https://m.kugou.com/share/zlist.html?specialid=546903&type=0": "546903",
+	} {
+		id, err := getKugouSpecialId(context.Background(), link)
+		assert.NoError(t, err, link)
+		assert.Equal(t, want, id, link)
+	}
+	_, err := getKugouSpecialId(context.Background(), UrlPhone)
+	assert.ErrorIs(t, err, errUnsupportedKugouLink)
+}
+
+func TestKugouSongName(t *testing.T) {
+	name, authors := kugouSongName(&models.KugouSong{Name: "周杰伦、费玉清 - 千里之外"})
+	assert.Equal(t, "千里之外", name)
+	assert.Equal(t, []string{"周杰伦", "费玉清"}, authors)
+
+	song := &models.KugouSong{Name: "周杰伦 - 晴天"}
+	song.SingerInfo = append(song.SingerInfo, struct {
+		Name string `json:"name"`
+	}{Name: "Jay Chou"})
+	name, authors = kugouSongName(song)
+	assert.Equal(t, "晴天", name)
+	assert.Equal(t, []string{"Jay Chou"}, authors)
 }
@@ -0,0 +1,131 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"GoMusic/common/format"
+	"GoMusic/common/models"
+	"GoMusic/httputil"
+	"GoMusic/initialize/config"
+	"GoMusic/initialize/log"
+)
+
+const (
+	kuwoList    = "kuwo:%v"
+	kuwoPattern = `playlist_detail/(\d+)`
+
+	platformKuwo = "kuwo"
+	// kuwoPageSize 每次请求获取的歌曲数
+	kuwoPageSize = 100
+)
+
+var (
+	kuwoRegx = regexp.MustCompile(kuwoPattern)
+
+	errUnsupportedKuwoLink = errors.New("暂不支持该酷我歌单链接，请使用 kuwo.cn/playlist_detail 链接")
+)
+
+func init() {
+	RegisterProvider(&linkProvider{name: platformKuwo, hosts: []string{"kuwo.cn"}, discover: KuwoDiscover})
+}
+
+// KuwoDiscover 获取酷我歌单，如 https://www.kuwo.cn/playlist_detail/3462559430，歌曲较多时分页并发获取
+func KuwoDiscover(ctx context.Context, link string) (*models.SongList, error) {
+	m := kuwoRegx.FindStringSubmatch(link)
+	if m == nil {
+		return nil, errUnsupportedKuwoLink
+	}
+	// 同一歌单的并发请求只向酷我转发一次
+	return shared(ctx, fmt.Sprintf(kuwoList, m[1]), func(ctx context.Context) (*models.SongList, error) {
+		return kuwoDiscover(ctx, m[1])
+	})
+}
+
+func kuwoDiscover(ctx context.Context, pid string) (*models.SongList, error) {
+	// 首页同时返回歌单信息与歌曲总数
+	first, err := getKuwoPage(ctx, pid, 0)
+	if err != nil {
+		return nil, err
+	}
+	pages := make([][]*models.KuwoSong, (first.Total+kuwoPageSize-1)/kuwoPageSize)
+	if len(pages) == 0 {
+		pages = append(pages, nil)
+	}
+	pages[0] = first.MusicList
+
+	group, groupCtx := chunkGroup(ctx, platformKuwo)
+	for i := 1; i < len(pages); i++ {
+		i := i
+		group.Go(func() error {
+			return limitChunk(groupCtx, platformKuwo, func(ctx context.Context) error {
+				page, err := getKuwoPage(ctx, pid, i)
+				if err != nil {
+					return err
+				}
+				pages[i] = page.MusicList
+				return nil
+			})
+		})
+	}
+	if err = group.Wait(); err != nil {
+		log.WithContext(ctx).Errorf("fail to wait: %v", err)
+		return nil, err
+	}
+
+	tracks := make([]*models.Song, 0, first.Total)
+	durations := make([]int, 0, first.Total)
+	totalDuration := 0
+	for _, page := range pages {
+		for _, v := range page {
+			seconds, _ := v.Duration.Int64()
+			totalDuration += int(seconds) * 1000
+			durations = append(durations, int(seconds)*1000)
+			tracks = append(tracks, &models.Song{
+				Name: v.Name, Artists: strings.Split(v.Artist, "&"), Album: v.Album, DurationMs: int(seconds) * 1000, SourceId: v.Id.String(),
+			})
+		}
+	}
+	songsString := format.Tracks(tracks)
+	tags := make([]string, 0)
+	for _, v := range strings.Split(first.Tag, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			tags = append(tags, v)
+		}
+	}
+	return &models.SongList{
+		Name:        first.Title,
+		Songs:       songsString,
+		Tracks:      tracks,
+		Durations:   durations,
+		SongsCount:  first.Total,
+		Cover:       first.Pic,
+		Description: first.Info,
+		Tags:        tags,
+		Summary:     format.Summarize(songsString, totalDuration),
+	}, nil
+}
+
+// getKuwoPage 获取歌单的第 page 页歌曲，page 从 0 开始
+func getKuwoPage(ctx context.Context, pid string, page int) (*models.KuwoPlaylist, error) {
+	link := fmt.Sprintf("%v?op=getlistinfo&pid=%v&pn=%d&rn=%d&encode=utf8&keyset=pl2012&identity=kuwo&vipver=MUSIC_9.0.5.0_W1&newver=1",
+		config.Conf.Upstream.Kuwo, pid, page, kuwoPageSize)
+	resp, err := httputil.Get(ctx, link)
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to get kuwo playlist: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	playlist := &models.KuwoPlaylist{}
+	if err = decodeBody(resp.Body, playlist); err != nil {
+		return nil, err
+	}
+	if playlist.Result != "ok" {
+		log.WithContext(ctx).Errorf("fail to get kuwo playlist %v, result: %v", pid, playlist.Result)
+		return nil, errors.New("获取酷我歌单失败，请检查歌单是否存在")
+	}
+	return playlist, nil
+}
@@ -0,0 +1,49 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/models"
+	"GoMusic/initialize/config"
+)
+
+func TestKuwoDiscover(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 共 101 首，第二页仅剩一首
+		songs := `[{"id":"1","name":"晴天","artist":"周杰伦","album":"叶惠美","duration":"269"}]`
+		if r.URL.Query().Get("pn") == "0" {
+			songs = "[" + songs[1:len(songs)-1]
+			for i := 1; i < kuwoPageSize; i++ {
+				songs += fmt.Sprintf(`,{"name":"歌曲%d","artist":"A&B","duration":60}`, i)
+			}
+			songs += "]"
+		}
+		_, _ = fmt.Fprintf(w, `{"result":"ok","title":"测试歌单","info":"简介","tag":"华语,流行","total":101,"musiclist":%s}`, songs)
+	}))
+	defer server.Close()
+	upstream := config.Conf.Upstream.Kuwo
+	config.Conf.Upstream.Kuwo = server.URL
+	defer func() { config.Conf.Upstream.Kuwo = upstream }()
+
+	songList, err := KuwoDiscover(context.Background(), "https://www.kuwo.cn/playlist_detail/3462559430")
+	assert.NoError(t, err)
+	assert.Equal(t, "测试歌单", songList.Name)
+	assert.Equal(t, 101, songList.SongsCount)
+	assert.Len(t, songList.Songs, 101)
+	assert.Equal(t, "晴天 - 周杰伦", songList.Songs[0])
+	assert.Equal(t, "歌曲1 - A / B", songList.Songs[1])
+	assert.Equal(t, "晴天 - 周杰伦", songList.Songs[100])
+	assert.Equal(t, 269000, songList.Durations[0])
+	assert.Len(t, songList.Tracks, 101)
+	assert.Equal(t, &models.Song{Name: "晴天", Artists: []string{"周杰伦"}, Album: "叶惠美", DurationMs: 269000, SourceId: "1"}, songList.Tracks[0])
+	assert.Equal(t, []string{"华语", "流行"}, songList.Tags)
+
+	_, err = KuwoDiscover(context.Background(), "https://www.kuwo.cn/singer_detail/336")
+	assert.ErrorIs(t, err, errUnsupportedKuwoLink)
+}
@@ -0,0 +1,96 @@
+package logic
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"GoMusic/initialize/config"
+)
+
+var localeRegx = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+type localeKey struct{}
+
+// WithLocale 返回携带用户偏好语言的 ctx，locale 为 BCP 47 语言标签，如 zh-CN、zh-Hant-TW、en-US，
+// 支持本地化元数据的平台据此返回对应语言的歌名与歌手名；无效或为空时使用配置的默认语言
+func WithLocale(ctx context.Context, locale string) context.Context {
+	if locale = normalizeLocale(locale); locale == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// requestLocale 请求的偏好语言，未指定时为配置的默认语言，均为空时返回空字符串，由平台使用默认语言
+func requestLocale(ctx context.Context) string {
+	if locale, _ := ctx.Value(localeKey{}).(string); locale != "" {
+		return locale
+	}
+	return normalizeLocale(config.Conf.DefaultLocale)
+}
+
+// normalizeLocale 规范语言标签的大小写：语言小写、文字首字母大写、地区大写，如 zh_hant_tw 规范为 zh-Hant-TW
+func normalizeLocale(locale string) string {
+	locale = strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+	if !localeRegx.MatchString(locale) {
+		return ""
+	}
+	parts := strings.Split(locale, "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		switch len(parts[i]) {
+		case 2:
+			parts[i] = strings.ToUpper(parts[i])
+		case 4:
+			parts[i] = strings.ToUpper(parts[i][:1]) + strings.ToLower(parts[i][1:])
+		default:
+			parts[i] = strings.ToLower(parts[i])
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// matchLocale 在平台支持的语言中选择与 locale 最接近的一个：先完全匹配，再按语言与文字匹配，最后按语言匹配；
+// 均不匹配时返回空字符串
+func matchLocale(locale string, supported []string) string {
+	if locale == "" {
+		return ""
+	}
+	for _, v := range supported {
+		if strings.EqualFold(v, locale) {
+			return v
+		}
+	}
+	script := localeScript(locale)
+	lang, _, _ := strings.Cut(locale, "-")
+	for _, v := range supported {
+		if l, _, _ := strings.Cut(v, "-"); strings.EqualFold(l, lang) && localeScript(normalizeLocale(v)) == script {
+			return v
+		}
+	}
+	for _, v := range supported {
+		if l, _, _ := strings.Cut(v, "-"); strings.EqualFold(l, lang) {
+			return v
+		}
+	}
+	return ""
+}
+
+// localeScript 语言标签的文字，中文未标注文字时按地区推断：台湾、香港、澳门为繁体，其余为简体
+func localeScript(locale string) string {
+	parts := strings.Split(locale, "-")
+	for _, v := range parts[1:] {
+		if len(v) == 4 {
+			return v
+		}
+	}
+	if parts[0] != "zh" {
+		return ""
+	}
+	for _, v := range parts[1:] {
+		if v == "TW" || v == "HK" || v == "MO" {
+			return "Hant"
+		}
+	}
+	return "Hans"
+}
@@ -0,0 +1,49 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/initialize/config"
+)
+
+func TestNormalizeLocale(t *testing.T) {
+	assert.Equal(t, "zh-Hant-TW", normalizeLocale("zh_hant_tw"))
+	assert.Equal(t, "en-US", normalizeLocale(" EN-us "))
+	assert.Equal(t, "ja", normalizeLocale("ja"))
+	assert.Equal(t, "", normalizeLocale("zh-CN;q=0.9"))
+	assert.Equal(t, "", normalizeLocale(""))
+}
+
+func TestMatchLocale(t *testing.T) {
+	supported := []string{"en-GB", "zh-Hans-CN", "zh-Hant-HK"}
+	assert.Equal(t, "zh-Hant-HK", matchLocale("zh-TW", supported))
+	assert.Equal(t, "zh-Hans-CN", matchLocale("zh-SG", supported))
+	assert.Equal(t, "en-GB", matchLocale("en-US", supported))
+	assert.Equal(t, "en-GB", matchLocale("en-GB", supported))
+	assert.Equal(t, "", matchLocale("ja", supported))
+	assert.Equal(t, "", matchLocale("", supported))
+}
+
+func TestRequestLocale(t *testing.T) {
+	defaultLocale := config.Conf.DefaultLocale
+	defer func() { config.Conf.DefaultLocale = defaultLocale }()
+
+	config.Conf.DefaultLocale = ""
+	assert.Equal(t, "zh-CN", youtubeMusicHl(context.Background()))
+	assert.Equal(t, "zh_TW", jooxLang(context.Background(), "hk"))
+	assert.Equal(t, "en", jooxLang(context.Background(), "th"))
+
+	ctx := WithLocale(context.Background(), "zh-Hant-HK")
+	assert.Equal(t, "zh-TW", youtubeMusicHl(ctx))
+	assert.Equal(t, "en-GB", youtubeMusicHl(WithLocale(context.Background(), "en_gb")))
+	assert.Equal(t, "th", jooxLang(WithLocale(context.Background(), "th-TH"), "th"))
+	assert.Equal(t, "zh_CN", jooxLang(WithLocale(context.Background(), "zh-CN"), "hk"))
+
+	// 无效的 locale 被忽略，使用默认语言
+	config.Conf.DefaultLocale = "ja-JP"
+	assert.Equal(t, "ja-JP", requestLocale(WithLocale(context.Background(), "bad locale")))
+	assert.Equal(t, "ja-JP", youtubeMusicHl(context.Background()))
+}
@@ -1,159 +1,280 @@
 package logic
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
+	"net/http"
+	"strconv"
 	"strings"
-	"sync"
-
-	"golang.org/x/sync/errgroup"
 
+	"GoMusic/common/format"
 	"GoMusic/common/utils"
+	"GoMusic/httputil"
+	"GoMusic/initialize/config"
 	"GoMusic/initialize/log"
-	"GoMusic/repo/db"
+	"GoMusic/initialize/trace"
 
 	"GoMusic/common/models"
-	"GoMusic/httputil"
 	"GoMusic/repo/cache"
+	"GoMusic/repo/songrepo"
 )
 
 const (
-	netEasyRedis = "net:%v"
-	netEasyUrlV6 = "https://music.163.com/api/v6/playlist/detail"
-	netEasyUrlV3 = "https://music.163.com/api/v3/song/detail"
-	chunkSize    = 500
+	netEasyList = "net_list:%v"
+	chunkSize   = 500
+
+	platformNetEasy = "netease"
 )
 
+// netEasyPlaylistCache 组装好的歌单，key 含歌曲数与修改时间，歌单变化后自然失效
+var netEasyPlaylistCache = cache.NewSchema("net_playlist", 1)
+
+func init() {
+	RegisterProvider(&netEasyProvider{linkProvider{name: platformNetEasy, hosts: []string{"163cn", "163.com"}, discover: NetEasyDiscover}})
+	songrepo.Register(platformNetEasy, &songrepo.Source{Schema: cache.SongSchema, Fetch: batchGetSongs, Persist: true})
+}
+
 // NetEasyDiscover 需转发 2~3 次请求
-func NetEasyDiscover(link string) (*models.SongList, error) {
+func NetEasyDiscover(ctx context.Context, link string) (*models.SongList, error) {
+	songListId, err := utils.GetNetEasyParam(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	// 携带用户 cookie 的请求可能获取到私密歌单，不与其他请求合并
+	if netEasyMusicU(ctx) != "" {
+		return netEasyDiscover(ctx, link, songListId)
+	}
+	// 同一歌单的并发请求只向网易云转发一次
+	return shared(ctx, fmt.Sprintf(netEasyList, songListId), func(ctx context.Context) (*models.SongList, error) {
+		return netEasyDiscover(ctx, link, songListId)
+	})
+}
+
+func netEasyDiscover(ctx context.Context, link, songListId string) (*models.SongList, error) {
+	ctx = log.WithFields(ctx, "playlist", fmt.Sprintf(netEasyList, songListId))
 	// 批量获取歌单信息：歌单名、歌曲ids、歌曲总数
-	SongIdsResp, err := getSongsInfo(link)
+	SongIdsResp, err := getSongsInfo(ctx, songListId)
 	if err != nil {
 		return nil, err
 	}
 
+	cacheKey := netEasyPlaylistCache.Key(fmt.Sprintf("%v:%d:%d", songListId, SongIdsResp.Playlist.TrackCount, SongIdsResp.Playlist.UpdateTime))
+	if songList := getCachedPlaylist(cacheKey); songList != nil {
+		return songList, nil
+	}
+
 	SongsListName := SongIdsResp.Playlist.Name     // 歌单名
+	cover := SongIdsResp.Playlist.CoverImgUrl      // 歌单封面
 	trackIds := SongIdsResp.Playlist.TrackIds      // 歌曲列表
 	tracksCount := SongIdsResp.Playlist.TrackCount // 歌曲总数
 
-	songCacheKey := make([]string, 0, len(trackIds))
+	ids := make([]uint, 0, len(trackIds))
 	for _, v := range trackIds {
-		songCacheKey = append(songCacheKey, fmt.Sprintf(netEasyRedis, v.Id))
+		ids = append(ids, v.Id)
+	}
+	songs, err := songrepo.GetSongs(ctx, platformNetEasy, ids)
+	if err != nil {
+		return nil, err
 	}
 
-	resultMap := sync.Map{} // 结果
+	collector := models.NewSongCollector(len(songs))
+	collector.Merge(songs)
+	songList := NewSongList(SongsListName, trackIds, collector, tracksCount, cover)
+	songList.Description = SongIdsResp.Playlist.Description
+	songList.Tags = SongIdsResp.Playlist.Tags
+	setCachedPlaylist(cacheKey, songList)
+	return songList, nil
+}
 
-	// 1、尝试获取缓存，失败不退出
-	cacheResult, _ := cache.MGet(songCacheKey...)
+// cachedPlaylist 缓存的歌单，Durations 与 Explicit 在 SongList 中不参与 JSON 序列化，单独保存
+type cachedPlaylist struct {
+	*models.SongList
+	Durations []int  `json:"durations"`
+	Explicit  []bool `json:"explicit"`
+}
 
-	missCacheKey := make([]uint, 0)
-	for k, v := range cacheResult {
-		if v != nil {
-			resultMap.Store(trackIds[k].Id, v.(string))
-			continue
-		}
-		missCacheKey = append(missCacheKey, trackIds[k].Id)
+// getCachedPlaylist 读取组装好的歌单，未命中或缓存不可用时返回 nil
+func getCachedPlaylist(key string) *models.SongList {
+	if config.Conf.PlaylistCacheTTL <= 0 {
+		return nil
 	}
-	if len(missCacheKey) == 0 { // 缓存全部命中，直接返回
-		log.Infof("全部命中缓存（网易云）: %v", link)
-		return &models.SongList{
-			Name:       SongsListName,
-			Songs:      utils.SyncMapToSortedSlice(trackIds, resultMap),
-			SongsCount: tracksCount,
-		}, nil
+	data, _ := cache.GetBytes(key)
+	if len(data) == 0 {
+		return nil
 	}
-
-	// 2、查询数据库，失败不退出
-	dbResultMap, _ := db.BatchGetSongById(missCacheKey)
-
-	missDBKey := make([]uint, 0)
-	for _, v := range missCacheKey {
-		if val, ok := dbResultMap[v]; ok {
-			resultMap.Store(v, val)
-			continue
-		}
-		missDBKey = append(missDBKey, v)
+	cached := &cachedPlaylist{SongList: &models.SongList{}}
+	if err := json.Unmarshal(data, cached); err != nil {
+		log.Errorf("fail to decode cached playlist %v: %v", key, err)
+		return nil
 	}
-	if len(dbResultMap) == len(missCacheKey) { // 数据库全部命中
-		missKeyCacheMap := sync.Map{}
-		for k, v := range dbResultMap {
-			missKeyCacheMap.Store(fmt.Sprintf(netEasyRedis, k), v)
-		}
-		_ = cache.MSet(missKeyCacheMap)
+	cached.SongList.Durations, cached.SongList.Explicit = cached.Durations, cached.Explicit
+	return cached.SongList
+}
 
-		return NewSongList(SongsListName, trackIds, resultMap, tracksCount), nil
+func setCachedPlaylist(key string, songList *models.SongList) {
+	if config.Conf.PlaylistCacheTTL <= 0 {
+		return
 	}
-
-	missKeyCacheMap, err := batchGetSongs(missDBKey, resultMap)
+	data, err := json.Marshal(&cachedPlaylist{SongList: songList, Durations: songList.Durations, Explicit: songList.Explicit})
 	if err != nil {
-		return nil, err
-	}
-
-	// 写数据库
-	missDbData := make([]*models.NetEasySong, 0, len(missDBKey))
-	for _, v := range missDBKey {
-		value, _ := missKeyCacheMap.Load(fmt.Sprintf(netEasyRedis, v))
-		missDbData = append(missDbData, &models.NetEasySong{Id: v, Name: value.(string)})
+		log.Errorf("fail to encode playlist %v: %v", key, err)
+		return
 	}
-	_ = db.BatchInsertSong(missDbData)
+	_ = cache.SetBytes(key, data, config.Conf.PlaylistCacheTTL)
+}
 
-	// 写缓存
-	_ = cache.MSet(missKeyCacheMap)
+// netEasyProvider 网易云歌单，支持流式获取
+type netEasyProvider struct {
+	linkProvider
+}
 
-	return &models.SongList{
-		Name:       SongsListName,
-		Songs:      utils.SyncMapToSortedSlice(trackIds, resultMap),
-		SongsCount: tracksCount,
-	}, nil
+// Stream 按 chunkSize 分批获取歌曲并写入 sink，同一时刻只持有一批歌曲
+func (netEasyProvider) Stream(ctx context.Context, link string, sink SongSink) error {
+	songListId, err := utils.GetNetEasyParam(ctx, link)
+	if err != nil {
+		return err
+	}
+	ctx = log.WithFields(ctx, "playlist", fmt.Sprintf(netEasyList, songListId))
+	SongIdsResp, err := getSongsInfo(ctx, songListId)
+	if err != nil {
+		return err
+	}
+	playlist := SongIdsResp.Playlist
+	err = sink.Begin(&models.SongList{
+		Name:        playlist.Name,
+		SongsCount:  playlist.TrackCount,
+		Cover:       playlist.CoverImgUrl,
+		Description: playlist.Description,
+		Tags:        playlist.Tags,
+	})
+	if err != nil {
+		return err
+	}
+	ids := make([]uint, 0, chunkSize)
+	for i := 0; i < len(playlist.TrackIds); i += chunkSize {
+		end := i + chunkSize
+		if end > len(playlist.TrackIds) {
+			end = len(playlist.TrackIds)
+		}
+		ids = ids[:0]
+		for _, v := range playlist.TrackIds[i:end] {
+			ids = append(ids, v.Id)
+		}
+		songs, err := songrepo.GetSongs(ctx, platformNetEasy, ids)
+		if err != nil {
+			return err
+		}
+		for _, v := range ids {
+			if song, ok := songs[v]; ok {
+				if err = sink.Song(format.Song(song), song.Duration, song.Explicit); err != nil {
+					return err
+				}
+				err = track(sink, &models.Song{
+					Name: song.Name, Artists: song.Artists, Album: song.Album, DurationMs: song.Duration, SourceId: strconv.FormatUint(uint64(v), 10),
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+		if err = chunk(sink, end); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func NewSongList(SongsListName string, trackIds []*models.TrackId, resultMap sync.Map, tracksCount int) *models.SongList {
-	return &models.SongList{
+// NewSongList 按 trackIds 的顺序组装歌单，未获取到的歌曲被跳过
+func NewSongList(SongsListName string, trackIds []*models.TrackId, collector *models.SongCollector, tracksCount int, cover string) *models.SongList {
+	ids := make([]uint, 0, len(trackIds))
+	for _, v := range trackIds {
+		ids = append(ids, v.Id)
+	}
+	found, songs := collector.Ordered(ids)
+	songList := &models.SongList{
 		Name:       SongsListName,
-		Songs:      utils.SyncMapToSortedSlice(trackIds, resultMap),
+		Songs:      make([]string, 0, len(songs)),
+		Durations:  make([]int, 0, len(songs)),
+		Explicit:   make([]bool, 0, len(songs)),
+		Tracks:     make([]*models.Song, 0, len(songs)),
 		SongsCount: tracksCount,
+		Cover:      cover,
 	}
+	totalDuration := 0
+	for i, song := range songs {
+		songList.Songs = append(songList.Songs, format.Song(song))
+		songList.Durations = append(songList.Durations, song.Duration)
+		songList.Explicit = append(songList.Explicit, song.Explicit)
+		songList.Tracks = append(songList.Tracks, &models.Song{
+			Name: song.Name, Artists: song.Artists, Album: song.Album, DurationMs: song.Duration, SourceId: strconv.FormatUint(uint64(found[i]), 10),
+		})
+		totalDuration += song.Duration
+	}
+	songList.Summary = format.Summarize(songList.Songs, totalDuration)
+	return songList
 }
 
-func getSongsInfo(link string) (*models.NetEasySongId, error) {
-	songListId, err := utils.GetNetEasyParam(link)
-	if err != nil {
-		return nil, err
+type netEasyMusicUKey struct{}
+
+// WithNetEasyCookie 返回携带用户网易云 MUSIC_U 的 ctx，用于获取该用户的私密歌单；
+// 可传入 MUSIC_U 的值或完整的 Cookie 请求头
+func WithNetEasyCookie(ctx context.Context, cookie string) context.Context {
+	musicU := strings.TrimSpace(cookie)
+	if strings.Contains(musicU, "=") {
+		musicU = ""
+		for _, v := range strings.Split(cookie, ";") {
+			if name, value, ok := strings.Cut(strings.TrimSpace(v), "="); ok && name == "MUSIC_U" {
+				musicU = value
+			}
+		}
 	}
-	resp, err := httputil.Post(netEasyUrlV6, strings.NewReader("id="+songListId))
-	if err != nil {
-		log.Errorf("fail to result: %v", err)
-		return nil, err
+	if musicU == "" {
+		return ctx
 	}
-	defer resp.Body.Close()
+	return context.WithValue(ctx, netEasyMusicUKey{}, musicU)
+}
 
-	body, _ := io.ReadAll(resp.Body)
-	SongIdsResp := &models.NetEasySongId{}
-	err = json.Unmarshal(body, SongIdsResp)
+// netEasyMusicU 请求中用户的 MUSIC_U
+func netEasyMusicU(ctx context.Context) string {
+	musicU, _ := ctx.Value(netEasyMusicUKey{}).(string)
+	return musicU
+}
+
+func getSongsInfo(ctx context.Context, songListId string) (*models.NetEasySongId, error) {
+	// 仅歌单详情需要登录态，歌曲详情不携带 cookie
+	musicU := netEasyMusicU(ctx)
+	if musicU == "" {
+		musicU = config.Conf.Upstream.NetEasyMusicU
+	}
+	if musicU != "" {
+		ctx = httputil.WithCookies(ctx, &http.Cookie{Name: "MUSIC_U", Value: musicU})
+	}
+	ctx, span := trace.Start(ctx, "netease.playlist_detail", "playlist", songListId)
+	SongIdsResp, err := netEasyApi().playlistDetail(ctx, songListId)
+	span.End(err)
 	switch {
 	case err != nil:
-		log.Errorf("fail to unmarshal: %v", err)
 		return nil, err
 	case SongIdsResp.Code == 401:
-		log.Errorf("无权限访问, songList id: %v", songListId)
-		return nil, errors.New("抱歉，您无权限访问该歌单")
+		log.WithContext(ctx).Errorf("无权限访问, songList id: %v", songListId)
+		return nil, errors.New("抱歉，您无权限访问该歌单，私密歌单可在请求头 X-NetEase-Cookie 中携带歌单所有者的 MUSIC_U")
 	}
 	return SongIdsResp, nil
 }
 
 // 批量从网易云音乐查询歌曲数据
-func batchGetSongs(missKey []uint, resultMap sync.Map) (sync.Map, error) {
+func batchGetSongs(ctx context.Context, missKey []uint) (map[uint]*models.CachedSong, error) {
 	missSongIds := make([]*models.SongId, 0, len(missKey))
 	for _, v := range missKey {
 		missSongIds = append(missSongIds, &models.SongId{Id: v})
 	}
 	missSize := len(missSongIds)
-	// errgroup 并发编程
-	errgroup := errgroup.Group{}
+	// 任一分片失败或 ctx 被取消时，其余分片的请求随之取消
+	group, groupCtx := chunkGroup(ctx, platformNetEasy)
 	chunks := make([][]*models.SongId, 0, missSize/500+1)
-	missKeyCacheMap := sync.Map{}
+	collector := models.NewSongCollector(missSize)
 
 	for i := 0; i < missSize; i += chunkSize {
 		end := i + chunkSize
@@ -164,46 +285,30 @@ func batchGetSongs(missKey []uint, resultMap sync.Map) (sync.Map, error) {
 	}
 	for _, v := range chunks {
 		chunk := v
-		errgroup.Go(func() error {
-			marshal, _ := json.Marshal(chunk)
-			resp, err := httputil.Post(netEasyUrlV3, strings.NewReader("c="+string(marshal)))
-			if err != nil {
-				log.Errorf("fail to result: %v", err)
+		group.Go(func() error {
+			var songs *models.Songs
+			err := limitChunk(groupCtx, platformNetEasy, func(ctx context.Context) (err error) {
+				songs, err = netEasyApi().songDetail(ctx, chunk)
 				return err
-			}
-			defer resp.Body.Close()
-			bytes, _ := io.ReadAll(resp.Body)
-			songs := &models.Songs{}
-			err = json.Unmarshal(bytes, &songs)
+			})
 			if err != nil {
-				log.Errorf("fail to unmarshal: %v", err)
 				return err
 			}
 
-			builder := strings.Builder{}
 			for _, v := range songs.Songs {
-				builder.Reset()
-				// 去除多余符号
-				builder.WriteString(utils.StandardSongName(v.Name))
-				builder.WriteString(" - ")
-
 				authors := make([]string, 0, len(v.Ar))
 				for _, v := range v.Ar {
 					authors = append(authors, v.Name)
 				}
-				authorsString := strings.Join(authors, " / ")
-				builder.WriteString(authorsString)
-				song := builder.String()
-				missKeyCacheMap.Store(fmt.Sprintf(netEasyRedis, v.Id), song)
-				resultMap.Store(v.Id, song)
+				collector.Insert(v.Id, &models.CachedSong{Name: v.Name, Artists: authors, Duration: v.Dt, Explicit: v.Mark&models.NetEasyMarkExplicit != 0, Album: v.Al.Name})
 			}
 			return nil
 		})
 	}
 	// 等待所有 goroutine 完成
-	if err := errgroup.Wait(); err != nil {
-		log.Errorf("fail to wait: %v", err)
-		return sync.Map{}, err
+	if err := group.Wait(); err != nil {
+		log.WithContext(ctx).Errorf("fail to wait: %v", err)
+		return nil, err
 	}
-	return missKeyCacheMap, nil
+	return collector.Map(), nil
 }
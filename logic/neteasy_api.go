@@ -0,0 +1,200 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"GoMusic/common/models"
+	"GoMusic/httputil"
+	"GoMusic/initialize/config"
+	"GoMusic/initialize/log"
+)
+
+// netEasyBackend 网易云接口的访问方式，直连官方接口或经由 NeteaseCloudMusicApi 转发，两者返回结构一致
+type netEasyBackend interface {
+	playlistDetail(ctx context.Context, songListId string) (*models.NetEasySongId, error)
+	songDetail(ctx context.Context, ids []*models.SongId) (*models.Songs, error)
+	artistAlbums(ctx context.Context, artistId int64) (*models.NetEasyArtistAlbums, error)
+	searchArtist(ctx context.Context, name string) (*models.NetEasyArtistSearch, error)
+	artistTopSongs(ctx context.Context, artistId int64) (*models.NetEasyArtistTopSongs, error)
+	account(ctx context.Context) (*models.NetEasyAccount, error)
+}
+
+// artistAlbumsLimit 每次查询歌手最近的专辑数
+const artistAlbumsLimit = 30
+
+var ncm = ncmApi{baseUrl: strings.TrimSuffix(config.Conf.Upstream.NCMApi, "/")}
+
+func netEasyApi() netEasyBackend {
+	switch config.Conf.Upstream.NetEasyBackend {
+	case config.NetEasyBackendNCMApi:
+		return ncm
+	case config.NetEasyBackendFailover:
+		return netEasyFailover
+	}
+	return directApi{}
+}
+
+// directApi 直连 music.163.com
+type directApi struct{}
+
+func (directApi) playlistDetail(ctx context.Context, songListId string) (*models.NetEasySongId, error) {
+	resp, err := httputil.Post(ctx, config.Conf.Upstream.NetEasyPlaylist, strings.NewReader("id="+songListId))
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to result: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	SongIdsResp := &models.NetEasySongId{}
+	return SongIdsResp, decodeBody(resp.Body, SongIdsResp)
+}
+
+func (directApi) songDetail(ctx context.Context, ids []*models.SongId) (*models.Songs, error) {
+	marshal, _ := json.Marshal(ids)
+	resp, err := httputil.Post(ctx, config.Conf.Upstream.NetEasySongDetail, strings.NewReader("c="+string(marshal)))
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to result: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	songs := &models.Songs{}
+	return songs, decodeBody(resp.Body, songs)
+}
+
+func (directApi) artistAlbums(ctx context.Context, artistId int64) (*models.NetEasyArtistAlbums, error) {
+	resp, err := httputil.Get(ctx, fmt.Sprintf("%v/%v?limit=%v", config.Conf.Upstream.NetEasyArtistAlbums, artistId, artistAlbumsLimit))
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to result: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	albums := &models.NetEasyArtistAlbums{}
+	return albums, decodeBody(resp.Body, albums)
+}
+
+func (directApi) searchArtist(ctx context.Context, name string) (*models.NetEasyArtistSearch, error) {
+	resp, err := httputil.Post(ctx, config.Conf.Upstream.NetEasySearch, strings.NewReader("type=100&limit=5&s="+url.QueryEscape(name)))
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to result: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	result := &models.NetEasyArtistSearch{}
+	return result, decodeBody(resp.Body, result)
+}
+
+func (directApi) artistTopSongs(ctx context.Context, artistId int64) (*models.NetEasyArtistTopSongs, error) {
+	resp, err := httputil.Get(ctx, fmt.Sprintf("%v/%v", config.Conf.Upstream.NetEasyArtist, artistId))
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to result: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	songs := &models.NetEasyArtistTopSongs{}
+	return songs, decodeBody(resp.Body, songs)
+}
+
+func (directApi) account(ctx context.Context) (*models.NetEasyAccount, error) {
+	resp, err := httputil.Post(ctx, config.Conf.Upstream.NetEasyAccount, strings.NewReader(""))
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to result: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	account := &models.NetEasyAccount{}
+	return account, decodeBody(resp.Body, account)
+}
+
+// ncmApi 自建的 NeteaseCloudMusicApi 服务，https://github.com/Binaryify/NeteaseCloudMusicApi
+type ncmApi struct {
+	baseUrl string
+}
+
+func (a ncmApi) playlistDetail(ctx context.Context, songListId string) (*models.NetEasySongId, error) {
+	resp, err := httputil.Get(ctx, a.baseUrl+"/playlist/detail?id="+url.QueryEscape(songListId))
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to get ncm api playlist: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	SongIdsResp := &models.NetEasySongId{}
+	return SongIdsResp, decodeBody(resp.Body, SongIdsResp)
+}
+
+func (a ncmApi) songDetail(ctx context.Context, ids []*models.SongId) (*models.Songs, error) {
+	idStrings := make([]string, 0, len(ids))
+	for _, v := range ids {
+		idStrings = append(idStrings, strconv.FormatUint(uint64(v.Id), 10))
+	}
+	resp, err := httputil.Get(ctx, a.baseUrl+"/song/detail?ids="+strings.Join(idStrings, ","))
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to get ncm api songs: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	songs := &models.Songs{}
+	return songs, decodeBody(resp.Body, songs)
+}
+
+func (a ncmApi) artistAlbums(ctx context.Context, artistId int64) (*models.NetEasyArtistAlbums, error) {
+	resp, err := httputil.Get(ctx, fmt.Sprintf("%v/artist/album?id=%v&limit=%v", a.baseUrl, artistId, artistAlbumsLimit))
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to get ncm api artist albums: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	albums := &models.NetEasyArtistAlbums{}
+	return albums, decodeBody(resp.Body, albums)
+}
+
+func (a ncmApi) searchArtist(ctx context.Context, name string) (*models.NetEasyArtistSearch, error) {
+	resp, err := httputil.Get(ctx, a.baseUrl+"/search?type=100&limit=5&keywords="+url.QueryEscape(name))
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to get ncm api search: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	result := &models.NetEasyArtistSearch{}
+	return result, decodeBody(resp.Body, result)
+}
+
+func (a ncmApi) artistTopSongs(ctx context.Context, artistId int64) (*models.NetEasyArtistTopSongs, error) {
+	resp, err := httputil.Get(ctx, fmt.Sprintf("%v/artists?id=%v", a.baseUrl, artistId))
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to get ncm api artist: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	songs := &models.NetEasyArtistTopSongs{}
+	return songs, decodeBody(resp.Body, songs)
+}
+
+func (a ncmApi) account(ctx context.Context) (*models.NetEasyAccount, error) {
+	resp, err := httputil.Get(ctx, a.baseUrl+"/user/account")
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to get ncm api account: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	account := &models.NetEasyAccount{}
+	return account, decodeBody(resp.Body, account)
+}
+
+// decodeBody 读取并解析响应体，响应被截断或超出大小限制时返回读取错误
+func decodeBody(body io.Reader, v any) error {
+	bytes, err := io.ReadAll(body)
+	if err != nil {
+		log.Errorf("fail to read body: %v", err)
+		return err
+	}
+	if err := json.Unmarshal(bytes, v); err != nil {
+		log.Errorf("fail to unmarshal: %v", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"GoMusic/common/models"
+	"GoMusic/initialize/config"
+	"GoMusic/initialize/log"
+)
+
+// failoverApi 优先直连网易云，直连失败时改由代理完成本次请求；
+// 连续失败达到阈值后在冷却时间内只走代理，冷却结束后重新尝试直连
+type failoverApi struct {
+	primary   netEasyBackend
+	secondary netEasyBackend
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+
+	servedPrimary   atomic.Int64
+	servedSecondary atomic.Int64
+	failovers       atomic.Int64
+}
+
+// BackendStats 各访问方式实际完成的请求数
+type BackendStats struct {
+	Direct      int64     `json:"direct"`
+	Proxy       int64     `json:"proxy"`
+	Failovers   int64     `json:"failovers"`
+	DirectAfter time.Time `json:"direct_after"` // 在此之前只走代理
+}
+
+var netEasyFailover = &failoverApi{
+	primary:   directApi{},
+	secondary: ncm,
+	threshold: config.Conf.Upstream.FailoverThreshold,
+	cooldown:  config.Conf.Upstream.FailoverCooldown,
+}
+
+// NetEasyBackendStats 网易云接口访问方式的统计，仅 failover 模式下有数据
+func NetEasyBackendStats() *BackendStats {
+	netEasyFailover.mu.Lock()
+	openUntil := netEasyFailover.openUntil
+	netEasyFailover.mu.Unlock()
+	return &BackendStats{
+		Direct:      netEasyFailover.servedPrimary.Load(),
+		Proxy:       netEasyFailover.servedSecondary.Load(),
+		Failovers:   netEasyFailover.failovers.Load(),
+		DirectAfter: openUntil,
+	}
+}
+
+func (f *failoverApi) playlistDetail(ctx context.Context, songListId string) (*models.NetEasySongId, error) {
+	var rs *models.NetEasySongId
+	err := f.do(ctx, func(b netEasyBackend) (err error) {
+		rs, err = b.playlistDetail(ctx, songListId)
+		// 401 为歌单本身无权限，换用代理也无法获取
+		if err == nil && rs.Code != 200 && rs.Code != 401 {
+			err = fmt.Errorf("unexpected playlist code: %d", rs.Code)
+		}
+		return err
+	})
+	return rs, err
+}
+
+func (f *failoverApi) songDetail(ctx context.Context, ids []*models.SongId) (*models.Songs, error) {
+	var rs *models.Songs
+	err := f.do(ctx, func(b netEasyBackend) (err error) {
+		rs, err = b.songDetail(ctx, ids)
+		// 风控时接口仍返回成功，但歌曲列表为空
+		if err == nil && len(ids) > 0 && len(rs.Songs) == 0 {
+			err = fmt.Errorf("empty song detail")
+		}
+		return err
+	})
+	return rs, err
+}
+
+func (f *failoverApi) artistAlbums(ctx context.Context, artistId int64) (*models.NetEasyArtistAlbums, error) {
+	var rs *models.NetEasyArtistAlbums
+	err := f.do(ctx, func(b netEasyBackend) (err error) {
+		rs, err = b.artistAlbums(ctx, artistId)
+		if err == nil && rs.Code != 200 {
+			err = fmt.Errorf("unexpected artist albums code: %d", rs.Code)
+		}
+		return err
+	})
+	return rs, err
+}
+
+func (f *failoverApi) searchArtist(ctx context.Context, name string) (*models.NetEasyArtistSearch, error) {
+	var rs *models.NetEasyArtistSearch
+	err := f.do(ctx, func(b netEasyBackend) (err error) {
+		rs, err = b.searchArtist(ctx, name)
+		if err == nil && rs.Code != 200 {
+			err = fmt.Errorf("unexpected search code: %d", rs.Code)
+		}
+		return err
+	})
+	return rs, err
+}
+
+func (f *failoverApi) artistTopSongs(ctx context.Context, artistId int64) (*models.NetEasyArtistTopSongs, error) {
+	var rs *models.NetEasyArtistTopSongs
+	err := f.do(ctx, func(b netEasyBackend) (err error) {
+		rs, err = b.artistTopSongs(ctx, artistId)
+		if err == nil && rs.Code != 200 {
+			err = fmt.Errorf("unexpected artist code: %d", rs.Code)
+		}
+		return err
+	})
+	return rs, err
+}
+
+func (f *failoverApi) account(ctx context.Context) (*models.NetEasyAccount, error) {
+	var rs *models.NetEasyAccount
+	err := f.do(ctx, func(b netEasyBackend) (err error) {
+		rs, err = b.account(ctx)
+		// cookie 无效时接口仍返回 200，Account 为 null
+		if err == nil && rs.Code != 200 {
+			err = fmt.Errorf("unexpected account code: %d", rs.Code)
+		}
+		return err
+	})
+	return rs, err
+}
+
+func (f *failoverApi) do(ctx context.Context, call func(netEasyBackend) error) error {
+	if f.primaryAvailable() {
+		err := call(f.primary)
+		// 请求被取消不代表直连不可用，既不计入失败也不再转由代理请求
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		f.report(err)
+		if err == nil {
+			f.servedPrimary.Add(1)
+			return nil
+		}
+		log.WithContext(ctx).Warnf("网易云直连失败，改由代理请求：%v", err)
+	}
+	if err := call(f.secondary); err != nil {
+		return err
+	}
+	f.servedSecondary.Add(1)
+	return nil
+}
+
+func (f *failoverApi) primaryAvailable() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return time.Now().After(f.openUntil)
+}
+
+func (f *failoverApi) report(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err == nil {
+		f.failures = 0
+		return
+	}
+	f.failures++
+	if f.failures >= f.threshold {
+		f.failures = 0
+		f.openUntil = time.Now().Add(f.cooldown)
+		f.failovers.Add(1)
+		log.Warnf("网易云直连连续失败，%v 内仅通过代理访问", f.cooldown)
+	}
+}
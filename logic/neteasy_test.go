@@ -1,13 +1,18 @@
 package logic
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"regexp"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
+	"GoMusic/common/models"
 	"GoMusic/common/utils"
+	"GoMusic/repo/db"
 )
 
 const (
@@ -53,11 +58,45 @@ func TestBracketRegex(t *testing.T) {
 	fmt.Println(utils.StandardSongName("幻听（女声版） - 星月酱"))
 }
 
+var (
+	dbOnce sync.Once
+	dbErr  error
+)
+
+// requireDB 连接数据库，未配置 MySQL 时跳过需要数据库的测试
+func requireDB(t *testing.T) {
+	t.Helper()
+	dbOnce.Do(func() { dbErr = db.Open() })
+	if dbErr != nil {
+		t.Skipf("mysql unavailable: %v", dbErr)
+	}
+}
+
 func TestDiscover(t *testing.T) {
+	requireDB(t)
 	sample := []string{V1, V2, V3, V4, V5}
 	for _, v := range sample {
-		discover, err := NetEasyDiscover(v)
+		discover, err := NetEasyDiscover(context.Background(), v)
 		assert.NoError(t, err)
 		t.Log(discover)
 	}
 }
+
+func TestWithNetEasyCookie(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, "", netEasyMusicU(WithNetEasyCookie(ctx, "")))
+	assert.Equal(t, "abc", netEasyMusicU(WithNetEasyCookie(ctx, " abc ")))
+	assert.Equal(t, "abc", netEasyMusicU(WithNetEasyCookie(ctx, "os=pc; MUSIC_U=abc; __csrf=1")))
+	assert.Equal(t, "", netEasyMusicU(WithNetEasyCookie(ctx, "os=pc")))
+}
+
+func TestCachedPlaylist(t *testing.T) {
+	songList := &models.SongList{Name: "歌单", Songs: []string{"晴天 - 周杰伦"}, Durations: []int{269000}, Explicit: []bool{true}}
+	data, err := json.Marshal(&cachedPlaylist{SongList: songList, Durations: songList.Durations, Explicit: songList.Explicit})
+	assert.NoError(t, err)
+	cached := &cachedPlaylist{SongList: &models.SongList{}}
+	assert.NoError(t, json.Unmarshal(data, cached))
+	assert.Equal(t, songList.Songs, cached.SongList.Songs)
+	assert.Equal(t, songList.Durations, cached.Durations)
+	assert.Equal(t, songList.Explicit, cached.Explicit)
+}
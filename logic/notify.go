@@ -0,0 +1,91 @@
+package logic
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/smtp"
+	"strings"
+
+	"GoMusic/common/models"
+	"GoMusic/httputil"
+	"GoMusic/initialize/config"
+	"GoMusic/initialize/log"
+)
+
+// notification 发送给订阅者的通知，webhook 收到 Payload 的 JSON，邮件收到 Subject 与 Lines
+type notification struct {
+	Subject string
+	Lines   []string
+	Payload any
+}
+
+// notify 通过 webhook 与邮件发送通知，任一渠道失败不影响其他渠道
+func notify(watch *models.WatchedPlaylist, n *notification) {
+	if watch.Webhook != "" {
+		// 通知与触发检查的请求无关，客户端断开后仍需送达
+		if err := sendWebhook(context.Background(), watch.Webhook, n.Payload); err != nil {
+			log.Errorf("fail to send webhook: %v", err)
+		}
+	}
+	if watch.Email != "" {
+		if err := sendEmail(watch.Email, n.Subject, n.Lines); err != nil {
+			log.Errorf("fail to send email: %v", err)
+		}
+	}
+}
+
+func healthReportNotification(report *models.HealthReport) *notification {
+	lines := []string{fmt.Sprintf("歌单《%v》中有 %v 首歌曲新近下架：", report.Name, len(report.NewlyDelisted)), ""}
+	for _, v := range report.NewlyDelisted {
+		song := v.Song
+		if song == "" {
+			song = fmt.Sprintf("未知歌曲（id: %v）", v.Id)
+		}
+		lines = append(lines, song)
+	}
+	lines = append(lines, "", report.Link)
+	return &notification{Subject: "GoMusic 歌单下架提醒：" + report.Name, Lines: lines, Payload: report}
+}
+
+func releaseReportNotification(report *models.ReleaseReport) *notification {
+	lines := []string{fmt.Sprintf("你关注的歌手发行了 %v 张新专辑：", len(report.Releases)), ""}
+	for _, v := range report.Releases {
+		lines = append(lines, fmt.Sprintf("%v《%v》 %v %v", v.Artist, v.Album, v.PublishTime.Format("2006-01-02"), v.Link))
+	}
+	return &notification{Subject: "GoMusic 新歌提醒", Lines: lines, Payload: report}
+}
+
+func sendWebhook(ctx context.Context, link string, payload any) error {
+	marshal, _ := json.Marshal(payload)
+	resp, err := httputil.PostJSON(ctx, link, bytes.NewReader(marshal))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook 返回状态码：%d", resp.StatusCode)
+	}
+	return nil
+}
+
+func sendEmail(to, subject string, lines []string) error {
+	conf := config.Conf.SMTP
+	if conf.Host == "" {
+		log.Warnf("未配置 SMTP，跳过邮件通知：%v", to)
+		return nil
+	}
+	msg := "From: " + conf.From + "\r\n" +
+		"To: " + to + "\r\n" +
+		"Subject: =?UTF-8?B?" + base64.StdEncoding.EncodeToString([]byte(subject)) + "?=\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n\r\n" + strings.Join(lines, "\r\n") + "\r\n"
+
+	var auth smtp.Auth
+	if conf.Username != "" {
+		auth = smtp.PlainAuth("", conf.Username, conf.Password, conf.Host)
+	}
+	return smtp.SendMail(fmt.Sprintf("%v:%v", conf.Host, conf.Port), auth, conf.From, []string{to}, []byte(msg))
+}
@@ -0,0 +1,175 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"GoMusic/common/models"
+)
+
+const (
+	platformPlex = "plex"
+	// plexTrackType 歌曲的元数据类型
+	plexTrackType = "10"
+	// plexAddLimit 每次添加到歌单的歌曲数上限，避免 uri 参数过长
+	plexAddLimit = 100
+)
+
+var (
+	errPlexAccount = errors.New("请提供 Plex 服务器地址与 X-Plex-Token")
+	errPlexLibrary = errors.New("Plex 服务器中没有音乐资料库")
+)
+
+// PlexExport 在 Plex Media Server 的音乐资料库中匹配歌曲并创建歌单
+func PlexExport(ctx context.Context, link, server, token string) (*models.ExportReport, error) {
+	provider := MatchProvider(link)
+	if provider == nil {
+		return nil, errors.New("不支持的歌单链接")
+	}
+	exporter, err := newPlexExporter(ctx, server, token)
+	if err != nil {
+		return nil, err
+	}
+	songList, err := provider.Discover(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	return Export(ctx, platformPlex, exporter, songList)
+}
+
+// newPlexExporter 先确认令牌有效并找到音乐资料库，避免获取歌单后才发现无法创建
+func newPlexExporter(ctx context.Context, server, token string) (*plexExporter, error) {
+	if server == "" || token == "" {
+		return nil, errPlexAccount
+	}
+	server, err := targetServer(server)
+	if err != nil {
+		return nil, err
+	}
+	exporter := &plexExporter{server: server, token: token}
+	if err = exporter.init(ctx); err != nil {
+		return nil, err
+	}
+	return exporter, nil
+}
+
+// plexExporter 使用 X-Plex-Token 访问 Plex Media Server
+type plexExporter struct {
+	server  string
+	token   string
+	machine string // 服务器标识
+	section string // 音乐资料库的 key
+}
+
+// init 获取服务器标识与首个音乐资料库
+func (e *plexExporter) init(ctx context.Context) error {
+	identity := &models.PlexResponse{}
+	if err := e.request(ctx, "GET", "/identity", identity); err != nil {
+		return err
+	}
+	sections := &models.PlexResponse{}
+	if err := e.request(ctx, "GET", "/library/sections", sections); err != nil {
+		return err
+	}
+	for _, v := range sections.MediaContainer.Directory {
+		if v.Type == "artist" {
+			e.machine, e.section = identity.MediaContainer.MachineIdentifier, v.Key
+			return nil
+		}
+	}
+	return errPlexLibrary
+}
+
+func (e *plexExporter) Search(ctx context.Context, title, _ string) ([]*models.ExportCandidate, error) {
+	query := url.Values{"type": {plexTrackType}, "title": {title}}
+	resp := &models.PlexResponse{}
+	if err := e.request(ctx, "GET", "/library/sections/"+url.PathEscape(e.section)+"/all?"+query.Encode(), resp); err != nil {
+		return nil, err
+	}
+	candidates := make([]*models.ExportCandidate, 0, len(resp.MediaContainer.Metadata))
+	for _, v := range resp.MediaContainer.Metadata {
+		artist := v.OriginalTitle
+		if artist == "" {
+			artist = v.GrandparentTitle
+		}
+		candidates = append(candidates, &models.ExportCandidate{Id: v.RatingKey, Title: v.Title, Artist: artist})
+	}
+	return candidates, nil
+}
+
+func (e *plexExporter) CreatePlaylist(ctx context.Context, name, _ string, ids []string) (string, string, error) {
+	if len(ids) == 0 {
+		return "", "", errors.New("Plex 资料库中未找到任何歌曲，无法创建歌单")
+	}
+	end := len(ids)
+	if end > plexAddLimit {
+		end = plexAddLimit
+	}
+	query := url.Values{"type": {"audio"}, "title": {name}, "smart": {"0"}, "uri": {e.uri(ids[:end])}}
+	playlist := &models.PlexResponse{}
+	if err := e.request(ctx, "POST", "/playlists?"+query.Encode(), playlist); err != nil {
+		return "", "", err
+	}
+	if len(playlist.MediaContainer.Metadata) == 0 {
+		return "", "", errors.New("Plex 创建歌单失败")
+	}
+	id := playlist.MediaContainer.Metadata[0].RatingKey
+	if _, err := e.AppendPlaylist(ctx, id, ids[end:]); err != nil {
+		return "", "", err
+	}
+	// 网页地址依赖 Plex Web 的部署方式，不返回歌单链接；歌单仅创建者可见，不支持设置可见性
+	return id, "", nil
+}
+
+func (e *plexExporter) PlaylistTracks(ctx context.Context, id string) ([]string, error) {
+	resp := &models.PlexResponse{}
+	if err := e.request(ctx, "GET", "/playlists/"+url.PathEscape(id)+"/items", resp); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(resp.MediaContainer.Metadata))
+	for _, v := range resp.MediaContainer.Metadata {
+		ids = append(ids, v.RatingKey)
+	}
+	return ids, nil
+}
+
+// AppendPlaylist 每次最多添加 plexAddLimit 首
+func (e *plexExporter) AppendPlaylist(ctx context.Context, id string, ids []string) (string, error) {
+	for i := 0; i < len(ids); i += plexAddLimit {
+		end := i + plexAddLimit
+		if end > len(ids) {
+			end = len(ids)
+		}
+		query := url.Values{"uri": {e.uri(ids[i:end])}}
+		if err := e.request(ctx, "PUT", "/playlists/"+url.PathEscape(id)+"/items?"+query.Encode(), nil); err != nil {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+func (e *plexExporter) Playlists(ctx context.Context) ([]*models.TargetPlaylist, error) {
+	resp := &models.PlexResponse{}
+	if err := e.request(ctx, "GET", "/playlists?playlistType=audio", resp); err != nil {
+		return nil, err
+	}
+	playlists := make([]*models.TargetPlaylist, 0, len(resp.MediaContainer.Metadata))
+	for _, v := range resp.MediaContainer.Metadata {
+		playlists = append(playlists, &models.TargetPlaylist{Id: v.RatingKey, Name: v.Title, SongsCount: v.LeafCount})
+	}
+	return playlists, nil
+}
+
+// uri 资料库中歌曲的引用，多首歌曲以逗号分隔
+func (e *plexExporter) uri(ids []string) string {
+	return fmt.Sprintf("server://%v/com.plexapp.plugins.library/library/metadata/%v", e.machine, strings.Join(ids, ","))
+}
+
+func (e *plexExporter) request(ctx context.Context, method, path string, v any) error {
+	header := http.Header{"X-Plex-Token": {e.token}, "Accept": {"application/json"}, "X-Plex-Product": {"GoMusic"}}
+	return exportRequest(ctx, "Plex", method, e.server+path, header, nil, v)
+}
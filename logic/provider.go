@@ -0,0 +1,203 @@
+package logic
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"GoMusic/common/event"
+	"GoMusic/common/models"
+	"GoMusic/initialize/log"
+	"GoMusic/initialize/metrics"
+	"GoMusic/initialize/trace"
+)
+
+// Provider 歌单来源平台
+type Provider interface {
+	// Name 平台名，如 netease
+	Name() string
+	// Match 链接是否属于该平台
+	Match(link string) bool
+	// Discover 获取歌单
+	Discover(ctx context.Context, link string) (*models.SongList, error)
+}
+
+// SongSink 流式接收歌单
+type SongSink interface {
+	// Begin 在写入歌曲前调用一次，songList 中不含歌曲
+	Begin(songList *models.SongList) error
+	// Song 按歌单顺序逐首写入“歌名 - 歌手”
+	Song(song string, durationMs int, explicit bool) error
+}
+
+// ChunkSink 可选实现，每写完一批歌曲后调用 Chunk，resolved 为已处理的歌曲数（含已下架而未写入的），
+// 便于按批推送并展示进度
+type ChunkSink interface {
+	Chunk(resolved int) error
+}
+
+// TrackSink 可选实现，平台提供结构化歌曲时在每次 Song 之后以同一首歌曲调用 Track
+type TrackSink interface {
+	Track(track *models.Song) error
+}
+
+// Streamer 支持流式获取歌单的平台，分批获取并写入歌曲，内存占用与歌单大小无关
+type Streamer interface {
+	Stream(ctx context.Context, link string, sink SongSink) error
+}
+
+var (
+	providerMu sync.RWMutex
+	providers  []Provider
+
+	providerRequests = metrics.NewCounterVec("gomusic_provider_requests_total", "Playlist fetches by provider and result.", "provider", "result")
+)
+
+// RegisterProvider 注册歌单来源平台，通常在 init 中调用；按注册顺序匹配链接
+func RegisterProvider(provider Provider) {
+	providerMu.Lock()
+	defer providerMu.Unlock()
+	providers = append(providers, provider)
+}
+
+// MatchProvider 返回首个能处理该链接的平台，均不匹配时返回 nil
+func MatchProvider(link string) Provider {
+	providerMu.RLock()
+	defer providerMu.RUnlock()
+	for _, v := range providers {
+		if v.Match(link) {
+			return v
+		}
+	}
+	return nil
+}
+
+// linkProvider 按域名匹配链接的平台，链接包含任一 hosts 即视为匹配。
+// 使用子串匹配而非正则，垃圾请求也不会产生内存分配
+type linkProvider struct {
+	name     string
+	hosts    []string
+	discover func(ctx context.Context, link string) (*models.SongList, error)
+}
+
+func (p *linkProvider) Name() string {
+	return p.name
+}
+
+func (p *linkProvider) Match(link string) bool {
+	for _, v := range p.hosts {
+		if strings.Contains(link, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *linkProvider) Discover(ctx context.Context, link string) (*models.SongList, error) {
+	ctx = log.WithFields(ctx, "provider", p.name)
+	ctx, span := trace.Start(ctx, "provider.discover", "provider", p.name)
+	songList, err := p.discover(ctx, link)
+	span.End(err)
+	countRequest(p.name, err)
+	if err == nil {
+		event.Publish(ctx, event.DiscoveryCompleted, &models.DiscoveryResult{
+			Provider: p.name, Link: link, Name: songList.Name, SongsCount: songList.SongsCount, Resolved: len(songList.Songs),
+		})
+	}
+	return songList, err
+}
+
+// countRequest 按结果统计平台的歌单请求
+func countRequest(provider string, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	providerRequests.Inc(provider, result)
+}
+
+// Stream 将歌单逐首写入 sink；平台不支持流式获取时先获取完整歌单再写入
+func Stream(ctx context.Context, provider Provider, link string, sink SongSink) error {
+	if streamer, ok := provider.(Streamer); ok {
+		ctx = log.WithFields(ctx, "provider", provider.Name())
+		ctx, span := trace.Start(ctx, "provider.stream", "provider", provider.Name())
+		result := &models.DiscoveryResult{Provider: provider.Name(), Link: link}
+		err := streamer.Stream(ctx, link, &eventSink{SongSink: sink, result: result})
+		span.End(err)
+		countRequest(provider.Name(), err)
+		if err == nil {
+			event.Publish(ctx, event.DiscoveryCompleted, result)
+		}
+		return err
+	}
+	songList, err := provider.Discover(ctx, link)
+	if err != nil {
+		return err
+	}
+	info := *songList
+	info.Songs, info.Tracks, info.Durations, info.Explicit = nil, nil, nil, nil
+	if err = sink.Begin(&info); err != nil {
+		return err
+	}
+	for i, v := range songList.Songs {
+		var (
+			duration int
+			explicit bool
+		)
+		if i < len(songList.Durations) {
+			duration = songList.Durations[i]
+		}
+		if i < len(songList.Explicit) {
+			explicit = songList.Explicit[i]
+		}
+		if err = sink.Song(v, duration, explicit); err != nil {
+			return err
+		}
+		if i < len(songList.Tracks) {
+			if err = track(sink, songList.Tracks[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return chunk(sink, len(songList.Songs))
+}
+
+// eventSink 统计流式获取的歌曲，供获取完成事件使用
+type eventSink struct {
+	SongSink
+	result *models.DiscoveryResult
+}
+
+func (s *eventSink) Begin(songList *models.SongList) error {
+	s.result.Name, s.result.SongsCount = songList.Name, songList.SongsCount
+	return s.SongSink.Begin(songList)
+}
+
+func (s *eventSink) Song(song string, durationMs int, explicit bool) error {
+	s.result.Resolved++
+	return s.SongSink.Song(song, durationMs, explicit)
+}
+
+func (s *eventSink) Track(t *models.Song) error {
+	return track(s.SongSink, t)
+}
+
+func (s *eventSink) Chunk(resolved int) error {
+	return chunk(s.SongSink, resolved)
+}
+
+// chunk sink 实现 ChunkSink 时通知其一批歌曲已写完
+func chunk(sink SongSink, resolved int) error {
+	if v, ok := sink.(ChunkSink); ok {
+		return v.Chunk(resolved)
+	}
+	return nil
+}
+
+// track sink 实现 TrackSink 时写入结构化歌曲
+func track(sink SongSink, t *models.Song) error {
+	if v, ok := sink.(TrackSink); ok {
+		return v.Track(t)
+	}
+	return nil
+}
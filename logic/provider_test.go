@@ -0,0 +1,116 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/models"
+)
+
+func TestMatchProvider(t *testing.T) {
+	assert.Equal(t, platformNetEasy, MatchProvider("https://music.163.com/playlist?id=2026265113").Name())
+	assert.Equal(t, platformNetEasy, MatchProvider("http://163cn.tv/zoIxm3").Name())
+	assert.Equal(t, platformQQMusic, MatchProvider("https://y.qq.com/n/ryqq/playlist/7364061065").Name())
+	assert.Equal(t, platformKugou, MatchProvider("https://www.kugou.com/yy/special/single/5163.html").Name())
+	assert.Equal(t, platformKuwo, MatchProvider("https://www.kuwo.cn/playlist_detail/3462559430").Name())
+	assert.Nil(t, MatchProvider("https://example.com/playlist/1"))
+}
+
+func TestRegisterProvider(t *testing.T) {
+	providerMu.Lock()
+	saved := providers
+	providerMu.Unlock()
+	defer func() {
+		providerMu.Lock()
+		providers = saved
+		providerMu.Unlock()
+	}()
+
+	RegisterProvider(&linkProvider{
+		name:  "example",
+		hosts: []string{"example.com"},
+		discover: func(ctx context.Context, link string) (*models.SongList, error) {
+			return &models.SongList{Name: link}, nil
+		},
+	})
+	provider := MatchProvider("https://example.com/playlist/1")
+	assert.Equal(t, "example", provider.Name())
+	songList, err := provider.Discover(context.Background(), "https://example.com/playlist/1")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/playlist/1", songList.Name)
+}
+
+func BenchmarkMatchProvider(b *testing.B) {
+	links := []string{
+		"https://music.163.com/playlist?id=2026265113",
+		"https://y.qq.com/n/ryqq/playlist/7364061065",
+		"https://www.kuwo.cn/playlist_detail/3462559430",
+		"junk" + strings.Repeat("a", 200),
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		MatchProvider(links[i%len(links)])
+	}
+}
+
+type recordSink struct {
+	name      string
+	songs     []string
+	durations []int
+}
+
+func (s *recordSink) Begin(songList *models.SongList) error {
+	s.name = songList.Name
+	if len(songList.Songs) > 0 {
+		return errors.New("songs in begin")
+	}
+	return nil
+}
+
+func (s *recordSink) Song(song string, durationMs int, _ bool) error {
+	s.songs = append(s.songs, song)
+	s.durations = append(s.durations, durationMs)
+	return nil
+}
+
+func TestStream(t *testing.T) {
+	provider := &linkProvider{
+		name: "example",
+		discover: func(ctx context.Context, link string) (*models.SongList, error) {
+			return &models.SongList{Name: "歌单", Songs: []string{"a - b", "c - d"}, Durations: []int{1000}}, nil
+		},
+	}
+	sink := &recordSink{}
+	assert.NoError(t, Stream(context.Background(), provider, "", sink))
+	assert.Equal(t, "歌单", sink.name)
+	assert.Equal(t, []string{"a - b", "c - d"}, sink.songs)
+	assert.Equal(t, []int{1000, 0}, sink.durations)
+}
+
+type chunkSink struct {
+	recordSink
+	chunks []int
+}
+
+func (s *chunkSink) Chunk(resolved int) error {
+	s.chunks = append(s.chunks, resolved)
+	return nil
+}
+
+func TestStreamChunk(t *testing.T) {
+	provider := &linkProvider{
+		name: "example",
+		discover: func(ctx context.Context, link string) (*models.SongList, error) {
+			return &models.SongList{Name: "歌单", Songs: []string{"a - b", "c - d"}}, nil
+		},
+	}
+	sink := &chunkSink{}
+	assert.NoError(t, Stream(context.Background(), provider, "", sink))
+	// 不支持流式获取的平台写完全部歌曲后通知一次
+	assert.Equal(t, []int{2}, sink.chunks)
+	assert.Equal(t, []string{"a - b", "c - d"}, sink.songs)
+}
@@ -0,0 +1,154 @@
+package logic
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"GoMusic/common/models"
+	"GoMusic/initialize/config"
+)
+
+const (
+	platformQobuz = "qobuz"
+	// qobuzSearchLimit 每首歌曲的候选数
+	qobuzSearchLimit = 10
+	// qobuzAddLimit 每次添加到歌单的歌曲数上限
+	qobuzAddLimit = 50
+)
+
+var (
+	errQobuzDisabled = errors.New("未配置 Qobuz 应用，无法导入 Qobuz")
+	errQobuzAccount  = errors.New("请提供 Qobuz 邮箱与密码，或 user_auth_token")
+)
+
+// QobuzExport 在用户的 Qobuz 资料库中创建同名私密歌单；token 为空时使用邮箱与密码登录
+func QobuzExport(ctx context.Context, link, email, password, token string) (*models.ExportReport, error) {
+	if config.Conf.QobuzAppId == "" {
+		return nil, errQobuzDisabled
+	}
+	if token == "" && (email == "" || password == "") {
+		return nil, errQobuzAccount
+	}
+	provider := MatchProvider(link)
+	if provider == nil {
+		return nil, errors.New("不支持的歌单链接")
+	}
+	exporter := &qobuzExporter{token: token}
+	// 先登录，避免获取歌单后才发现无法创建
+	if token == "" {
+		if err := exporter.login(ctx, email, password); err != nil {
+			return nil, err
+		}
+	}
+	songList, err := provider.Discover(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	return Export(ctx, platformQobuz, exporter, songList)
+}
+
+// qobuzExporter 使用 app_id 与用户令牌访问 Qobuz API
+type qobuzExporter struct {
+	token string
+}
+
+// login Qobuz 没有面向第三方的 OAuth，按官方客户端的方式以邮箱与密码的 MD5 换取用户令牌
+func (e *qobuzExporter) login(ctx context.Context, email, password string) error {
+	sum := md5.Sum([]byte(password))
+	query := url.Values{"email": {email}, "password": {hex.EncodeToString(sum[:])}}
+	login := &models.QobuzLogin{}
+	if err := e.request(ctx, "POST", "/user/login", query, login); err != nil {
+		return err
+	}
+	if login.UserAuthToken == "" {
+		return errors.New("Qobuz 登录失败，请检查邮箱与密码")
+	}
+	e.token = login.UserAuthToken
+	return nil
+}
+
+func (e *qobuzExporter) Search(ctx context.Context, title, artist string) ([]*models.ExportCandidate, error) {
+	q := strings.TrimSpace(title + " " + strings.ReplaceAll(artist, " / ", " "))
+	query := url.Values{"query": {q}, "limit": {fmt.Sprint(qobuzSearchLimit)}}
+	result := &models.QobuzSearch{}
+	if err := e.request(ctx, "GET", "/track/search", query, result); err != nil {
+		return nil, err
+	}
+	candidates := make([]*models.ExportCandidate, 0, len(result.Tracks.Items))
+	for _, v := range result.Tracks.Items {
+		candidates = append(candidates, &models.ExportCandidate{Id: strconv.FormatInt(v.Id, 10), Title: v.Title, Artist: v.Performer.Name})
+	}
+	return candidates, nil
+}
+
+func (e *qobuzExporter) CreatePlaylist(ctx context.Context, name, description string, ids []string) (string, string, error) {
+	playlist := &models.QobuzPlaylist{}
+	public := "0"
+	if transferOptions(ctx).Privacy == models.PrivacyPublic {
+		public = "1"
+	}
+	query := url.Values{"name": {name}, "description": {description}, "is_public": {public}, "is_collaborative": {"0"}}
+	if err := e.request(ctx, "POST", "/playlist/create", query, playlist); err != nil {
+		return "", "", err
+	}
+	id := strconv.FormatInt(playlist.Id, 10)
+	if err := e.addTracks(ctx, id, ids); err != nil {
+		return "", "", err
+	}
+	return id, "https://open.qobuz.com/playlist/" + id, nil
+}
+
+func (e *qobuzExporter) PlaylistTracks(ctx context.Context, id string) ([]string, error) {
+	ids := make([]string, 0)
+	for offset := 0; ; offset += qobuzAddLimit {
+		query := url.Values{"playlist_id": {id}, "extra": {"tracks"}, "offset": {strconv.Itoa(offset)}, "limit": {strconv.Itoa(qobuzAddLimit)}}
+		playlist := &models.QobuzPlaylist{}
+		if err := e.request(ctx, "GET", "/playlist/get", query, playlist); err != nil {
+			return nil, err
+		}
+		for _, v := range playlist.Tracks.Items {
+			ids = append(ids, strconv.FormatInt(v.Id, 10))
+		}
+		if len(playlist.Tracks.Items) == 0 || offset+len(playlist.Tracks.Items) >= playlist.Tracks.Total {
+			return ids, nil
+		}
+	}
+}
+
+func (e *qobuzExporter) AppendPlaylist(ctx context.Context, id string, ids []string) (string, error) {
+	if err := e.addTracks(ctx, id, ids); err != nil {
+		return "", err
+	}
+	return "https://open.qobuz.com/playlist/" + id, nil
+}
+
+// addTracks 每次最多添加 qobuzAddLimit 首
+func (e *qobuzExporter) addTracks(ctx context.Context, id string, ids []string) error {
+	for i := 0; i < len(ids); i += qobuzAddLimit {
+		end := i + qobuzAddLimit
+		if end > len(ids) {
+			end = len(ids)
+		}
+		query := url.Values{"playlist_id": {id}, "track_ids": {strings.Join(ids[i:end], ",")}}
+		if err := e.request(ctx, "POST", "/playlist/addTracks", query, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// request Qobuz API 的参数均通过查询字符串传递
+func (e *qobuzExporter) request(ctx context.Context, method, path string, query url.Values, v any) error {
+	header := http.Header{"X-App-Id": {config.Conf.QobuzAppId}}
+	if e.token != "" {
+		header.Set("X-User-Auth-Token", e.token)
+	}
+	return exportRequest(ctx, "Qobuz", method, config.Conf.Upstream.QobuzApi+path+"?"+query.Encode(), header, nil, v)
+}
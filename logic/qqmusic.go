@@ -1,91 +1,167 @@
 package logic
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
-	"io"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
+	"GoMusic/common/format"
 	"GoMusic/common/models"
 	"GoMusic/common/utils"
 	"GoMusic/httputil"
+	"GoMusic/initialize/config"
 	"GoMusic/initialize/log"
+	"GoMusic/repo/cache"
 )
 
 const (
-	qqMusicRedis   = "qq_music:%d"
-	qqMusicPattern = "https://u6.y.qq.com/cgi-bin/musics.fcg?sign=%s&_=%d"
+	qqMusicList    = "qq:%v"
+	qqMusicPattern = "%s?sign=%s&_=%d"
 	qqMusicV1      = `fcgi-bin`
 	qqMusicV2      = `details`
-	qqMusicV3      = `playlist`
+	qqMusicV3      = `playlist/(\d+)`
+
+	platformQQMusic = "qqmusic"
+	// qqMusicPageSize 每次请求获取的歌曲数
+	qqMusicPageSize = 500
 )
 
 var (
 	qqMusicV1Regx = regexp.MustCompile(qqMusicV1)
 	qqMusicV2Regx = regexp.MustCompile(qqMusicV2)
 	qqMusicV3Regx = regexp.MustCompile(qqMusicV3)
+
+	// qqMusicPlaylistCache 组装好的歌单，首页已包含全部歌曲信息，无法在请求前判断歌单是否变化，按 PlaylistCacheTTL 过期
+	qqMusicPlaylistCache = cache.NewSchema("qq_playlist", 1)
 )
 
-// QQMusicDiscover 获取qq音乐歌单
-func QQMusicDiscover(link string) (*models.SongList, error) {
-	tid, platform, err := getParams(link)
+func init() {
+	RegisterProvider(&linkProvider{name: platformQQMusic, hosts: []string{"qq.com"}, discover: QQMusicDiscover})
+}
+
+// QQMusicDiscover 获取 QQ 音乐歌单，歌曲较多时分页并发获取
+func QQMusicDiscover(ctx context.Context, link string) (*models.SongList, error) {
+	tid, platform, err := getParams(ctx, link)
 	if err != nil {
 		return nil, err
 	}
+	// 同一歌单的并发请求只向 QQ 音乐转发一次
+	return shared(ctx, fmt.Sprintf(qqMusicList, tid), func(ctx context.Context) (*models.SongList, error) {
+		cacheKey := qqMusicPlaylistCache.Key(fmt.Sprintf("%v:%v", tid, platform))
+		if songList := getCachedPlaylist(cacheKey); songList != nil {
+			return songList, nil
+		}
+		songList, err := qqMusicDiscover(ctx, tid, platform)
+		if err != nil {
+			return nil, err
+		}
+		setCachedPlaylist(cacheKey, songList)
+		return songList, nil
+	})
+}
 
-	// 获取请求参数与验证签名
-	paramString := models.GetQQMusicReqString(tid, platform)
-	sign := utils.Encrypt(paramString)
-
-	// 构建并发送请求
-	link = fmt.Sprintf(qqMusicPattern, sign, time.Now().UnixMilli())
-	resp, err := httputil.Post(link, strings.NewReader(paramString))
+func qqMusicDiscover(ctx context.Context, tid int, platform string) (*models.SongList, error) {
+	// 首页同时返回歌单信息与歌曲总数
+	first, err := getQQMusicPage(ctx, tid, platform, 0)
 	if err != nil {
-		log.Errorf("fail to get qqmusic: %v", err)
 		return nil, err
 	}
-	defer resp.Body.Close()
-	bytes, _ := io.ReadAll(resp.Body)
+	dirinfo := first.Req0.Data.Dirinfo
+	pages := make([][]*models.QQMusicSong, 1, dirinfo.Songnum/qqMusicPageSize+1)
+	pages[0] = first.Req0.Data.Songlist
+	for begin := qqMusicPageSize; begin < dirinfo.Songnum; begin += qqMusicPageSize {
+		pages = append(pages, nil)
+	}
 
-	qqmusicResponse := &models.QQMusicResp{}
-	err = json.Unmarshal(bytes, qqmusicResponse)
-	if err != nil {
-		log.Errorf("fail to unmarshal qqmusic: %v", err)
+	group, groupCtx := chunkGroup(ctx, platformQQMusic)
+	for i := 1; i < len(pages); i++ {
+		i := i
+		group.Go(func() error {
+			return limitChunk(groupCtx, platformQQMusic, func(ctx context.Context) error {
+				page, err := getQQMusicPage(ctx, tid, platform, i*qqMusicPageSize)
+				if err != nil {
+					return err
+				}
+				pages[i] = page.Req0.Data.Songlist
+				return nil
+			})
+		})
+	}
+	if err = group.Wait(); err != nil {
+		log.WithContext(ctx).Errorf("fail to wait: %v", err)
 		return nil, err
 	}
-	songsString := make([]string, 0, len(qqmusicResponse.Req0.Data.Songlist))
-	builder := strings.Builder{}
-	for _, v := range qqmusicResponse.Req0.Data.Songlist {
-		builder.Reset()
-		// 去除多余符号
-		builder.WriteString(utils.StandardSongName(v.Name))
-		builder.WriteString(" - ")
 
-		authors := make([]string, 0, len(v.Singer))
-		for _, v := range v.Singer {
-			authors = append(authors, v.Name)
+	tracks := make([]*models.Song, 0, dirinfo.Songnum)
+	durations := make([]int, 0, dirinfo.Songnum)
+	totalDuration := 0
+	for _, page := range pages {
+		for _, v := range page {
+			totalDuration += v.Interval * 1000
+			durations = append(durations, v.Interval*1000)
+			authors := make([]string, 0, len(v.Singer))
+			for _, v := range v.Singer {
+				authors = append(authors, v.Name)
+			}
+			tracks = append(tracks, &models.Song{Name: v.Name, Artists: authors, Album: v.Album.Name, DurationMs: v.Interval * 1000, SourceId: v.Mid})
 		}
-		authorsString := strings.Join(authors, " / ")
-		builder.WriteString(authorsString)
-		songsString = append(songsString, builder.String())
+	}
+	songsString := format.Tracks(tracks)
+	tags := make([]string, 0, len(dirinfo.Tag))
+	for _, v := range dirinfo.Tag {
+		tags = append(tags, v.Name)
 	}
 	return &models.SongList{
-		Name:       qqmusicResponse.Req0.Data.Dirinfo.Title,
-		Songs:      songsString,
-		SongsCount: qqmusicResponse.Req0.Data.Dirinfo.Songnum,
+		Name:        dirinfo.Title,
+		Songs:       songsString,
+		Tracks:      tracks,
+		Durations:   durations,
+		SongsCount:  dirinfo.Songnum,
+		Cover:       dirinfo.Picurl,
+		Description: dirinfo.Desc,
+		Tags:        tags,
+		Summary:     format.Summarize(songsString, totalDuration),
 	}, nil
 }
 
-// GetNetEasyParam 获取歌单id
-func getParams(link string) (tid int, platform string, err error) {
+// getQQMusicPage 获取歌单中从 begin 开始的一页歌曲
+func getQQMusicPage(ctx context.Context, tid int, platform string, begin int) (*models.QQMusicResp, error) {
+	paramString := models.GetQQMusicReqString(tid, platform, begin, qqMusicPageSize)
+	resp := &models.QQMusicResp{}
+	if err := qqMusicRequest(ctx, paramString, resp); err != nil {
+		return nil, err
+	}
+	if resp.Req0.Code != 0 {
+		log.WithContext(ctx).Errorf("fail to get qqmusic playlist %v, code: %v", tid, resp.Req0.Code)
+		return nil, fmt.Errorf("获取 QQ 音乐歌单失败，错误码：%d", resp.Req0.Code)
+	}
+	return resp, nil
+}
+
+// qqMusicRequest 对请求参数签名后发送至 QQ 音乐统一请求入口
+func qqMusicRequest(ctx context.Context, paramString string, v any) error {
+	sign := utils.Encrypt(paramString)
+	link := fmt.Sprintf(qqMusicPattern, config.Conf.Upstream.QQMusic, sign, time.Now().UnixMilli())
+	resp, err := httputil.Post(ctx, link, strings.NewReader(paramString))
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to get qqmusic: %v", err)
+		return err
+	}
+	defer resp.Body.Close()
+	return decodeBody(resp.Body, v)
+}
+
+// getParams 解析歌单 id 与请求平台，短链先获取跳转地址
+func getParams(ctx context.Context, link string) (tid int, platform string, err error) {
 	if qqMusicV1Regx.MatchString(link) {
-		link, err = httputil.GetRedirectLocation(link)
+		link, err = httputil.GetRedirectLocation(ctx, link)
 		if err != nil {
-			log.Errorf("fail to get redirection url: %v", err)
+			log.WithContext(ctx).Errorf("fail to get redirection url: %v", err)
 			return
 		}
 	}
@@ -93,24 +169,24 @@ func getParams(link string) (tid int, platform string, err error) {
 		var tidString string
 		tidString, platform, err = utils.GetQQMusicParam(link)
 		if err != nil {
-			log.Errorf("fail to get songs id: %v", err)
+			log.WithContext(ctx).Errorf("fail to get songs id: %v", err)
 			return
 		}
 		tid, err = strconv.Atoi(tidString)
 		if err != nil {
-			log.Errorf("fail to convert tid: %v", err)
+			log.WithContext(ctx).Errorf("fail to convert tid: %v", err)
 			return
 		}
 		return tid, platform, nil
 	}
-	if qqMusicV3Regx.MatchString(link) {
-		index := strings.Index(link, "playlist")
-		if index < 0 || index+19 > len(link) {
-			log.Errorf("fail to get tid: %v", err)
-			return
-		}
-		tid, err = strconv.Atoi(link[index+9 : index+19])
-		return tid, "h5", nil
+	if m := qqMusicV3Regx.FindStringSubmatch(link); m != nil {
+		tid, err = strconv.Atoi(m[1])
+		return tid, "h5", err
+	}
+	// 旧版分享链接，如 https://y.qq.com/w/taoge.html?dissid=7364061065
+	if parse, parseErr := url.Parse(link); parseErr == nil && parse.Query().Get("dissid") != "" {
+		tid, err = strconv.Atoi(parse.Query().Get("dissid"))
+		return tid, "h5", err
 	}
 	return 0, "", errors.New("invalid link")
 }
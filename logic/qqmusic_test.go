@@ -1,6 +1,7 @@
 package logic
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -13,23 +14,39 @@ func TestQQMusicDiscover(t *testing.T) {
 	// https://i.y.qq.com/n2/m/share/details/taoge.html?hosteuin=oKE57evqoiEPoz**&id=1596010000&appversion=120801&ADTAG=wxfshare&appshare=iphone_wx
 	// https://i.y.qq.com/n2/m/share/details/taoge.html?platform=11&appshare=android_qq&appversion=12090008&hosteuin=oK6kowEAoK4z7eSk7eEloKCFoz**&id=5204875759&ADTAG=wxfshare
 	t.Run("v1", func(t *testing.T) {
-		discover, err := QQMusicDiscover("https://c6.y.qq.com/base/fcgi-bin/u?__=4V33zWKDE3tI")
+		discover, err := QQMusicDiscover(context.Background(), "https://c6.y.qq.com/base/fcgi-bin/u?__=4V33zWKDE3tI")
 		assert.NoError(t, err)
 		fmt.Println(discover)
 	})
 	t.Run("v2", func(t *testing.T) {
-		discover, err := QQMusicDiscover("https://y.qq.com/n/ryqq/playlist/7364061065")
+		discover, err := QQMusicDiscover(context.Background(), "https://y.qq.com/n/ryqq/playlist/7364061065")
 		assert.NoError(t, err)
 		fmt.Println(discover)
 	})
 	t.Run("v3", func(t *testing.T) {
-		discover, err := QQMusicDiscover("https://i.y.qq.com/n2/m/share/details/taoge.html?hosteuin=oKE57evqoiEPoz**&id=1596010000&appversion=120801&ADTAG=wxfshare&appshare=iphone_wx")
+		discover, err := QQMusicDiscover(context.Background(), "https://i.y.qq.com/n2/m/share/details/taoge.html?hosteuin=oKE57evqoiEPoz**&id=1596010000&appversion=120801&ADTAG=wxfshare&appshare=iphone_wx")
 		assert.NoError(t, err)
 		fmt.Println(discover)
 	})
 	t.Run("v4", func(t *testing.T) {
-		discover, err := QQMusicDiscover("https://i.y.qq.com/n2/m/share/details/taoge.html?platform=11&appshare=android_qq&appversion=12090008&hosteuin=oK6kowEAoK4z7eSk7eEloKCFoz**&id=5204875759&ADTAG=wxfshare")
+		discover, err := QQMusicDiscover(context.Background(), "https://i.y.qq.com/n2/m/share/details/taoge.html?platform=11&appshare=android_qq&appversion=12090008&hosteuin=oK6kowEAoK4z7eSk7eEloKCFoz**&id=5204875759&ADTAG=wxfshare")
 		assert.NoError(t, err)
 		fmt.Println(discover)
 	})
 }
+
+func TestGetParams(t *testing.T) {
+	cases := map[string]int{
+		"https://y.qq.com/n/ryqq/playlist/7364061065":                                       7364061065,
+		"https://y.qq.com/n/ryqq/playlist/123456":                                           123456,
+		"https://y.qq.com/w/taoge.html?dissid=7364061065":                                   7364061065,
+		"https://i.y.qq.com/n2/m/share/details/taoge.html?id=1596010000&appshare=iphone_wx": 1596010000,
+	}
+	for link, want := range cases {
+		tid, _, err := getParams(context.Background(), link)
+		assert.NoError(t, err, link)
+		assert.Equal(t, want, tid, link)
+	}
+	_, _, err := getParams(context.Background(), "https://y.qq.com/n/ryqq/singer/0025NhlN2yWrP4")
+	assert.Error(t, err)
+}
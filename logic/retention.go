@@ -0,0 +1,76 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"GoMusic/initialize/config"
+	"GoMusic/initialize/log"
+	"GoMusic/repo/cache"
+	"GoMusic/repo/db"
+)
+
+const retentionLock = "lock:retention"
+
+// StartRetention 启动后台清理任务，未配置任何保留规则时不启动
+func StartRetention() {
+	if config.Conf.SongRetention <= 0 && config.Conf.SongMaxRows <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(config.Conf.RetentionInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := runRetention(); err != nil {
+				log.Errorf("fail to run retention: %v", err)
+			}
+		}
+	}()
+}
+
+// runRetention 多副本部署时同一时刻只有一个副本执行清理；无法获取租约（如 Redis 不可用）时由本副本清理，
+// 此时多个副本可能同时执行，按行数上限清理时可能多删少量最久未更新的歌曲，它们会在下次请求时重新获取
+func runRetention() error {
+	ran := false
+	err := cache.RunWithLease(context.Background(), retentionLock, config.Conf.LockTTL, func(ctx context.Context) error {
+		ran = true
+		_, err := Purge(ctx)
+		return err
+	})
+	switch {
+	case errors.Is(err, cache.ErrLeaseHeld):
+		return nil
+	case err != nil && !ran:
+		if !errors.Is(err, cache.ErrUnavailable) {
+			log.Warnf("fail to acquire retention lease, purge locally: %v", err)
+		}
+		_, err = Purge(context.Background())
+	}
+	return err
+}
+
+// Purge 按配置的保留规则立即清理数据库
+func Purge(ctx context.Context) (int64, error) {
+	var before time.Time
+	if config.Conf.SongRetention > 0 {
+		before = time.Now().Add(-config.Conf.SongRetention)
+	}
+	purged, err := db.PurgeSongs(ctx, before, config.Conf.SongMaxRows)
+	if err != nil {
+		return purged, err
+	}
+	log.Infof("数据清理完成，共删除 %v 条歌曲数据", purged)
+	return purged, nil
+}
+
+// InvalidateCache 删除旧版本与已弃用的缓存
+func InvalidateCache() (int64, error) {
+	deleted, err := cache.InvalidateStale()
+	if err != nil {
+		log.Errorf("fail to invalidate cache: %v", err)
+		return deleted, err
+	}
+	log.Infof("缓存清理完成，共删除 %v 个 key", deleted)
+	return deleted, nil
+}
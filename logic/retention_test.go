@@ -0,0 +1,13 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Redis 不可用时仍由本副本清理
+func TestRunRetention(t *testing.T) {
+	requireDB(t)
+	assert.NoError(t, runRetention())
+}
@@ -0,0 +1,82 @@
+package logic
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"net/url"
+
+	"GoMusic/common/models"
+	"GoMusic/repo/db"
+)
+
+const (
+	// shortCodeLength 短链接长度，发生碰撞时加长
+	shortCodeLength    = 8
+	shortCodeMaxLength = 16
+)
+
+// shortLinkOptions 可保存的导出参数，与 /export 一致
+var shortLinkOptions = []string{
+	"profile", "format", "encoding", "filename", "title_case", "collapse_space", "unify_brackets", "symbols",
+	"exclude_programs", "exclude_instrumental", "exclude_explicit", "min_duration", "max_duration",
+	"sort", "collation", "stream",
+}
+
+var ErrShortLinkNotFound = errors.New("短链接不存在")
+
+// CreateShortLink 保存歌单链接与导出参数，返回短链接 code；相同的链接与参数总是得到相同的 code
+func CreateShortLink(link string, options url.Values) (string, error) {
+	if MatchProvider(link) == nil {
+		return "", errors.New("不支持的歌单链接")
+	}
+	encoded := filterShortLinkOptions(options).Encode()
+	sum := shortLinkHash(link, encoded)
+	for length := shortCodeLength; length <= shortCodeMaxLength; length++ {
+		code := sum[:length]
+		existing, err := db.GetShortLink(code)
+		switch {
+		case err != nil:
+			return "", err
+		case existing == nil:
+			return code, db.CreateShortLink(&models.ShortLink{Code: code, Link: link, Options: encoded})
+		case existing.Link == link && existing.Options == encoded:
+			return code, nil
+		}
+	}
+	return "", errors.New("生成短链接失败")
+}
+
+// ResolveShortLink 返回短链接对应的 /export 查询参数
+func ResolveShortLink(code string) (url.Values, error) {
+	shortLink, err := db.GetShortLink(code)
+	switch {
+	case err != nil:
+		return nil, err
+	case shortLink == nil:
+		return nil, ErrShortLinkNotFound
+	}
+	query, err := url.ParseQuery(shortLink.Options)
+	if err != nil {
+		return nil, err
+	}
+	query.Set("url", shortLink.Link)
+	return query, nil
+}
+
+// filterShortLinkOptions 只保留导出参数，丢弃空值
+func filterShortLinkOptions(options url.Values) url.Values {
+	filtered := url.Values{}
+	for _, v := range shortLinkOptions {
+		if value := options.Get(v); value != "" {
+			filtered.Set(v, value)
+		}
+	}
+	return filtered
+}
+
+// shortLinkHash 以 base62 表示的链接与参数摘要
+func shortLinkHash(link, options string) string {
+	sum := sha256.Sum256([]byte(link + "\n" + options))
+	return new(big.Int).SetBytes(sum[:]).Text(62)
+}
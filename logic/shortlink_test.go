@@ -0,0 +1,20 @@
+package logic
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterShortLinkOptions(t *testing.T) {
+	options := url.Values{"url": {"https://music.163.com/playlist?id=1"}, "profile": {"spotify"}, "sort": {""}, "token": {"secret"}}
+	assert.Equal(t, "profile=spotify", filterShortLinkOptions(options).Encode())
+}
+
+func TestShortLinkHash(t *testing.T) {
+	a := shortLinkHash("https://music.163.com/playlist?id=1", "profile=spotify")
+	assert.Equal(t, a, shortLinkHash("https://music.163.com/playlist?id=1", "profile=spotify"))
+	assert.NotEqual(t, a, shortLinkHash("https://music.163.com/playlist?id=1", "profile=apple"))
+	assert.GreaterOrEqual(t, len(a), shortCodeMaxLength)
+}
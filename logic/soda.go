@@ -1,9 +1,13 @@
 package logic
 
-import "GoMusic/common/models"
+import (
+	"context"
+
+	"GoMusic/common/models"
+)
 
 // 汽水音乐
 
-func SodaDiscover(link string) (*models.SongList, error) {
+func SodaDiscover(ctx context.Context, link string) (*models.SongList, error) {
 	return nil, nil
 }
@@ -0,0 +1,347 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"GoMusic/common/format"
+	"GoMusic/common/models"
+	"GoMusic/httputil"
+	"GoMusic/initialize/config"
+	"GoMusic/initialize/log"
+)
+
+const (
+	soundcloudList = "soundcloud:%v"
+
+	platformSoundCloud = "soundcloud"
+	// soundcloudTrackBatch 每次通过 /tracks?ids= 补全的歌曲数，接口上限为 50
+	soundcloudTrackBatch = 50
+	// soundcloudLikesPageSize 每页获取的喜欢数
+	soundcloudLikesPageSize = 200
+	// soundcloudMaxLikesPages 最多获取的喜欢页数
+	soundcloudMaxLikesPages = 50
+)
+
+var (
+	soundcloudScriptRegx   = regexp.MustCompile(`<script[^>]+src="([^"]+\.js)"`)
+	soundcloudClientIdRegx = regexp.MustCompile(`client_id\s*[:=]\s*"(\w{32})"`)
+	// soundcloudTitleNoiseRegx 标题中与歌名无关的括号标注，如 (Official Video)、[Free Download]
+	soundcloudTitleNoiseRegx = regexp.MustCompile(`(?i)\s*[(\[【][^)\]】]*\b(?:official|video|audio|lyrics?|visuali[sz]er|free\s*(?:download|dl)|out\s*now|premiere|hq|hd)\b[^)\]】]*[)\]】]`)
+	// soundcloudSeparators 上传者惯用的“歌手 - 歌名”分隔符
+	soundcloudSeparators = []string{" - ", " – ", " — ", " ~ "}
+
+	errUnsupportedSoundCloudLink = errors.New("暂不支持该 SoundCloud 链接，请使用 soundcloud.com/{用户}/sets/{歌单} 或 soundcloud.com/{用户}/likes 链接")
+	errSoundCloudClientId        = errors.New("获取 SoundCloud client_id 失败，请配置 GOMUSIC_SOUNDCLOUD_CLIENT_ID")
+
+	// soundcloudScrapedClientId 未配置 client_id 时从网页版脚本中获取，失效后重新获取
+	soundcloudScrapedClientId struct {
+		sync.Mutex
+		id string
+	}
+)
+
+func init() {
+	RegisterProvider(&linkProvider{name: platformSoundCloud, hosts: []string{"soundcloud.com"}, discover: SoundCloudDiscover})
+}
+
+// SoundCloudDiscover 获取 SoundCloud 歌单或用户喜欢的歌曲，如 https://soundcloud.com/user/sets/playlist、
+// https://soundcloud.com/user/likes，也支持 on.soundcloud.com 短链
+func SoundCloudDiscover(ctx context.Context, link string) (*models.SongList, error) {
+	if strings.Contains(link, "on.soundcloud.com") {
+		location, err := httputil.GetRedirectLocation(ctx, link)
+		if err != nil {
+			log.WithContext(ctx).Errorf("fail to get redirect location: %v", err)
+			return nil, err
+		}
+		link = location
+	}
+	path, likes, err := getSoundCloudPath(link)
+	if err != nil {
+		return nil, err
+	}
+	// 同一歌单的并发请求只向 SoundCloud 转发一次
+	return shared(ctx, fmt.Sprintf(soundcloudList, path), func(ctx context.Context) (*models.SongList, error) {
+		if likes {
+			return soundcloudLikes(ctx, strings.TrimSuffix(path, "/likes"))
+		}
+		return soundcloudPlaylist(ctx, path)
+	})
+}
+
+// getSoundCloudPath 解析链接的路径，去掉查询参数并统一小写；likes 表示用户喜欢页
+func getSoundCloudPath(link string) (string, bool, error) {
+	parse, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || !strings.HasSuffix(parse.Host, "soundcloud.com") {
+		return "", false, errUnsupportedSoundCloudLink
+	}
+	segments := strings.Split(strings.Trim(parse.Path, "/"), "/")
+	switch {
+	case len(segments) == 3 && segments[1] == "sets" && segments[2] != "":
+		return strings.ToLower("/" + strings.Join(segments, "/")), false, nil
+	case len(segments) == 2 && segments[1] == "likes" && segments[0] != "":
+		return strings.ToLower("/" + strings.Join(segments, "/")), true, nil
+	}
+	return "", false, errUnsupportedSoundCloudLink
+}
+
+func soundcloudPlaylist(ctx context.Context, path string) (*models.SongList, error) {
+	playlist, err := soundcloudResolve(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if playlist.Kind != "playlist" {
+		return nil, errUnsupportedSoundCloudLink
+	}
+	// 歌单只返回前几首的完整信息，其余歌曲仅含 id
+	stubs := make(map[int64]int)
+	ids := make([]string, 0)
+	for i, v := range playlist.Tracks {
+		if v.Title == "" {
+			stubs[v.Id] = i
+			ids = append(ids, strconv.FormatInt(v.Id, 10))
+		}
+	}
+	batches := make([][]*models.SoundCloudTrack, (len(ids)+soundcloudTrackBatch-1)/soundcloudTrackBatch)
+	group, groupCtx := chunkGroup(ctx, platformSoundCloud)
+	for i := range batches {
+		i := i
+		end := (i + 1) * soundcloudTrackBatch
+		if end > len(ids) {
+			end = len(ids)
+		}
+		batch := ids[i*soundcloudTrackBatch : end]
+		group.Go(func() error {
+			return limitChunk(groupCtx, platformSoundCloud, func(ctx context.Context) error {
+				query := url.Values{"ids": {strings.Join(batch, ",")}}
+				return soundcloudRequest(ctx, config.Conf.Upstream.SoundCloudApi+"/tracks?"+query.Encode(), &batches[i])
+			})
+		})
+	}
+	if err = group.Wait(); err != nil {
+		log.WithContext(ctx).Errorf("fail to wait: %v", err)
+		return nil, err
+	}
+	for _, batch := range batches {
+		for _, v := range batch {
+			if i, ok := stubs[v.Id]; ok {
+				playlist.Tracks[i] = v
+			}
+		}
+	}
+
+	tracks := make([]*models.SoundCloudTrack, 0, len(playlist.Tracks))
+	for _, v := range playlist.Tracks {
+		// 已删除或不可见的歌曲不会被补全
+		if v.Title != "" {
+			tracks = append(tracks, v)
+		}
+	}
+	songList := soundcloudSongList(tracks)
+	songList.Name = playlist.Title
+	songList.Cover = strings.Replace(playlist.ArtworkUrl, "-large.", "-t500x500.", 1)
+	songList.Description = playlist.Description
+	songList.Tags = soundcloudTags(playlist.TagList)
+	return songList, nil
+}
+
+func soundcloudLikes(ctx context.Context, path string) (*models.SongList, error) {
+	user, err := soundcloudResolve(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if user.Kind != "user" {
+		return nil, errUnsupportedSoundCloudLink
+	}
+	tracks := make([]*models.SoundCloudTrack, 0)
+	next := fmt.Sprintf("%v/users/%d/likes?limit=%d", config.Conf.Upstream.SoundCloudApi, user.Id, soundcloudLikesPageSize)
+	// 喜欢只能按 next_href 游标顺序翻页
+	for page := 0; next != "" && page < soundcloudMaxLikesPages; page++ {
+		likes := &models.SoundCloudLikes{}
+		if err = soundcloudRequest(ctx, next, likes); err != nil {
+			return nil, err
+		}
+		for _, v := range likes.Collection {
+			if v.Track != nil {
+				tracks = append(tracks, v.Track)
+			}
+		}
+		next = likes.NextHref
+	}
+	songList := soundcloudSongList(tracks)
+	songList.Name = user.Username + " 喜欢的音乐"
+	songList.Cover = strings.Replace(user.AvatarUrl, "-large.", "-t500x500.", 1)
+	return songList, nil
+}
+
+func soundcloudSongList(list []*models.SoundCloudTrack) *models.SongList {
+	tracks := make([]*models.Song, 0, len(list))
+	durations := make([]int, 0, len(list))
+	explicit := make([]bool, 0, len(list))
+	totalDuration := 0
+	for _, v := range list {
+		name, artist := soundcloudSongName(v)
+		album := ""
+		if v.PublisherMetadata != nil {
+			album = v.PublisherMetadata.AlbumTitle
+		}
+		totalDuration += v.Duration
+		durations = append(durations, v.Duration)
+		explicit = append(explicit, v.PublisherMetadata != nil && v.PublisherMetadata.Explicit)
+		tracks = append(tracks, &models.Song{
+			Name: name, Artists: []string{artist}, Album: album, DurationMs: v.Duration, SourceId: strconv.FormatInt(v.Id, 10),
+		})
+	}
+	songsString := format.Tracks(tracks)
+	return &models.SongList{
+		Songs:      songsString,
+		Tracks:     tracks,
+		Durations:  durations,
+		Explicit:   explicit,
+		SongsCount: len(tracks),
+		Tags:       make([]string, 0),
+		Summary:    format.Summarize(songsString, totalDuration),
+	}
+}
+
+// soundcloudSongName 拆分歌名与歌手。SoundCloud 歌曲只有上传者而没有歌手字段，上传者常把“歌手 - 歌名”写进标题，
+// 也常附带 (Official Video) 等标注：优先使用发行方元数据中的歌手，其次按分隔符拆分标题，最后使用上传者用户名
+func soundcloudSongName(track *models.SoundCloudTrack) (string, string) {
+	title := strings.TrimSpace(soundcloudTitleNoiseRegx.ReplaceAllString(track.Title, ""))
+	if title == "" {
+		title = strings.TrimSpace(track.Title)
+	}
+	if track.PublisherMetadata != nil && strings.TrimSpace(track.PublisherMetadata.Artist) != "" {
+		artist := strings.TrimSpace(track.PublisherMetadata.Artist)
+		for _, sep := range soundcloudSeparators {
+			if prefix, name, ok := strings.Cut(title, sep); ok && strings.EqualFold(strings.TrimSpace(prefix), artist) {
+				return strings.TrimSpace(name), artist
+			}
+		}
+		return title, artist
+	}
+	for _, sep := range soundcloudSeparators {
+		if artist, name, ok := strings.Cut(title, sep); ok && strings.TrimSpace(artist) != "" && strings.TrimSpace(name) != "" {
+			return strings.TrimSpace(name), strings.TrimSpace(artist)
+		}
+	}
+	return title, track.User.Username
+}
+
+// soundcloudTags 解析以空格分隔、多词标签带引号的 tag_list
+func soundcloudTags(tagList string) []string {
+	tags := make([]string, 0)
+	for i, v := range strings.Split(tagList, `"`) {
+		if i%2 == 1 {
+			if v = strings.TrimSpace(v); v != "" {
+				tags = append(tags, v)
+			}
+			continue
+		}
+		tags = append(tags, strings.Fields(v)...)
+	}
+	return tags
+}
+
+func soundcloudResolve(ctx context.Context, path string) (*models.SoundCloudResolve, error) {
+	query := url.Values{"url": {"https://soundcloud.com" + path}}
+	resolve := &models.SoundCloudResolve{}
+	if err := soundcloudRequest(ctx, config.Conf.Upstream.SoundCloudApi+"/resolve?"+query.Encode(), resolve); err != nil {
+		return nil, err
+	}
+	return resolve, nil
+}
+
+// soundcloudRequest 附加 client_id 请求 api-v2；获取的 client_id 失效时重新获取并重试一次
+func soundcloudRequest(ctx context.Context, link string, v any) error {
+	for retried := false; ; retried = true {
+		clientId, scraped, err := soundcloudClientId(ctx)
+		if err != nil {
+			return err
+		}
+		parse, err := url.Parse(link)
+		if err != nil {
+			return errUnsupportedSoundCloudLink
+		}
+		query := parse.Query()
+		query.Set("client_id", clientId)
+		parse.RawQuery = query.Encode()
+		resp, err := httputil.Get(ctx, parse.String())
+		if err != nil {
+			log.WithContext(ctx).Errorf("fail to get soundcloud playlist: %v", err)
+			return err
+		}
+		if (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) && scraped && !retried {
+			resp.Body.Close()
+			resetSoundCloudClientId(clientId)
+			continue
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			log.WithContext(ctx).Errorf("fail to get soundcloud %v: %v", parse.Path, resp.StatusCode)
+			return errors.New("获取 SoundCloud 歌单失败，请检查歌单是否存在或已设为私密")
+		}
+		return decodeBody(resp.Body, v)
+	}
+}
+
+// soundcloudClientId 返回配置的 client_id，未配置时从网页版脚本中获取；scraped 表示为获取所得
+func soundcloudClientId(ctx context.Context) (id string, scraped bool, err error) {
+	if config.Conf.SoundCloudClientId != "" {
+		return config.Conf.SoundCloudClientId, false, nil
+	}
+	soundcloudScrapedClientId.Lock()
+	defer soundcloudScrapedClientId.Unlock()
+	if soundcloudScrapedClientId.id != "" {
+		return soundcloudScrapedClientId.id, true, nil
+	}
+	page, err := soundcloudFetch(ctx, config.Conf.Upstream.SoundCloud)
+	if err != nil {
+		return "", true, err
+	}
+	// client_id 通常位于最后几个脚本中，从后往前查找
+	scripts := soundcloudScriptRegx.FindAllStringSubmatch(page, -1)
+	for i := len(scripts) - 1; i >= 0; i-- {
+		script, err := soundcloudFetch(ctx, scripts[i][1])
+		if err != nil {
+			continue
+		}
+		if m := soundcloudClientIdRegx.FindStringSubmatch(script); m != nil {
+			soundcloudScrapedClientId.id = m[1]
+			return m[1], true, nil
+		}
+	}
+	log.WithContext(ctx).Errorf("fail to find soundcloud client_id in %d scripts", len(scripts))
+	return "", true, errSoundCloudClientId
+}
+
+// resetSoundCloudClientId 丢弃失效的 client_id，其他请求已更新时保留新值
+func resetSoundCloudClientId(id string) {
+	soundcloudScrapedClientId.Lock()
+	defer soundcloudScrapedClientId.Unlock()
+	if soundcloudScrapedClientId.id == id {
+		soundcloudScrapedClientId.id = ""
+	}
+}
+
+func soundcloudFetch(ctx context.Context, link string) (string, error) {
+	resp, err := httputil.Get(ctx, link)
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to get %v: %v", link, err)
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("获取 %v 失败，状态码：%d", link, resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	return string(body), err
+}
@@ -0,0 +1,109 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/models"
+	"GoMusic/common/schema"
+	"GoMusic/initialize/config"
+)
+
+func TestSoundCloudDiscover(t *testing.T) {
+	var server *httptest.Server
+	clientId := "a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4"
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			_, _ = fmt.Fprintf(w, `<script crossorigin src="%v/assets/0.js"></script><script crossorigin src="%v/assets/1.js"></script>`, server.URL, server.URL)
+			return
+		case "/assets/0.js":
+			_, _ = w.Write([]byte(`({client_id:"` + clientId + `",env:"production"})`))
+			return
+		case "/assets/1.js":
+			_, _ = w.Write([]byte(`var a=1`))
+			return
+		}
+		// 首个 client_id 已失效
+		if r.URL.Query().Get("client_id") != clientId {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/resolve":
+			switch r.URL.Query().Get("url") {
+			case "https://soundcloud.com/someone/sets/mix":
+				_, _ = w.Write([]byte(`{"kind":"playlist","title":"Mix","artwork_url":"https://i1.sndcdn.com/a-large.jpg","tag_list":"house \"deep house\"",
+					"tracks":[{"id":1,"title":"Daft Punk - One More Time (Official Video)","duration":320000,"user":{"username":"uploader"}},{"id":2},{"id":3}]}`))
+			case "https://soundcloud.com/someone":
+				_, _ = w.Write([]byte(`{"kind":"user","id":42,"username":"someone"}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case "/v2/tracks":
+			assert.Equal(t, "2,3", r.URL.Query().Get("ids"))
+			// 已删除的歌曲 3 不返回
+			_, _ = w.Write([]byte(`[{"id":2,"title":"Strobe [Free Download]","duration":600000,"user":{"username":"deadmau5"},
+				"publisher_metadata":{"artist":"deadmau5","album_title":"For Lack of a Better Name","explicit":true}}]`))
+		case "/v2/users/42/likes":
+			if r.URL.Query().Get("offset") == "" {
+				_, _ = fmt.Fprintf(w, `{"collection":[{"track":{"id":5,"title":"Intro","duration":1000,"user":{"username":"The xx"}}},{"playlist":{"id":9}}],
+					"next_href":"%v/v2/users/42/likes?offset=abc&limit=200"}`, server.URL)
+				return
+			}
+			_, _ = w.Write([]byte(`{"collection":[{"track":{"id":6,"title":"Artist – Song","duration":2000,"user":{"username":"label"}}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	upstream, id := config.Conf.Upstream, config.Conf.SoundCloudClientId
+	config.Conf.Upstream.SoundCloud, config.Conf.Upstream.SoundCloudApi = server.URL+"/", server.URL+"/v2"
+	config.Conf.SoundCloudClientId = ""
+	soundcloudScrapedClientId.id = "expired"
+	defer func() {
+		config.Conf.Upstream, config.Conf.SoundCloudClientId = upstream, id
+		soundcloudScrapedClientId.id = ""
+	}()
+
+	songList, err := SoundCloudDiscover(context.Background(), "https://soundcloud.com/someone/sets/mix?si=xyz")
+	assert.NoError(t, err)
+	assert.Equal(t, "Mix", songList.Name)
+	assert.Equal(t, "https://i1.sndcdn.com/a-t500x500.jpg", songList.Cover)
+	assert.Equal(t, []string{"house", "deep house"}, songList.Tags)
+	assert.Equal(t, []string{"One More Time - Daft Punk", "Strobe - deadmau5"}, songList.Songs)
+	assert.Equal(t, []bool{false, true}, songList.Explicit)
+	assert.Equal(t, &models.Song{Name: "Strobe", Artists: []string{"deadmau5"}, Album: "For Lack of a Better Name", DurationMs: 600000, SourceId: "2"}, songList.Tracks[1])
+	assert.Equal(t, clientId, soundcloudScrapedClientId.id)
+	assert.NoError(t, schema.ValidateSongList(songList))
+
+	songList, err = SoundCloudDiscover(context.Background(), "https://m.soundcloud.com/someone/likes")
+	assert.NoError(t, err)
+	assert.Equal(t, "someone 喜欢的音乐", songList.Name)
+	assert.Equal(t, []string{"Intro - The xx", "Song - Artist"}, songList.Songs)
+
+	_, err = SoundCloudDiscover(context.Background(), "https://soundcloud.com/someone/track")
+	assert.ErrorIs(t, err, errUnsupportedSoundCloudLink)
+}
+
+func TestSoundCloudSongName(t *testing.T) {
+	cases := []struct {
+		track         *models.SoundCloudTrack
+		title, artist string
+	}{
+		{&models.SoundCloudTrack{Title: "Title Only", User: models.SoundCloudUser{Username: "uploader"}}, "Title Only", "uploader"},
+		{&models.SoundCloudTrack{Title: "Artist - Title (Lyric Video) [HQ]"}, "Title", "Artist"},
+		{&models.SoundCloudTrack{Title: "Song (feat. Someone)", User: models.SoundCloudUser{Username: "Artist"}}, "Song (feat. Someone)", "Artist"},
+		{&models.SoundCloudTrack{Title: "The Artist - Song - Remix", PublisherMetadata: &models.SoundCloudPublisher{Artist: "the artist"}}, "Song - Remix", "the artist"},
+	}
+	for _, v := range cases {
+		title, artist := soundcloudSongName(v.track)
+		assert.Equal(t, v.title, title)
+		assert.Equal(t, v.artist, artist)
+	}
+}
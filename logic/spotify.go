@@ -0,0 +1,221 @@
+package logic
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"GoMusic/common/models"
+	"GoMusic/httputil"
+	"GoMusic/initialize/config"
+	"GoMusic/initialize/log"
+	"GoMusic/repo/cache"
+)
+
+const (
+	platformSpotify = "spotify"
+	// spotifyScope 仅申请修改歌单的权限
+	spotifyScope = "playlist-modify-private playlist-modify-public"
+	// spotifyStateTTL 用户完成授权的时限
+	spotifyStateTTL = 10 * time.Minute
+	// spotifyAddLimit 每次添加到歌单的歌曲数上限
+	spotifyAddLimit = 100
+)
+
+var (
+	spotifyState = cache.NewSchema("spotify_state", 2)
+
+	errSpotifyDisabled = errors.New("未配置 Spotify 应用，无法导入 Spotify")
+	errSpotifyState    = errors.New("授权已过期，请重新发起导入")
+)
+
+// SpotifyAuthorizeUrl 记录待导入的歌单链接与 ctx 中的偏好语言、网易云 cookie，返回 Spotify 授权页地址；
+// 用户授权后跳转回 /spotify/callback
+func SpotifyAuthorizeUrl(ctx context.Context, link string) (string, error) {
+	if config.Conf.Spotify.ClientId == "" {
+		return "", errSpotifyDisabled
+	}
+	if MatchProvider(link) == nil {
+		return "", errors.New("不支持的歌单链接")
+	}
+	state := newWatchToken()
+	auth := &models.SpotifyAuthState{Link: link, MusicU: netEasyMusicU(ctx)}
+	auth.Locale, _ = ctx.Value(localeKey{}).(string)
+	auth.Transfer, _ = ctx.Value(transferOptionsKey{}).(*models.TransferOptions)
+	data, _ := json.Marshal(auth)
+	if err := cache.SetBytes(spotifyState.Key(state), data, spotifyStateTTL); err != nil {
+		log.WithContext(ctx).Errorf("fail to save spotify state: %v", err)
+		return "", err
+	}
+	query := url.Values{
+		"response_type": {"code"},
+		"client_id":     {config.Conf.Spotify.ClientId},
+		"scope":         {spotifyScope},
+		"redirect_uri":  {config.Conf.Spotify.RedirectUrl},
+		"state":         {state},
+	}
+	return config.Conf.Upstream.SpotifyAccounts + "/authorize?" + query.Encode(), nil
+}
+
+// SpotifyCallback 用授权码换取令牌，获取歌单后在用户的 Spotify 资料库中创建同名私密歌单
+func SpotifyCallback(ctx context.Context, code, state string) (*models.ExportReport, error) {
+	if config.Conf.Spotify.ClientId == "" {
+		return nil, errSpotifyDisabled
+	}
+	data, err := cache.GetDelBytes(spotifyState.Key(state))
+	switch {
+	case err != nil:
+		log.WithContext(ctx).Errorf("fail to get spotify state: %v", err)
+		return nil, err
+	case data == nil:
+		return nil, errSpotifyState
+	}
+	auth := &models.SpotifyAuthState{}
+	if err = json.Unmarshal(data, auth); err != nil {
+		return nil, errSpotifyState
+	}
+	// 回调由 Spotify 跳转发起，不携带原请求的参数，按授权时记录的值还原
+	ctx = withSavedTransferOptions(WithLocale(WithNetEasyCookie(ctx, auth.MusicU), auth.Locale), auth.Transfer)
+	token, err := spotifyToken(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	provider := MatchProvider(auth.Link)
+	if provider == nil {
+		return nil, errors.New("不支持的歌单链接")
+	}
+	songList, err := provider.Discover(ctx, auth.Link)
+	if err != nil {
+		return nil, err
+	}
+	return Export(ctx, platformSpotify, &spotifyExporter{token: token}, songList)
+}
+
+// spotifyToken 授权码模式换取用户的访问令牌
+func spotifyToken(ctx context.Context, code string) (string, error) {
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {config.Conf.Spotify.RedirectUrl},
+	}
+	credential := base64.StdEncoding.EncodeToString([]byte(config.Conf.Spotify.ClientId + ":" + config.Conf.Spotify.ClientSecret))
+	header := http.Header{"Content-Type": {"application/x-www-form-urlencoded"}, "Authorization": {"Basic " + credential}}
+	resp, err := httputil.Do(ctx, "POST", config.Conf.Upstream.SpotifyAccounts+"/api/token", header, strings.NewReader(form.Encode()))
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to get spotify token: %v", err)
+		return "", err
+	}
+	defer resp.Body.Close()
+	token := &models.SpotifyToken{}
+	if err = decodeBody(resp.Body, token); err != nil {
+		return "", err
+	}
+	if token.AccessToken == "" {
+		log.WithContext(ctx).Errorf("fail to get spotify token: %v %v", token.Error, token.Description)
+		return "", fmt.Errorf("Spotify 授权失败：%v", token.Description)
+	}
+	return token.AccessToken, nil
+}
+
+// spotifyExporter 使用用户令牌访问 Spotify Web API
+type spotifyExporter struct {
+	token string
+}
+
+func (e *spotifyExporter) Search(ctx context.Context, title, artist string) ([]*models.ExportCandidate, error) {
+	q := "track:" + title
+	if artist != "" {
+		q += " artist:" + strings.ReplaceAll(artist, " / ", " ")
+	}
+	// 各地区的曲库差异较大，默认在账号所在地区搜索，避免匹配到用户无法播放的歌曲
+	market := transferOptions(ctx).Market
+	if market == "" {
+		market = "from_token"
+	}
+	result := &models.SpotifySearch{}
+	if err := e.request(ctx, "GET", "/search?type=track&limit=5&market="+market+"&q="+url.QueryEscape(q), nil, result); err != nil {
+		return nil, err
+	}
+	candidates := make([]*models.ExportCandidate, 0, len(result.Tracks.Items))
+	for _, v := range result.Tracks.Items {
+		artists := make([]string, 0, len(v.Artists))
+		for _, v := range v.Artists {
+			artists = append(artists, v.Name)
+		}
+		candidates = append(candidates, &models.ExportCandidate{Id: v.Uri, Title: v.Name, Artist: strings.Join(artists, " / ")})
+	}
+	return candidates, nil
+}
+
+func (e *spotifyExporter) CreatePlaylist(ctx context.Context, name, description string, uris []string) (string, string, error) {
+	user := &models.SpotifyUser{}
+	if err := e.request(ctx, "GET", "/me", nil, user); err != nil {
+		return "", "", err
+	}
+	playlist := &models.SpotifyPlaylist{}
+	// Spotify 的非公开歌单不显示在个人主页，持有链接仍可访问，unlisted 与 private 相同
+	body := map[string]any{"name": name, "description": description, "public": transferOptions(ctx).Privacy == models.PrivacyPublic}
+	if err := e.request(ctx, "POST", "/users/"+url.PathEscape(user.Id)+"/playlists", body, playlist); err != nil {
+		return "", "", err
+	}
+	if err := e.addTracks(ctx, playlist.Id, uris); err != nil {
+		return "", "", err
+	}
+	return playlist.Id, playlist.ExternalUrls.Spotify, nil
+}
+
+func (e *spotifyExporter) PlaylistTracks(ctx context.Context, id string) ([]string, error) {
+	uris := make([]string, 0)
+	for offset := 0; ; offset += spotifyAddLimit {
+		query := url.Values{"offset": {strconv.Itoa(offset)}, "limit": {strconv.Itoa(spotifyAddLimit)}, "fields": {"total,items(track(uri))"}}
+		page := &models.SpotifyPlaylistTracks{}
+		if err := e.request(ctx, "GET", "/playlists/"+url.PathEscape(id)+"/tracks?"+query.Encode(), nil, page); err != nil {
+			return nil, err
+		}
+		for _, v := range page.Items {
+			if v.Track != nil {
+				uris = append(uris, v.Track.Uri)
+			}
+		}
+		if len(page.Items) == 0 || offset+len(page.Items) >= page.Total {
+			return uris, nil
+		}
+	}
+}
+
+func (e *spotifyExporter) AppendPlaylist(ctx context.Context, id string, uris []string) (string, error) {
+	if err := e.addTracks(ctx, id, uris); err != nil {
+		return "", err
+	}
+	return "https://open.spotify.com/playlist/" + id, nil
+}
+
+// addTracks 每次最多添加 spotifyAddLimit 首
+func (e *spotifyExporter) addTracks(ctx context.Context, id string, uris []string) error {
+	for i := 0; i < len(uris); i += spotifyAddLimit {
+		end := i + spotifyAddLimit
+		if end > len(uris) {
+			end = len(uris)
+		}
+		if err := e.request(ctx, "POST", "/playlists/"+url.PathEscape(id)+"/tracks", map[string]any{"uris": uris[i:end]}, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *spotifyExporter) request(ctx context.Context, method, path string, body, v any) error {
+	header := http.Header{"Authorization": {"Bearer " + e.token}}
+	// Spotify 按 Accept-Language 返回本地化的歌名与歌手名
+	if locale := requestLocale(ctx); locale != "" {
+		header.Set("Accept-Language", locale)
+	}
+	return exportRequest(ctx, "Spotify", method, config.Conf.Upstream.SpotifyApi+path, header, body, v)
+}
@@ -0,0 +1,162 @@
+package logic
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"GoMusic/common/models"
+	"GoMusic/httputil"
+	"GoMusic/initialize/log"
+)
+
+const (
+	platformSubsonic = "subsonic"
+	// subsonicApiVersion 请求使用的 API 版本，1.13 起支持令牌认证，1.14 起 createPlaylist 返回歌单
+	subsonicApiVersion = "1.16.1"
+	// subsonicSearchLimit 每首歌曲的候选数，本地曲库按歌名搜索，由相似度筛选歌手
+	subsonicSearchLimit = 20
+)
+
+var errSubsonicAccount = errors.New("请提供 Subsonic 服务器地址、用户名与密码")
+
+// SubsonicExport 在 Subsonic 兼容的服务器（Navidrome、Airsonic 等）中按曲库匹配歌曲并创建歌单
+func SubsonicExport(ctx context.Context, link, server, username, password string) (*models.ExportReport, error) {
+	provider := MatchProvider(link)
+	if provider == nil {
+		return nil, errors.New("不支持的歌单链接")
+	}
+	exporter, err := newSubsonicExporter(ctx, server, username, password)
+	if err != nil {
+		return nil, err
+	}
+	songList, err := provider.Discover(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	return Export(ctx, platformSubsonic, exporter, songList)
+}
+
+// newSubsonicExporter 校验账号，先确认账号有效，避免获取歌单后才发现无法创建
+func newSubsonicExporter(ctx context.Context, server, username, password string) (*subsonicExporter, error) {
+	if server == "" || username == "" || password == "" {
+		return nil, errSubsonicAccount
+	}
+	server, err := targetServer(server)
+	if err != nil {
+		return nil, err
+	}
+	exporter := &subsonicExporter{server: server, username: username, password: password}
+	if err = exporter.request(ctx, "ping", nil, nil); err != nil {
+		return nil, err
+	}
+	return exporter, nil
+}
+
+// subsonicExporter 使用令牌认证访问 Subsonic API，密码不随请求发送
+type subsonicExporter struct {
+	server   string
+	username string
+	password string
+}
+
+func (e *subsonicExporter) Search(ctx context.Context, title, _ string) ([]*models.ExportCandidate, error) {
+	params := url.Values{"query": {title}, "songCount": {fmt.Sprint(subsonicSearchLimit)}, "artistCount": {"0"}, "albumCount": {"0"}}
+	resp := &models.SubsonicResponse{}
+	if err := e.request(ctx, "search3", params, resp); err != nil {
+		return nil, err
+	}
+	songs := resp.Response.SearchResult3.Song
+	candidates := make([]*models.ExportCandidate, 0, len(songs))
+	for _, v := range songs {
+		candidates = append(candidates, &models.ExportCandidate{Id: v.Id, Title: v.Title, Artist: v.Artist})
+	}
+	return candidates, nil
+}
+
+func (e *subsonicExporter) CreatePlaylist(ctx context.Context, name, _ string, ids []string) (string, string, error) {
+	params := url.Values{"name": {name}, "songId": ids}
+	resp := &models.SubsonicResponse{}
+	if err := e.request(ctx, "createPlaylist", params, resp); err != nil {
+		return "", "", err
+	}
+	// 新建歌单默认不公开，createPlaylist 不支持设置
+	if transferOptions(ctx).Privacy == models.PrivacyPublic {
+		params = url.Values{"playlistId": {resp.Response.Playlist.Id}, "public": {"true"}}
+		if err := e.request(ctx, "updatePlaylist", params, nil); err != nil {
+			return "", "", err
+		}
+	}
+	// 各服务器的网页地址不同，不返回歌单链接
+	return resp.Response.Playlist.Id, "", nil
+}
+
+func (e *subsonicExporter) PlaylistTracks(ctx context.Context, id string) ([]string, error) {
+	resp := &models.SubsonicResponse{}
+	if err := e.request(ctx, "getPlaylist", url.Values{"id": {id}}, resp); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(resp.Response.Playlist.Entry))
+	for _, v := range resp.Response.Playlist.Entry {
+		ids = append(ids, v.Id)
+	}
+	return ids, nil
+}
+
+func (e *subsonicExporter) AppendPlaylist(ctx context.Context, id string, ids []string) (string, error) {
+	if len(ids) == 0 {
+		return "", nil
+	}
+	return "", e.request(ctx, "updatePlaylist", url.Values{"playlistId": {id}, "songIdToAdd": ids}, nil)
+}
+
+func (e *subsonicExporter) Playlists(ctx context.Context) ([]*models.TargetPlaylist, error) {
+	resp := &models.SubsonicResponse{}
+	if err := e.request(ctx, "getPlaylists", nil, resp); err != nil {
+		return nil, err
+	}
+	playlists := make([]*models.TargetPlaylist, 0, len(resp.Response.Playlists.Playlist))
+	for _, v := range resp.Response.Playlists.Playlist {
+		playlists = append(playlists, &models.TargetPlaylist{Id: v.Id, Name: v.Name, SongsCount: v.SongCount})
+	}
+	return playlists, nil
+}
+
+// request 以表单提交参数，歌曲较多时 createPlaylist 的 songId 不会超出 URL 长度限制
+func (e *subsonicExporter) request(ctx context.Context, method string, params url.Values, v *models.SubsonicResponse) error {
+	salt := newWatchToken()[:12]
+	sum := md5.Sum([]byte(e.password + salt))
+	form := url.Values{"u": {e.username}, "t": {hex.EncodeToString(sum[:])}, "s": {salt}, "v": {subsonicApiVersion}, "c": {"GoMusic"}, "f": {"json"}}
+	for k, values := range params {
+		form[k] = values
+	}
+	header := http.Header{"Content-Type": {"application/x-www-form-urlencoded"}}
+	resp, err := httputil.Do(ctx, "POST", e.server+"/rest/"+method, header, strings.NewReader(form.Encode()))
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to request subsonic: %v", err)
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.WithContext(ctx).Errorf("fail to request subsonic %v: %v", method, resp.StatusCode)
+		return fmt.Errorf("Subsonic 请求失败，状态码：%d", resp.StatusCode)
+	}
+	if v == nil {
+		v = &models.SubsonicResponse{}
+	}
+	if err = decodeBody(resp.Body, v); err != nil {
+		return err
+	}
+	if v.Response.Status != "ok" {
+		if v.Response.Error != nil {
+			return fmt.Errorf("Subsonic 请求失败：%v", v.Response.Error.Message)
+		}
+		return errors.New("Subsonic 请求失败")
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"GoMusic/common/models"
+	"GoMusic/initialize/config"
+	"GoMusic/initialize/log"
+	"GoMusic/repo/cache"
+)
+
+var (
+	transferSchema = cache.NewSchema("transfer", 1)
+
+	// transfers 本副本完成的导入记录，Redis 不可用时仍可查询；同时写入 Redis，供其他副本查询
+	transfers = struct {
+		sync.Mutex
+		m map[string]*models.ExportReport
+	}{m: make(map[string]*models.ExportReport)}
+
+	ErrTransferNotFound = errors.New("导入记录不存在或已过期")
+)
+
+type transferOptionsKey struct{}
+
+var marketRegx = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// WithTransferOptions 校验导入选项并返回携带选项的 ctx，各平台的 Exporter 创建歌单时读取
+func WithTransferOptions(ctx context.Context, opts *models.TransferOptions) (context.Context, error) {
+	copied := *opts
+	switch copied.Privacy {
+	case "":
+		copied.Privacy = models.PrivacyPrivate
+	case models.PrivacyPrivate, models.PrivacyUnlisted, models.PrivacyPublic:
+	default:
+		return ctx, fmt.Errorf("不支持的歌单可见性：%v，可选 private、unlisted、public", opts.Privacy)
+	}
+	if copied.Market = strings.ToUpper(strings.TrimSpace(copied.Market)); copied.Market != "" && !marketRegx.MatchString(copied.Market) {
+		return ctx, fmt.Errorf("无效的地区代码：%v，请使用两位地区代码，如 TW", opts.Market)
+	}
+	return context.WithValue(ctx, transferOptionsKey{}, &copied), nil
+}
+
+// transferOptions ctx 中的导入选项，未设置时为默认值
+func transferOptions(ctx context.Context) *models.TransferOptions {
+	if opts, ok := ctx.Value(transferOptionsKey{}).(*models.TransferOptions); ok {
+		return opts
+	}
+	return &models.TransferOptions{Privacy: models.PrivacyPrivate}
+}
+
+// withSavedTransferOptions 还原授权回调或后台任务保存的导入选项，保存前已校验
+func withSavedTransferOptions(ctx context.Context, opts *models.TransferOptions) context.Context {
+	if opts == nil {
+		return ctx
+	}
+	ctx, _ = WithTransferOptions(ctx, opts)
+	return ctx
+}
+
+// GetTransfer 查询导入目标平台的结果，与后台任务一样保留 JobTTL
+func GetTransfer(id string) (*models.ExportReport, error) {
+	transfers.Lock()
+	report, ok := transfers.m[id]
+	transfers.Unlock()
+	if ok {
+		return report, nil
+	}
+	data, err := cache.GetBytes(transferSchema.Key(id))
+	if err != nil && !errors.Is(err, cache.ErrUnavailable) {
+		log.Errorf("fail to get transfer %v: %v", id, err)
+		return nil, err
+	}
+	if data == nil {
+		return nil, ErrTransferNotFound
+	}
+	report = &models.ExportReport{}
+	if err = json.Unmarshal(data, report); err != nil {
+		log.Errorf("fail to decode transfer %v: %v", id, err)
+		return nil, err
+	}
+	return report, nil
+}
+
+// saveTransfer 保存导入记录并清理本副本中过期的记录，记录保存后不再修改
+func saveTransfer(report *models.ExportReport) {
+	transfers.Lock()
+	transfers.m[report.Id] = report
+	for k, v := range transfers.m {
+		if time.Since(v.FinishedAt) > config.Conf.JobTTL {
+			delete(transfers.m, k)
+		}
+	}
+	transfers.Unlock()
+	data, _ := json.Marshal(report)
+	if err := cache.SetBytes(transferSchema.Key(report.Id), data, config.Conf.JobTTL); err != nil && !errors.Is(err, cache.ErrUnavailable) {
+		log.Warnf("fail to save transfer %v: %v", report.Id, err)
+	}
+}
@@ -0,0 +1,32 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/models"
+)
+
+func TestWithTransferOptions(t *testing.T) {
+	// 未指定时新建私密歌单
+	assert.Equal(t, models.PrivacyPrivate, transferOptions(context.Background()).Privacy)
+	ctx, err := WithTransferOptions(context.Background(), &models.TransferOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, models.PrivacyPrivate, transferOptions(ctx).Privacy)
+
+	ctx, err = WithTransferOptions(context.Background(), &models.TransferOptions{Privacy: models.PrivacyUnlisted})
+	assert.NoError(t, err)
+	assert.Equal(t, models.PrivacyUnlisted, transferOptions(ctx).Privacy)
+
+	_, err = WithTransferOptions(context.Background(), &models.TransferOptions{Privacy: "friends"})
+	assert.Error(t, err)
+
+	// 地区代码统一为大写
+	ctx, err = WithTransferOptions(context.Background(), &models.TransferOptions{Market: " tw"})
+	assert.NoError(t, err)
+	assert.Equal(t, "TW", transferOptions(ctx).Market)
+	_, err = WithTransferOptions(context.Background(), &models.TransferOptions{Market: "TWN"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,166 @@
+package logic
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"GoMusic/common/event"
+	"GoMusic/common/models"
+	"GoMusic/initialize/config"
+	"GoMusic/initialize/log"
+	"GoMusic/repo/cache"
+	"GoMusic/repo/db"
+)
+
+const healthCheckLock = "lock:health_check"
+
+var ErrWatchNotFound = errors.New("订阅不存在")
+
+// Watch 订阅网易云歌单的下架提醒，创建时记录当前已下架的歌曲作为基线，之后只通知新下架的歌曲
+func Watch(ctx context.Context, link, webhook, email string) (*models.WatchedPlaylist, error) {
+	if webhook == "" && email == "" {
+		return nil, errors.New("webhook 与 email 至少填写一项")
+	}
+	if webhook != "" {
+		if u, err := url.ParseRequestURI(webhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return nil, errors.New("无效的 webhook 地址")
+		}
+	}
+	if email != "" {
+		if _, err := mail.ParseAddress(email); err != nil {
+			return nil, errors.New("无效的邮箱地址")
+		}
+	}
+	availability, err := NetEasyAvailability(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	watch := &models.WatchedPlaylist{
+		Token:       newWatchToken(),
+		Link:        link,
+		Webhook:     webhook,
+		Email:       email,
+		Unavailable: joinSongIds(availability.Unavailable),
+		CheckedAt:   &now,
+	}
+	return watch, db.CreateWatch(watch)
+}
+
+// Unwatch 取消订阅
+func Unwatch(token string) error {
+	watch, err := db.GetWatch(token)
+	if err != nil {
+		return err
+	}
+	if watch == nil {
+		return ErrWatchNotFound
+	}
+	if err := db.DeleteFollowedArtists(watch.ID); err != nil {
+		return err
+	}
+	_, err = db.DeleteWatch(token)
+	return err
+}
+
+// CheckWatchNow 立即检查订阅的歌单，有新下架歌曲时发送通知
+func CheckWatchNow(ctx context.Context, token string) (*models.HealthReport, error) {
+	watch, err := db.GetWatch(token)
+	if err != nil {
+		return nil, err
+	}
+	if watch == nil {
+		return nil, ErrWatchNotFound
+	}
+	return checkWatch(ctx, watch)
+}
+
+func checkWatch(ctx context.Context, watch *models.WatchedPlaylist) (*models.HealthReport, error) {
+	availability, err := NetEasyAvailability(ctx, watch.Link)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]struct{})
+	for _, v := range strings.Split(watch.Unavailable, ",") {
+		known[v] = struct{}{}
+	}
+	now := time.Now()
+	report := &models.HealthReport{
+		Type:          models.ReportDelisted,
+		Link:          watch.Link,
+		Name:          availability.Name,
+		Total:         availability.Total,
+		Unavailable:   len(availability.Unavailable),
+		NewlyDelisted: make([]*models.UnavailableSong, 0),
+		CheckedAt:     now,
+	}
+	for _, v := range availability.Unavailable {
+		if _, ok := known[strconv.FormatUint(uint64(v.Id), 10)]; !ok {
+			report.NewlyDelisted = append(report.NewlyDelisted, v)
+		}
+	}
+	if len(report.NewlyDelisted) > 0 {
+		notify(watch, healthReportNotification(report))
+		event.Publish(ctx, event.SyncDiffDetected, report)
+	}
+
+	watch.Unavailable = joinSongIds(availability.Unavailable)
+	watch.CheckedAt = &now
+	return report, db.UpdateWatch(watch)
+}
+
+// StartHealthCheck 启动订阅歌单的定期检查，同时检查关注歌手的新专辑
+func StartHealthCheck() {
+	go func() {
+		ticker := time.NewTicker(config.Conf.HealthCheckInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			// 多副本部署时同一时刻只有一个副本执行检查
+			err := cache.RunWithLease(context.Background(), healthCheckLock, config.Conf.LockTTL, checkAllWatches)
+			if err != nil && !errors.Is(err, cache.ErrLeaseHeld) {
+				log.Errorf("fail to run health check: %v", err)
+			}
+		}
+	}()
+}
+
+// checkAllWatches 逐个检查订阅歌单，ctx 被取消（租约丢失）时停止
+func checkAllWatches(ctx context.Context) error {
+	watches, err := db.ListWatches()
+	if err != nil {
+		return err
+	}
+	for _, watch := range watches {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if _, err := checkWatch(ctx, watch); err != nil {
+			log.WithContext(ctx).Errorf("fail to check watched playlist %v: %v", watch.Link, err)
+		}
+		if _, err := checkArtists(ctx, watch); err != nil {
+			log.WithContext(ctx).Errorf("fail to check followed artists of %v: %v", watch.Link, err)
+		}
+	}
+	return nil
+}
+
+func joinSongIds(songs []*models.UnavailableSong) string {
+	ids := make([]string, 0, len(songs))
+	for _, v := range songs {
+		ids = append(ids, strconv.FormatUint(uint64(v.Id), 10))
+	}
+	return strings.Join(ids, ",")
+}
+
+func newWatchToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,328 @@
+package logic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"GoMusic/common/format"
+	"GoMusic/common/models"
+	"GoMusic/httputil"
+	"GoMusic/initialize/config"
+	"GoMusic/initialize/log"
+)
+
+const (
+	youtubeMusicList = "youtube:%v:%v"
+
+	platformYouTubeMusic = "youtube"
+	// youtubeMusicClientVersion InnerTube 网页版客户端版本，过旧时接口会拒绝请求
+	youtubeMusicClientVersion = "1.20240101.01.00"
+	// youtubeMusicMaxPages 最多获取的页数，每页约 100 首
+	youtubeMusicMaxPages = 100
+)
+
+var (
+	// youtubeMusicIdPrefixes 歌单 id 的前缀：PL 用户歌单、OLAK5uy_ 专辑、RDCLAK5uy_ 官方歌单
+	youtubeMusicIdPrefixes = []string{"PL", "OLAK5uy_", "RDCLAK5uy_"}
+
+	errUnsupportedYouTubeMusicLink = errors.New("暂不支持该 YouTube 链接，请使用包含 list= 的歌单链接")
+)
+
+func init() {
+	RegisterProvider(&youtubeMusicProvider{linkProvider{
+		name:     platformYouTubeMusic,
+		hosts:    []string{"youtube.com", "youtu.be"},
+		discover: YouTubeMusicDiscover,
+	}})
+}
+
+// youtubeMusicProvider 除链接外也接受单独的歌单 id
+type youtubeMusicProvider struct {
+	linkProvider
+}
+
+func (p *youtubeMusicProvider) Match(link string) bool {
+	return p.linkProvider.Match(link) || isYouTubeMusicPlaylistId(strings.TrimSpace(link))
+}
+
+// YouTubeMusicDiscover 通过 InnerTube browse 接口获取 YouTube Music 歌单，如
+// https://music.youtube.com/playlist?list=PL4fGSI1pDJn6O1LS0XSdF3RyO0Rq_LDeI，也支持 youtube.com 链接与单独的歌单 id
+func YouTubeMusicDiscover(ctx context.Context, link string) (*models.SongList, error) {
+	id, err := getYouTubeMusicPlaylistId(link)
+	if err != nil {
+		return nil, err
+	}
+	// 同一歌单、同一语言的并发请求只向 YouTube 转发一次
+	return shared(ctx, fmt.Sprintf(youtubeMusicList, id, youtubeMusicHl(ctx)), func(ctx context.Context) (*models.SongList, error) {
+		return youtubeMusicDiscover(ctx, id)
+	})
+}
+
+func youtubeMusicDiscover(ctx context.Context, id string) (*models.SongList, error) {
+	first, err := youtubeMusicBrowse(ctx, "VL"+id, "")
+	if err != nil {
+		return nil, err
+	}
+	header, items, continuation := youtubeMusicPlaylist(first)
+	if header == nil && len(items) == 0 {
+		log.WithContext(ctx).Errorf("fail to get youtube music playlist %v: no playlist shelf", id)
+		return nil, errors.New("获取 YouTube Music 歌单失败，请检查歌单是否存在或已设为私密")
+	}
+	// 后续页依赖上一页返回的 continuation，只能依次获取
+	for page := 1; continuation != "" && page < youtubeMusicMaxPages; page++ {
+		next, err := youtubeMusicBrowse(ctx, "", continuation)
+		if err != nil {
+			return nil, err
+		}
+		var more []*models.YouTubeMusicShelfItem
+		more, continuation = youtubeMusicContinuation(next)
+		items = append(items, more...)
+	}
+
+	tracks := make([]*models.Song, 0, len(items))
+	durations := make([]int, 0, len(items))
+	totalDuration := 0
+	for _, v := range items {
+		track := youtubeMusicSong(v)
+		if track == nil {
+			continue
+		}
+		totalDuration += track.DurationMs
+		durations = append(durations, track.DurationMs)
+		tracks = append(tracks, track)
+	}
+	songsString := format.Tracks(tracks)
+	songList := &models.SongList{
+		Songs:      songsString,
+		Tracks:     tracks,
+		Durations:  durations,
+		SongsCount: len(songsString),
+		Tags:       make([]string, 0),
+		Summary:    format.Summarize(songsString, totalDuration),
+	}
+	if header != nil {
+		songList.Name = header.Title.String()
+		songList.Description = header.Description.String()
+		songList.Cover = youtubeMusicCover(header)
+	}
+	return songList, nil
+}
+
+// youtubeMusicHl InnerTube 的界面语言，决定返回的歌名与歌手名；不支持文字子标签，中文按简繁体取 zh-CN 或 zh-TW，默认 zh-CN
+func youtubeMusicHl(ctx context.Context) string {
+	locale := requestLocale(ctx)
+	if locale == "" {
+		return "zh-CN"
+	}
+	parts := strings.Split(locale, "-")
+	if parts[0] == "zh" {
+		if localeScript(locale) == "Hant" {
+			return "zh-TW"
+		}
+		return "zh-CN"
+	}
+	for _, v := range parts[1:] {
+		if len(v) == 2 {
+			return parts[0] + "-" + v
+		}
+	}
+	return parts[0]
+}
+
+// youtubeMusicBrowse 请求 browse 接口；首页传入 browseId，后续页传入 continuation
+func youtubeMusicBrowse(ctx context.Context, browseId, continuation string) (*models.YouTubeMusicBrowse, error) {
+	req := &models.YouTubeMusicBrowseReq{BrowseId: browseId, Continuation: continuation}
+	req.Context.Client.ClientName = "WEB_REMIX"
+	req.Context.Client.ClientVersion = youtubeMusicClientVersion
+	req.Context.Client.Hl = youtubeMusicHl(ctx)
+	data, _ := json.Marshal(req)
+
+	link := config.Conf.Upstream.YouTubeMusic + "/browse?prettyPrint=false"
+	if continuation != "" {
+		// 旧版布局从查询参数读取 continuation
+		link += "&type=next&ctoken=" + url.QueryEscape(continuation) + "&continuation=" + url.QueryEscape(continuation)
+	}
+	header := http.Header{
+		"Content-Type": {"application/json"},
+		"Origin":       {"https://music.youtube.com"},
+		"Referer":      {"https://music.youtube.com/"},
+	}
+	resp, err := httputil.Do(ctx, "POST", link, header, bytes.NewReader(data))
+	if err != nil {
+		log.WithContext(ctx).Errorf("fail to get youtube music playlist: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.WithContext(ctx).Errorf("fail to get youtube music playlist %v, status: %v", browseId, resp.StatusCode)
+		return nil, fmt.Errorf("获取 YouTube Music 歌单失败，状态码：%d", resp.StatusCode)
+	}
+	browse := &models.YouTubeMusicBrowse{}
+	if err = decodeBody(resp.Body, browse); err != nil {
+		return nil, err
+	}
+	return browse, nil
+}
+
+// youtubeMusicPlaylist 从首页中取出歌单信息、歌曲与下一页的 continuation
+func youtubeMusicPlaylist(browse *models.YouTubeMusicBrowse) (*models.YouTubeMusicHeader, []*models.YouTubeMusicShelfItem, string) {
+	header := browse.Header.MusicDetailHeaderRenderer
+	var sections []models.YouTubeMusicSectionList
+	if single := browse.Contents.SingleColumnBrowseResultsRenderer; single != nil {
+		for _, v := range single.Tabs {
+			sections = append(sections, v.TabRenderer.Content)
+		}
+	}
+	if two := browse.Contents.TwoColumnBrowseResultsRenderer; two != nil {
+		for _, v := range two.Tabs {
+			sections = append(sections, v.TabRenderer.Content)
+		}
+		sections = append(sections, two.SecondaryContents)
+	}
+
+	var (
+		items        []*models.YouTubeMusicShelfItem
+		continuation string
+	)
+	for _, section := range sections {
+		for _, v := range section.SectionListRenderer.Contents {
+			if v.MusicResponsiveHeaderRenderer != nil && header == nil {
+				header = v.MusicResponsiveHeaderRenderer
+			}
+			if v.MusicPlaylistShelfRenderer != nil {
+				shelf := v.MusicPlaylistShelfRenderer
+				items = append(items, shelf.Contents...)
+				continuation = youtubeMusicNext(shelf, shelf.Contents)
+			}
+		}
+	}
+	return header, items, continuation
+}
+
+// youtubeMusicContinuation 从后续页中取出歌曲与下一页的 continuation
+func youtubeMusicContinuation(browse *models.YouTubeMusicBrowse) ([]*models.YouTubeMusicShelfItem, string) {
+	if shelf := browse.ContinuationContents.MusicPlaylistShelfContinuation; shelf != nil {
+		return shelf.Contents, youtubeMusicNext(shelf, shelf.Contents)
+	}
+	var items []*models.YouTubeMusicShelfItem
+	for _, v := range browse.OnResponseReceivedActions {
+		items = append(items, v.AppendContinuationItemsAction.ContinuationItems...)
+	}
+	return items, youtubeMusicNext(nil, items)
+}
+
+// youtubeMusicNext 旧版布局的 continuation 位于 shelf，新版布局位于最后一项
+func youtubeMusicNext(shelf *models.YouTubeMusicShelf, items []*models.YouTubeMusicShelfItem) string {
+	if shelf != nil && len(shelf.Continuations) > 0 {
+		return shelf.Continuations[0].NextContinuationData.Continuation
+	}
+	if len(items) > 0 {
+		if last := items[len(items)-1].ContinuationItemRenderer; last != nil {
+			return last.ContinuationEndpoint.ContinuationCommand.Token
+		}
+	}
+	return ""
+}
+
+// youtubeMusicSong 解析歌名、歌手、专辑与时长，continuation 等非歌曲项返回 nil
+func youtubeMusicSong(item *models.YouTubeMusicShelfItem) *models.Song {
+	song := item.MusicResponsiveListItemRenderer
+	if song == nil || len(song.FlexColumns) == 0 {
+		return nil
+	}
+	name := song.FlexColumns[0].Renderer.Text.String()
+	if name == "" {
+		return nil
+	}
+	authors := make([]string, 0)
+	if len(song.FlexColumns) > 1 {
+		// 歌手之间以 " & "、", " 分隔
+		for _, v := range song.FlexColumns[1].Renderer.Text.Runs {
+			switch text := strings.TrimSpace(v.Text); text {
+			case "", "&", ",", "•":
+			default:
+				authors = append(authors, text)
+			}
+		}
+	}
+	track := &models.Song{Name: name, Artists: authors, SourceId: song.PlaylistItemData.VideoId}
+	if len(song.FlexColumns) > 2 {
+		track.Album = song.FlexColumns[2].Renderer.Text.String()
+	}
+	if len(song.FixedColumns) > 0 {
+		track.DurationMs = parseYouTubeMusicDuration(song.FixedColumns[0].Renderer.Text.String())
+	}
+	return track
+}
+
+// parseYouTubeMusicDuration 解析 3:45、1:02:03 格式的时长，返回毫秒，无法解析时返回 0
+func parseYouTubeMusicDuration(s string) int {
+	seconds := 0
+	for _, v := range strings.Split(s, ":") {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0
+		}
+		seconds = seconds*60 + n
+	}
+	return seconds * 1000
+}
+
+// youtubeMusicCover 取尺寸最大的封面，即最后一张
+func youtubeMusicCover(header *models.YouTubeMusicHeader) string {
+	thumbnail := header.Thumbnail.CroppedSquareThumbnailRenderer
+	if thumbnail == nil {
+		thumbnail = header.Thumbnail.MusicThumbnailRenderer
+	}
+	if thumbnail == nil || len(thumbnail.Thumbnail.Thumbnails) == 0 {
+		return ""
+	}
+	thumbnails := thumbnail.Thumbnail.Thumbnails
+	return thumbnails[len(thumbnails)-1].Url
+}
+
+// getYouTubeMusicPlaylistId 从链接的 list 参数或 /browse/VL{id} 路径中解析歌单 id
+func getYouTubeMusicPlaylistId(link string) (string, error) {
+	link = strings.TrimSpace(link)
+	if isYouTubeMusicPlaylistId(link) {
+		return link, nil
+	}
+	parse, err := url.Parse(link)
+	if err != nil {
+		return "", errUnsupportedYouTubeMusicLink
+	}
+	if id := parse.Query().Get("list"); isYouTubeMusicPlaylistId(id) {
+		return id, nil
+	}
+	if _, id, ok := strings.Cut(parse.Path, "/browse/VL"); ok && isYouTubeMusicPlaylistId(id) {
+		return id, nil
+	}
+	return "", errUnsupportedYouTubeMusicLink
+}
+
+// isYouTubeMusicPlaylistId 是否为歌单 id：已知前缀且仅含字母、数字、- 与 _
+func isYouTubeMusicPlaylistId(id string) bool {
+	prefixed := false
+	for _, v := range youtubeMusicIdPrefixes {
+		if strings.HasPrefix(id, v) && len(id) > len(v)+8 {
+			prefixed = true
+			break
+		}
+	}
+	if !prefixed {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
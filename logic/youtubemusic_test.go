@@ -0,0 +1,87 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/models"
+	"GoMusic/initialize/config"
+)
+
+// youtubeMusicItem 生成一首歌曲的 musicResponsiveListItemRenderer
+func youtubeMusicItem(name, duration string, artists ...string) string {
+	runs := ""
+	for i, v := range artists {
+		if i > 0 {
+			runs += `,{"text":" & "},`
+		}
+		runs += fmt.Sprintf(`{"text":%q}`, v)
+	}
+	return fmt.Sprintf(`{"musicResponsiveListItemRenderer":{"playlistItemData":{"videoId":"v-%s"},"flexColumns":[
+		{"musicResponsiveListItemFlexColumnRenderer":{"text":{"runs":[{"text":%q}]}}},
+		{"musicResponsiveListItemFlexColumnRenderer":{"text":{"runs":[%s]}}}],
+		"fixedColumns":[{"musicResponsiveListItemFixedColumnRenderer":{"text":{"runs":[{"text":%q}]}}}]}}`, name, name, runs, duration)
+}
+
+func TestYouTubeMusicDiscover(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &models.YouTubeMusicBrowseReq{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(req))
+		assert.Equal(t, "WEB_REMIX", req.Context.Client.ClientName)
+		switch {
+		case req.BrowseId == "VLPLtest1234567890":
+			// 新版布局，最后一项为下一页
+			_, _ = fmt.Fprintf(w, `{"contents":{"twoColumnBrowseResultsRenderer":{
+				"tabs":[{"tabRenderer":{"content":{"sectionListRenderer":{"contents":[{"musicResponsiveHeaderRenderer":{
+					"title":{"runs":[{"text":"测试歌单"}]},
+					"description":{"musicDescriptionShelfRenderer":{"description":{"runs":[{"text":"简介"}]}}},
+					"thumbnail":{"musicThumbnailRenderer":{"thumbnail":{"thumbnails":[{"url":"small"},{"url":"large"}]}}}}}]}}}}],
+				"secondaryContents":{"sectionListRenderer":{"contents":[{"musicPlaylistShelfRenderer":{"contents":[%s,%s,
+					{"continuationItemRenderer":{"continuationEndpoint":{"continuationCommand":{"token":"next"}}}}]}}]}}}}}`,
+				youtubeMusicItem("晴天", "4:29", "周杰伦"), youtubeMusicItem("Song", "1:02:03", "A", "B"))
+		case req.Continuation == "next":
+			_, _ = fmt.Fprintf(w, `{"onResponseReceivedActions":[{"appendContinuationItemsAction":{"continuationItems":[%s]}}]}`,
+				youtubeMusicItem("稻香", "3:43", "周杰伦"))
+		default:
+			t.Errorf("unexpected request: %+v", req)
+		}
+	}))
+	defer server.Close()
+	upstream := config.Conf.Upstream.YouTubeMusic
+	config.Conf.Upstream.YouTubeMusic = server.URL
+	defer func() { config.Conf.Upstream.YouTubeMusic = upstream }()
+
+	songList, err := YouTubeMusicDiscover(context.Background(), "https://music.youtube.com/playlist?list=PLtest1234567890")
+	assert.NoError(t, err)
+	assert.Equal(t, "测试歌单", songList.Name)
+	assert.Equal(t, "简介", songList.Description)
+	assert.Equal(t, "large", songList.Cover)
+	assert.Equal(t, []string{"晴天 - 周杰伦", "Song - A / B", "稻香 - 周杰伦"}, songList.Songs)
+	assert.Equal(t, []int{269000, 3723000, 223000}, songList.Durations)
+	assert.Equal(t, 3, songList.SongsCount)
+	assert.Equal(t, &models.Song{Name: "Song", Artists: []string{"A", "B"}, DurationMs: 3723000, SourceId: "v-Song"}, songList.Tracks[1])
+}
+
+func TestGetYouTubeMusicPlaylistId(t *testing.T) {
+	for link, id := range map[string]string{
+		"https://music.youtube.com/playlist?list=PL4fGSI1pDJn6O1LS0XSdF3RyO0Rq_LDeI":   "PL4fGSI1pDJn6O1LS0XSdF3RyO0Rq_LDeI",
+		"https://www.youtube.com/watch?v=abc&list=PL4fGSI1pDJn6O1LS0XSdF3RyO0Rq_LDeI":  "PL4fGSI1pDJn6O1LS0XSdF3RyO0Rq_LDeI",
+		"https://music.youtube.com/browse/VLOLAK5uy_kx6pM1cD9mQcSPKLMc2yJQ4gZ4XnHd5Yc": "OLAK5uy_kx6pM1cD9mQcSPKLMc2yJQ4gZ4XnHd5Yc",
+		" PL4fGSI1pDJn6O1LS0XSdF3RyO0Rq_LDeI ":                                         "PL4fGSI1pDJn6O1LS0XSdF3RyO0Rq_LDeI",
+	} {
+		got, err := getYouTubeMusicPlaylistId(link)
+		assert.NoError(t, err, link)
+		assert.Equal(t, id, got, link)
+	}
+	_, err := getYouTubeMusicPlaylistId("https://www.youtube.com/watch?v=dQw4w9WgXcQ")
+	assert.ErrorIs(t, err, errUnsupportedYouTubeMusicLink)
+
+	assert.Equal(t, platformYouTubeMusic, MatchProvider("PL4fGSI1pDJn6O1LS0XSdF3RyO0Rq_LDeI").Name())
+	assert.Nil(t, MatchProvider("PLAYLIST"))
+}
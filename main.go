@@ -1,18 +1,9 @@
 package main
 
 import (
-	"fmt"
-
-	_ "GoMusic/repo/db"
-
-	"GoMusic/initialize"
-	"GoMusic/initialize/log"
+	"os"
 )
 
 func main() {
-	r := initialize.NewRouter()
-	if err := r.Run(fmt.Sprintf(":%d", 8081)); err != nil {
-		log.Errorf("fail to run server: %v", err)
-		panic(err)
-	}
+	os.Exit(run(os.Args[1:]))
 }
@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"GoMusic/initialize/config"
+	"GoMusic/initialize/log"
+	"GoMusic/initialize/metrics"
+)
+
+// ErrUnavailable Redis 不可用，旁路期间缓存操作直接返回此错误，不再等待连接超时
+var ErrUnavailable = errors.New("redis unavailable, cache bypassed")
+
+// health 记录 Redis 的可用性：连续失败 threshold 次后进入旁路模式，
+// 由后台每隔 retry 探测一次，探测成功后恢复；旁路期间调用方应退化为直接请求数据库或上游
+type health struct {
+	threshold int
+	retry     time.Duration
+	ping      func(ctx context.Context) error
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	since    time.Time // 进入旁路模式的时间，为零表示可用
+	outages  int64
+	errors   int64
+	bypassed int64
+}
+
+// CacheStats 缓存的可用性统计
+type CacheStats struct {
+	Available bool      `json:"available"`
+	Failures  int       `json:"failures"`        // 当前连续失败次数
+	Outages   int64     `json:"outages"`         // 累计进入旁路模式的次数
+	Errors    int64     `json:"errors"`          // 累计失败的操作数
+	Bypassed  int64     `json:"bypassed"`        // 旁路期间跳过的操作数
+	Since     time.Time `json:"since,omitempty"` // 本次不可用的开始时间
+	LocalSize int       `json:"local_size"`      // 一级缓存中的值个数
+}
+
+var (
+	redisHealth = newHealth(config.Conf.CacheFailureThreshold, config.Conf.CacheRetryInterval, func(c context.Context) error {
+		return rdb.Ping(c).Err()
+	})
+
+	redisErrors = metrics.NewCounterVec("gomusic_redis_errors_total", "Failed Redis operations, excluding operations skipped while bypassed.")
+	_           = metrics.NewGaugeFunc("gomusic_redis_available", "Whether Redis is in use (1) or bypassed (0).", func() float64 {
+		if Available() {
+			return 1
+		}
+		return 0
+	})
+)
+
+// newHealth threshold 为 0 时不进入旁路模式
+func newHealth(threshold int, retry time.Duration, ping func(ctx context.Context) error) *health {
+	return &health{threshold: threshold, retry: retry, ping: ping, now: time.Now}
+}
+
+// do 在 Redis 可用时执行 fn 并记录结果，旁路期间直接返回 ErrUnavailable
+func (h *health) do(fn func() error) error {
+	h.mu.Lock()
+	if !h.since.IsZero() {
+		h.bypassed++
+		h.mu.Unlock()
+		return ErrUnavailable
+	}
+	h.mu.Unlock()
+	err := fn()
+	h.record(err)
+	return err
+}
+
+func (h *health) record(err error) {
+	var redisErr redis.Error
+	// key 不存在、命令错误与调用方取消均不代表 Redis 不可用
+	if err != nil && (err == redis.Nil || errors.As(err, &redisErr) || errors.Is(err, context.Canceled)) {
+		err = nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err == nil {
+		h.failures = 0
+		return
+	}
+	h.errors++
+	h.failures++
+	redisErrors.Inc()
+	if h.threshold > 0 && h.failures >= h.threshold && h.since.IsZero() {
+		h.since = h.now()
+		h.outages++
+		log.Errorf("redis unavailable after %d failures, bypass cache: %v", h.failures, err)
+		go h.reconnect()
+	}
+}
+
+// reconnect 每隔 retry 探测一次，成功后退出旁路模式
+func (h *health) reconnect() {
+	ticker := time.NewTicker(h.retry)
+	defer ticker.Stop()
+	for range ticker.C {
+		c, cancel := context.WithTimeout(context.Background(), h.retry)
+		err := h.ping(c)
+		cancel()
+		if err == nil {
+			h.mu.Lock()
+			log.Infof("redis recovered after %v", h.now().Sub(h.since))
+			h.failures, h.since = 0, time.Time{}
+			h.mu.Unlock()
+			return
+		}
+	}
+}
+
+func (h *health) stats() *CacheStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return &CacheStats{
+		Available: h.since.IsZero(), Failures: h.failures, Outages: h.outages, Errors: h.errors, Bypassed: h.bypassed, Since: h.since,
+	}
+}
+
+// Stats 缓存的可用性统计
+func Stats() *CacheStats {
+	stats := redisHealth.stats()
+	stats.LocalSize = local.Len()
+	return stats
+}
+
+// Available Redis 当前是否可用，旁路期间返回 false
+func Available() bool {
+	return redisHealth.stats().Available
+}
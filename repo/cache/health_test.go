@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth(t *testing.T) {
+	down := errors.New("dial tcp: connection refused")
+	recovered := make(chan struct{})
+	h := newHealth(2, 10*time.Millisecond, func(context.Context) error {
+		select {
+		case <-recovered:
+			return nil
+		default:
+			return down
+		}
+	})
+
+	// key 不存在不计为失败
+	assert.Equal(t, redis.Nil, h.do(func() error { return redis.Nil }))
+	assert.Equal(t, down, h.do(func() error { return down }))
+	assert.NoError(t, h.do(func() error { return nil }))
+	assert.Equal(t, 0, h.stats().Failures)
+
+	_ = h.do(func() error { return down })
+	_ = h.do(func() error { return down })
+	stats := h.stats()
+	assert.False(t, stats.Available)
+	assert.Equal(t, int64(1), stats.Outages)
+
+	// 旁路期间不再访问 Redis
+	called := false
+	assert.Equal(t, ErrUnavailable, h.do(func() error { called = true; return nil }))
+	assert.False(t, called)
+	assert.Equal(t, int64(1), h.stats().Bypassed)
+
+	close(recovered)
+	assert.Eventually(t, func() bool { return h.stats().Available }, time.Second, 5*time.Millisecond)
+	assert.NoError(t, h.do(func() error { return nil }))
+	assert.Equal(t, int64(3), h.stats().Errors)
+}
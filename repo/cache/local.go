@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// lru 进程内的 LRU 缓存，作为 Redis 之前的一级缓存；热门歌单的歌曲无需每次请求 Redis
+type lru struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	items map[string]*list.Element
+	order *list.List // 队首为最近使用
+	now   func() time.Time
+}
+
+type lruEntry struct {
+	key      string
+	value    string
+	expireAt time.Time
+}
+
+// newLRU size 为 0 时不缓存
+func newLRU(size int, ttl time.Duration) *lru {
+	return &lru{size: size, ttl: ttl, items: make(map[string]*list.Element), order: list.New(), now: time.Now}
+}
+
+// Get 返回未过期的值
+func (l *lru) Get(key string) (string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.items[key]
+	if !ok {
+		return "", false
+	}
+	entry := e.Value.(*lruEntry)
+	if !l.now().Before(entry.expireAt) {
+		l.order.Remove(e)
+		delete(l.items, key)
+		return "", false
+	}
+	l.order.MoveToFront(e)
+	return entry.value, true
+}
+
+// Set 写入值，过期时间取 ttl 与一级缓存 TTL 中较短者，ttl 为 0 表示不限；超出容量时淘汰最久未使用的值
+func (l *lru) Set(key, value string, ttl time.Duration) {
+	if l.size <= 0 {
+		return
+	}
+	if ttl <= 0 || ttl > l.ttl {
+		ttl = l.ttl
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	expireAt := l.now().Add(ttl)
+	if e, ok := l.items[key]; ok {
+		entry := e.Value.(*lruEntry)
+		entry.value, entry.expireAt = value, expireAt
+		l.order.MoveToFront(e)
+		return
+	}
+	l.items[key] = l.order.PushFront(&lruEntry{key: key, value: value, expireAt: expireAt})
+	for l.order.Len() > l.size {
+		e := l.order.Back()
+		l.order.Remove(e)
+		delete(l.items, e.Value.(*lruEntry).key)
+	}
+}
+
+// Len 当前缓存的值个数，包括已过期但尚未淘汰的值
+func (l *lru) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
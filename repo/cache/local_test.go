@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	now := time.Now()
+	l := newLRU(2, time.Minute)
+	l.now = func() time.Time { return now }
+
+	l.Set("a", "1", 0)
+	l.Set("b", "2", time.Hour)
+	_, _ = l.Get("a")
+	l.Set("c", "3", 10*time.Second) // 淘汰最久未使用的 b
+	assert.Equal(t, 2, l.Len())
+	_, ok := l.Get("b")
+	assert.False(t, ok)
+	v, ok := l.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, "1", v)
+
+	// c 的过期时间短于一级缓存 TTL
+	now = now.Add(30 * time.Second)
+	_, ok = l.Get("c")
+	assert.False(t, ok)
+	_, ok = l.Get("a")
+	assert.True(t, ok)
+	now = now.Add(30 * time.Second)
+	_, ok = l.Get("a")
+	assert.False(t, ok)
+
+	disabled := newLRU(0, time.Minute)
+	disabled.Set("a", "1", 0)
+	assert.Equal(t, 0, disabled.Len())
+}
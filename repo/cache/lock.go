@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"GoMusic/initialize/log"
+)
+
+// ErrLeaseHeld 租约正被其他副本持有
+var ErrLeaseHeld = errors.New("lease is held by another owner")
+
+// 仅当锁仍由自己持有时才删除，避免误删其他副本在过期后重新获取的锁
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// 仅当锁仍由自己持有时才续期
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// TryLock 尝试获取锁，成功时返回用于释放锁的 token
+func TryLock(key string, ttl time.Duration) (string, bool, error) {
+	token := newToken()
+	var ok bool
+	err := redisHealth.do(func() (err error) {
+		ok, err = rdb.SetNX(ctx, key, token, ttl).Result()
+		return err
+	})
+	if err != nil {
+		log.Errorf("TryLock error: %v", err)
+		return "", false, err
+	}
+	return token, ok, nil
+}
+
+// Renew 续期锁，锁已过期或被其他副本接管时返回 false
+func Renew(key, token string, ttl time.Duration) (bool, error) {
+	var n int
+	err := redisHealth.do(func() (err error) {
+		n, err = renewScript.Run(ctx, rdb, []string{key}, token, ttl.Milliseconds()).Int()
+		return err
+	})
+	if err != nil {
+		log.Errorf("Renew error: %v", err)
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// Unlock 释放锁
+func Unlock(key, token string) error {
+	err := redisHealth.do(func() error {
+		return unlockScript.Run(ctx, rdb, []string{key}, token).Err()
+	})
+	if err != nil && err != redis.Nil {
+		log.Errorf("Unlock error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// WaitUnlock 等待锁被释放或超时，返回锁是否已释放
+func WaitUnlock(key string, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		var n int64
+		err := redisHealth.do(func() (err error) {
+			n, err = rdb.Exists(ctx, key).Result()
+			return err
+		})
+		if err != nil || n == 0 {
+			return true
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return false
+}
+
+// RunWithLease 持有租约期间执行 fn，执行过程中每 ttl/3 自动续期一次，ttl 至少为 3ns。
+// 持有者异常退出后租约在 ttl 后过期，其他副本即可接管；租约已被持有时返回 ErrLeaseHeld。
+// 租约被其他副本接管，或 ttl 内均未能续期时取消传给 fn 的 ctx，fn 应尽快返回，避免与接管的副本重复执行
+func RunWithLease(c context.Context, key string, ttl time.Duration, fn func(ctx context.Context) error) error {
+	token, ok, err := TryLock(key, ttl)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLeaseHeld
+	}
+	defer Unlock(key, token)
+
+	leaseCtx, cancel := context.WithCancel(c)
+	defer cancel()
+	go func() {
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		renewed := time.Now()
+		for {
+			select {
+			case <-leaseCtx.Done():
+				return
+			case <-ticker.C:
+				ok, err := Renew(key, token, ttl)
+				switch {
+				case err == nil && ok:
+					renewed = time.Now()
+					continue
+				case err != nil && time.Since(renewed) < ttl:
+					// 租约尚未过期，下次再续期
+					continue
+				}
+				log.Warnf("lease %v lost before task finished", key)
+				cancel()
+				return
+			}
+		}
+	}()
+	return fn(leaseCtx)
+}
+
+func newToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
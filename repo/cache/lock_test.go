@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunWithLease(t *testing.T) {
+	requireRedis(t)
+	key := "lock:test"
+	err := RunWithLease(context.Background(), key, time.Second, func(ctx context.Context) error {
+		// 持有期间其他副本无法获取
+		assert.ErrorIs(t, RunWithLease(ctx, key, time.Second, func(context.Context) error { return nil }), ErrLeaseHeld)
+		// 超过 ttl 仍被续期
+		time.Sleep(1500 * time.Millisecond)
+		_, ok, err := TryLock(key, time.Second)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		assert.NoError(t, ctx.Err())
+		return nil
+	})
+	assert.NoError(t, err)
+	// 释放后可重新获取
+	assert.True(t, WaitUnlock(key, time.Second))
+}
+
+func TestRunWithLeaseLost(t *testing.T) {
+	requireRedis(t)
+	key := "lock:test_lost"
+	err := RunWithLease(context.Background(), key, 300*time.Millisecond, func(ctx context.Context) error {
+		// 租约被其他副本接管后取消 ctx
+		assert.NoError(t, rdb.Set(ctx, key, "other", time.Second).Err())
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			t.Error("ctx is not cancelled after the lease is lost")
+		}
+		return ctx.Err()
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	// 不会删除其他副本持有的锁
+	token, _ := GetKey(key)
+	assert.Equal(t, "other", token)
+}
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 队列未满时才入队，返回入队后的长度，已满时返回 0
+var pushScript = redis.NewScript(`
+if redis.call("LLEN", KEYS[1]) >= tonumber(ARGV[2]) then
+	return 0
+end
+return redis.call("LPUSH", KEYS[1], ARGV[1])
+`)
+
+// Push 将 value 加入队列 key，队列长度已达 max 时不入队并返回 false
+func Push(key string, value []byte, max int) (bool, error) {
+	var n int
+	err := redisHealth.do(func() (err error) {
+		n, err = pushScript.Run(ctx, rdb, []string{key}, value, max).Int()
+		return err
+	})
+	return n > 0, err
+}
+
+// Pop 取出队列 key 中最早加入的值，队列为空时最多阻塞 timeout，超时返回 nil
+func Pop(c context.Context, key string, timeout time.Duration) ([]byte, error) {
+	var values []string
+	err := redisHealth.do(func() (err error) {
+		values, err = rdb.BRPop(c, timeout, key).Result()
+		return err
+	})
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// BRPOP 返回 key 与值
+	return []byte(values[1]), nil
+}
+
+// HSet 写入哈希 key 的字段并将过期时间重置为 ttl
+func HSet(key string, fields map[string]any, ttl time.Duration) error {
+	return redisHealth.do(func() error {
+		pipeline := rdb.TxPipeline()
+		pipeline.HSet(ctx, key, fields)
+		pipeline.PExpire(ctx, key, ttl)
+		_, err := pipeline.Exec(ctx)
+		return err
+	})
+}
+
+// HGetAll 读取哈希 key 的全部字段，key 不存在时返回空 map
+func HGetAll(key string) (map[string]string, error) {
+	var fields map[string]string
+	err := redisHealth.do(func() (err error) {
+		fields, err = rdb.HGetAll(ctx, key).Result()
+		return err
+	})
+	return fields, err
+}
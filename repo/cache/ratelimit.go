@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+
+	"GoMusic/common/ratelimit"
+)
+
+// 令牌桶保存为哈希 tokens、last，使用 Redis 的时间，避免副本间的时钟偏差；
+// 返回是否放行与消耗后剩余的令牌数，桶回满后自动过期
+var tokenBucketScript = redis.NewScript(`
+redis.replicate_commands()
+local rate, burst = tonumber(ARGV[1]), tonumber(ARGV[2])
+local time = redis.call("TIME")
+local now = tonumber(time[1]) + tonumber(time[2]) / 1000000
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "last")
+local tokens, last = tonumber(bucket[1]) or burst, tonumber(bucket[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - last) * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "last", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.ceil((burst - tokens) / rate * 1000) + 1)
+return {allowed, tostring(tokens)}
+`)
+
+// Allow 消耗 Redis 中令牌桶 key 的一个令牌，速率与容量取自 l，所有副本共用同一个令牌桶
+func Allow(key string, l *ratelimit.Limiter) (*ratelimit.Result, error) {
+	var values []interface{}
+	err := redisHealth.do(func() (err error) {
+		values, err = tokenBucketScript.Run(ctx, rdb, []string{key}, l.Rate, l.Burst).Slice()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	allowed, _ := values[0].(int64)
+	tokens, _ := values[1].(string)
+	remaining, err := strconv.ParseFloat(tokens, 64)
+	if err != nil {
+		return nil, err
+	}
+	return l.Result(allowed == 1, remaining), nil
+}
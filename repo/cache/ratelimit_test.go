@@ -0,0 +1,26 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/ratelimit"
+)
+
+func TestAllow(t *testing.T) {
+	requireRedis(t)
+	key := "ratelimit:test:" + newToken()
+	l := ratelimit.New(0.001, 2)
+	r, err := Allow(key, l)
+	assert.NoError(t, err)
+	assert.True(t, r.Allowed)
+	assert.Equal(t, 1, r.Remaining)
+
+	r, _ = Allow(key, l)
+	assert.True(t, r.Allowed)
+	r, _ = Allow(key, l)
+	assert.False(t, r.Allowed)
+	assert.Equal(t, 0, r.Remaining)
+	assert.Positive(t, r.RetryAfter)
+}
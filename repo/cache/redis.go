@@ -3,61 +3,170 @@ package cache
 import (
 	"context"
 	"errors"
-	"sync"
+	"math/rand"
+	"strconv"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 
+	"GoMusic/initialize/config"
 	"GoMusic/initialize/log"
+	"GoMusic/initialize/metrics"
+	"GoMusic/initialize/trace"
 )
 
 var (
 	ctx = context.Background()
 	rdb *redis.Client
+	// local 一级缓存，仅用于 MGet/MSet 读写的歌曲数据
+	local = newLRU(config.Conf.LocalCacheSize, config.Conf.LocalCacheTTL)
+
+	cacheLookups = metrics.NewCounterVec("gomusic_cache_lookups_total", "Song cache lookups by tier and result.", "tier", "result")
 )
 
 func init() {
 	rdb = redis.NewClient(&redis.Options{
-		Addr:     "127.0.0.1:6379",   // redis 服务端地址
-		Password: "SzW7fh2Fs5d2ypwT", // redis 密码
-		DB:       0,
+		Addr:     config.Conf.RedisAddr,     // redis 服务端地址
+		Password: config.Conf.RedisPassword, // redis 密码
+		DB:       config.Conf.RedisDB,
 	})
 }
 
 func SetKey(key string, value string) error {
-	return rdb.Set(ctx, key, value, 30*time.Second).Err() // 缓存 30 秒
+	return redisHealth.do(func() error {
+		return rdb.Set(ctx, key, value, 30*time.Second).Err() // 缓存 30 秒
+	})
 }
 
 func GetKey(key string) (string, error) {
-	val, err := rdb.Get(ctx, key).Result()
+	var val string
+	err := redisHealth.do(func() (err error) {
+		val, err = rdb.Get(ctx, key).Result()
+		return err
+	})
 	if err != redis.Nil && err != nil {
 		return "", err
 	}
 	return val, nil
 }
 
-func MGet(keys ...string) ([]interface{}, error) {
+func SetBytes(key string, value []byte, expiration time.Duration) error {
+	return redisHealth.do(func() error {
+		return rdb.Set(ctx, key, value, expiration).Err()
+	})
+}
+
+// GetBytes 获取二进制数据，key 不存在时返回 nil
+func GetBytes(key string) ([]byte, error) {
+	var val []byte
+	err := redisHealth.do(func() (err error) {
+		val, err = rdb.Get(ctx, key).Bytes()
+		return err
+	})
+	if err == redis.Nil {
+		return nil, nil
+	}
+	return val, err
+}
+
+// GetDelBytes 获取并删除二进制数据，用于一次性的令牌，key 不存在时返回 nil
+func GetDelBytes(key string) ([]byte, error) {
+	var val []byte
+	err := redisHealth.do(func() (err error) {
+		val, err = rdb.GetDel(ctx, key).Bytes()
+		return err
+	})
+	if err == redis.Nil {
+		return nil, nil
+	}
+	return val, err
+}
+
+// MGet 先从一级缓存读取，其余 key 再请求 Redis 并回填一级缓存；
+// Redis 失败或处于旁路模式时仍返回一级缓存命中的值，未命中的位置为 nil
+func MGet(c context.Context, keys ...string) ([]interface{}, error) {
 	if len(keys) == 0 {
 		return nil, errors.New("keys is empty")
 	}
-	result, err := rdb.MGet(ctx, keys...).Result()
+	result := make([]interface{}, len(keys))
+	missKeys := make([]string, 0, len(keys))
+	missIndex := make([]int, 0, len(keys))
+	for i, k := range keys {
+		if v, ok := local.Get(k); ok {
+			result[i] = v
+			cacheLookups.Inc("local", "hit")
+			continue
+		}
+		missKeys = append(missKeys, k)
+		missIndex = append(missIndex, i)
+	}
+	if len(missKeys) == 0 {
+		return result, nil
+	}
+	_, span := trace.Start(c, "redis.mget", "keys", strconv.Itoa(len(missKeys)))
+	var values []interface{}
+	err := redisHealth.do(func() (err error) {
+		values, err = rdb.MGet(c, missKeys...).Result()
+		return err
+	})
+	span.End(err)
 	if err != nil {
-		log.Errorf("MGet error: %v", err)
+		cacheLookups.Add(float64(len(missKeys)), "redis", "error")
+		if err != ErrUnavailable {
+			log.WithContext(c).Errorf("MGet error: %v", err)
+		}
+		return result, err
 	}
-	return result, err
+	for i, v := range values {
+		result[missIndex[i]] = v
+		if s, ok := v.(string); ok {
+			local.Set(missKeys[i], s, config.Conf.LocalCacheTTL)
+			cacheLookups.Inc("redis", "hit")
+			continue
+		}
+		cacheLookups.Inc("redis", "miss")
+	}
+	return result, nil
 }
 
-func MSet(kv sync.Map) error {
+// MSet 批量写入一级缓存与 Redis，旁路期间仅写入一级缓存；每个 key 的过期时间在 ttl 基础上随机浮动 CacheJitter，ttl 为 0 时永不过期
+func MSet(c context.Context, kv map[string]any, ttl time.Duration) error {
 	pipeline := rdb.Pipeline()
-	kv.Range(func(k, v any) bool {
-		// 缓存 72 小时
-		pipeline.Set(ctx, k.(string), v, 72*time.Hour)
-		return true
-	})
+	for k, v := range kv {
+		expiration := jitter(ttl, config.Conf.CacheJitter, rand.Float64())
+		switch v := v.(type) {
+		case []byte:
+			local.Set(k, string(v), expiration)
+		case string:
+			local.Set(k, v, expiration)
+		}
+		pipeline.Set(c, k, v, expiration)
+	}
 	// 不关注单个命令的执行结果，只关注 pipeline 执行的结果
-	if _, err := pipeline.Exec(ctx); err != nil {
-		log.Error("MSet error: ", err)
+	_, span := trace.Start(c, "redis.mset", "keys", strconv.Itoa(len(kv)))
+	err := redisHealth.do(func() error {
+		_, err := pipeline.Exec(c)
+		return err
+	})
+	span.End(err)
+	if err == ErrUnavailable {
+		return err
+	}
+	if err != nil {
+		log.WithContext(c).Error("MSet error: ", err)
 		return err
 	}
 	return nil
 }
+
+// jitter 将 ttl 按 ratio 随机浮动，random 为 [0, 1) 的随机数，浮动后的 ttl 不低于 1 秒
+func jitter(ttl time.Duration, ratio, random float64) time.Duration {
+	if ttl <= 0 || ratio <= 0 {
+		return ttl
+	}
+	ttl += time.Duration(float64(ttl) * ratio * (random*2 - 1))
+	if ttl < time.Second {
+		return time.Second
+	}
+	return ttl
+}
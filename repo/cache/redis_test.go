@@ -1,12 +1,24 @@
 package cache
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
+// requireRedis Redis 不可用时跳过测试
+func requireRedis(t *testing.T) {
+	c, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := rdb.Ping(c).Err(); err != nil {
+		t.Skipf("redis unavailable: %v", err)
+	}
+}
+
 func TestSet(t *testing.T) {
+	requireRedis(t)
 	msg := []string{"test1", "value1"}
 	err := SetKey(msg[0], msg[1])
 	assert.NoError(t, err)
@@ -14,3 +26,20 @@ func TestSet(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, msg[1], rs)
 }
+
+func TestJitter(t *testing.T) {
+	assert.Equal(t, time.Duration(0), jitter(0, 0.1, 0.5))
+	assert.Equal(t, time.Hour, jitter(time.Hour, 0, 0))
+	assert.Equal(t, 54*time.Minute, jitter(time.Hour, 0.1, 0))
+	assert.Equal(t, time.Hour, jitter(time.Hour, 0.1, 0.5))
+	assert.Equal(t, time.Second, jitter(time.Second, 1, 0))
+}
+
+func TestMGetLocal(t *testing.T) {
+	// Redis 不可用时仍能读到一级缓存中的值
+	_ = MSet(context.Background(), map[string]any{"test_local:1": []byte("value1")}, time.Minute)
+	values, _ := MGet(context.Background(), "test_local:1", "test_local:2")
+	assert.Len(t, values, 2)
+	assert.Equal(t, "value1", values[0])
+	assert.Nil(t, values[1])
+}
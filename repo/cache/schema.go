@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Schema 一类缓存数据的命名空间，key 形如 prefix:v1:id；
+// 值结构不兼容时递增 Version，滚动发布期间新旧版本的副本各自读写自己的 key，互不读到对方的数据
+type Schema struct {
+	Prefix  string
+	Version int
+}
+
+var (
+	schemaMu sync.Mutex
+	schemas  = make(map[string]*Schema)
+	// retired 已不再使用的 key 前缀
+	retired = []string{"net", "net_dt"}
+)
+
+// NewSchema 注册缓存命名空间，同一前缀只能注册一次
+func NewSchema(prefix string, version int) *Schema {
+	schemaMu.Lock()
+	defer schemaMu.Unlock()
+	if _, ok := schemas[prefix]; ok {
+		panic("duplicate cache schema: " + prefix)
+	}
+	s := &Schema{Prefix: prefix, Version: version}
+	schemas[prefix] = s
+	return s
+}
+
+func (s *Schema) Key(id any) string {
+	return fmt.Sprintf("%v:v%d:%v", s.Prefix, s.Version, id)
+}
+
+func (s *Schema) current() string {
+	return fmt.Sprintf("%v:v%d:", s.Prefix, s.Version)
+}
+
+// InvalidateStale 删除旧版本与已弃用前缀的缓存，应在所有副本升级完成后执行
+func InvalidateStale() (int64, error) {
+	schemaMu.Lock()
+	current := make(map[string]string, len(schemas))
+	for prefix, s := range schemas {
+		current[prefix] = s.current()
+	}
+	schemaMu.Unlock()
+
+	var deleted int64
+	for prefix, keep := range current {
+		n, err := deleteMatching(prefix+":*", func(key string) bool { return !strings.HasPrefix(key, keep) })
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+	}
+	for _, prefix := range retired {
+		n, err := deleteMatching(prefix+":*", func(string) bool { return true })
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
+
+// deleteMatching 通过 SCAN 遍历，避免 KEYS 阻塞 redis
+func deleteMatching(pattern string, stale func(key string) bool) (int64, error) {
+	var (
+		cursor  uint64
+		deleted int64
+	)
+	for {
+		var (
+			keys []string
+			next uint64
+		)
+		err := redisHealth.do(func() (err error) {
+			keys, next, err = rdb.Scan(ctx, cursor, pattern, 1000).Result()
+			return err
+		})
+		if err != nil {
+			return deleted, err
+		}
+		batch := make([]string, 0, len(keys))
+		for _, key := range keys {
+			if stale(key) {
+				batch = append(batch, key)
+			}
+		}
+		if len(batch) > 0 {
+			var n int64
+			err := redisHealth.do(func() (err error) {
+				n, err = rdb.Unlink(ctx, batch...).Result()
+				return err
+			})
+			deleted += n
+			if err != nil {
+				return deleted, err
+			}
+		}
+		if cursor = next; cursor == 0 {
+			return deleted, nil
+		}
+	}
+}
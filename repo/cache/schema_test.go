@@ -0,0 +1,12 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaKey(t *testing.T) {
+	assert.Equal(t, "net_song:v4:123", SongSchema.Key(uint(123)))
+	assert.Panics(t, func() { NewSchema(SongSchema.Prefix, 2) })
+}
@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"errors"
+
+	"github.com/ugorji/go/codec"
+
+	"GoMusic/common/models"
+)
+
+// songVersion 歌曲缓存的编码版本，写在数据首字节，结构不兼容时递增
+const songVersion byte = 4
+
+var (
+	msgpack = &codec.MsgpackHandle{}
+
+	// SongSchema 网易云歌曲缓存，key 的版本号与编码版本保持一致
+	SongSchema = NewSchema("net_song", int(songVersion))
+
+	ErrSongVersion = errors.New("unknown cached song version")
+)
+
+// EncodeSong 将歌曲编码为带版本号的 msgpack 数据
+func EncodeSong(song *models.CachedSong) ([]byte, error) {
+	var buf []byte
+	if err := codec.NewEncoderBytes(&buf, msgpack).Encode(song); err != nil {
+		return nil, err
+	}
+	return append([]byte{songVersion}, buf...), nil
+}
+
+// DecodeSong 解码 EncodeSong 的结果，版本号不一致时返回 ErrSongVersion，应视为未命中
+func DecodeSong(data []byte) (*models.CachedSong, error) {
+	if len(data) == 0 || data[0] != songVersion {
+		return nil, ErrSongVersion
+	}
+	song := &models.CachedSong{}
+	if err := codec.NewDecoderBytes(data[1:], msgpack).Decode(song); err != nil {
+		return nil, err
+	}
+	return song, nil
+}
@@ -0,0 +1,26 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/models"
+)
+
+func TestEncodeSong(t *testing.T) {
+	song := &models.CachedSong{Name: "江南", Artists: []string{"林俊杰"}, Duration: 267000, Explicit: true}
+	data, err := EncodeSong(song)
+	assert.NoError(t, err)
+	assert.Equal(t, songVersion, data[0])
+
+	decoded, err := DecodeSong(data)
+	assert.NoError(t, err)
+	assert.Equal(t, song, decoded)
+
+	// 旧版本的字符串缓存与未知版本均视为未命中
+	_, err = DecodeSong([]byte("江南 - 林俊杰"))
+	assert.ErrorIs(t, err, ErrSongVersion)
+	_, err = DecodeSong(nil)
+	assert.ErrorIs(t, err, ErrSongVersion)
+}
@@ -0,0 +1,49 @@
+package db
+
+import (
+	"GoMusic/common/models"
+	"GoMusic/initialize/log"
+)
+
+func ListFollowedArtists(watchId uint) ([]*models.FollowedArtist, error) {
+	var artists []*models.FollowedArtist
+	err := db.Where("watch_id = ?", watchId).Find(&artists).Error
+	if err != nil {
+		log.Errorf("查询数据库失败：%v", err)
+	}
+	return artists, err
+}
+
+// ReplaceFollowedArtists 以新的歌手列表替换订阅关注的歌手
+func ReplaceFollowedArtists(watchId uint, artists []*models.FollowedArtist) error {
+	tx := db.Begin()
+	if err := tx.Unscoped().Where("watch_id = ?", watchId).Delete(&models.FollowedArtist{}).Error; err != nil {
+		tx.Rollback()
+		log.Errorf("数据库删除数据失败：%v", err)
+		return err
+	}
+	if len(artists) > 0 {
+		if err := tx.Create(&artists).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("数据库插入失败：%v", err)
+			return err
+		}
+	}
+	return tx.Commit().Error
+}
+
+func UpdateFollowedArtist(artist *models.FollowedArtist) error {
+	err := db.Save(artist).Error
+	if err != nil {
+		log.Errorf("数据库更新失败：%v", err)
+	}
+	return err
+}
+
+func DeleteFollowedArtists(watchId uint) error {
+	err := db.Unscoped().Where("watch_id = ?", watchId).Delete(&models.FollowedArtist{}).Error
+	if err != nil {
+		log.Errorf("数据库删除数据失败：%v", err)
+	}
+	return err
+}
@@ -0,0 +1,190 @@
+package db
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"GoMusic/common/models"
+	"GoMusic/initialize/log"
+)
+
+// 迁移文件命名为 <版本号>_<说明>.sql，按版本号顺序执行，已发布的文件不应再修改
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+const (
+	migrationLock        = "gomusic_migrate"
+	migrationLockTimeout = 60 // 秒
+)
+
+// SchemaMigration 已执行的迁移
+type SchemaMigration struct {
+	Version int    `gorm:"column:version;primaryKey;autoIncrement:false"`
+	Name    string `gorm:"column:name;type:varchar(256)"`
+}
+
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// Migrate 执行尚未执行的迁移，返回本次执行的版本号；多副本同时启动时通过 MySQL 命名锁串行执行
+func Migrate() ([]int, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	var applied []int
+	err = db.Connection(func(conn *gorm.DB) error {
+		var locked int
+		if err := conn.Raw("SELECT GET_LOCK(?, ?)", migrationLock, migrationLockTimeout).Scan(&locked).Error; err != nil {
+			return err
+		}
+		if locked != 1 {
+			return fmt.Errorf("等待迁移锁超时")
+		}
+		defer conn.Exec("SELECT RELEASE_LOCK(?)", migrationLock)
+
+		if err := adoptLegacySchema(conn); err != nil {
+			return err
+		}
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range migrations {
+			if _, ok := done[m.version]; ok {
+				continue
+			}
+			// MySQL 的 DDL 会隐式提交，无法放在事务中，失败时需人工处理后重新执行
+			for _, stmt := range splitStatements(m.sql) {
+				if err := conn.Exec(stmt).Error; err != nil {
+					return fmt.Errorf("迁移 %04d_%v 失败：%w", m.version, m.name, err)
+				}
+			}
+			if err := conn.Create(&SchemaMigration{Version: m.version, Name: m.name}).Error; err != nil {
+				return err
+			}
+			log.Infof("已执行数据库迁移：%04d_%v", m.version, m.name)
+			applied = append(applied, m.version)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Errorf("数据库迁移失败：%v", err)
+	}
+	return applied, err
+}
+
+// MigrationVersion 当前数据库已执行的最新迁移版本
+func MigrationVersion() (int, error) {
+	var version int
+	err := db.Model(&SchemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	return version, err
+}
+
+// adoptLegacySchema 引入迁移前的数据库由 AutoMigrate 建表，先补齐至基线结构并记为已执行基线
+func adoptLegacySchema(conn *gorm.DB) error {
+	migrator := conn.Migrator()
+	if migrator.HasTable(&SchemaMigration{}) {
+		return nil
+	}
+	legacy := migrator.HasTable(&models.NetEasySong{})
+	if err := migrator.CreateTable(&SchemaMigration{}); err != nil {
+		return err
+	}
+	if !legacy {
+		return nil
+	}
+	log.Infof("检测到未使用迁移的旧数据库，按基线结构补齐")
+	if err := conn.AutoMigrate(&baselineNetEasySong{}, &models.WatchedPlaylist{}, &models.FollowedArtist{}); err != nil {
+		return err
+	}
+	if err := MigrateNameField(conn); err != nil {
+		return err
+	}
+	return conn.Create(&SchemaMigration{Version: 1, Name: "baseline"}).Error
+}
+
+// baselineNetEasySong 基线版本的歌曲表结构，旧数据库只补齐至基线，之后新增的字段由迁移文件添加
+type baselineNetEasySong struct {
+	gorm.Model
+	Id       uint   `gorm:"column:id"`
+	Name     string `gorm:"column:name;type:varchar(512);unique:true"`
+	Exist    byte   `gorm:"column:exist;default:1"`
+	Duration uint   `gorm:"column:duration;default:0"`
+}
+
+func (baselineNetEasySong) TableName() string {
+	return "net_easy_songs"
+}
+
+func appliedVersions(conn *gorm.DB) (map[int]struct{}, error) {
+	var versions []int
+	if err := conn.Model(&SchemaMigration{}).Pluck("version", &versions).Error; err != nil {
+		return nil, err
+	}
+	done := make(map[int]struct{}, len(versions))
+	for _, v := range versions {
+		done[v] = struct{}{}
+	}
+	return done, nil
+}
+
+func loadMigrations() ([]*migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	migrations := make([]*migration, 0, len(entries))
+	seen := make(map[int]string, len(entries))
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".sql")
+		version, desc, ok := strings.Cut(name, "_")
+		v, err := strconv.Atoi(version)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("无效的迁移文件名：%v", entry.Name())
+		}
+		if other, ok := seen[v]; ok {
+			return nil, fmt.Errorf("迁移版本 %d 重复：%v、%v", v, other, entry.Name())
+		}
+		seen[v] = entry.Name()
+		content, err := migrationFiles.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, &migration{version: v, name: desc, sql: string(content)})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+	return migrations, nil
+}
+
+// splitStatements 按行尾分号拆分语句并去除注释，驱动默认不支持一次执行多条语句
+func splitStatements(sql string) []string {
+	statements := make([]string, 0)
+	builder := strings.Builder{}
+	for _, line := range strings.Split(sql, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		builder.WriteString(line)
+		builder.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(builder.String()), ";"))
+			builder.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(builder.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
@@ -0,0 +1,22 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrations)
+	for i, m := range migrations {
+		assert.Equal(t, i+1, m.version)
+		assert.NotEmpty(t, splitStatements(m.sql))
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	sql := "-- 注释\nCREATE TABLE a (\n  id int\n);\n\nALTER TABLE a ADD b int;\nSELECT 1"
+	assert.Equal(t, []string{"CREATE TABLE a (\n  id int\n)", "ALTER TABLE a ADD b int", "SELECT 1"}, splitStatements(sql))
+}
@@ -0,0 +1,42 @@
+-- 引入迁移前由 gorm AutoMigrate 创建的表结构
+CREATE TABLE IF NOT EXISTS `net_easy_songs` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `name` varchar(512) UNIQUE,
+  `exist` tinyint unsigned DEFAULT 1,
+  `duration` bigint unsigned DEFAULT 0,
+  PRIMARY KEY (`id`),
+  INDEX `idx_net_easy_songs_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `watched_playlists` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `token` varchar(64),
+  `link` varchar(512),
+  `webhook` varchar(512),
+  `email` varchar(256),
+  `unavailable` text,
+  `checked_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_watched_playlists_token` (`token`),
+  INDEX `idx_watched_playlists_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `followed_artists` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `watch_id` bigint unsigned,
+  `artist_id` bigint,
+  `name` varchar(256),
+  `latest_publish_time` bigint,
+  PRIMARY KEY (`id`),
+  INDEX `idx_followed_artists_watch_id` (`watch_id`),
+  INDEX `idx_followed_artists_deleted_at` (`deleted_at`)
+);
@@ -0,0 +1,13 @@
+-- 短链接：保存歌单链接与导出参数
+CREATE TABLE IF NOT EXISTS `short_links` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `code` varchar(16),
+  `link` varchar(512),
+  `options` text,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_short_links_code` (`code`),
+  INDEX `idx_short_links_deleted_at` (`deleted_at`)
+);
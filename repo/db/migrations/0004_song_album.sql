@@ -0,0 +1,2 @@
+-- 歌曲所属专辑，NULL 表示记录早于该字段，读取时视为未命中并重新获取
+ALTER TABLE `net_easy_songs` ADD COLUMN `album` varchar(512) NULL;
@@ -0,0 +1,4 @@
+-- 歌曲的原始歌名与歌手（JSON 数组），name 列为格式化后的“歌名 - 歌手”，无法准确还原；
+-- NULL 表示记录早于该字段，读取时视为未命中并重新获取
+ALTER TABLE `net_easy_songs` ADD COLUMN `raw_name` varchar(512) NULL;
+ALTER TABLE `net_easy_songs` ADD COLUMN `artists` text NULL;
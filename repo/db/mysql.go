@@ -1,32 +1,44 @@
 package db
 
 import (
+	"context"
+	stdlog "log"
+	"os"
+	"time"
+
 	//_ "github.com/go-sql-driver/mysql"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
 
 	"GoMusic/common/models"
+	"GoMusic/initialize/config"
 	"GoMusic/initialize/log"
 )
 
 var db *gorm.DB
 
-func init() {
-	dsn := "root:12345678@tcp(127.0.0.1:3306)/go_music?charset=utf8mb4&parseTime=True&loc=Local"
-	open, err := gorm.Open(mysql.Open(dsn))
+// Open 连接数据库，需在调用其他函数前执行；启动时按配置执行数据库迁移，
+// 关闭后需通过 GoMusic migrate 手动执行
+func Open() error {
+	// 日志输出至 stderr，避免干扰命令行输出
+	open, err := gorm.Open(mysql.Open(config.Conf.MySQLDSN), &gorm.Config{
+		Logger: logger.New(stdlog.New(os.Stderr, "\r\n", stdlog.LstdFlags), logger.Config{
+			SlowThreshold: 200 * time.Millisecond,
+			LogLevel:      logger.Warn,
+			Colorful:      true,
+		}),
+	})
 	if err != nil {
 		log.Errorf("数据库连接失败：%v", err)
-		panic(err)
+		return err
 	}
 	db = open
-	// 自动创建表
-	db.AutoMigrate(&models.NetEasySong{})
-
-	// 调用自定义迁移函数修改表结构
-	if err := MigrateNameField(db); err != nil {
-		log.Errorf("failed to migrate database: %v", err)
+	if config.Conf.AutoMigrate {
+		_, _ = Migrate()
 	}
+	return nil
 }
 
 func MigrateNameField(db *gorm.DB) error {
@@ -35,16 +47,29 @@ func MigrateNameField(db *gorm.DB) error {
 }
 
 func BatchGetSongById(ids []uint) (map[uint]string, error) {
+	netEasySongs, err := BatchGetSongs(ids)
+	if err != nil {
+		return nil, err
+	}
+	// 歌曲id:歌曲信息
+	netEasySongMap := make(map[uint]string)
+	for k, v := range netEasySongs {
+		netEasySongMap[k] = v.Name
+	}
+	return netEasySongMap, nil
+}
+
+// BatchGetSongs 批量查询歌曲，返回 歌曲id:歌曲
+func BatchGetSongs(ids []uint) (map[uint]*models.NetEasySong, error) {
 	var netEasySongs []*models.NetEasySong
 	err := db.Where("id in ?", ids).Find(&netEasySongs).Error
 	if err != nil {
 		log.Errorf("查询数据库失败：%v", err)
 		return nil, err
 	}
-	// 歌曲id:歌曲信息
-	netEasySongMap := make(map[uint]string)
+	netEasySongMap := make(map[uint]*models.NetEasySong, len(netEasySongs))
 	for _, v := range netEasySongs {
-		netEasySongMap[v.Id] = v.Name
+		netEasySongMap[v.Id] = v
 	}
 	return netEasySongMap, nil
 }
@@ -67,3 +92,34 @@ func BatchDelSong(ids []int) error {
 	}
 	return err
 }
+
+// PurgeSongs 物理删除 before 之前未更新的歌曲，并在总行数超过 maxRows 时删除最久未更新的部分。
+// before 为零值或 maxRows 为 0 时跳过对应规则，返回删除的行数
+func PurgeSongs(ctx context.Context, before time.Time, maxRows int) (int64, error) {
+	tx := db.WithContext(ctx)
+	var purged int64
+	if !before.IsZero() {
+		rs := tx.Unscoped().Where("updated_at < ? OR deleted_at IS NOT NULL", before).Delete(&models.NetEasySong{})
+		if rs.Error != nil {
+			log.Errorf("数据库清理过期数据失败：%v", rs.Error)
+			return purged, rs.Error
+		}
+		purged += rs.RowsAffected
+	}
+	if maxRows > 0 {
+		var count int64
+		if err := tx.Unscoped().Model(&models.NetEasySong{}).Count(&count).Error; err != nil {
+			log.Errorf("数据库统计数据失败：%v", err)
+			return purged, err
+		}
+		if over := int(count) - maxRows; over > 0 {
+			rs := tx.Unscoped().Order("updated_at").Limit(over).Delete(&models.NetEasySong{})
+			if rs.Error != nil {
+				log.Errorf("数据库清理超额数据失败：%v", rs.Error)
+				return purged, rs.Error
+			}
+			purged += rs.RowsAffected
+		}
+	}
+	return purged, nil
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -8,7 +9,22 @@ import (
 	"GoMusic/common/models"
 )
 
+var (
+	openOnce sync.Once
+	openErr  error
+)
+
+// requireDB 连接数据库，未配置 MySQL 时跳过需要数据库的测试
+func requireDB(t *testing.T) {
+	t.Helper()
+	openOnce.Do(func() { openErr = Open() })
+	if openErr != nil {
+		t.Skipf("mysql unavailable: %v", openErr)
+	}
+}
+
 func TestBatchDelAndSet(t *testing.T) {
+	requireDB(t)
 	var songs []*models.NetEasySong
 	songs = append(songs, &models.NetEasySong{
 		Id:   5241457,
@@ -29,6 +45,7 @@ func TestBatchDelAndSet(t *testing.T) {
 }
 
 func TestBatchGet(t *testing.T) {
+	requireDB(t)
 	songs, err := BatchGetSongById([]uint{5241457, 1935948203})
 	assert.NoError(t, err)
 	assert.Equal(t, "小酒窝(Live) - 蔡卓妍 / 林俊杰", songs[5241457])
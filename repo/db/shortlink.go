@@ -0,0 +1,28 @@
+package db
+
+import (
+	"GoMusic/common/models"
+	"GoMusic/initialize/log"
+)
+
+func CreateShortLink(shortLink *models.ShortLink) error {
+	err := db.Create(shortLink).Error
+	if err != nil {
+		log.Errorf("数据库插入失败：%v", err)
+	}
+	return err
+}
+
+// GetShortLink 根据 code 查询短链接，不存在时返回 nil
+func GetShortLink(code string) (*models.ShortLink, error) {
+	var shortLinks []*models.ShortLink
+	err := db.Where("code = ?", code).Limit(1).Find(&shortLinks).Error
+	if err != nil {
+		log.Errorf("查询数据库失败：%v", err)
+		return nil, err
+	}
+	if len(shortLinks) == 0 {
+		return nil, nil
+	}
+	return shortLinks[0], nil
+}
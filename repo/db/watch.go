@@ -0,0 +1,53 @@
+package db
+
+import (
+	"GoMusic/common/models"
+	"GoMusic/initialize/log"
+)
+
+func CreateWatch(watch *models.WatchedPlaylist) error {
+	err := db.Create(watch).Error
+	if err != nil {
+		log.Errorf("数据库插入失败：%v", err)
+	}
+	return err
+}
+
+// GetWatch 根据 token 查询订阅，不存在时返回 nil
+func GetWatch(token string) (*models.WatchedPlaylist, error) {
+	var watches []*models.WatchedPlaylist
+	err := db.Where("token = ?", token).Limit(1).Find(&watches).Error
+	if err != nil {
+		log.Errorf("查询数据库失败：%v", err)
+		return nil, err
+	}
+	if len(watches) == 0 {
+		return nil, nil
+	}
+	return watches[0], nil
+}
+
+func ListWatches() ([]*models.WatchedPlaylist, error) {
+	var watches []*models.WatchedPlaylist
+	err := db.Find(&watches).Error
+	if err != nil {
+		log.Errorf("查询数据库失败：%v", err)
+	}
+	return watches, err
+}
+
+func UpdateWatch(watch *models.WatchedPlaylist) error {
+	err := db.Save(watch).Error
+	if err != nil {
+		log.Errorf("数据库更新失败：%v", err)
+	}
+	return err
+}
+
+func DeleteWatch(token string) (bool, error) {
+	rs := db.Unscoped().Where("token = ?", token).Delete(&models.WatchedPlaylist{})
+	if rs.Error != nil {
+		log.Errorf("数据库删除数据失败：%v", rs.Error)
+	}
+	return rs.RowsAffected > 0, rs.Error
+}
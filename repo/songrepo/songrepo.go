@@ -0,0 +1,152 @@
+package songrepo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"GoMusic/common/format"
+	"GoMusic/common/models"
+	"GoMusic/initialize/config"
+	"GoMusic/initialize/log"
+	"GoMusic/initialize/trace"
+	"GoMusic/repo/cache"
+	"GoMusic/repo/db"
+)
+
+// Fetcher 从上游平台批量获取歌曲，未返回的歌曲视为已下架
+type Fetcher func(ctx context.Context, ids []uint) (map[uint]*models.CachedSong, error)
+
+// Source 平台的歌曲来源
+type Source struct {
+	Schema *cache.Schema
+	Fetch  Fetcher
+	// Persist 是否写入数据库，目前仅网易云有歌曲表
+	Persist bool
+}
+
+var (
+	mu      sync.RWMutex
+	sources = make(map[string]*Source)
+)
+
+// Register 注册平台的歌曲来源，通常在 init 中调用
+func Register(platform string, source *Source) {
+	mu.Lock()
+	defer mu.Unlock()
+	sources[platform] = source
+}
+
+// GetSongs 依次从缓存、数据库与上游平台查询歌曲，并回写未命中的层级；
+// 缓存与数据库失败时不退出，上游失败时返回错误。已下架的歌曲不在结果中
+func GetSongs(ctx context.Context, platform string, ids []uint) (map[uint]*models.CachedSong, error) {
+	mu.RLock()
+	source, ok := sources[platform]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown song source: %v", platform)
+	}
+
+	result := make(map[uint]*models.CachedSong, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+	missSongs := make(map[uint]*models.CachedSong) // 待写入缓存的歌曲
+
+	// 1、尝试获取缓存
+	keys := make([]string, 0, len(ids))
+	for _, v := range ids {
+		keys = append(keys, source.Schema.Key(v))
+	}
+	cacheResult, _ := cache.MGet(ctx, keys...)
+	missCacheKey := make([]uint, 0)
+	for k, v := range ids {
+		if len(cacheResult) == len(keys) && cacheResult[k] != nil {
+			// 版本不一致的缓存视为未命中，以便刷新
+			if song, err := cache.DecodeSong([]byte(cacheResult[k].(string))); err == nil {
+				result[v] = song
+				continue
+			}
+		}
+		missCacheKey = append(missCacheKey, v)
+	}
+	if len(missCacheKey) == 0 {
+		return result, nil
+	}
+
+	// 2、查询数据库
+	missDBKey := missCacheKey
+	if source.Persist {
+		_, span := trace.Start(ctx, "db.batch_get_songs", "ids", strconv.Itoa(len(missCacheKey)))
+		dbResultMap, err := db.BatchGetSongs(missCacheKey)
+		span.End(err)
+		missDBKey = make([]uint, 0)
+		for _, v := range missCacheKey {
+			if song := dbSong(dbResultMap[v]); song != nil {
+				result[v] = song
+				missSongs[v] = song
+				continue
+			}
+			missDBKey = append(missDBKey, v)
+		}
+	}
+
+	// 3、查询上游平台并写数据库
+	if len(missDBKey) > 0 {
+		songs, err := source.Fetch(ctx, missDBKey)
+		if err != nil {
+			return nil, err
+		}
+		missDbData := make([]*models.NetEasySong, 0, len(songs))
+		for id, song := range songs {
+			result[id] = song
+			missSongs[id] = song
+			missDbData = append(missDbData, newDBSong(id, song))
+		}
+		if source.Persist {
+			_, span := trace.Start(ctx, "db.batch_insert_song", "songs", strconv.Itoa(len(missDbData)))
+			span.End(db.BatchInsertSong(missDbData))
+		}
+	}
+
+	// 写缓存
+	missKeyCacheMap := make(map[string]any, len(missSongs))
+	for id, song := range missSongs {
+		data, err := cache.EncodeSong(song)
+		if err != nil {
+			log.WithContext(ctx).Errorf("fail to encode song %v: %v", id, err)
+			continue
+		}
+		missKeyCacheMap[source.Schema.Key(id)] = data
+	}
+	_ = cache.MSet(ctx, missKeyCacheMap, config.Conf.SongCacheTTL(platform))
+	return result, nil
+}
+
+// dbSong 读取数据库中的歌曲，缺少时长、露骨内容标记、专辑或原始歌名与歌手的旧数据视为未命中
+func dbSong(val *models.NetEasySong) *models.CachedSong {
+	if val == nil || val.Duration == 0 || val.Explicit == nil || val.Album == nil || val.RawName == nil || val.Artists == nil {
+		return nil
+	}
+	song := &models.CachedSong{Name: *val.RawName, Duration: int(val.Duration), Explicit: *val.Explicit, Album: *val.Album}
+	if err := json.Unmarshal([]byte(*val.Artists), &song.Artists); err != nil {
+		return nil
+	}
+	return song
+}
+
+func newDBSong(id uint, song *models.CachedSong) *models.NetEasySong {
+	explicit, album, name := song.Explicit, song.Album, song.Name
+	artists := song.Artists
+	if artists == nil {
+		artists = make([]string, 0)
+	}
+	data, _ := json.Marshal(artists)
+	encoded := string(data)
+	return &models.NetEasySong{
+		Id: id, Name: format.Song(song), Duration: uint(song.Duration),
+		Explicit: &explicit, Album: &album, RawName: &name, Artists: &encoded,
+	}
+}
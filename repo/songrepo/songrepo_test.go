@@ -0,0 +1,56 @@
+package songrepo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"GoMusic/common/models"
+	"GoMusic/repo/cache"
+)
+
+func TestGetSongs(t *testing.T) {
+	fetched := 0
+	Register("test", &Source{
+		Schema: cache.NewSchema("test_song", 1),
+		Fetch: func(_ context.Context, ids []uint) (map[uint]*models.CachedSong, error) {
+			fetched += len(ids)
+			songs := make(map[uint]*models.CachedSong)
+			for _, v := range ids {
+				if v != 3 { // 3 已下架
+					songs[v] = &models.CachedSong{Name: "song", Artists: []string{"artist"}, Duration: int(v)}
+				}
+			}
+			return songs, nil
+		},
+	})
+
+	songs, err := GetSongs(context.Background(), "test", []uint{1, 2, 3})
+	assert.NoError(t, err)
+	assert.Len(t, songs, 2)
+	assert.Equal(t, 2, songs[2].Duration)
+
+	// 第二次命中缓存，仅重新请求已下架的歌曲
+	fetched = 0
+	songs, err = GetSongs(context.Background(), "test", []uint{1, 2, 3})
+	assert.NoError(t, err)
+	assert.Len(t, songs, 2)
+	assert.Equal(t, 1, fetched)
+
+	_, err = GetSongs(context.Background(), "unknown", []uint{1})
+	assert.Error(t, err)
+}
+
+func TestDBSong(t *testing.T) {
+	song := &models.CachedSong{Name: "A - B", Artists: []string{"C", "D/E"}, Duration: 200, Album: "F"}
+	got := dbSong(newDBSong(1, song))
+	// 原始字段原样读回，不受格式化文本中的分隔符影响
+	assert.Equal(t, song, got)
+
+	// 早于原始字段的旧数据视为未命中
+	old := newDBSong(1, song)
+	old.RawName, old.Artists = nil, nil
+	assert.Nil(t, dbSong(old))
+	assert.Nil(t, dbSong(nil))
+}